@@ -16,6 +16,7 @@ package function
 
 import (
 	"fmt"
+	"reflect"
 
 	"github.com/lf-edge/ekuiper/v2/pkg/cast"
 )
@@ -108,6 +109,23 @@ func getCount(s []interface{}) int {
 	return c
 }
 
+// getRowCount returns the number of rows whose values of all the columns are not nil
+func getRowCount(cols []interface{}) int {
+	c := 0
+	rows, _ := cols[0].([]interface{})
+outer:
+	for j := range rows {
+		for _, col := range cols {
+			vals, ok := col.([]interface{})
+			if !ok || j >= len(vals) || vals[j] == nil {
+				continue outer
+			}
+		}
+		c++
+	}
+	return c
+}
+
 func getFirstValidArg(s []interface{}) interface{} {
 	for _, v := range s {
 		if v != nil {
@@ -133,6 +151,36 @@ func sliceIntTotal(s []interface{}) (int64, error) {
 	return total, nil
 }
 
+// sliceIntBitwise accumulates the integers by the bitwise op. The null values are ignored and nil is returned if
+// there is no valid value. The integral float like 3.0 decoded from JSON is accepted.
+func sliceIntBitwise(s []interface{}, op func(a, b int64) int64) (interface{}, error) {
+	var (
+		result int64
+		found  bool
+	)
+	for _, v := range s {
+		if v == nil {
+			continue
+		}
+		vi, err := cast.ToInt64(v, cast.CONVERT_SAMEKIND)
+		if err != nil {
+			return nil, fmt.Errorf("requires int but found %[1]T(%[1]v)", v)
+		}
+		if f, ok := v.(float64); ok && f != float64(vi) {
+			return nil, fmt.Errorf("requires int but found %[1]T(%[1]v)", v)
+		}
+		if found {
+			result = op(result, vi)
+		} else {
+			result, found = vi, true
+		}
+	}
+	if !found {
+		return nil, nil
+	}
+	return result, nil
+}
+
 func sliceFloatTotal(s []interface{}) (float64, error) {
 	var total float64
 	for _, v := range s {
@@ -270,3 +318,163 @@ func dedup(r []interface{}, col []interface{}, all bool) (interface{}, error) {
 		return result, nil
 	}
 }
+
+// argExtreme returns the index of the max or min numeric element in the array. Nil elements are ignored.
+// If there are multiple extreme elements, the index of the first one is returned.
+func argExtreme(arr []interface{}, isMax bool) (interface{}, error) {
+	index := -1
+	var extreme float64
+	for i, v := range arr {
+		if v == nil {
+			continue
+		}
+		f, err := cast.ToFloat64(v, cast.CONVERT_SAMEKIND)
+		if err != nil {
+			return nil, fmt.Errorf("requires numeric element but found %[2]T(%[2]v) at index %[1]d", i, v)
+		}
+		if index < 0 || (isMax && f > extreme) || (!isMax && f < extreme) {
+			index = i
+			extreme = f
+		}
+	}
+	if index < 0 {
+		return nil, nil
+	}
+	return index, nil
+}
+
+// arrayDiff returns the differences between the consecutive elements. The difference is int64 if both elements are
+// integers, otherwise float64. The difference is nil if any of the elements is nil.
+func arrayDiff(arr []interface{}) ([]interface{}, error) {
+	if len(arr) < 2 {
+		return []interface{}{}, nil
+	}
+	for i, v := range arr {
+		if v == nil {
+			continue
+		}
+		if _, err := cast.ToFloat64(v, cast.CONVERT_SAMEKIND); err != nil {
+			return nil, fmt.Errorf("requires numeric element but found %[2]T(%[2]v) at index %[1]d", i, v)
+		}
+	}
+	result := make([]interface{}, len(arr)-1)
+	for i := 1; i < len(arr); i++ {
+		prev, cur := arr[i-1], arr[i]
+		if prev == nil || cur == nil {
+			continue
+		}
+		if isIntValue(prev) && isIntValue(cur) {
+			p, _ := cast.ToInt64(prev, cast.CONVERT_SAMEKIND)
+			c, _ := cast.ToInt64(cur, cast.CONVERT_SAMEKIND)
+			result[i-1] = c - p
+		} else {
+			p, _ := cast.ToFloat64(prev, cast.CONVERT_SAMEKIND)
+			c, _ := cast.ToFloat64(cur, cast.CONVERT_SAMEKIND)
+			result[i-1] = c - p
+		}
+	}
+	return result, nil
+}
+
+func isIntValue(v interface{}) bool {
+	switch v.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return true
+	default:
+		return false
+	}
+}
+
+// arraySetFilter returns the distinct elements of arr1 which are (if contained is true) or are not in arr2 by deep
+// equality. The order of arr1 is preserved.
+func arraySetFilter(arr1, arr2 []interface{}, contained bool) []interface{} {
+	result := make([]interface{}, 0, len(arr1))
+	for _, v := range arr1 {
+		if deepContains(arr2, v) == contained && !deepContains(result, v) {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+func deepContains(arr []interface{}, v interface{}) bool {
+	for _, a := range arr {
+		if reflect.DeepEqual(a, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// EqualFunc evaluates the = operator of the SQL
+type EqualFunc func(a, b interface{}) bool
+
+// sqlEqual is the comparison engine of the SQL. The engine lives in the xsql package which depends on this package,
+// so it is registered by xsql. valueEqual is only used when the functions run without xsql.
+var sqlEqual EqualFunc = valueEqual
+
+// RegisterEqualFunc sets the comparison engine for the functions which match values like the = operator
+func RegisterEqualFunc(f EqualFunc) {
+	sqlEqual = f
+}
+
+// valueEqual compares the values like the = operator. The numbers are compared by value regardless of the int or
+// float types and the other values are compared deeply.
+func valueEqual(a, b interface{}) bool {
+	if a == nil || b == nil {
+		return false
+	}
+	if isNumber(a) && isNumber(b) {
+		if ai, err := cast.ToInt64(a, cast.STRICT); err == nil {
+			if bi, err := cast.ToInt64(b, cast.STRICT); err == nil {
+				return ai == bi
+			}
+		}
+		af, _ := cast.ToFloat64(a, cast.CONVERT_SAMEKIND)
+		bf, _ := cast.ToFloat64(b, cast.CONVERT_SAMEKIND)
+		return af == bf
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func isNumber(v interface{}) bool {
+	switch v.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return true
+	}
+	return false
+}
+
+// toFloat64Values converts the numeric values to float64 and ignores the nil values
+func toFloat64Values(arr []interface{}) ([]float64, error) {
+	result := make([]float64, 0, len(arr))
+	for _, v := range arr {
+		if v == nil {
+			continue
+		}
+		if !isNumber(v) {
+			return nil, fmt.Errorf("requires float64 but found %[1]T(%[1]v)", v)
+		}
+		f, _ := cast.ToFloat64(v, cast.CONVERT_SAMEKIND)
+		result = append(result, f)
+	}
+	return result, nil
+}
+
+// toInt64Values converts the values to int64 and ignores the nil values. It returns false if any value is not int.
+func toInt64Values(arr []interface{}) ([]int64, bool) {
+	result := make([]int64, 0, len(arr))
+	for _, v := range arr {
+		switch vt := v.(type) {
+		case nil:
+			continue
+		case int:
+			result = append(result, int64(vt))
+		case int64:
+			result = append(result, vt)
+		default:
+			return nil, false
+		}
+	}
+	return result, true
+}
@@ -15,8 +15,12 @@
 package function
 
 import (
+	"errors"
 	"fmt"
+	"math"
+	"reflect"
 	"sort"
+	"time"
 
 	"github.com/lf-edge/ekuiper/contract/v2/api"
 	"github.com/montanaflynn/stats"
@@ -30,25 +34,76 @@ func registerAggFunc() {
 		fType: ast.FuncTypeAgg,
 		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
 			arg0 := args[0].([]interface{})
-			if len(arg0) < 1 {
-				return int64(0), true
+			f64s, err := toFloat64Values(arg0)
+			if err != nil {
+				return err, false
 			}
-			switch arg0[0].(type) {
-			case float64:
-				f64s, err := cast.ToFloat64Slice(arg0, cast.CONVERT_SAMEKIND, cast.IGNORE_NIL)
-				if err != nil {
-					return err, false
-				}
-				return median(f64s), true
-			case int64, int:
-				i64s, err := cast.ToInt64Slice(arg0, cast.CONVERT_SAMEKIND)
-				if err != nil {
-					return err, false
-				}
+			if len(f64s) == 0 {
+				return nil, true
+			}
+			// keep the int type if all values are int
+			if i64s, ok := toInt64Values(arg0); ok {
 				return median(i64s), true
+			}
+			return median(f64s), true
+		},
+		val:   ValidateOneNumberArg,
+		check: returnNilIfHasAnyNil,
+	}
+	builtins["percentile"] = builtinFunc{
+		fType: ast.FuncTypeAgg,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			arg0 := args[0].([]interface{})
+			arg1 := args[1].([]interface{})
+			f64s, err := toFloat64Values(arg0)
+			if err != nil {
+				return err, false
+			}
+			if len(arg1) == 0 {
+				return nil, true
+			}
+			v1 := getFirstValidArg(arg1)
+			p, err := cast.ToFloat64(v1, cast.CONVERT_SAMEKIND)
+			if err != nil {
+				return fmt.Errorf("the second parameter requires float64 but found %[1]T(%[1]v)", v1), false
+			}
+			if p < 0 || p > 1 {
+				return fmt.Errorf("the percentile must be between 0 and 1 but found %v", p), false
+			}
+			return percentileCont(f64s, p), true
+		},
+		val: func(ctx api.FunctionContext, args []ast.Expr) error {
+			if err := ValidateTwoNumberArg(ctx, args); err != nil {
+				return err
+			}
+			var p float64
+			switch a := args[1].(type) {
+			case *ast.NumberLiteral:
+				p = a.Val
+			case *ast.IntegerLiteral:
+				p = float64(a.Val)
 			default:
-				return fmt.Errorf("%v should be number", arg0[0]), false
+				return nil
 			}
+			if p < 0 || p > 1 {
+				return fmt.Errorf("the percentile must be between 0 and 1 but found %v", p)
+			}
+			return nil
+		},
+		check: returnNilIfHasAnyNil,
+	}
+	builtins["iqr"] = builtinFunc{
+		fType: ast.FuncTypeAgg,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			f64s, err := toFloat64Values(args[0].([]interface{}))
+			if err != nil {
+				return err, false
+			}
+			// The quartiles are interpolated between the closest ranks, which needs at least two values to get a spread
+			if len(f64s) < 2 {
+				return nil, true
+			}
+			return percentileCont(f64s, 0.75).(float64) - percentileCont(f64s, 0.25).(float64), true
 		},
 		val:   ValidateOneNumberArg,
 		check: returnNilIfHasAnyNil,
@@ -88,10 +143,22 @@ func registerAggFunc() {
 		fType: ast.FuncTypeAgg,
 		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
 			arg0 := args[0].([]interface{})
+			if len(args) > 1 {
+				return getRowCount(args), true
+			}
 			return getCount(arg0), true
 		},
-		val:   ValidateOneArg,
-		check: returnNilIfHasAnyNil,
+		// Multiple arguments are only allowed with DISTINCT which is checked by the parser
+		val: func(_ api.FunctionContext, args []ast.Expr) error {
+			return ValidateAtLeast(1, len(args))
+		},
+		check: func(args []interface{}) (interface{}, bool) {
+			// no row to count such as all rows are filtered out by the FILTER clause
+			if arr, ok := args[0].([]interface{}); ok && len(arr) == 0 {
+				return 0, true
+			}
+			return returnNilIfHasAnyNil(args)
+		},
 	}
 	builtins["max"] = builtinFunc{
 		fType: ast.FuncTypeAgg,
@@ -141,6 +208,24 @@ func registerAggFunc() {
 		val:   ValidateOneNumberArg,
 		check: returnNilIfHasAnyNil,
 	}
+	builtins["bit_and"] = builtinFunc{
+		fType: ast.FuncTypeAgg,
+		exec:  bitwiseAggExec(func(a, b int64) int64 { return a & b }),
+		val:   ValidateOneNumberArg,
+		check: returnNilIfHasAnyNil,
+	}
+	builtins["bit_or"] = builtinFunc{
+		fType: ast.FuncTypeAgg,
+		exec:  bitwiseAggExec(func(a, b int64) int64 { return a | b }),
+		val:   ValidateOneNumberArg,
+		check: returnNilIfHasAnyNil,
+	}
+	builtins["bit_xor"] = builtinFunc{
+		fType: ast.FuncTypeAgg,
+		exec:  bitwiseAggExec(func(a, b int64) int64 { return a ^ b }),
+		val:   ValidateOneNumberArg,
+		check: returnNilIfHasAnyNil,
+	}
 	builtins["collect"] = builtinFunc{
 		fType: ast.FuncTypeAgg,
 		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
@@ -151,6 +236,29 @@ func registerAggFunc() {
 		},
 		val: ValidateOneArg,
 	}
+	builtins["array_agg"] = builtins["collect"] // Synonym for COLLECT.
+	builtins["collect_concat"] = builtinFunc{
+		fType: ast.FuncTypeAgg,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			arg0 := args[0].([]interface{})
+			result := make([]interface{}, 0, len(arg0))
+			for _, ele := range arg0 {
+				if ele == nil {
+					continue
+				}
+				v := reflect.ValueOf(ele)
+				if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+					return fmt.Errorf("requires array but found %[1]T(%[1]v)", ele), false
+				}
+				for i := 0; i < v.Len(); i++ {
+					result = append(result, v.Index(i).Interface())
+				}
+			}
+			return result, true
+		},
+		val:   ValidateOneArg,
+		check: returnNilIfHasAnyNil,
+	}
 	builtins["merge_agg"] = builtinFunc{
 		fType: ast.FuncTypeAgg,
 		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
@@ -179,6 +287,12 @@ func registerAggFunc() {
 			v1, ok1 := args[0].([]interface{})
 			v2, ok2 := args[1].([]interface{})
 			v3a, ok3 := args[2].([]interface{})
+			// the key can be an expression which may fail for some rows
+			for _, k := range v2 {
+				if e, ok := k.(error); ok {
+					return e, false
+				}
+			}
 
 			if ok1 && ok2 && ok3 && len(v3a) > 0 {
 				v3, ok4 := getFirstValidArg(v3a).(bool)
@@ -295,6 +409,265 @@ func registerAggFunc() {
 		val:   ValidateOneNumberArg,
 		check: returnNilIfHasAnyNil,
 	}
+	builtins["regr_slope"] = builtinFunc{
+		fType: ast.FuncTypeAgg,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			slope, _, err := linearRegression(args[0].([]interface{}), args[1].([]interface{}))
+			if err != nil {
+				return err, false
+			}
+			return slope, true
+		},
+		val:   ValidateTwoNumberArg,
+		check: returnNilIfHasAnyNil,
+	}
+	builtins["regr_intercept"] = builtinFunc{
+		fType: ast.FuncTypeAgg,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			_, intercept, err := linearRegression(args[0].([]interface{}), args[1].([]interface{}))
+			if err != nil {
+				return err, false
+			}
+			return intercept, true
+		},
+		val:   ValidateTwoNumberArg,
+		check: returnNilIfHasAnyNil,
+	}
+	builtins["interpolate_at"] = builtinFunc{
+		fType: ast.FuncTypeAgg,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			r, err := interpolateAt(args[0].([]interface{}), args[1].([]interface{}), getFirstValidArg(args[2].([]interface{})))
+			if err != nil {
+				return err, false
+			}
+			return r, true
+		},
+		val: func(_ api.FunctionContext, args []ast.Expr) error {
+			if err := ValidateLen(3, len(args)); err != nil {
+				return err
+			}
+			if ast.IsStringArg(args[1]) || ast.IsBooleanArg(args[1]) {
+				return ProduceErrInfo(1, "number")
+			}
+			return nil
+		},
+		check: returnNilIfHasAnyNil,
+	}
+	builtins["find_gaps"] = builtinFunc{
+		fType: ast.FuncTypeAgg,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			v1 := getFirstValidArg(args[1].([]interface{}))
+			step, err := cast.ToInt64(v1, cast.CONVERT_SAMEKIND)
+			if err != nil || step <= 0 {
+				return fmt.Errorf("the step must be a positive integer but found %[1]T(%[1]v)", v1), false
+			}
+			sortFirst := true
+			if len(args) > 2 {
+				v2 := getFirstValidArg(args[2].([]interface{}))
+				b, ok := v2.(bool)
+				if !ok {
+					return fmt.Errorf("the third parameter requires bool but found %[1]T(%[1]v)", v2), false
+				}
+				sortFirst = b
+			}
+			r, err := findGaps(args[0].([]interface{}), step, sortFirst)
+			if err != nil {
+				return err, false
+			}
+			return r, true
+		},
+		val: func(_ api.FunctionContext, args []ast.Expr) error {
+			if len(args) != 2 && len(args) != 3 {
+				return fmt.Errorf("Expect 2 or 3 arguments but found %d.", len(args))
+			}
+			if ast.IsStringArg(args[1]) || ast.IsTimeArg(args[1]) || ast.IsBooleanArg(args[1]) || ast.IsFloatArg(args[1]) {
+				return ProduceErrInfo(1, "int")
+			}
+			if len(args) > 2 && !ast.IsBooleanArg(args[2]) {
+				return ProduceErrInfo(2, "bool")
+			}
+			return nil
+		},
+		check: returnNilIfHasAnyNil,
+	}
+	builtins["autocorr"] = builtinFunc{
+		fType: ast.FuncTypeAgg,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			v1 := getFirstValidArg(args[1].([]interface{}))
+			lag, err := cast.ToInt(v1, cast.CONVERT_SAMEKIND)
+			if err != nil || lag < 0 {
+				return fmt.Errorf("the lag must be a non-negative integer but found %[1]T(%[1]v)", v1), false
+			}
+			r, err := autocorr(args[0].([]interface{}), lag)
+			if err != nil {
+				return err, false
+			}
+			return r, true
+		},
+		val: func(_ api.FunctionContext, args []ast.Expr) error {
+			if err := ValidateLen(2, len(args)); err != nil {
+				return err
+			}
+			if ast.IsStringArg(args[1]) || ast.IsTimeArg(args[1]) || ast.IsBooleanArg(args[1]) || ast.IsFloatArg(args[1]) {
+				return ProduceErrInfo(1, "int")
+			}
+			return nil
+		},
+		check: returnNilIfHasAnyNil,
+	}
+	builtins["time_in_state"] = builtinFunc{
+		fType: ast.FuncTypeAgg,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			var end interface{}
+			if len(args) > 2 {
+				end = getFirstValidArg(args[2].([]interface{}))
+			}
+			r, err := timeInState(args[0].([]interface{}), args[1].([]interface{}), end)
+			if err != nil {
+				return err, false
+			}
+			return r, true
+		},
+		val: func(_ api.FunctionContext, args []ast.Expr) error {
+			if len(args) != 2 && len(args) != 3 {
+				return fmt.Errorf("Expect 2 or 3 arguments but found %d.", len(args))
+			}
+			for i := 1; i < len(args); i++ {
+				if ast.IsStringArg(args[i]) || ast.IsBooleanArg(args[i]) {
+					return ProduceErrInfo(i, "datetime")
+				}
+			}
+			return nil
+		},
+		check: returnNilIfHasAnyNil,
+	}
+	builtins["histogram_pct"] = builtinFunc{
+		fType: ast.FuncTypeAgg,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			r, err := histogramPct(args[0].([]interface{}), getFirstValidArg(args[1].([]interface{})))
+			if err != nil {
+				return err, false
+			}
+			return r, true
+		},
+		val: func(_ api.FunctionContext, args []ast.Expr) error {
+			if err := ValidateLen(2, len(args)); err != nil {
+				return err
+			}
+			if ast.IsNumericArg(args[1]) || ast.IsStringArg(args[1]) || ast.IsTimeArg(args[1]) || ast.IsBooleanArg(args[1]) {
+				return ProduceErrInfo(1, "array")
+			}
+			return nil
+		},
+		check: returnNilIfHasAnyNil,
+	}
+	builtins["weighted_median"] = builtinFunc{
+		fType: ast.FuncTypeAgg,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			r, err := weightedMedian(args[0].([]interface{}), args[1].([]interface{}))
+			if err != nil {
+				return err, false
+			}
+			return r, true
+		},
+		val:   ValidateTwoNumberArg,
+		check: returnNilIfHasAnyNil,
+	}
+	builtins["geomean"] = builtinFunc{
+		fType: ast.FuncTypeAgg,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			r, err := geomean(args[0].([]interface{}))
+			if err != nil {
+				return err, false
+			}
+			return r, true
+		},
+		val:   ValidateOneNumberArg,
+		check: returnNilIfHasAnyNil,
+	}
+	builtins["harmmean"] = builtinFunc{
+		fType: ast.FuncTypeAgg,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			r, err := harmmean(args[0].([]interface{}))
+			if err != nil {
+				return err, false
+			}
+			return r, true
+		},
+		val:   ValidateOneNumberArg,
+		check: returnNilIfHasAnyNil,
+	}
+	builtins["skewness"] = builtinFunc{
+		fType: ast.FuncTypeAgg,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			m2, m3, _, err := centralMoments(args[0].([]interface{}))
+			if err != nil {
+				return err, false
+			}
+			if m2 == 0 {
+				return nil, true
+			}
+			return m3 / (m2 * math.Sqrt(m2)), true
+		},
+		val:   ValidateOneNumberArg,
+		check: returnNilIfHasAnyNil,
+	}
+	builtins["kurtosis"] = builtinFunc{
+		fType: ast.FuncTypeAgg,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			m2, _, m4, err := centralMoments(args[0].([]interface{}))
+			if err != nil {
+				return err, false
+			}
+			if m2 == 0 {
+				return nil, true
+			}
+			return m4/(m2*m2) - 3, true
+		},
+		val:   ValidateOneNumberArg,
+		check: returnNilIfHasAnyNil,
+	}
+	builtins["mode_binned"] = builtinFunc{
+		fType: ast.FuncTypeAgg,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			arg1 := args[1].([]interface{})
+			if len(arg1) == 0 {
+				return nil, true
+			}
+			v1 := getFirstValidArg(arg1)
+			width, err := cast.ToFloat64(v1, cast.CONVERT_SAMEKIND)
+			if err != nil {
+				return fmt.Errorf("the second parameter requires float64 but found %[1]T(%[1]v)", v1), false
+			}
+			if width <= 0 {
+				return fmt.Errorf("the bin width must be positive but found %v", width), false
+			}
+			r, err := modeBinned(args[0].([]interface{}), width)
+			if err != nil {
+				return err, false
+			}
+			return r, true
+		},
+		val: func(ctx api.FunctionContext, args []ast.Expr) error {
+			if err := ValidateTwoNumberArg(ctx, args); err != nil {
+				return err
+			}
+			var width float64
+			switch a := args[1].(type) {
+			case *ast.NumberLiteral:
+				width = a.Val
+			case *ast.IntegerLiteral:
+				width = float64(a.Val)
+			default:
+				return nil
+			}
+			if width <= 0 {
+				return fmt.Errorf("the bin width must be positive but found %v", width)
+			}
+			return nil
+		},
+		check: returnNilIfHasAnyNil,
+	}
 	builtins["percentile_cont"] = builtinFunc{
 		fType: ast.FuncTypeAgg,
 		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
@@ -368,50 +741,714 @@ func registerAggFunc() {
 		val:   ValidateTwoNumberArg,
 		check: returnNilIfHasAnyNil,
 	}
+	builtins["approx_percentile"] = builtinFunc{
+		fType: ast.FuncTypeAgg,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			if err := ValidateLen(2, len(args)); err != nil {
+				return err, false
+			}
+			arg0 := args[0].([]interface{})
+			arg1 := args[1].([]interface{})
+			if len(arg0) == 0 || len(arg1) == 0 {
+				return nil, true
+			}
+			p, err := cast.ToFloat64(getFirstValidArg(arg1), cast.CONVERT_SAMEKIND)
+			if err != nil {
+				return fmt.Errorf("the second parameter requires float64 but found %[1]T(%[1]v)", arg1), false
+			}
+			if p < 0 || p > 1 {
+				return fmt.Errorf("the second parameter must be between 0 and 1 but found %v", p), false
+			}
+			td := newTDigest(defaultCompression)
+			for _, v := range arg0 {
+				if v == nil {
+					continue
+				}
+				f, err := cast.ToFloat64(v, cast.CONVERT_SAMEKIND)
+				if err != nil {
+					return fmt.Errorf("requires float64 slice but found %[1]T(%[1]v)", arg0), false
+				}
+				td.add(f)
+			}
+			r := td.quantile(p)
+			if math.IsNaN(r) {
+				return nil, true
+			}
+			return r, true
+		},
+		val:   ValidateTwoNumberArg,
+		check: returnNilIfHasAnyNil,
+	}
+	builtins["first_value"] = builtinFunc{
+		fType: ast.FuncTypeAgg,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			return firstLastValue(args, false)
+		},
+		val:   validateFirstLastValue,
+		check: returnNilIfHasAnyNil,
+	}
 	builtins["last_value"] = builtinFunc{
 		fType: ast.FuncTypeAgg,
 		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
-			arg0, ok := args[0].([]interface{})
+			return firstLastValue(args, true)
+		},
+		val:   validateFirstLastValue,
+		check: returnNilIfHasAnyNil,
+	}
+	builtins["nth_value"] = builtinFunc{
+		fType: ast.FuncTypeAgg,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			arg0 := args[0].([]interface{})
+			args1, ok := args[1].([]interface{})
 			if !ok {
-				return fmt.Errorf("the first argument to the aggregate function should be []interface but found %[1]T(%[1]v)", args[0]), false
+				return fmt.Errorf("the second argument to the aggregate function should be []interface but found %[1]T(%[1]v)", args[1]), false
+			}
+			n, err := cast.ToInt(getFirstValidArg(args1), cast.STRICT)
+			if err != nil {
+				return fmt.Errorf("the second parameter requires int but found %[1]T(%[1]v)", getFirstValidArg(args1)), false
 			}
-			if len(arg0) == 0 {
+			// n is 1-based
+			if n < 1 || n > len(arg0) {
 				return nil, true
 			}
+			return arg0[n-1], true
+		},
+		val: func(_ api.FunctionContext, args []ast.Expr) error {
+			if err := ValidateLen(2, len(args)); err != nil {
+				return err
+			}
+			if !ast.IsIntegerArg(args[1]) {
+				return ProduceErrInfo(1, "int")
+			}
+			if n := args[1].(*ast.IntegerLiteral).Val; n < 1 {
+				return fmt.Errorf("the second parameter n must be larger than 0 but found %d", n)
+			}
+			return nil
+		},
+		check: returnNilIfHasAnyNil,
+	}
+	builtins["chunk"] = builtinFunc{
+		fType: ast.FuncTypeAgg,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			arg0, _ := args[0].([]interface{})
 			args1, ok := args[1].([]interface{})
 			if !ok {
 				return fmt.Errorf("the second argument to the aggregate function should be []interface but found %[1]T(%[1]v)", args[1]), false
 			}
-			arg1, ok := getFirstValidArg(args1).(bool)
-			if !ok {
-				return fmt.Errorf("the second parameter requires bool but found %[1]T(%[1]v)", getFirstValidArg(args1)), false
+			n, err := cast.ToInt(getFirstValidArg(args1), cast.STRICT)
+			if err != nil || n < 1 {
+				return fmt.Errorf("the second parameter requires positive int but found %[1]T(%[1]v)", getFirstValidArg(args1)), false
 			}
-			if arg1 {
-				for i := len(arg0) - 1; i >= 0; i-- {
-					if arg0[i] != nil {
-						return arg0[i], true
-					}
+			// distribute the remainder to the earlier chunks
+			size, rem := len(arg0)/n, len(arg0)%n
+			result := make([]interface{}, n)
+			start := 0
+			for i := 0; i < n; i++ {
+				l := size
+				if i < rem {
+					l++
 				}
+				c := make([]interface{}, l)
+				copy(c, arg0[start:start+l])
+				result[i] = c
+				start += l
 			}
-			return arg0[len(arg0)-1], true
+			return result, true
 		},
 		val: func(_ api.FunctionContext, args []ast.Expr) error {
 			if err := ValidateLen(2, len(args)); err != nil {
 				return err
 			}
-			if !ast.IsBooleanArg(args[1]) {
-				return ProduceErrInfo(1, "bool")
+			if !ast.IsIntegerArg(args[1]) {
+				return ProduceErrInfo(1, "int")
+			}
+			if n := args[1].(*ast.IntegerLiteral).Val; n < 1 {
+				return fmt.Errorf("the second parameter n must be larger than 0 but found %d", n)
 			}
 			return nil
 		},
+		// An empty window still produces n empty chunks
+		check: func(args []interface{}) (interface{}, bool) {
+			if args[0] == nil {
+				return nil, true
+			}
+			return nil, false
+		},
+	}
+	builtins["mode_freq"] = builtinFunc{
+		fType: ast.FuncTypeAgg,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			arg0 := args[0].([]interface{})
+			counts := make(map[string]int)
+			// the distinct values by the first seen order
+			var distinct []interface{}
+			for _, v := range arg0 {
+				if v == nil {
+					continue
+				}
+				key := fmt.Sprintf("%v", v)
+				if _, ok := counts[key]; !ok {
+					distinct = append(distinct, v)
+				}
+				counts[key]++
+			}
+			var (
+				mode     interface{}
+				modeFreq int
+			)
+			// only replace when the count is larger, so that the first seen value wins the tie
+			for _, v := range distinct {
+				if c := counts[fmt.Sprintf("%v", v)]; c > modeFreq {
+					mode = v
+					modeFreq = c
+				}
+			}
+			if modeFreq == 0 {
+				return nil, true
+			}
+			return map[string]interface{}{
+				"value": mode,
+				"count": modeFreq,
+			}, true
+		},
+		val:   ValidateOneArg,
 		check: returnNilIfHasAnyNil,
 	}
+	builtins["rle"] = builtinFunc{
+		fType: ast.FuncTypeAgg,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			arg0 := args[0].([]interface{})
+			result := make([]interface{}, 0)
+			var run map[string]interface{}
+			for _, v := range arg0 {
+				if run != nil && reflect.DeepEqual(run["value"], v) {
+					run["count"] = run["count"].(int) + 1
+					continue
+				}
+				run = map[string]interface{}{
+					"value": v,
+					"count": 1,
+				}
+				result = append(result, run)
+			}
+			return result, true
+		},
+		val:   ValidateOneArg,
+		check: returnNilIfHasAnyNil,
+	}
+	// The event timestamps of the tuples are passed implicitly by the valuer
+	builtins["min_time"] = builtinFunc{
+		fType: ast.FuncTypeAgg,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			return extremeTime(args[0].([]interface{}), false)
+		},
+		val:   ValidateNoArg,
+		check: returnNilIfHasAnyNil,
+	}
+	builtins["max_time"] = builtinFunc{
+		fType: ast.FuncTypeAgg,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			return extremeTime(args[0].([]interface{}), true)
+		},
+		val:   ValidateNoArg,
+		check: returnNilIfHasAnyNil,
+	}
+}
+
+func extremeTime(times []interface{}, isMax bool) (interface{}, bool) {
+	var (
+		result time.Time
+		found  bool
+	)
+	for _, v := range times {
+		t, ok := v.(time.Time)
+		if !ok {
+			return fmt.Errorf("requires time but found %[1]T(%[1]v)", v), false
+		}
+		if !found || (isMax && t.After(result)) || (!isMax && t.Before(result)) {
+			result = t
+			found = true
+		}
+	}
+	if !found {
+		return nil, true
+	}
+	return result, true
+}
+
+// linearRegression computes the least-squares slope and intercept of y = slope * x + intercept.
+// The pairs with any nil value are ignored. Returns nil if there are fewer than 2 valid pairs or all x are the same.
+func linearRegression(ys, xs []interface{}) (interface{}, interface{}, error) {
+	var n, sumX, sumY, sumXY, sumXX float64
+	for i := 0; i < len(ys) && i < len(xs); i++ {
+		if ys[i] == nil || xs[i] == nil {
+			continue
+		}
+		y, err := cast.ToFloat64(ys[i], cast.CONVERT_SAMEKIND)
+		if err != nil {
+			return nil, nil, fmt.Errorf("requires number but found %[1]T(%[1]v)", ys[i])
+		}
+		x, err := cast.ToFloat64(xs[i], cast.CONVERT_SAMEKIND)
+		if err != nil {
+			return nil, nil, fmt.Errorf("requires number but found %[1]T(%[1]v)", xs[i])
+		}
+		n++
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+	if n < 2 {
+		return nil, nil, nil
+	}
+	d := n*sumXX - sumX*sumX
+	if d == 0 {
+		return nil, nil, nil
+	}
+	slope := (n*sumXY - sumX*sumY) / d
+	return slope, (sumY - slope*sumX) / n, nil
+}
+
+// interpolateAt linearly interpolates the value at the target timestamp with the two nearest points around it.
+// The timestamps can be datetime or epoch milliseconds. The pairs with any nil value are ignored.
+// Returns nil if the target is out of the range of the timestamps since it does not extrapolate.
+func interpolateAt(tss, vs []interface{}, target interface{}) (interface{}, error) {
+	if target == nil {
+		return nil, nil
+	}
+	tt, err := interpolateTs(target)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		lt, rt, lv, rv float64
+		hasL, hasR     bool
+	)
+	for i := 0; i < len(tss) && i < len(vs); i++ {
+		if tss[i] == nil || vs[i] == nil {
+			continue
+		}
+		ts, err := interpolateTs(tss[i])
+		if err != nil {
+			return nil, err
+		}
+		v, err := cast.ToFloat64(vs[i], cast.CONVERT_SAMEKIND)
+		if err != nil {
+			return nil, fmt.Errorf("requires number but found %[1]T(%[1]v)", vs[i])
+		}
+		if ts <= tt && (!hasL || ts > lt) {
+			lt, lv, hasL = ts, v, true
+		}
+		if ts >= tt && (!hasR || ts < rt) {
+			rt, rv, hasR = ts, v, true
+		}
+	}
+	if !hasL || !hasR {
+		return nil, nil
+	}
+	if rt == lt {
+		return lv, nil
+	}
+	return lv + (rv-lv)*(tt-lt)/(rt-lt), nil
+}
+
+func interpolateTs(v interface{}) (float64, error) {
+	if t, ok := v.(time.Time); ok {
+		return float64(t.UnixMilli()), nil
+	}
+	f, err := cast.ToFloat64(v, cast.CONVERT_SAMEKIND)
+	if err != nil {
+		return 0, fmt.Errorf("requires datetime or number timestamp but found %[1]T(%[1]v)", v)
+	}
+	return f, nil
+}
+
+// findGaps returns the expected but missing values of a monotonic integer sequence with the given step.
+// The values are sorted first if sortFirst is true, otherwise the unsorted values raise an error. The nil values and
+// the duplicated values are ignored.
+func findGaps(arr []interface{}, step int64, sortFirst bool) (interface{}, error) {
+	nums := make([]int64, 0, len(arr))
+	for i, v := range arr {
+		if v == nil {
+			continue
+		}
+		n, err := cast.ToInt64(v, cast.CONVERT_SAMEKIND)
+		if err != nil {
+			return nil, fmt.Errorf("requires integer but found %[1]T(%[1]v)", v)
+		}
+		if f, ok := v.(float64); ok && f != float64(n) {
+			return nil, fmt.Errorf("requires integer but found %[1]T(%[1]v)", v)
+		}
+		if !sortFirst && len(nums) > 0 && n < nums[len(nums)-1] {
+			return nil, fmt.Errorf("the sequence is not sorted at index %d: %d is less than %d", i, n, nums[len(nums)-1])
+		}
+		nums = append(nums, n)
+	}
+	if sortFirst {
+		sort.Slice(nums, func(i, j int) bool { return nums[i] < nums[j] })
+	}
+	result := make([]interface{}, 0)
+	for i := 1; i < len(nums); i++ {
+		for x := nums[i-1] + step; x < nums[i]; x += step {
+			result = append(result, x)
+		}
+	}
+	return result, nil
+}
+
+func bitwiseAggExec(op func(a, b int64) int64) funcExe {
+	return func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+		r, err := sliceIntBitwise(args[0].([]interface{}), op)
+		if err != nil {
+			return err, false
+		}
+		return r, true
+	}
+}
+
+// autocorr calculates the Pearson correlation between the series and itself shifted by lag.
+// The null values are ignored. It returns nil if there are less than 2 pairs or the variance is 0.
+func autocorr(arr []interface{}, lag int) (interface{}, error) {
+	nums := make([]float64, 0, len(arr))
+	for _, v := range arr {
+		if v == nil {
+			continue
+		}
+		n, err := cast.ToFloat64(v, cast.CONVERT_SAMEKIND)
+		if err != nil {
+			return nil, fmt.Errorf("requires number but found %[1]T(%[1]v)", v)
+		}
+		nums = append(nums, n)
+	}
+	size := len(nums) - lag
+	if size < 2 {
+		return nil, nil
+	}
+	var meanX, meanY float64
+	for i := 0; i < size; i++ {
+		meanX += nums[i]
+		meanY += nums[i+lag]
+	}
+	meanX /= float64(size)
+	meanY /= float64(size)
+	var cov, varX, varY float64
+	for i := 0; i < size; i++ {
+		dx, dy := nums[i]-meanX, nums[i+lag]-meanY
+		cov += dx * dy
+		varX += dx * dx
+		varY += dy * dy
+	}
+	if varX == 0 || varY == 0 {
+		return nil, nil
+	}
+	return cov / math.Sqrt(varX*varY), nil
+}
+
+// timeInState returns how long in milliseconds the latest value has been unchanged, which is from the timestamp of its
+// last change to the end. The end is the timestamp of the last row if not specified. The rows with null value or
+// timestamp are ignored. It returns nil if there is no valid row.
+func timeInState(vs, tss []interface{}, end interface{}) (interface{}, error) {
+	var (
+		last             interface{}
+		changeTs, lastTs float64
+		found            bool
+	)
+	for i := 0; i < len(vs) && i < len(tss); i++ {
+		if vs[i] == nil || tss[i] == nil {
+			continue
+		}
+		ts, err := interpolateTs(tss[i])
+		if err != nil {
+			return nil, err
+		}
+		if !found || !valueEqual(vs[i], last) {
+			last, changeTs, found = vs[i], ts, true
+		}
+		lastTs = ts
+	}
+	if !found {
+		return nil, nil
+	}
+	if end != nil {
+		et, err := interpolateTs(end)
+		if err != nil {
+			return nil, err
+		}
+		lastTs = et
+	}
+	if lastTs < changeTs {
+		return int64(0), nil
+	}
+	return int64(lastTs - changeTs), nil
+}
+
+// histogramPct returns the percentage of the values in each bucket split by the ascending boundaries.
+// For n boundaries, there are n+1 buckets: (-inf, b1), [b1, b2), ..., [bn, +inf). The null values are ignored.
+// It returns nil if there is no value.
+func histogramPct(arr []interface{}, boundaries interface{}) (interface{}, error) {
+	bs, ok := boundaries.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("the boundaries must be an array but found %[1]T(%[1]v)", boundaries)
+	}
+	bounds := make([]float64, len(bs))
+	for i, b := range bs {
+		f, err := cast.ToFloat64(b, cast.CONVERT_SAMEKIND)
+		if err != nil {
+			return nil, fmt.Errorf("the boundary requires number but found %[1]T(%[1]v)", b)
+		}
+		if i > 0 && f <= bounds[i-1] {
+			return nil, fmt.Errorf("the boundaries must be in ascending order but found %v after %v", f, bounds[i-1])
+		}
+		bounds[i] = f
+	}
+	counts := make([]int, len(bounds)+1)
+	total := 0
+	for _, v := range arr {
+		if v == nil {
+			continue
+		}
+		f, err := cast.ToFloat64(v, cast.CONVERT_SAMEKIND)
+		if err != nil {
+			return nil, fmt.Errorf("requires number but found %[1]T(%[1]v)", v)
+		}
+		counts[sort.Search(len(bounds), func(i int) bool { return bounds[i] > f })]++
+		total++
+	}
+	if total == 0 {
+		return nil, nil
+	}
+	result := make([]interface{}, len(counts))
+	for i, c := range counts {
+		result[i] = float64(c) / float64(total)
+	}
+	return result, nil
+}
+
+// firstLastValue returns the first or last value of the group. The optional second arg specifies whether to
+// ignore the null values, which is true by default.
+func firstLastValue(args []interface{}, last bool) (interface{}, bool) {
+	arg0, ok := args[0].([]interface{})
+	if !ok {
+		return fmt.Errorf("the first argument to the aggregate function should be []interface but found %[1]T(%[1]v)", args[0]), false
+	}
+	if len(arg0) == 0 {
+		return nil, true
+	}
+	ignoreNull := true
+	if len(args) > 1 {
+		args1, ok := args[1].([]interface{})
+		if !ok {
+			return fmt.Errorf("the second argument to the aggregate function should be []interface but found %[1]T(%[1]v)", args[1]), false
+		}
+		ignoreNull, ok = getFirstValidArg(args1).(bool)
+		if !ok {
+			return fmt.Errorf("the second parameter requires bool but found %[1]T(%[1]v)", getFirstValidArg(args1)), false
+		}
+	}
+	if !ignoreNull {
+		if last {
+			return arg0[len(arg0)-1], true
+		}
+		return arg0[0], true
+	}
+	for i := range arg0 {
+		if last {
+			i = len(arg0) - 1 - i
+		}
+		if arg0[i] != nil {
+			return arg0[i], true
+		}
+	}
+	return nil, true
+}
+
+func validateFirstLastValue(_ api.FunctionContext, args []ast.Expr) error {
+	if len(args) != 1 && len(args) != 2 {
+		return errors.New("Expect 1 or 2 arguments only")
+	}
+	if len(args) > 1 && !ast.IsBooleanArg(args[1]) {
+		return ProduceErrInfo(1, "bool")
+	}
+	return nil
+}
+
+// geomean returns the geometric mean of the values by averaging the logarithms to avoid overflow of the product.
+// The nil values are ignored and it returns nil if there is no value.
+func geomean(arr []interface{}) (interface{}, error) {
+	var (
+		logSum float64
+		count  int
+	)
+	for _, v := range arr {
+		if v == nil {
+			continue
+		}
+		f, err := cast.ToFloat64(v, cast.CONVERT_SAMEKIND)
+		if err != nil {
+			return nil, fmt.Errorf("requires number but found %[1]T(%[1]v)", v)
+		}
+		if f <= 0 {
+			return nil, fmt.Errorf("requires positive number but found %v", v)
+		}
+		logSum += math.Log(f)
+		count++
+	}
+	if count == 0 {
+		return nil, nil
+	}
+	return math.Exp(logSum / float64(count)), nil
+}
+
+// harmmean returns the harmonic mean of the values which is the count divided by the sum of the reciprocals.
+// The nil values are ignored and it returns nil if there is no value.
+func harmmean(arr []interface{}) (interface{}, error) {
+	var (
+		recSum float64
+		count  int
+	)
+	for _, v := range arr {
+		if v == nil {
+			continue
+		}
+		f, err := cast.ToFloat64(v, cast.CONVERT_SAMEKIND)
+		if err != nil {
+			return nil, fmt.Errorf("requires number but found %[1]T(%[1]v)", v)
+		}
+		if f == 0 {
+			return nil, fmt.Errorf("requires non-zero number but found %v", v)
+		}
+		recSum += 1 / f
+		count++
+	}
+	if count == 0 {
+		return nil, nil
+	}
+	// only possible with the mixed positive and negative values
+	if recSum == 0 {
+		return nil, fmt.Errorf("the sum of the reciprocals is zero")
+	}
+	return float64(count) / recSum, nil
+}
+
+// centralMoments returns the 2nd, 3rd and 4th population central moments of the values.
+// The nil values are ignored and the moments are 0 if there are less than two values.
+func centralMoments(arr []interface{}) (float64, float64, float64, error) {
+	f64s := make([]float64, 0, len(arr))
+	var sum float64
+	for _, v := range arr {
+		if v == nil {
+			continue
+		}
+		f, err := cast.ToFloat64(v, cast.CONVERT_SAMEKIND)
+		if err != nil {
+			return 0, 0, 0, fmt.Errorf("requires number but found %[1]T(%[1]v)", v)
+		}
+		f64s = append(f64s, f)
+		sum += f
+	}
+	n := len(f64s)
+	if n < 2 {
+		return 0, 0, 0, nil
+	}
+	mean := sum / float64(n)
+	var m2, m3, m4 float64
+	for _, f := range f64s {
+		d := f - mean
+		d2 := d * d
+		m2 += d2
+		m3 += d2 * d
+		m4 += d2 * d2
+	}
+	return m2 / float64(n), m3 / float64(n), m4 / float64(n), nil
+}
+
+// modeBinned bins the values into the buckets of the width and returns the center of the most populated bin.
+// The bin with the smallest values wins a tie. The nil values are ignored and it returns nil if there is no value.
+func modeBinned(arr []interface{}, width float64) (interface{}, error) {
+	counts := make(map[float64]int)
+	var (
+		mode     float64
+		maxCount int
+	)
+	for _, v := range arr {
+		if v == nil {
+			continue
+		}
+		f, err := cast.ToFloat64(v, cast.CONVERT_SAMEKIND)
+		if err != nil {
+			return nil, fmt.Errorf("requires number but found %[1]T(%[1]v)", v)
+		}
+		bin := math.Floor(f / width)
+		counts[bin]++
+		if c := counts[bin]; c > maxCount || (c == maxCount && bin < mode) {
+			mode, maxCount = bin, c
+		}
+	}
+	if maxCount == 0 {
+		return nil, nil
+	}
+	return (mode + 0.5) * width, nil
+}
+
+// weightedMedian returns the first value in ascending order where the cumulative weight reaches half of the total
+// weight. The pairs with nil value or weight are ignored. It returns nil if the total weight is zero.
+func weightedMedian(vs, ws []interface{}) (interface{}, error) {
+	type pair struct {
+		v, w float64
+	}
+	pairs := make([]pair, 0, len(vs))
+	var total float64
+	for i := 0; i < len(vs) && i < len(ws); i++ {
+		if vs[i] == nil || ws[i] == nil {
+			continue
+		}
+		v, err := cast.ToFloat64(vs[i], cast.CONVERT_SAMEKIND)
+		if err != nil {
+			return nil, fmt.Errorf("requires number but found %[1]T(%[1]v)", vs[i])
+		}
+		w, err := cast.ToFloat64(ws[i], cast.CONVERT_SAMEKIND)
+		if err != nil {
+			return nil, fmt.Errorf("requires number but found %[1]T(%[1]v)", ws[i])
+		}
+		if w < 0 {
+			return nil, fmt.Errorf("requires non-negative weight but found %v", w)
+		}
+		pairs = append(pairs, pair{v: v, w: w})
+		total += w
+	}
+	if total == 0 {
+		return nil, nil
+	}
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i].v < pairs[j].v })
+	var cum float64
+	for _, p := range pairs {
+		cum += p.w
+		if cum >= total/2 {
+			return p.v, nil
+		}
+	}
+	return pairs[len(pairs)-1].v, nil
 }
 
 type Number interface {
 	int64 | float64
 }
 
+// percentileCont returns the percentile p in [0, 1] by the linear interpolation between the closest ranks. It returns
+// nil if there is no value.
+func percentileCont(nums []float64, p float64) interface{} {
+	if len(nums) == 0 {
+		return nil
+	}
+	sort.Float64s(nums)
+	rank := p * float64(len(nums)-1)
+	lower := int(math.Floor(rank))
+	if lower+1 >= len(nums) {
+		return nums[lower]
+	}
+	return nums[lower] + (nums[lower+1]-nums[lower])*(rank-float64(lower))
+}
+
 func median[T Number](nums []T) interface{} {
 	sort.Slice(nums, func(i, j int) bool {
 		return nums[i] < nums[j]
@@ -15,9 +15,14 @@
 package function
 
 import (
+	"errors"
 	"fmt"
+	"math"
+	"math/rand"
 	"reflect"
+	"sort"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -27,6 +32,7 @@ import (
 	kctx "github.com/lf-edge/ekuiper/v2/internal/topo/context"
 	"github.com/lf-edge/ekuiper/v2/internal/topo/state"
 	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
 )
 
 func TestAggExec(t *testing.T) {
@@ -299,6 +305,73 @@ func TestPercentileExec(t *testing.T) {
 	}
 }
 
+func TestApproxPercentileExec(t *testing.T) {
+	f, ok := builtins["approx_percentile"]
+	require.True(t, ok)
+	exact, ok := builtins["percentile_cont"]
+	require.True(t, ok)
+	contextLogger := conf.Log.WithField("rule", "testExec")
+	ctx := kctx.WithValue(kctx.Background(), kctx.LoggerKey, contextLogger)
+	tempStore, _ := state.CreateStore("mockRule0", def.AtMostOnce)
+	fctx := kctx.NewDefaultFuncContext(ctx.WithMeta("mockRule0", "test", tempStore), 2)
+	const n = 20000
+	// each distribution has its own source so that the data does not depend on the map iteration order
+	dists := map[string]func(r *rand.Rand) interface{}{
+		"uniform": func(r *rand.Rand) interface{} {
+			return r.Float64() * 1000
+		},
+		"normal": func(r *rand.Rand) interface{} {
+			return r.NormFloat64()*10 + 50
+		},
+		"exponential": func(r *rand.Rand) interface{} {
+			return r.ExpFloat64()
+		},
+		"int": func(r *rand.Rand) interface{} {
+			return int64(r.Intn(500))
+		},
+	}
+	for name, gen := range dists {
+		r := rand.New(rand.NewSource(42))
+		data := make([]interface{}, n)
+		sorted := make([]float64, n)
+		for i := range data {
+			data[i] = gen(r)
+			sorted[i], _ = cast.ToFloat64(data[i], cast.CONVERT_SAMEKIND)
+		}
+		sort.Float64s(sorted)
+		for _, p := range []float64{0.001, 0.01, 0.1, 0.25, 0.5, 0.75, 0.9, 0.99, 0.999} {
+			ps := []interface{}{p}
+			est, ok := f.exec(fctx, []interface{}{data, ps})
+			require.True(t, ok)
+			want, ok := exact.exec(fctx, []interface{}{data, ps})
+			require.True(t, ok)
+			// The rank range of the estimate must be close to the requested quantile. The tolerance is tighter at the tails.
+			lo := float64(sort.SearchFloat64s(sorted, est.(float64))) / n
+			hi := float64(sort.Search(n, func(i int) bool { return sorted[i] > est.(float64) })) / n
+			tolerance := math.Max(0.0005, 0.01*math.Min(p, 1-p))
+			require.True(t, p >= lo-tolerance && p <= hi+tolerance, "%s p=%v rank=[%v, %v]", name, p, lo, hi)
+			require.InDelta(t, want, est, (sorted[n-1]-sorted[0])*0.01, "%s p=%v", name, p)
+		}
+		minV, _ := f.exec(fctx, []interface{}{data, []interface{}{0}})
+		require.Equal(t, sorted[0], minV)
+		maxV, _ := f.exec(fctx, []interface{}{data, []interface{}{1}})
+		require.Equal(t, sorted[n-1], maxV)
+	}
+	// small set is exact at the centroids
+	v, ok := f.exec(fctx, []interface{}{[]interface{}{int64(1), nil, int64(3), int64(2)}, []interface{}{0.5}})
+	require.True(t, ok)
+	require.Equal(t, 2.0, v)
+	v, ok = f.exec(fctx, []interface{}{[]interface{}{}, []interface{}{}})
+	require.True(t, ok)
+	require.Nil(t, v)
+	v, ok = f.exec(fctx, []interface{}{[]interface{}{"foo", "bar"}, []interface{}{0.5, 0.5}})
+	require.False(t, ok)
+	require.EqualError(t, v.(error), "requires float64 slice but found []interface {}([foo bar])")
+	v, ok = f.exec(fctx, []interface{}{[]interface{}{1, 2}, []interface{}{1.5, 1.5}})
+	require.False(t, ok)
+	require.EqualError(t, v.(error), "the second parameter must be between 0 and 1 but found 1.5")
+}
+
 func TestConcatExec(t *testing.T) {
 	fcon, ok := builtins["merge_agg"]
 	if !ok {
@@ -351,16 +424,525 @@ func TestConcatExec(t *testing.T) {
 			args: []interface{}{
 				[]interface{}{},
 			},
-			result: nil,
+			result: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, ok := fcon.exec(fctx, tt.args)
+			assert.True(t, ok, "failed to execute concat")
+			assert.Equal(t, tt.result, r)
+		})
+	}
+}
+
+func TestModeFreqExec(t *testing.T) {
+	f, ok := builtins["mode_freq"]
+	require.True(t, ok)
+	contextLogger := conf.Log.WithField("rule", "testExec")
+	ctx := kctx.WithValue(kctx.Background(), kctx.LoggerKey, contextLogger)
+	tempStore, _ := state.CreateStore("mockRule0", def.AtMostOnce)
+	fctx := kctx.NewDefaultFuncContext(ctx.WithMeta("mockRule0", "test", tempStore), 2)
+	tests := []struct {
+		name   string
+		args   []interface{}
+		result interface{}
+	}{
+		{
+			name: "dominant",
+			args: []interface{}{
+				[]interface{}{1, 2, 2, nil, 3, 2},
+			},
+			result: map[string]interface{}{"value": 2, "count": 3},
+		},
+		{
+			name: "tie",
+			args: []interface{}{
+				[]interface{}{"b", "a", "a", "b"},
+			},
+			result: map[string]interface{}{"value": "b", "count": 2},
+		},
+		{
+			name: "all nil",
+			args: []interface{}{
+				[]interface{}{nil, nil},
+			},
+			result: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, ok := f.exec(fctx, tt.args)
+			require.True(t, ok)
+			require.Equal(t, tt.result, r)
+		})
+	}
+}
+
+func TestWeightedMedianExec(t *testing.T) {
+	f, ok := builtins["weighted_median"]
+	require.True(t, ok)
+	contextLogger := conf.Log.WithField("rule", "testExec")
+	ctx := kctx.WithValue(kctx.Background(), kctx.LoggerKey, contextLogger)
+	tempStore, _ := state.CreateStore("mockRule0", def.AtMostOnce)
+	fctx := kctx.NewDefaultFuncContext(ctx.WithMeta("mockRule0", "test", tempStore), 2)
+	tests := []struct {
+		name   string
+		args   []interface{}
+		result interface{}
+	}{
+		{
+			name: "crossing half",
+			args: []interface{}{
+				[]interface{}{3, 1, 2, 4}, []interface{}{1, 1, 1, 1},
+			},
+			result: 2.0,
+		},
+		{
+			name: "heavy weight",
+			args: []interface{}{
+				[]interface{}{1, 2, 3, nil}, []interface{}{0.1, 0.1, 5, 100},
+			},
+			result: 3.0,
+		},
+		{
+			name: "zero weight",
+			args: []interface{}{
+				[]interface{}{1, 2}, []interface{}{0, 0},
+			},
+			result: nil,
+		},
+		{
+			name: "non numeric value",
+			args: []interface{}{
+				[]interface{}{1, "a"}, []interface{}{1, 1},
+			},
+			result: errors.New("requires number but found string(a)"),
+		},
+		{
+			name: "negative weight",
+			args: []interface{}{
+				[]interface{}{1, 2}, []interface{}{1, -1},
+			},
+			result: errors.New("requires non-negative weight but found -1"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := f.exec(fctx, tt.args)
+			require.Equal(t, tt.result, r)
+		})
+	}
+}
+
+func TestInterpolateAtExec(t *testing.T) {
+	f, ok := builtins["interpolate_at"]
+	require.True(t, ok)
+	contextLogger := conf.Log.WithField("rule", "testExec")
+	ctx := kctx.WithValue(kctx.Background(), kctx.LoggerKey, contextLogger)
+	tempStore, _ := state.CreateStore("mockRule0", def.AtMostOnce)
+	fctx := kctx.NewDefaultFuncContext(ctx.WithMeta("mockRule0", "test", tempStore), 2)
+	tests := []struct {
+		name   string
+		args   []interface{}
+		result interface{}
+	}{
+		{
+			name: "between points",
+			args: []interface{}{
+				[]interface{}{3000, 1000, 2000}, []interface{}{40, 10, 20}, []interface{}{2500, 2500, 2500},
+			},
+			result: 30.0,
+		},
+		{
+			name: "exact point",
+			args: []interface{}{
+				[]interface{}{1000, 2000}, []interface{}{10, 20}, []interface{}{2000, 2000},
+			},
+			result: 20.0,
+		},
+		{
+			name: "datetime",
+			args: []interface{}{
+				[]interface{}{time.UnixMilli(1000), time.UnixMilli(3000)}, []interface{}{1, 2}, []interface{}{time.UnixMilli(1500), time.UnixMilli(1500)},
+			},
+			result: 1.25,
+		},
+		{
+			name: "skip nil",
+			args: []interface{}{
+				[]interface{}{1000, nil, 3000}, []interface{}{10, 100, nil}, []interface{}{2000, 2000, 2000},
+			},
+			result: nil,
+		},
+		{
+			name: "out of range",
+			args: []interface{}{
+				[]interface{}{1000, 2000}, []interface{}{10, 20}, []interface{}{3000, 3000},
+			},
+			result: nil,
+		},
+		{
+			name: "non numeric value",
+			args: []interface{}{
+				[]interface{}{1000, 2000}, []interface{}{10, "a"}, []interface{}{1500, 1500},
+			},
+			result: errors.New("requires number but found string(a)"),
+		},
+		{
+			name: "invalid timestamp",
+			args: []interface{}{
+				[]interface{}{1000, "b"}, []interface{}{10, 20}, []interface{}{1500, 1500},
+			},
+			result: errors.New("requires datetime or number timestamp but found string(b)"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := f.exec(fctx, tt.args)
+			require.Equal(t, tt.result, r)
+		})
+	}
+}
+
+func TestFindGapsExec(t *testing.T) {
+	f, ok := builtins["find_gaps"]
+	require.True(t, ok)
+	contextLogger := conf.Log.WithField("rule", "testExec")
+	ctx := kctx.WithValue(kctx.Background(), kctx.LoggerKey, contextLogger)
+	tempStore, _ := state.CreateStore("mockRule0", def.AtMostOnce)
+	fctx := kctx.NewDefaultFuncContext(ctx.WithMeta("mockRule0", "test", tempStore), 2)
+	tests := []struct {
+		name   string
+		args   []interface{}
+		result interface{}
+	}{
+		{
+			name: "step 1",
+			args: []interface{}{
+				[]interface{}{1, 2, 4, 5, 8}, []interface{}{1, 1, 1, 1, 1},
+			},
+			result: []interface{}{int64(3), int64(6), int64(7)},
+		},
+		{
+			name: "step 10 unsorted",
+			args: []interface{}{
+				[]interface{}{40.0, 10, nil, 20, 20}, []interface{}{10, 10, 10, 10, 10},
+			},
+			result: []interface{}{int64(30)},
+		},
+		{
+			name: "no gap",
+			args: []interface{}{
+				[]interface{}{1, 2, 3}, []interface{}{1, 1, 1},
+			},
+			result: []interface{}{},
+		},
+		{
+			name: "sorted",
+			args: []interface{}{
+				[]interface{}{1, 3, 3}, []interface{}{1, 1, 1}, []interface{}{false, false, false},
+			},
+			result: []interface{}{int64(2)},
+		},
+		{
+			name: "not sorted",
+			args: []interface{}{
+				[]interface{}{1, 3, 2}, []interface{}{1, 1, 1}, []interface{}{false, false, false},
+			},
+			result: errors.New("the sequence is not sorted at index 2: 2 is less than 3"),
+		},
+		{
+			name: "non integer",
+			args: []interface{}{
+				[]interface{}{1, 2.5}, []interface{}{1, 1},
+			},
+			result: errors.New("requires integer but found float64(2.5)"),
+		},
+		{
+			name: "non numeric",
+			args: []interface{}{
+				[]interface{}{1, "a"}, []interface{}{1, 1},
+			},
+			result: errors.New("requires integer but found string(a)"),
+		},
+		{
+			name: "invalid step",
+			args: []interface{}{
+				[]interface{}{1, 2}, []interface{}{0, 0},
+			},
+			result: errors.New("the step must be a positive integer but found int(0)"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := f.exec(fctx, tt.args)
+			require.Equal(t, tt.result, r)
+		})
+	}
+	require.EqualError(t, f.val(fctx, []ast.Expr{&ast.FieldRef{Name: "a"}}), "Expect 2 or 3 arguments but found 1.")
+	require.EqualError(t, f.val(fctx, []ast.Expr{&ast.FieldRef{Name: "a"}, &ast.NumberLiteral{Val: 1.5}}), "Expect int type for parameter 2")
+	require.EqualError(t, f.val(fctx, []ast.Expr{&ast.FieldRef{Name: "a"}, &ast.IntegerLiteral{Val: 1}, &ast.IntegerLiteral{Val: 1}}), "Expect bool type for parameter 3")
+	require.NoError(t, f.val(fctx, []ast.Expr{&ast.FieldRef{Name: "a"}, &ast.IntegerLiteral{Val: 1}, &ast.BooleanLiteral{Val: false}}))
+}
+
+func TestAutocorrExec(t *testing.T) {
+	f, ok := builtins["autocorr"]
+	require.True(t, ok)
+	contextLogger := conf.Log.WithField("rule", "testExec")
+	ctx := kctx.WithValue(kctx.Background(), kctx.LoggerKey, contextLogger)
+	tempStore, _ := state.CreateStore("mockRule0", def.AtMostOnce)
+	fctx := kctx.NewDefaultFuncContext(ctx.WithMeta("mockRule0", "test", tempStore), 2)
+	tests := []struct {
+		name   string
+		args   []interface{}
+		result interface{}
+	}{
+		{
+			name: "period lag",
+			args: []interface{}{
+				[]interface{}{1, 5, 1, 5, 1, 5}, []interface{}{2, 2, 2, 2, 2, 2},
+			},
+			result: 1.0,
+		},
+		{
+			name: "opposite phase",
+			args: []interface{}{
+				[]interface{}{1, 5.0, nil, 1, 5, 1}, []interface{}{1, 1, 1, 1, 1, 1},
+			},
+			result: -1.0,
+		},
+		{
+			name: "insufficient data",
+			args: []interface{}{
+				[]interface{}{1, 2, 3}, []interface{}{2, 2, 2},
+			},
+			result: nil,
+		},
+		{
+			name: "constant",
+			args: []interface{}{
+				[]interface{}{3, 3, 3, 3}, []interface{}{1, 1, 1, 1},
+			},
+			result: nil,
+		},
+		{
+			name: "non numeric",
+			args: []interface{}{
+				[]interface{}{1, "a", 3}, []interface{}{1, 1, 1},
+			},
+			result: errors.New("requires number but found string(a)"),
+		},
+		{
+			name: "invalid lag",
+			args: []interface{}{
+				[]interface{}{1, 2, 3}, []interface{}{-1, -1, -1},
+			},
+			result: errors.New("the lag must be a non-negative integer but found int(-1)"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := f.exec(fctx, tt.args)
+			require.Equal(t, tt.result, r)
+		})
+	}
+	require.EqualError(t, f.val(fctx, []ast.Expr{&ast.FieldRef{Name: "a"}}), "Expect 2 arguments but found 1.")
+	require.EqualError(t, f.val(fctx, []ast.Expr{&ast.FieldRef{Name: "a"}, &ast.NumberLiteral{Val: 1.5}}), "Expect int type for parameter 2")
+	require.NoError(t, f.val(fctx, []ast.Expr{&ast.FieldRef{Name: "a"}, &ast.IntegerLiteral{Val: 2}}))
+}
+
+func TestTimeInStateExec(t *testing.T) {
+	f, ok := builtins["time_in_state"]
+	require.True(t, ok)
+	contextLogger := conf.Log.WithField("rule", "testExec")
+	ctx := kctx.WithValue(kctx.Background(), kctx.LoggerKey, contextLogger)
+	tempStore, _ := state.CreateStore("mockRule0", def.AtMostOnce)
+	fctx := kctx.NewDefaultFuncContext(ctx.WithMeta("mockRule0", "test", tempStore), 2)
+	tests := []struct {
+		name   string
+		args   []interface{}
+		result interface{}
+	}{
+		{
+			name: "change mid window",
+			args: []interface{}{
+				[]interface{}{"on", "on", "off", "off"}, []interface{}{1000, 2000, 3000, 4000},
+			},
+			result: int64(1000),
+		},
+		{
+			name: "until end",
+			args: []interface{}{
+				[]interface{}{1, 2.0, 2}, []interface{}{1000, 2000, 3000}, []interface{}{5000, 5000, 5000},
+			},
+			result: int64(3000),
+		},
+		{
+			name: "datetime",
+			args: []interface{}{
+				[]interface{}{"on", nil, "on"}, []interface{}{time.UnixMilli(1000), time.UnixMilli(2000), time.UnixMilli(3000)},
+			},
+			result: int64(2000),
+		},
+		{
+			name: "no value",
+			args: []interface{}{
+				[]interface{}{nil, nil}, []interface{}{1000, 2000},
+			},
+			result: nil,
+		},
+		{
+			name: "non temporal",
+			args: []interface{}{
+				[]interface{}{"on"}, []interface{}{"a"},
+			},
+			result: errors.New("requires datetime or number timestamp but found string(a)"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := f.exec(fctx, tt.args)
+			require.Equal(t, tt.result, r)
+		})
+	}
+	require.EqualError(t, f.val(fctx, []ast.Expr{&ast.FieldRef{Name: "a"}}), "Expect 2 or 3 arguments but found 1.")
+	require.EqualError(t, f.val(fctx, []ast.Expr{&ast.FieldRef{Name: "a"}, &ast.StringLiteral{Val: "ts"}}), "Expect datetime type for parameter 2")
+	require.NoError(t, f.val(fctx, []ast.Expr{&ast.FieldRef{Name: "a"}, &ast.FieldRef{Name: "ts"}, &ast.Call{Name: "window_end"}}))
+}
+
+func TestHistogramPctExec(t *testing.T) {
+	f, ok := builtins["histogram_pct"]
+	require.True(t, ok)
+	contextLogger := conf.Log.WithField("rule", "testExec")
+	ctx := kctx.WithValue(kctx.Background(), kctx.LoggerKey, contextLogger)
+	tempStore, _ := state.CreateStore("mockRule0", def.AtMostOnce)
+	fctx := kctx.NewDefaultFuncContext(ctx.WithMeta("mockRule0", "test", tempStore), 2)
+	bounds := []interface{}{10, 20.0}
+	tests := []struct {
+		name   string
+		args   []interface{}
+		result interface{}
+	}{
+		{
+			name: "buckets",
+			args: []interface{}{
+				[]interface{}{5, 10, nil, 15.5, 30}, []interface{}{bounds, bounds, bounds, bounds, bounds},
+			},
+			result: []interface{}{0.25, 0.5, 0.25},
+		},
+		{
+			name: "no value",
+			args: []interface{}{
+				[]interface{}{nil}, []interface{}{bounds},
+			},
+			result: nil,
+		},
+		{
+			name: "non numeric",
+			args: []interface{}{
+				[]interface{}{1, "a"}, []interface{}{bounds, bounds},
+			},
+			result: errors.New("requires number but found string(a)"),
+		},
+		{
+			name: "not ascending",
+			args: []interface{}{
+				[]interface{}{1}, []interface{}{[]interface{}{20, 10}},
+			},
+			result: errors.New("the boundaries must be in ascending order but found 10 after 20"),
+		},
+		{
+			name: "not array",
+			args: []interface{}{
+				[]interface{}{1}, []interface{}{10},
+			},
+			result: errors.New("the boundaries must be an array but found int(10)"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := f.exec(fctx, tt.args)
+			require.Equal(t, tt.result, r)
+		})
+	}
+	require.EqualError(t, f.val(fctx, []ast.Expr{&ast.FieldRef{Name: "a"}}), "Expect 2 arguments but found 1.")
+	require.EqualError(t, f.val(fctx, []ast.Expr{&ast.FieldRef{Name: "a"}, &ast.IntegerLiteral{Val: 1}}), "Expect array type for parameter 2")
+}
+
+func TestBitwiseAggExec(t *testing.T) {
+	contextLogger := conf.Log.WithField("rule", "testExec")
+	ctx := kctx.WithValue(kctx.Background(), kctx.LoggerKey, contextLogger)
+	tempStore, _ := state.CreateStore("mockRule0", def.AtMostOnce)
+	fctx := kctx.NewDefaultFuncContext(ctx.WithMeta("mockRule0", "test", tempStore), 2)
+	tests := []struct {
+		name   string
+		args   []interface{}
+		result map[string]interface{}
+	}{
+		{
+			name: "int",
+			args: []interface{}{[]interface{}{12, int64(10), nil, 6}},
+			result: map[string]interface{}{
+				"bit_and": int64(0),
+				"bit_or":  int64(14),
+				"bit_xor": int64(0),
+			},
+		},
+		{
+			name: "json numbers",
+			args: []interface{}{[]interface{}{3.0, 5.0}},
+			result: map[string]interface{}{
+				"bit_and": int64(1),
+				"bit_or":  int64(7),
+				"bit_xor": int64(6),
+			},
+		},
+		{
+			name: "all nil",
+			args: []interface{}{[]interface{}{nil, nil}},
+			result: map[string]interface{}{
+				"bit_and": nil,
+				"bit_or":  nil,
+				"bit_xor": nil,
+			},
+		},
+		{
+			name: "string",
+			args: []interface{}{[]interface{}{1, "a"}},
+			result: map[string]interface{}{
+				"bit_and": errors.New("requires int but found string(a)"),
+				"bit_or":  errors.New("requires int but found string(a)"),
+				"bit_xor": errors.New("requires int but found string(a)"),
+			},
+		},
+		{
+			name: "float",
+			args: []interface{}{[]interface{}{1, 1.5}},
+			result: map[string]interface{}{
+				"bit_and": errors.New("requires int but found float64(1.5)"),
+				"bit_or":  errors.New("requires int but found float64(1.5)"),
+				"bit_xor": errors.New("requires int but found float64(1.5)"),
+			},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			r, ok := fcon.exec(fctx, tt.args)
-			assert.True(t, ok, "failed to execute concat")
-			assert.Equal(t, tt.result, r)
+			for name, expected := range tt.result {
+				f, ok := builtins[name]
+				require.True(t, ok)
+				r, _ := f.exec(fctx, tt.args)
+				require.Equal(t, expected, r, name)
+			}
 		})
 	}
+	for _, name := range []string{"bit_and", "bit_or", "bit_xor"} {
+		f := builtins[name]
+		r, b := f.check([]interface{}{[]interface{}{}})
+		require.True(t, b)
+		require.Nil(t, r)
+		require.EqualError(t, f.val(fctx, []ast.Expr{&ast.StringLiteral{Val: "a"}}), "Expect number - float or int type for parameter 1")
+	}
 }
 
 func TestAggFuncNil(t *testing.T) {
@@ -396,6 +978,9 @@ func TestAggFuncNil(t *testing.T) {
 			r, b = function.check([]interface{}{nil})
 			require.True(t, b, fmt.Sprintf("%v failed", name))
 			require.Nil(t, r, fmt.Sprintf("%v failed", name))
+			r, b = function.check([]interface{}{[]interface{}{}})
+			require.True(t, b, fmt.Sprintf("%v failed", name))
+			require.Equal(t, r, 0, fmt.Sprintf("%v failed", name))
 		case "max":
 			r, b := function.exec(fctx, []interface{}{[]interface{}{nil}})
 			require.True(t, b, fmt.Sprintf("%v failed", name))
@@ -426,7 +1011,7 @@ func TestAggFuncNil(t *testing.T) {
 			r, b = function.check([]interface{}{nil})
 			require.True(t, b, fmt.Sprintf("%v failed", name))
 			require.Nil(t, r, fmt.Sprintf("%v failed", name))
-		case "collect":
+		case "collect", "array_agg":
 			r, b := function.exec(fctx, []interface{}{nil})
 			require.True(t, b, fmt.Sprintf("%v failed", name))
 			require.Nil(t, r, fmt.Sprintf("%v failed", name))
@@ -623,6 +1208,25 @@ func TestLastValue(t *testing.T) {
 			},
 			result: nil,
 		},
+		{
+			args: []interface{}{
+				[]interface{}{
+					"foo",
+					"bar",
+					nil,
+				},
+			},
+			result: "bar",
+		},
+		{
+			args: []interface{}{
+				[]interface{}{
+					nil,
+					nil,
+				},
+			},
+			result: nil,
+		},
 	}
 
 	for i, tt := range tests {
@@ -644,9 +1248,15 @@ func TestLastValueValidation(t *testing.T) {
 	}{
 		{
 			args: []ast.Expr{
+				&ast.FieldRef{Name: "foo"},
+			},
+		}, {
+			args: []ast.Expr{
+				&ast.FieldRef{Name: "foo"},
+				&ast.BooleanLiteral{Val: true},
 				&ast.BooleanLiteral{Val: true},
 			},
-			err: fmt.Errorf("Expect 2 arguments but found 1."),
+			err: errors.New("Expect 1 or 2 arguments only"),
 		}, {
 			args: []ast.Expr{
 				&ast.FieldRef{Name: "foo"},
@@ -749,7 +1359,19 @@ func TestMedianFunc(t *testing.T) {
 	}{
 		{
 			args:   []interface{}{},
-			expect: int64(0),
+			expect: nil,
+		},
+		{
+			args:   []interface{}{nil, int64(2), nil, int64(1)},
+			expect: 1.5,
+		},
+		{
+			args:   []interface{}{int64(1), 2.5, int64(3)},
+			expect: 2.5,
+		},
+		{
+			args:   []interface{}{int64(1), "a"},
+			expect: errors.New("requires float64 but found string(a)"),
 		},
 		{
 			args: []interface{}{
@@ -766,7 +1388,380 @@ func TestMedianFunc(t *testing.T) {
 	}
 	for _, tt := range tests {
 		got, ok := fm.exec(fctx, []interface{}{tt.args})
-		require.True(t, ok)
+		_, isErr := tt.expect.(error)
+		require.Equal(t, !isErr, ok)
 		require.Equal(t, tt.expect, got)
 	}
 }
+
+func TestPercentileFunc(t *testing.T) {
+	f, ok := builtins["percentile"]
+	require.True(t, ok)
+	contextLogger := conf.Log.WithField("rule", "testExec")
+	ctx := kctx.WithValue(kctx.Background(), kctx.LoggerKey, contextLogger)
+	tempStore, _ := state.CreateStore("mockRule0", def.AtMostOnce)
+	fctx := kctx.NewDefaultFuncContext(ctx.WithMeta("mockRule0", "test", tempStore), 2)
+	tests := []struct {
+		name   string
+		args   []interface{}
+		result interface{}
+	}{
+		{
+			name:   "median",
+			args:   []interface{}{[]interface{}{4, 1, nil, 3, 2}, []interface{}{0.5}},
+			result: 2.5,
+		},
+		{
+			name:   "interpolate",
+			args:   []interface{}{[]interface{}{10, 20, 30, 40, 50}, []interface{}{0.95}},
+			result: 48.0,
+		},
+		{
+			name:   "min",
+			args:   []interface{}{[]interface{}{3.5, 1.5}, []interface{}{0}},
+			result: 1.5,
+		},
+		{
+			name:   "low percentile of few values",
+			args:   []interface{}{[]interface{}{10, 20, 30}, []interface{}{0.01}},
+			result: 10.2,
+		},
+		{
+			name:   "quartile of few values",
+			args:   []interface{}{[]interface{}{10, 20, 30}, []interface{}{0.25}},
+			result: 15.0,
+		},
+		{
+			name:   "max",
+			args:   []interface{}{[]interface{}{3.5, 1.5}, []interface{}{1}},
+			result: 3.5,
+		},
+		{
+			name:   "empty",
+			args:   []interface{}{[]interface{}{nil}, []interface{}{0.5}},
+			result: nil,
+		},
+		{
+			name:   "non numeric",
+			args:   []interface{}{[]interface{}{1, "a"}, []interface{}{0.5}},
+			result: errors.New("requires float64 but found string(a)"),
+		},
+		{
+			name:   "out of range",
+			args:   []interface{}{[]interface{}{1, 2}, []interface{}{95}},
+			result: errors.New("the percentile must be between 0 and 1 but found 95"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := f.exec(fctx, tt.args)
+			require.Equal(t, tt.result, r)
+		})
+	}
+}
+
+func TestPercentileMedian(t *testing.T) {
+	fp, ok := builtins["percentile"]
+	require.True(t, ok)
+	fm, ok := builtins["median"]
+	require.True(t, ok)
+	contextLogger := conf.Log.WithField("rule", "testExec")
+	ctx := kctx.WithValue(kctx.Background(), kctx.LoggerKey, contextLogger)
+	tempStore, _ := state.CreateStore("mockRule0", def.AtMostOnce)
+	fctx := kctx.NewDefaultFuncContext(ctx.WithMeta("mockRule0", "test", tempStore), 2)
+	tests := [][]interface{}{
+		{1.0, 2.0, 3.0, 4.0},
+		{5.5, 1.5, 3.5},
+		{4.0, nil, 1.0, 2.5, 10.0, 7.0, 3.0},
+		{2.0},
+		{int64(3), int64(1), int64(2)},
+		{int64(4), int64(1), int64(3), int64(2)},
+	}
+	for _, tt := range tests {
+		p, ok := fp.exec(fctx, []interface{}{tt, []interface{}{0.5}})
+		require.True(t, ok)
+		m, ok := fm.exec(fctx, []interface{}{tt})
+		require.True(t, ok)
+		// median keeps the int type of the int values
+		require.EqualValues(t, m, p, "values %v", tt)
+	}
+}
+
+func TestGeomeanExec(t *testing.T) {
+	f, ok := builtins["geomean"]
+	require.True(t, ok)
+	contextLogger := conf.Log.WithField("rule", "testExec")
+	ctx := kctx.WithValue(kctx.Background(), kctx.LoggerKey, contextLogger)
+	tempStore, _ := state.CreateStore("mockRule0", def.AtMostOnce)
+	fctx := kctx.NewDefaultFuncContext(ctx.WithMeta("mockRule0", "test", tempStore), 2)
+	tests := []struct {
+		name   string
+		args   []interface{}
+		result interface{}
+	}{
+		{
+			name: "positive",
+			args: []interface{}{
+				[]interface{}{2, nil, 8.0},
+			},
+			result: 4.0,
+		},
+		{
+			name: "empty",
+			args: []interface{}{
+				[]interface{}{nil},
+			},
+			result: nil,
+		},
+		{
+			name: "zero value",
+			args: []interface{}{
+				[]interface{}{2, 0},
+			},
+			result: errors.New("requires positive number but found 0"),
+		},
+		{
+			name: "negative value",
+			args: []interface{}{
+				[]interface{}{2, -1.5},
+			},
+			result: errors.New("requires positive number but found -1.5"),
+		},
+		{
+			name: "non numeric value",
+			args: []interface{}{
+				[]interface{}{1, "a"},
+			},
+			result: errors.New("requires number but found string(a)"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := f.exec(fctx, tt.args)
+			require.Equal(t, tt.result, r)
+		})
+	}
+}
+
+func TestHarmmeanExec(t *testing.T) {
+	f, ok := builtins["harmmean"]
+	require.True(t, ok)
+	contextLogger := conf.Log.WithField("rule", "testExec")
+	ctx := kctx.WithValue(kctx.Background(), kctx.LoggerKey, contextLogger)
+	tempStore, _ := state.CreateStore("mockRule0", def.AtMostOnce)
+	fctx := kctx.NewDefaultFuncContext(ctx.WithMeta("mockRule0", "test", tempStore), 2)
+	tests := []struct {
+		name   string
+		args   []interface{}
+		result interface{}
+	}{
+		{
+			name: "positive",
+			args: []interface{}{
+				[]interface{}{2, nil, 6.0},
+			},
+			result: 3.0,
+		},
+		{
+			name: "empty",
+			args: []interface{}{
+				[]interface{}{nil},
+			},
+			result: nil,
+		},
+		{
+			name: "zero value",
+			args: []interface{}{
+				[]interface{}{2, 0},
+			},
+			result: errors.New("requires non-zero number but found 0"),
+		},
+		{
+			name: "zero reciprocal sum",
+			args: []interface{}{
+				[]interface{}{2, -2},
+			},
+			result: errors.New("the sum of the reciprocals is zero"),
+		},
+		{
+			name: "non numeric value",
+			args: []interface{}{
+				[]interface{}{1, "a"},
+			},
+			result: errors.New("requires number but found string(a)"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := f.exec(fctx, tt.args)
+			require.Equal(t, tt.result, r)
+		})
+	}
+}
+
+func TestModeBinnedExec(t *testing.T) {
+	f, ok := builtins["mode_binned"]
+	require.True(t, ok)
+	contextLogger := conf.Log.WithField("rule", "testExec")
+	ctx := kctx.WithValue(kctx.Background(), kctx.LoggerKey, contextLogger)
+	tempStore, _ := state.CreateStore("mockRule0", def.AtMostOnce)
+	fctx := kctx.NewDefaultFuncContext(ctx.WithMeta("mockRule0", "test", tempStore), 2)
+	tests := []struct {
+		name   string
+		args   []interface{}
+		result interface{}
+	}{
+		{
+			name:   "clustered",
+			args:   []interface{}{[]interface{}{1.2, 20.1, 21.5, nil, 23.9, 38, 39}, []interface{}{5}},
+			result: 22.5,
+		},
+		{
+			name:   "negative",
+			args:   []interface{}{[]interface{}{-0.5, -0.2, 0.3}, []interface{}{1}},
+			result: -0.5,
+		},
+		{
+			name:   "tie",
+			args:   []interface{}{[]interface{}{12, 2, 11, 3}, []interface{}{10}},
+			result: 5.0,
+		},
+		{
+			name:   "empty",
+			args:   []interface{}{[]interface{}{nil}, []interface{}{5}},
+			result: nil,
+		},
+		{
+			name:   "non numeric",
+			args:   []interface{}{[]interface{}{1, "a"}, []interface{}{5}},
+			result: errors.New("requires number but found string(a)"),
+		},
+		{
+			name:   "invalid width",
+			args:   []interface{}{[]interface{}{1, 2}, []interface{}{0}},
+			result: errors.New("the bin width must be positive but found 0"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := f.exec(fctx, tt.args)
+			require.Equal(t, tt.result, r)
+		})
+	}
+}
+
+func TestIQRExec(t *testing.T) {
+	f, ok := builtins["iqr"]
+	require.True(t, ok)
+	contextLogger := conf.Log.WithField("rule", "testExec")
+	ctx := kctx.WithValue(kctx.Background(), kctx.LoggerKey, contextLogger)
+	tempStore, _ := state.CreateStore("mockRule0", def.AtMostOnce)
+	fctx := kctx.NewDefaultFuncContext(ctx.WithMeta("mockRule0", "test", tempStore), 2)
+	tests := []struct {
+		name   string
+		args   []interface{}
+		result interface{}
+	}{
+		{
+			name:   "outlier",
+			args:   []interface{}{[]interface{}{5, 1, 100, nil, 3, 2, 4}},
+			result: 2.5,
+		},
+		{
+			name:   "float",
+			args:   []interface{}{[]interface{}{1.5, 0.5}},
+			result: 0.5,
+		},
+		{
+			name:   "single",
+			args:   []interface{}{[]interface{}{1, nil}},
+			result: nil,
+		},
+		{
+			name:   "empty",
+			args:   []interface{}{[]interface{}{}},
+			result: nil,
+		},
+		{
+			name:   "non numeric",
+			args:   []interface{}{[]interface{}{1, "a"}},
+			result: errors.New("requires float64 but found string(a)"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := f.exec(fctx, tt.args)
+			require.Equal(t, tt.result, r)
+		})
+	}
+}
+
+func TestSkewnessKurtosisExec(t *testing.T) {
+	skewness, ok := builtins["skewness"]
+	require.True(t, ok)
+	kurtosis, ok := builtins["kurtosis"]
+	require.True(t, ok)
+	contextLogger := conf.Log.WithField("rule", "testExec")
+	ctx := kctx.WithValue(kctx.Background(), kctx.LoggerKey, contextLogger)
+	tempStore, _ := state.CreateStore("mockRule0", def.AtMostOnce)
+	fctx := kctx.NewDefaultFuncContext(ctx.WithMeta("mockRule0", "test", tempStore), 2)
+	tests := []struct {
+		name     string
+		args     []interface{}
+		skewness interface{}
+		kurtosis interface{}
+	}{
+		{
+			name:     "right tail",
+			args:     []interface{}{[]interface{}{1, 1, nil, 1, 1, 6}},
+			skewness: 1.5,
+			kurtosis: 0.25,
+		},
+		{
+			name:     "symmetric",
+			args:     []interface{}{[]interface{}{-2.0, 2.0}},
+			skewness: 0.0,
+			kurtosis: -2.0,
+		},
+		{
+			name:     "uniform",
+			args:     []interface{}{[]interface{}{1, 2, 3, 4, 5}},
+			skewness: 0.0,
+			kurtosis: -1.3,
+		},
+		{
+			name:     "single",
+			args:     []interface{}{[]interface{}{1, nil}},
+			skewness: nil,
+			kurtosis: nil,
+		},
+		{
+			name:     "constant",
+			args:     []interface{}{[]interface{}{2, 2, 2}},
+			skewness: nil,
+			kurtosis: nil,
+		},
+		{
+			name:     "non numeric",
+			args:     []interface{}{[]interface{}{1, "a"}},
+			skewness: errors.New("requires number but found string(a)"),
+			kurtosis: errors.New("requires number but found string(a)"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := skewness.exec(fctx, tt.args)
+			if f, ok := tt.skewness.(float64); ok {
+				require.InDelta(t, f, r, 1e-9)
+			} else {
+				require.Equal(t, tt.skewness, r)
+			}
+			r, _ = kurtosis.exec(fctx, tt.args)
+			if f, ok := tt.kurtosis.(float64); ok {
+				require.InDelta(t, f, r, 1e-9)
+			} else {
+				require.Equal(t, tt.kurtosis, r)
+			}
+		})
+	}
+}
@@ -16,6 +16,7 @@ package function
 
 import (
 	"fmt"
+	"math"
 	"math/rand"
 	"reflect"
 	"sort"
@@ -82,6 +83,61 @@ func registerArrayFunc() {
 			return ValidateLen(2, len(args))
 		},
 	}
+	builtins["array_find"] = builtinFunc{
+		fType: ast.FuncTypeScalar,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			array, ok := args[0].([]interface{})
+			if !ok {
+				return errorArrayFirstArgumentNotArrayError, false
+			}
+			field, ok := args[1].(string)
+			if !ok {
+				return errorArraySecondArgumentNotStringError, false
+			}
+			for _, item := range array {
+				m, ok := item.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				if v, ok := m[field]; ok && sqlEqual(v, args[2]) {
+					return m, true
+				}
+			}
+			return nil, true
+		},
+		val: func(ctx api.FunctionContext, args []ast.Expr) error {
+			return ValidateLen(3, len(args))
+		},
+		check: returnNilIfAnyArgNil,
+	}
+	builtins["merge_by_key"] = builtinFunc{
+		fType: ast.FuncTypeScalar,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			array, ok := args[0].([]interface{})
+			if !ok {
+				return errorArrayFirstArgumentNotArrayError, false
+			}
+			field, ok := args[1].(string)
+			if !ok {
+				return errorArraySecondArgumentNotStringError, false
+			}
+			r, err := mergeByKey(array, field)
+			if err != nil {
+				return err, false
+			}
+			return r, true
+		},
+		val: func(ctx api.FunctionContext, args []ast.Expr) error {
+			if err := ValidateLen(2, len(args)); err != nil {
+				return err
+			}
+			if ast.IsNumericArg(args[1]) || ast.IsTimeArg(args[1]) || ast.IsBooleanArg(args[1]) {
+				return ProduceErrInfo(1, "string")
+			}
+			return nil
+		},
+		check: returnNilIfHasAnyNil,
+	}
 	builtins["element_at"] = builtinFunc{
 		fType: ast.FuncTypeScalar,
 		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
@@ -225,26 +281,7 @@ func registerArrayFunc() {
 				return errorArraySecondArgumentNotArrayError, false
 			}
 
-			capacity := len(array1)
-			if len(array2) > capacity {
-				capacity = len(array2)
-			}
-
-			intersection := make([]interface{}, 0, capacity)
-			set := make(map[interface{}]bool)
-
-			for _, a := range array1 {
-				set[a] = true
-			}
-
-			for _, b := range array2 {
-				if set[b] {
-					intersection = append(intersection, b)
-					set[b] = false
-				}
-			}
-
-			return intersection, true
+			return arraySetFilter(array1, array2, true), true
 		},
 		val: func(ctx api.FunctionContext, args []ast.Expr) error {
 			return ValidateLen(2, len(args))
@@ -318,6 +355,102 @@ func registerArrayFunc() {
 		},
 		check: returnNilIfHasAnyNil,
 	}
+	builtins["array_reduce"] = builtinFunc{
+		fType: ast.FuncTypeScalar,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			array, ok := args[0].([]interface{})
+			if !ok {
+				return errorArrayFirstArgumentNotArrayError, false
+			}
+			op, ok := args[1].(string)
+			if !ok {
+				return errorArraySecondArgumentNotStringError, false
+			}
+			op = strings.ToLower(op)
+			if !isReduceOp(op) {
+				return fmt.Errorf("unsupported reduce op %s, expect one of add, mul, min, max, concat", op), false
+			}
+			acc := args[2]
+			for i, v := range array {
+				if v == nil {
+					continue
+				}
+				r, err := reduceStep(op, acc, v)
+				if err != nil {
+					return fmt.Errorf("array_reduce element %d: %v", i, err), false
+				}
+				acc = r
+			}
+			return acc, true
+		},
+		val: func(ctx api.FunctionContext, args []ast.Expr) error {
+			if err := ValidateLen(3, len(args)); err != nil {
+				return err
+			}
+			if ast.IsNumericArg(args[1]) || ast.IsTimeArg(args[1]) || ast.IsBooleanArg(args[1]) {
+				return ProduceErrInfo(1, "string")
+			}
+			if s, ok := args[1].(*ast.StringLiteral); ok && !isReduceOp(strings.ToLower(s.Val)) {
+				return fmt.Errorf("unsupported reduce op %s, expect one of add, mul, min, max, concat", s.Val)
+			}
+			return nil
+		},
+		check: returnNilIfHasAnyNil,
+	}
+	builtins["array_argmax"] = builtinFunc{
+		fType: ast.FuncTypeScalar,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			array, ok := args[0].([]interface{})
+			if !ok {
+				return errorArrayFirstArgumentNotArrayError, false
+			}
+			r, err := argExtreme(array, true)
+			if err != nil {
+				return err, false
+			}
+			return r, true
+		},
+		val: func(ctx api.FunctionContext, args []ast.Expr) error {
+			return ValidateLen(1, len(args))
+		},
+		check: returnNilIfHasAnyNil,
+	}
+	builtins["array_argmin"] = builtinFunc{
+		fType: ast.FuncTypeScalar,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			array, ok := args[0].([]interface{})
+			if !ok {
+				return errorArrayFirstArgumentNotArrayError, false
+			}
+			r, err := argExtreme(array, false)
+			if err != nil {
+				return err, false
+			}
+			return r, true
+		},
+		val: func(ctx api.FunctionContext, args []ast.Expr) error {
+			return ValidateLen(1, len(args))
+		},
+		check: returnNilIfHasAnyNil,
+	}
+	builtins["array_diff"] = builtinFunc{
+		fType: ast.FuncTypeScalar,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			array, ok := args[0].([]interface{})
+			if !ok {
+				return errorArrayFirstArgumentNotArrayError, false
+			}
+			r, err := arrayDiff(array)
+			if err != nil {
+				return err, false
+			}
+			return r, true
+		},
+		val: func(ctx api.FunctionContext, args []ast.Expr) error {
+			return ValidateLen(1, len(args))
+		},
+		check: returnNilIfAnyArgNil,
+	}
 	builtins["array_except"] = builtinFunc{
 		fType: ast.FuncTypeScalar,
 		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
@@ -336,21 +469,7 @@ func registerArrayFunc() {
 					return errorArraySecondArgumentNotArrayError, false
 				}
 			}
-			except := make([]interface{}, 0, len(array1))
-			set := make(map[interface{}]bool)
-
-			for _, v := range array2 {
-				set[v] = true
-			}
-
-			for _, v := range array1 {
-				if !set[v] {
-					except = append(except, v)
-					set[v] = true
-				}
-			}
-
-			return except, true
+			return arraySetFilter(array1, array2, false), true
 		},
 		val: func(ctx api.FunctionContext, args []ast.Expr) error {
 			return ValidateLen(2, len(args))
@@ -675,6 +794,122 @@ func registerArrayFunc() {
 			return ValidateAtLeast(1, len(args))
 		},
 	}
+	builtins["dot"] = builtinFunc{
+		fType: ast.FuncTypeScalar,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			a1, a2, err := toFloatVectors(args[0], args[1])
+			if err != nil {
+				return err, false
+			}
+			return dotProduct(a1, a2), true
+		},
+		val: func(ctx api.FunctionContext, args []ast.Expr) error {
+			return ValidateLen(2, len(args))
+		},
+		check: returnNilIfAnyArgNil,
+	}
+	builtins["cosine_similarity"] = builtinFunc{
+		fType: ast.FuncTypeScalar,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			a1, a2, err := toFloatVectors(args[0], args[1])
+			if err != nil {
+				return err, false
+			}
+			norm := math.Sqrt(dotProduct(a1, a1)) * math.Sqrt(dotProduct(a2, a2))
+			// the similarity of zero vector is undefined, return 0
+			if norm == 0 {
+				return 0.0, true
+			}
+			// avoid the float error exceeding [-1, 1]
+			return math.Max(-1, math.Min(1, dotProduct(a1, a2)/norm)), true
+		},
+		val: func(ctx api.FunctionContext, args []ast.Expr) error {
+			return ValidateLen(2, len(args))
+		},
+		check: returnNilIfAnyArgNil,
+	}
+	builtins["head"] = builtinFunc{
+		fType: ast.FuncTypeScalar,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			v := reflect.ValueOf(args[0])
+			if v.Kind() != reflect.Slice {
+				return errorArrayFirstArgumentNotArrayError, false
+			}
+			if v.Len() == 0 {
+				return nil, true
+			}
+			return v.Index(0).Interface(), true
+		},
+		val: func(ctx api.FunctionContext, args []ast.Expr) error {
+			return ValidateLen(1, len(args))
+		},
+		check: returnNilIfHasAnyNil,
+	}
+	builtins["last"] = builtinFunc{
+		fType: ast.FuncTypeScalar,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			v := reflect.ValueOf(args[0])
+			if v.Kind() != reflect.Slice {
+				return errorArrayFirstArgumentNotArrayError, false
+			}
+			if v.Len() == 0 {
+				return nil, true
+			}
+			return v.Index(v.Len() - 1).Interface(), true
+		},
+		val: func(ctx api.FunctionContext, args []ast.Expr) error {
+			return ValidateLen(1, len(args))
+		},
+		check: returnNilIfHasAnyNil,
+	}
+	builtins["tail"] = builtinFunc{
+		fType: ast.FuncTypeScalar,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			v := reflect.ValueOf(args[0])
+			if v.Kind() != reflect.Slice {
+				return errorArrayFirstArgumentNotArrayError, false
+			}
+			// keep the slice type and return an empty array for an empty input
+			if v.Len() == 0 {
+				return args[0], true
+			}
+			return v.Slice(1, v.Len()).Interface(), true
+		},
+		val: func(ctx api.FunctionContext, args []ast.Expr) error {
+			return ValidateLen(1, len(args))
+		},
+		check: func(args []interface{}) (interface{}, bool) {
+			if args[0] == nil {
+				return nil, true
+			}
+			return nil, false
+		},
+	}
+	builtins["unwrap"] = builtinFunc{
+		fType: ast.FuncTypeScalar,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			// bytea is not an array, so return the non-array value as it is
+			if _, ok := args[0].([]byte); ok {
+				return args[0], true
+			}
+			v := reflect.ValueOf(args[0])
+			if v.Kind() != reflect.Slice {
+				return args[0], true
+			}
+			switch v.Len() {
+			case 0:
+				return nil, true
+			case 1:
+				return v.Index(0).Interface(), true
+			default:
+				return args[0], true
+			}
+		},
+		val: func(ctx api.FunctionContext, args []ast.Expr) error {
+			return ValidateLen(1, len(args))
+		},
+		check: returnNilIfHasAnyNil,
+	}
 	builtins["kvpair_array_to_obj"] = builtinFunc{
 		fType: ast.FuncTypeScalar,
 		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
@@ -705,4 +940,204 @@ func registerArrayFunc() {
 		val:   ValidateOneArg,
 		check: returnNilIfHasAnyNil,
 	}
+	builtins["kv_expand"] = builtinFunc{
+		fType: ast.FuncTypeScalar,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			arr, ok := args[0].([]interface{})
+			if !ok {
+				return errorArrayFirstArgumentNotArrayError, false
+			}
+			kName, ok := args[1].(string)
+			if !ok {
+				return errorArraySecondArgumentNotStringError, false
+			}
+			vName, ok := args[2].(string)
+			if !ok {
+				return errorArrayThirdArgumentNotStringError, false
+			}
+
+			obj := make(map[string]interface{}, len(arr))
+			for _, item := range arr {
+				pair, ok := item.(map[string]interface{})
+				if !ok {
+					return fmt.Errorf("array item should be map[string]interface{}"), false
+				}
+				// skip the elements without key or value
+				k, kExist := pair[kName]
+				v, vExist := pair[vName]
+				if !kExist || !vExist {
+					continue
+				}
+				kInStr, ok := k.(string)
+				if !ok {
+					continue
+				}
+				obj[kInStr] = v
+			}
+			return obj, true
+		},
+		val: func(ctx api.FunctionContext, args []ast.Expr) error {
+			if err := ValidateLen(3, len(args)); err != nil {
+				return err
+			}
+			for i := 1; i < 3; i++ {
+				if ast.IsNumericArg(args[i]) || ast.IsTimeArg(args[i]) || ast.IsBooleanArg(args[i]) {
+					return ProduceErrInfo(i, "string")
+				}
+			}
+			return nil
+		},
+		check: returnNilIfHasAnyNil,
+	}
+}
+
+// toFloatVectors converts the two arrays to float slices of the same length. The error names the invalid element index.
+func toFloatVectors(arg1, arg2 interface{}) ([]float64, []float64, error) {
+	v1, v2 := reflect.ValueOf(arg1), reflect.ValueOf(arg2)
+	if v1.Kind() != reflect.Slice {
+		return nil, nil, errorArrayFirstArgumentNotArrayError
+	}
+	if v2.Kind() != reflect.Slice {
+		return nil, nil, errorArraySecondArgumentNotArrayError
+	}
+	if v1.Len() != v2.Len() {
+		return nil, nil, fmt.Errorf("the two arrays must have the same length but found %d and %d", v1.Len(), v2.Len())
+	}
+	a1, a2 := make([]float64, v1.Len()), make([]float64, v2.Len())
+	for i := 0; i < v1.Len(); i++ {
+		e1, e2 := v1.Index(i).Interface(), v2.Index(i).Interface()
+		f1, err := cast.ToFloat64(e1, cast.CONVERT_SAMEKIND)
+		if err != nil {
+			return nil, nil, fmt.Errorf("requires number at index %d of the first array but found %[2]T(%[2]v)", i, e1)
+		}
+		f2, err := cast.ToFloat64(e2, cast.CONVERT_SAMEKIND)
+		if err != nil {
+			return nil, nil, fmt.Errorf("requires number at index %d of the second array but found %[2]T(%[2]v)", i, e2)
+		}
+		a1[i], a2[i] = f1, f2
+	}
+	return a1, a2, nil
+}
+
+// mergeByKey merges the objects of the array which have the same value of the key field. The later fields override
+// the earlier ones. The objects without the key field or with a nil key are merged together. The merged objects are
+// returned in the order of the first appearance of their keys.
+func mergeByKey(array []interface{}, field string) ([]interface{}, error) {
+	var (
+		keys    []interface{}
+		merged  []map[string]interface{}
+		nullIdx = -1
+	)
+	for i, item := range array {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("array element %d should be object but found %[2]T(%[2]v)", i, item)
+		}
+		k := m[field]
+		idx := -1
+		if k == nil {
+			idx = nullIdx
+		} else {
+			for j, key := range keys {
+				if valueEqual(key, k) {
+					idx = j
+					break
+				}
+			}
+		}
+		if idx < 0 {
+			idx = len(merged)
+			if k == nil {
+				nullIdx = idx
+			}
+			keys = append(keys, k)
+			merged = append(merged, make(map[string]interface{}, len(m)))
+		}
+		for key, v := range m {
+			merged[idx][key] = v
+		}
+	}
+	result := make([]interface{}, len(merged))
+	for i, m := range merged {
+		result[i] = m
+	}
+	return result, nil
+}
+
+func dotProduct(a1, a2 []float64) float64 {
+	var result float64
+	for i := range a1 {
+		result += a1[i] * a2[i]
+	}
+	return result
+}
+
+func isReduceOp(op string) bool {
+	switch op {
+	case "add", "mul", "min", "max", "concat":
+		return true
+	default:
+		return false
+	}
+}
+
+// reduceStep folds one element into the accumulator. Integers stay integers
+// for add and mul as long as both operands are integers.
+func reduceStep(op string, acc, v interface{}) (interface{}, error) {
+	if op == "concat" {
+		s1, err := cast.ToString(acc, cast.CONVERT_ALL)
+		if err != nil {
+			return nil, fmt.Errorf("cannot convert accumulator %[1]T(%[1]v) to string", acc)
+		}
+		s2, err := cast.ToString(v, cast.CONVERT_ALL)
+		if err != nil {
+			return nil, fmt.Errorf("cannot convert %[1]T(%[1]v) to string", v)
+		}
+		return s1 + s2, nil
+	}
+	f1, err := cast.ToFloat64(acc, cast.CONVERT_SAMEKIND)
+	if err != nil {
+		return nil, fmt.Errorf("requires number accumulator but found %[1]T(%[1]v)", acc)
+	}
+	f2, err := cast.ToFloat64(v, cast.CONVERT_SAMEKIND)
+	if err != nil {
+		return nil, fmt.Errorf("requires number but found %[1]T(%[1]v)", v)
+	}
+	switch op {
+	case "min":
+		if f2 < f1 {
+			return v, nil
+		}
+		return acc, nil
+	case "max":
+		if f2 > f1 {
+			return v, nil
+		}
+		return acc, nil
+	}
+	i1, ok1 := toReduceInt(acc)
+	i2, ok2 := toReduceInt(v)
+	if ok1 && ok2 {
+		if op == "add" {
+			return i1 + i2, nil
+		}
+		return i1 * i2, nil
+	}
+	if op == "add" {
+		return f1 + f2, nil
+	}
+	return f1 * f2, nil
+}
+
+func toReduceInt(v interface{}) (int64, bool) {
+	switch t := v.(type) {
+	case int:
+		return int64(t), true
+	case int32:
+		return int64(t), true
+	case int64:
+		return t, true
+	default:
+		return 0, false
+	}
 }
@@ -261,6 +261,21 @@ func TestArrayCommonFunctions(t *testing.T) {
 			},
 			result: errorArrayFirstArgumentNotArrayError,
 		},
+		{
+			name: "array_intersect",
+			args: []interface{}{
+				[]interface{}{"c", "a", "b", "a"}, []interface{}{"a", "b", "c"},
+			},
+			result: []interface{}{"c", "a", "b"},
+		},
+		{
+			name: "array_intersect",
+			args: []interface{}{
+				[]interface{}{map[string]interface{}{"k": "v1"}, []interface{}{1, 2}, map[string]interface{}{"k": "v2"}},
+				[]interface{}{[]interface{}{1, 2}, map[string]interface{}{"k": "v2"}, "x"},
+			},
+			result: []interface{}{[]interface{}{1, 2}, map[string]interface{}{"k": "v2"}},
+		},
 		{
 			name: "array_union",
 			args: []interface{}{
@@ -366,6 +381,83 @@ func TestArrayCommonFunctions(t *testing.T) {
 			},
 			result: int64(1),
 		},
+		{
+			name: "array_argmax",
+			args: []interface{}{
+				[]interface{}{1, 3.2, 4.1, 2, 4.1},
+			},
+			result: 2,
+		},
+		{
+			name: "array_argmax",
+			args: []interface{}{
+				[]interface{}{nil, int64(-1), nil},
+			},
+			result: 1,
+		},
+		{
+			name: "array_argmax",
+			args: []interface{}{
+				[]interface{}{},
+			},
+			result: nil,
+		},
+		{
+			name: "array_argmax",
+			args: []interface{}{
+				[]interface{}{1, "a", 3},
+			},
+			result: errors.New("requires numeric element but found string(a) at index 1"),
+		},
+		{
+			name: "array_argmin",
+			args: []interface{}{
+				[]interface{}{3, 1.5, 4.1, 1.5, 2},
+			},
+			result: 1,
+		},
+		{
+			name: "array_argmin",
+			args: []interface{}{
+				[]interface{}{nil, nil},
+			},
+			result: nil,
+		},
+		{
+			name: "array_argmin",
+			args: []interface{}{
+				[]interface{}{1, 2, true},
+			},
+			result: errors.New("requires numeric element but found bool(true) at index 2"),
+		},
+		{
+			name: "array_diff",
+			args: []interface{}{
+				[]interface{}{1, 3, int64(6), 10.5, 10.5, 8},
+			},
+			result: []interface{}{int64(2), int64(3), 4.5, 0.0, -2.5},
+		},
+		{
+			name: "array_diff",
+			args: []interface{}{
+				[]interface{}{1, nil, 3, 4},
+			},
+			result: []interface{}{nil, nil, int64(1)},
+		},
+		{
+			name: "array_diff",
+			args: []interface{}{
+				[]interface{}{1},
+			},
+			result: []interface{}{},
+		},
+		{
+			name: "array_diff",
+			args: []interface{}{
+				[]interface{}{1, 2, "a"},
+			},
+			result: errors.New("requires numeric element but found string(a) at index 2"),
+		},
 		{
 			name: "array_except",
 			args: []interface{}{
@@ -394,6 +486,28 @@ func TestArrayCommonFunctions(t *testing.T) {
 			},
 			result: []interface{}{1, 3},
 		},
+		{
+			name: "array_except",
+			args: []interface{}{
+				[]interface{}{"c", "a", "d", "b"}, []interface{}{"a", "b"},
+			},
+			result: []interface{}{"c", "d"},
+		},
+		{
+			name: "array_except",
+			args: []interface{}{
+				[]interface{}{map[string]interface{}{"k": "v1"}, []interface{}{1, 2}, map[string]interface{}{"k": "v2"}},
+				[]interface{}{[]interface{}{1, 2}, map[string]interface{}{"k": "v2"}, "x"},
+			},
+			result: []interface{}{map[string]interface{}{"k": "v1"}},
+		},
+		{
+			name: "array_except",
+			args: []interface{}{
+				[]interface{}{1, 2}, "x",
+			},
+			result: errorArraySecondArgumentNotArrayError,
+		},
 		{
 			name: "repeat",
 			args: []interface{}{
@@ -791,6 +905,413 @@ func TestArrayCommonFunctions(t *testing.T) {
 			},
 			result: fmt.Errorf("array item should be key-value pair"),
 		},
+		{
+			name: "kv_expand",
+			args: []interface{}{
+				[]interface{}{
+					map[string]interface{}{
+						"k": "unit",
+						"v": "C",
+					},
+					map[string]interface{}{
+						"k": "loc",
+						"v": 1,
+					},
+					map[string]interface{}{
+						"k": "missing",
+					},
+					map[string]interface{}{
+						"v": "missing",
+					},
+				},
+				"k", "v",
+			},
+			result: map[string]interface{}{
+				"unit": "C",
+				"loc":  1,
+			},
+		},
+		{
+			name: "kv_expand",
+			args: []interface{}{
+				[]interface{}{
+					"a",
+				},
+				"k", "v",
+			},
+			result: fmt.Errorf("array item should be map[string]interface{}"),
+		},
+		{
+			name: "kv_expand",
+			args: []interface{}{
+				1, "k", "v",
+			},
+			result: errorArrayFirstArgumentNotArrayError,
+		},
+		{
+			name: "kv_expand",
+			args: []interface{}{
+				[]interface{}{map[string]interface{}{"k": "a", "v": 1}}, 1, "v",
+			},
+			result: errorArraySecondArgumentNotStringError,
+		},
+		{
+			name: "dot",
+			args: []interface{}{
+				[]interface{}{1, 2.5, 3},
+				[]float64{4, 2, 0.5},
+			},
+			result: 10.5,
+		},
+		{
+			name: "dot",
+			args: []interface{}{
+				[]interface{}{},
+				[]interface{}{},
+			},
+			result: 0.0,
+		},
+		{
+			name: "dot",
+			args: []interface{}{
+				[]interface{}{1, 2},
+				[]interface{}{1, 2, 3},
+			},
+			result: fmt.Errorf("the two arrays must have the same length but found 2 and 3"),
+		},
+		{
+			name: "dot",
+			args: []interface{}{
+				[]interface{}{1, 2},
+				[]interface{}{1, "a"},
+			},
+			result: fmt.Errorf("requires number at index 1 of the second array but found string(a)"),
+		},
+		{
+			name: "dot",
+			args: []interface{}{
+				1,
+				[]interface{}{1},
+			},
+			result: errorArrayFirstArgumentNotArrayError,
+		},
+		{
+			name: "cosine_similarity",
+			args: []interface{}{
+				[]interface{}{1, 2, 2},
+				[]interface{}{2, 4, 4},
+			},
+			result: 1.0,
+		},
+		{
+			name: "cosine_similarity",
+			args: []interface{}{
+				[]interface{}{3, 4},
+				[]float64{4, 3},
+			},
+			result: 0.96,
+		},
+		{
+			name: "cosine_similarity",
+			args: []interface{}{
+				[]interface{}{1, 0},
+				[]interface{}{-1, 0},
+			},
+			result: -1.0,
+		},
+		{
+			name: "cosine_similarity",
+			args: []interface{}{
+				[]interface{}{0, 0},
+				[]interface{}{1, 2},
+			},
+			result: 0.0,
+		},
+		{
+			name: "cosine_similarity",
+			args: []interface{}{
+				[]interface{}{1, 2},
+				[]interface{}{1},
+			},
+			result: fmt.Errorf("the two arrays must have the same length but found 2 and 1"),
+		},
+		{
+			name: "cosine_similarity",
+			args: []interface{}{
+				[]interface{}{1, true},
+				[]interface{}{1, 2},
+			},
+			result: fmt.Errorf("requires number at index 1 of the first array but found bool(true)"),
+		},
+		{
+			name: "head",
+			args: []interface{}{
+				[]interface{}{true, false, true},
+			},
+			result: true,
+		},
+		{
+			name: "head",
+			args: []interface{}{
+				[]interface{}{},
+			},
+			result: nil,
+		},
+		{
+			name: "head",
+			args: []interface{}{
+				1,
+			},
+			result: errorArrayFirstArgumentNotArrayError,
+		},
+		{
+			name: "last",
+			args: []interface{}{
+				[]float64{3.14, 3.141, 3.1415},
+			},
+			result: 3.1415,
+		},
+		{
+			name: "last",
+			args: []interface{}{
+				[]interface{}{},
+			},
+			result: nil,
+		},
+		{
+			name: "tail",
+			args: []interface{}{
+				[]map[string]interface{}{{"b": "hello1"}, {"b": "hello2"}, {"b": "hello3"}},
+			},
+			result: []map[string]interface{}{{"b": "hello2"}, {"b": "hello3"}},
+		},
+		{
+			name: "tail",
+			args: []interface{}{
+				[]interface{}{1},
+			},
+			result: []interface{}{},
+		},
+		{
+			name: "tail",
+			args: []interface{}{
+				[]interface{}{},
+			},
+			result: []interface{}{},
+		},
+		{
+			name: "unwrap",
+			args: []interface{}{
+				[]interface{}{},
+			},
+			result: nil,
+		},
+		{
+			name: "unwrap",
+			args: []interface{}{
+				[]interface{}{20.5},
+			},
+			result: 20.5,
+		},
+		{
+			name: "unwrap",
+			args: []interface{}{
+				[]map[string]interface{}{{"b": "hello1"}},
+			},
+			result: map[string]interface{}{"b": "hello1"},
+		},
+		{
+			name: "unwrap",
+			args: []interface{}{
+				[]interface{}{1, 2},
+			},
+			result: []interface{}{1, 2},
+		},
+		{
+			name: "unwrap",
+			args: []interface{}{
+				"hello",
+			},
+			result: "hello",
+		},
+		{
+			name: "unwrap",
+			args: []interface{}{
+				[]byte("a"),
+			},
+			result: []byte("a"),
+		},
+		{
+			name: "array_find",
+			args: []interface{}{
+				[]interface{}{
+					map[string]interface{}{"id": "t1", "v": 20.5},
+					"invalid",
+					map[string]interface{}{"id": int64(2), "v": 21},
+					map[string]interface{}{"id": 2, "v": 22},
+				},
+				"id",
+				2.0,
+			},
+			result: map[string]interface{}{"id": int64(2), "v": 21},
+		},
+		{
+			name: "array_find",
+			args: []interface{}{
+				[]interface{}{
+					map[string]interface{}{"id": "t1", "v": 20.5},
+					map[string]interface{}{"id": "t2", "v": 21},
+				},
+				"id",
+				"t3",
+			},
+			result: nil,
+		},
+		{
+			name: "array_find",
+			args: []interface{}{
+				[]interface{}{map[string]interface{}{"id": "t1"}},
+				1,
+				"t1",
+			},
+			result: errorArraySecondArgumentNotStringError,
+		},
+		{
+			name: "array_reduce",
+			args: []interface{}{
+				[]interface{}{1, 2, 3, 4},
+				"add",
+				0,
+			},
+			result: int64(10),
+		},
+		{
+			name: "array_reduce",
+			args: []interface{}{
+				[]interface{}{1, 2, 3, 4},
+				"MUL",
+				1,
+			},
+			result: int64(24),
+		},
+		{
+			name: "array_reduce",
+			args: []interface{}{
+				[]interface{}{1, 2.5, nil, 4},
+				"add",
+				0,
+			},
+			result: 7.5,
+		},
+		{
+			name: "array_reduce",
+			args: []interface{}{
+				[]interface{}{3, 1.5, 4},
+				"min",
+				10,
+			},
+			result: 1.5,
+		},
+		{
+			name: "array_reduce",
+			args: []interface{}{
+				[]interface{}{3, 1.5, 4},
+				"max",
+				0,
+			},
+			result: 4,
+		},
+		{
+			name: "array_reduce",
+			args: []interface{}{
+				[]interface{}{"b", "c", 1},
+				"concat",
+				"a",
+			},
+			result: "abc1",
+		},
+		{
+			name: "array_reduce",
+			args: []interface{}{
+				[]interface{}{},
+				"add",
+				5,
+			},
+			result: nil,
+		},
+		{
+			name: "array_reduce",
+			args: []interface{}{
+				[]interface{}{1, "x", 3},
+				"add",
+				0,
+			},
+			result: fmt.Errorf("array_reduce element 1: requires number but found string(x)"),
+		},
+		{
+			name: "array_reduce",
+			args: []interface{}{
+				[]interface{}{1, 2},
+				"sub",
+				0,
+			},
+			result: fmt.Errorf("unsupported reduce op sub, expect one of add, mul, min, max, concat"),
+		},
+		{
+			name: "array_reduce",
+			args: []interface{}{
+				1,
+				"add",
+				0,
+			},
+			result: errorArrayFirstArgumentNotArrayError,
+		},
+		{
+			name: "merge_by_key",
+			args: []interface{}{
+				[]interface{}{
+					map[string]interface{}{"id": 1, "temp": 20, "hum": 50},
+					map[string]interface{}{"id": 2, "temp": 30},
+					map[string]interface{}{"id": 1.0, "temp": 21, "status": "ok"},
+				},
+				"id",
+			},
+			result: []interface{}{
+				map[string]interface{}{"id": 1.0, "temp": 21, "hum": 50, "status": "ok"},
+				map[string]interface{}{"id": 2, "temp": 30},
+			},
+		},
+		{
+			name: "merge_by_key",
+			args: []interface{}{
+				[]interface{}{
+					map[string]interface{}{"temp": 20},
+					map[string]interface{}{"id": "a", "temp": 30},
+					map[string]interface{}{"id": nil, "hum": 40},
+				},
+				"id",
+			},
+			result: []interface{}{
+				map[string]interface{}{"temp": 20, "id": nil, "hum": 40},
+				map[string]interface{}{"id": "a", "temp": 30},
+			},
+		},
+		{
+			name: "merge_by_key",
+			args: []interface{}{
+				[]interface{}{map[string]interface{}{"id": 1}, 2},
+				"id",
+			},
+			result: fmt.Errorf("array element 1 should be object but found int(2)"),
+		},
+		{
+			name: "merge_by_key",
+			args: []interface{}{
+				[]interface{}{map[string]interface{}{"id": 1}},
+				1,
+			},
+			result: errorArraySecondArgumentNotStringError,
+		},
 	}
 
 	fe := funcExecutor{}
@@ -966,6 +1487,26 @@ func TestArrayFuncVal(t *testing.T) {
 			},
 			err: fmt.Errorf("Expect 1 arguments but found 2."),
 		},
+		{
+			name:     "array reduce op type",
+			funcName: "array_reduce",
+			args: []ast.Expr{
+				&ast.FieldRef{Name: "a"},
+				&ast.IntegerLiteral{Val: 1},
+				&ast.IntegerLiteral{Val: 0},
+			},
+			err: fmt.Errorf("Expect string type for parameter 2"),
+		},
+		{
+			name:     "array reduce unknown op",
+			funcName: "array_reduce",
+			args: []ast.Expr{
+				&ast.FieldRef{Name: "a"},
+				&ast.StringLiteral{Val: "avg"},
+				&ast.IntegerLiteral{Val: 0},
+			},
+			err: fmt.Errorf("unsupported reduce op avg, expect one of add, mul, min, max, concat"),
+		},
 	}
 
 	for _, tt := range tests {
@@ -17,6 +17,7 @@ package function
 import (
 	"fmt"
 	"reflect"
+	"sync"
 
 	"github.com/lf-edge/ekuiper/contract/v2/api"
 
@@ -25,6 +26,40 @@ import (
 
 type ResultCols map[string]interface{}
 
+// The policies to resolve the collision between the expanded columns and the other selected fields
+const (
+	ExpandOverwrite = "overwrite"
+	ExpandKeep      = "keep"
+	ExpandError     = "error"
+)
+
+// ExpandedCols are the columns promoted from an object by the expand function. The Policy decides how to
+// resolve the collision with the other selected fields, which is done by the project operator.
+type ExpandedCols struct {
+	Cols   ResultCols
+	Policy string
+}
+
+// enrichTables are the static tables for the enrich function. Each table maps the string form of the key to the row.
+var (
+	enrichTables     = make(map[string]map[string]map[string]interface{})
+	enrichTablesLock sync.RWMutex
+)
+
+// RegisterEnrichTable registers or replaces a static table which can be looked up by the enrich function
+func RegisterEnrichTable(name string, rows map[string]map[string]interface{}) {
+	enrichTablesLock.Lock()
+	defer enrichTablesLock.Unlock()
+	enrichTables[name] = rows
+}
+
+// DeregisterEnrichTable removes the static table
+func DeregisterEnrichTable(name string) {
+	enrichTablesLock.Lock()
+	defer enrichTablesLock.Unlock()
+	delete(enrichTables, name)
+}
+
 // ColFunc Functions which will return columns directly instead of a map
 type ColFunc func(ctx api.FunctionContext, args []interface{}, keys []string) (ResultCols, error)
 
@@ -59,6 +94,105 @@ func registerColsFunc() {
 			return nil
 		},
 	}
+	builtins["enrich"] = builtinFunc{
+		fType: ast.FuncTypeCols,
+		exec:  wrapColFunc(enrichFunc),
+		val: func(_ api.FunctionContext, args []ast.Expr) error {
+			if err := ValidateLen(2, len(args)); err != nil {
+				return err
+			}
+			arg := args[1]
+			if cf, ok := arg.(*ast.ColFuncField); ok {
+				arg = cf.Expr
+			}
+			if ast.IsNumericArg(arg) || ast.IsTimeArg(arg) || ast.IsBooleanArg(arg) {
+				return ProduceErrInfo(1, "string")
+			}
+			return nil
+		},
+	}
+	builtins["expand"] = builtinFunc{
+		fType: ast.FuncTypeCols,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			r, err := expandFunc(args[:len(args)-1])
+			if err != nil {
+				return err, false
+			}
+			return r, true
+		},
+		val: func(_ api.FunctionContext, args []ast.Expr) error {
+			if len(args) != 1 && len(args) != 2 {
+				return fmt.Errorf("Expect 1 or 2 arguments but found %d.", len(args))
+			}
+			if len(args) == 2 {
+				arg := args[1]
+				if cf, ok := arg.(*ast.ColFuncField); ok {
+					arg = cf.Expr
+				}
+				if ast.IsNumericArg(arg) || ast.IsTimeArg(arg) || ast.IsBooleanArg(arg) {
+					return ProduceErrInfo(1, "string")
+				}
+				if sl, ok := arg.(*ast.StringLiteral); ok && !isExpandPolicy(sl.Val) {
+					return fmt.Errorf("unsupported expand policy %s, expect one of overwrite, keep, error", sl.Val)
+				}
+			}
+			return nil
+		},
+	}
+}
+
+func isExpandPolicy(p string) bool {
+	return p == ExpandOverwrite || p == ExpandKeep || p == ExpandError
+}
+
+// expandFunc promotes all keys of the object to columns. Nothing is merged if the object is null.
+func expandFunc(args []interface{}) (interface{}, error) {
+	policy := ExpandOverwrite
+	if len(args) > 1 {
+		p, ok := args[1].(string)
+		if !ok || !isExpandPolicy(p) {
+			return nil, fmt.Errorf("unsupported expand policy %v, expect one of overwrite, keep, error", args[1])
+		}
+		policy = p
+	}
+	if args[0] == nil {
+		return nil, nil
+	}
+	m, ok := args[0].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("the argument for the expand function should be object but found %[1]T(%[1]v)", args[0])
+	}
+	return &ExpandedCols{Cols: m, Policy: policy}, nil
+}
+
+// enrichFunc looks up the row by the key in the static table and returns its columns prefixed by the table name.
+// Nothing is merged if the key is nil or not found.
+func enrichFunc(_ api.FunctionContext, args []interface{}, _ []string) (ResultCols, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("expect two args but got %d", len(args))
+	}
+	name, ok := args[1].(string)
+	if !ok {
+		return nil, fmt.Errorf("second arg is not a string but got %v", args[1])
+	}
+	enrichTablesLock.RLock()
+	defer enrichTablesLock.RUnlock()
+	table, ok := enrichTables[name]
+	if !ok {
+		return nil, fmt.Errorf("enrich table %s is not found", name)
+	}
+	if args[0] == nil {
+		return nil, nil
+	}
+	row, ok := table[fmt.Sprintf("%v", args[0])]
+	if !ok {
+		return nil, nil
+	}
+	r := make(ResultCols, len(row))
+	for k, v := range row {
+		r[name+"_"+k] = v
+	}
+	return r, nil
 }
 
 func changedFunc(ctx api.FunctionContext, args []interface{}, keys []string) (ResultCols, error) {
@@ -19,6 +19,8 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/stretchr/testify/require"
+
 	"github.com/lf-edge/ekuiper/v2/internal/conf"
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
 	kctx "github.com/lf-edge/ekuiper/v2/internal/topo/context"
@@ -305,3 +307,58 @@ func TestExecIgnoreNull(t *testing.T) {
 		}
 	}
 }
+
+func TestExpand(t *testing.T) {
+	f, ok := builtins["expand"]
+	require.True(t, ok)
+	tests := []struct {
+		args   []interface{}
+		result interface{}
+	}{
+		{
+			args: []interface{}{
+				map[string]interface{}{"a": 1, "b": "x"},
+				[]string{"obj"},
+			},
+			result: &ExpandedCols{Cols: ResultCols{"a": 1, "b": "x"}, Policy: ExpandOverwrite},
+		},
+		{
+			args: []interface{}{
+				map[string]interface{}{"a": 1},
+				"keep",
+				[]string{"obj", "keep"},
+			},
+			result: &ExpandedCols{Cols: ResultCols{"a": 1}, Policy: ExpandKeep},
+		},
+		{
+			args: []interface{}{
+				nil,
+				[]string{"obj"},
+			},
+			result: nil,
+		},
+		{
+			args: []interface{}{
+				[]interface{}{1, 2},
+				[]string{"obj"},
+			},
+			result: fmt.Errorf("the argument for the expand function should be object but found []interface {}([1 2])"),
+		},
+		{
+			args: []interface{}{
+				map[string]interface{}{"a": 1},
+				"merge",
+				[]string{"obj", "merge"},
+			},
+			result: fmt.Errorf("unsupported expand policy merge, expect one of overwrite, keep, error"),
+		},
+	}
+	for i, tt := range tests {
+		r, _ := f.exec(nil, tt.args)
+		require.Equal(t, tt.result, r, "case %d", i)
+	}
+	require.EqualError(t, f.val(nil, []ast.Expr{}), "Expect 1 or 2 arguments but found 0.")
+	require.EqualError(t, f.val(nil, []ast.Expr{&ast.FieldRef{Name: "a"}, &ast.ColFuncField{Expr: &ast.IntegerLiteral{Val: 1}}}), "Expect string type for parameter 2")
+	require.EqualError(t, f.val(nil, []ast.Expr{&ast.FieldRef{Name: "a"}, &ast.ColFuncField{Expr: &ast.StringLiteral{Val: "merge"}}}), "unsupported expand policy merge, expect one of overwrite, keep, error")
+	require.NoError(t, f.val(nil, []ast.Expr{&ast.FieldRef{Name: "a"}, &ast.ColFuncField{Expr: &ast.StringLiteral{Val: "error"}}}))
+}
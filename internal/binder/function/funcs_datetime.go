@@ -16,6 +16,7 @@ package function
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 	"time"
 
@@ -155,6 +156,38 @@ func registerDateTimeFunc() {
 			return nil
 		},
 	}
+	builtins["add_duration"] = builtinFunc{
+		fType: ast.FuncTypeScalar,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			t, err := cast.InterfaceToTime(args[0], "")
+			if err != nil {
+				return err, false
+			}
+			d, err := cast.InterfaceToDuration(args[1])
+			if err != nil {
+				return fmt.Errorf("invalid duration %v: %v", args[1], err), false
+			}
+			return t.Add(d), true
+		},
+		val: func(ctx api.FunctionContext, args []ast.Expr) error {
+			if err := ValidateLen(2, len(args)); err != nil {
+				return err
+			}
+			if ast.IsNumericArg(args[0]) || ast.IsStringArg(args[0]) || ast.IsBooleanArg(args[0]) {
+				return ProduceErrInfo(0, "datetime")
+			}
+			if ast.IsNumericArg(args[1]) || ast.IsTimeArg(args[1]) || ast.IsBooleanArg(args[1]) {
+				return ProduceErrInfo(1, "string")
+			}
+			if s, ok := args[1].(*ast.StringLiteral); ok {
+				if _, err := time.ParseDuration(s.Val); err != nil {
+					return fmt.Errorf("invalid duration %s: %v", s.Val, err)
+				}
+			}
+			return nil
+		},
+		check: returnNilIfHasAnyNil,
+	}
 	builtins["day_name"] = builtinFunc{
 		fType: ast.FuncTypeScalar,
 		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
@@ -442,6 +475,73 @@ func registerDateTimeFunc() {
 			return nil
 		},
 	}
+	builtins["to_epoch"] = builtinFunc{
+		fType: ast.FuncTypeScalar,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			t, err := cast.InterfaceToTime(args[0], "")
+			if err != nil {
+				return err, false
+			}
+			unit, err := epochUnit(cast.ToStringAlways(args[1]))
+			if err != nil {
+				return err, false
+			}
+			base := time.UnixMilli(0)
+			if len(args) > 2 {
+				baseMs, err := cast.ToInt64(args[2], cast.CONVERT_SAMEKIND)
+				if err != nil {
+					return fmt.Errorf("the base epoch must be an int of milliseconds but got %v", args[2]), false
+				}
+				base = time.UnixMilli(baseMs)
+			}
+			return int64(t.Sub(base) / unit), true
+		},
+		val: func(ctx api.FunctionContext, args []ast.Expr) error {
+			if len(args) != 2 && len(args) != 3 {
+				return errors.New("Expect 2 or 3 arguments only")
+			}
+			if ast.IsNumericArg(args[0]) || ast.IsStringArg(args[0]) || ast.IsBooleanArg(args[0]) {
+				return ProduceErrInfo(0, "datetime")
+			}
+			if ast.IsNumericArg(args[1]) || ast.IsTimeArg(args[1]) || ast.IsBooleanArg(args[1]) {
+				return ProduceErrInfo(1, "string")
+			}
+			if s, ok := args[1].(*ast.StringLiteral); ok {
+				if _, err := epochUnit(s.Val); err != nil {
+					return err
+				}
+			}
+			if len(args) > 2 {
+				if ast.IsFloatArg(args[2]) || ast.IsStringArg(args[2]) || ast.IsTimeArg(args[2]) || ast.IsBooleanArg(args[2]) {
+					return ProduceErrInfo(2, "int")
+				}
+			}
+			return nil
+		},
+		check: returnNilIfHasAnyNil,
+	}
+}
+
+// epochUnit returns the duration of the time unit for to_epoch
+func epochUnit(unit string) (time.Duration, error) {
+	switch strings.ToLower(unit) {
+	case "ns":
+		return time.Nanosecond, nil
+	case "us":
+		return time.Microsecond, nil
+	case "ms":
+		return time.Millisecond, nil
+	case "s":
+		return time.Second, nil
+	case "m":
+		return time.Minute, nil
+	case "h":
+		return time.Hour, nil
+	case "d":
+		return 24 * time.Hour, nil
+	default:
+		return 0, fmt.Errorf("unsupported time unit %s, expect one of ns, us, ms, s, m, h, d", unit)
+	}
 }
 
 func execGetCurrentDate() funcExe {
@@ -918,3 +918,136 @@ func TestValidateFsp(t *testing.T) {
 	err := f(fctx, []ast.Expr{})
 	require.NoError(t, err)
 }
+
+func TestAddDuration(t *testing.T) {
+	f, ok := builtins["add_duration"]
+	require.True(t, ok)
+	contextLogger := conf.Log.WithField("rule", "testExec")
+	ctx := kctx.WithValue(kctx.Background(), kctx.LoggerKey, contextLogger)
+	tempStore, _ := state.CreateStore("mockRule0", def.AtMostOnce)
+	fctx := kctx.NewDefaultFuncContext(ctx.WithMeta("mockRule0", "test", tempStore), 2)
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name   string
+		args   []interface{}
+		result interface{}
+	}{
+		{
+			name:   "minutes",
+			args:   []interface{}{ts, "5m"},
+			result: time.Date(2024, 1, 2, 3, 9, 5, 0, time.UTC),
+		},
+		{
+			name:   "negative",
+			args:   []interface{}{ts, "-1h30m"},
+			result: time.Date(2024, 1, 2, 1, 34, 5, 0, time.UTC),
+		},
+		{
+			name:   "cross day",
+			args:   []interface{}{ts, "24h"},
+			result: time.Date(2024, 1, 3, 3, 4, 5, 0, time.UTC),
+		},
+		{
+			name:   "int timestamp",
+			args:   []interface{}{ts.UnixMilli(), "90s"},
+			result: cast.TimeFromUnixMilli(ts.UnixMilli()).Add(90 * time.Second),
+		},
+		{
+			name:   "invalid duration",
+			args:   []interface{}{ts, "5x"},
+			result: errors.New("invalid duration 5x: time: unknown unit \"x\" in duration \"5x\""),
+		},
+		{
+			name:   "non temporal",
+			args:   []interface{}{true, "5m"},
+			result: errors.New("unsupported type to convert to timestamp true"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, _ := f.exec(fctx, tt.args)
+			require.Equal(t, tt.result, result)
+		})
+	}
+	r, skip := f.check([]interface{}{nil, "5m"})
+	require.True(t, skip)
+	require.Nil(t, r)
+	err := f.val(fctx, []ast.Expr{&ast.FieldRef{Name: "ts"}, &ast.StringLiteral{Val: "5x"}})
+	require.EqualError(t, err, "invalid duration 5x: time: unknown unit \"x\" in duration \"5x\"")
+	err = f.val(fctx, []ast.Expr{&ast.FieldRef{Name: "ts"}, &ast.IntegerLiteral{Val: 5}})
+	require.EqualError(t, err, "Expect string type for parameter 2")
+}
+
+func TestToEpoch(t *testing.T) {
+	f, ok := builtins["to_epoch"]
+	require.True(t, ok)
+	contextLogger := conf.Log.WithField("rule", "testExec")
+	ctx := kctx.WithValue(kctx.Background(), kctx.LoggerKey, contextLogger)
+	tempStore, _ := state.CreateStore("mockRule0", def.AtMostOnce)
+	fctx := kctx.NewDefaultFuncContext(ctx.WithMeta("mockRule0", "test", tempStore), 2)
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 6_000_000, time.UTC)
+	tests := []struct {
+		name   string
+		args   []interface{}
+		result interface{}
+	}{
+		{
+			name:   "seconds",
+			args:   []interface{}{ts, "s"},
+			result: ts.Unix(),
+		},
+		{
+			name:   "milliseconds",
+			args:   []interface{}{ts, "ms"},
+			result: ts.UnixMilli(),
+		},
+		{
+			name:   "microseconds from int timestamp",
+			args:   []interface{}{int64(1704164645006), "us"},
+			result: int64(1704164645006000),
+		},
+		{
+			name:   "days",
+			args:   []interface{}{ts, "d"},
+			result: int64(19724),
+		},
+		{
+			name: "custom base",
+			// 2024-01-01T00:00:00Z
+			args:   []interface{}{ts, "s", int64(1704067200000)},
+			result: int64(24*3600 + 3*3600 + 4*60 + 5),
+		},
+		{
+			name:   "before custom base",
+			args:   []interface{}{ts, "h", int64(1704240000000)},
+			result: int64(-20),
+		},
+		{
+			name:   "invalid unit",
+			args:   []interface{}{ts, "week"},
+			result: errors.New("unsupported time unit week, expect one of ns, us, ms, s, m, h, d"),
+		},
+		{
+			name:   "non temporal",
+			args:   []interface{}{true, "s"},
+			result: errors.New("unsupported type to convert to timestamp true"),
+		},
+		{
+			name:   "invalid base",
+			args:   []interface{}{ts, "s", "x"},
+			result: errors.New("the base epoch must be an int of milliseconds but got x"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, _ := f.exec(fctx, tt.args)
+			require.Equal(t, tt.result, result)
+		})
+	}
+	r, skip := f.check([]interface{}{nil, "s"})
+	require.True(t, skip)
+	require.Nil(t, r)
+	require.EqualError(t, f.val(fctx, []ast.Expr{&ast.FieldRef{Name: "ts"}, &ast.StringLiteral{Val: "week"}}), "unsupported time unit week, expect one of ns, us, ms, s, m, h, d")
+	require.EqualError(t, f.val(fctx, []ast.Expr{&ast.FieldRef{Name: "ts"}}), "Expect 2 or 3 arguments only")
+	require.NoError(t, f.val(fctx, []ast.Expr{&ast.FieldRef{Name: "ts"}, &ast.StringLiteral{Val: "ms"}, &ast.IntegerLiteral{Val: 1000}}))
+}
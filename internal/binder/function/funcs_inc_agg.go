@@ -148,9 +148,14 @@ func registerIncAggFunc() {
 		fType: ast.FuncTypeScalar,
 		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
 			arg0 := args[0]
-			arg1, ok := args[1].(bool)
-			if !ok {
-				return fmt.Errorf("second argument is not a bool"), false
+			// ignore null by default
+			arg1 := true
+			if len(args) > 1 {
+				var ok bool
+				arg1, ok = args[1].(bool)
+				if !ok {
+					return fmt.Errorf("second argument is not a bool"), false
+				}
 			}
 			result, err := incrementalLastValue(ctx, arg0, arg1)
 			if err != nil {
@@ -158,8 +163,11 @@ func registerIncAggFunc() {
 			}
 			return result, true
 		},
-		val:   ValidateTwoNumberArg,
-		check: returnNilIfHasAnyNil,
+		val: validateFirstLastValue,
+		check: func(args []interface{}) (interface{}, bool) {
+			// the null value is passed to exec to return the last non-null value when ignoring null
+			return returnNilIfHasAnyNil(args[1:])
+		},
 	}
 }
 
@@ -24,6 +24,7 @@ import (
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
 	kctx "github.com/lf-edge/ekuiper/v2/internal/topo/context"
 	"github.com/lf-edge/ekuiper/v2/internal/topo/state"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
 )
 
 func TestIncAggFunction(t *testing.T) {
@@ -92,6 +93,13 @@ func TestIncAggFunction(t *testing.T) {
 			args2:    []interface{}{2, true},
 			output2:  2,
 		},
+		{
+			funcName: "inc_last_value",
+			args1:    []interface{}{1},
+			output1:  1,
+			args2:    []interface{}{nil},
+			output2:  1,
+		},
 	}
 	for index, tc := range testcases {
 		ctx := kctx.WithValue(kctx.Background(), kctx.LoggerKey, contextLogger)
@@ -108,6 +116,15 @@ func TestIncAggFunction(t *testing.T) {
 	}
 }
 
+func TestIncLastValueVal(t *testing.T) {
+	f, ok := builtins["inc_last_value"]
+	require.True(t, ok)
+	require.NoError(t, f.val(nil, []ast.Expr{&ast.FieldRef{Name: "a"}}))
+	require.NoError(t, f.val(nil, []ast.Expr{&ast.FieldRef{Name: "a"}, &ast.BooleanLiteral{Val: false}}))
+	require.EqualError(t, f.val(nil, []ast.Expr{&ast.FieldRef{Name: "a"}, &ast.IntegerLiteral{Val: 1}}), "Expect bool type for parameter 2")
+	require.EqualError(t, f.val(nil, []ast.Expr{}), "Expect 1 or 2 arguments only")
+}
+
 func TestIncAggFunctionErr(t *testing.T) {
 	contextLogger := conf.Log.WithField("rule", "testExec")
 	registerIncAggFunc()
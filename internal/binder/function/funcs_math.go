@@ -575,6 +575,75 @@ func registerMathFunc() {
 		},
 		check: returnNilIfHasAnyNil,
 	}
+	builtins["sanitize_float"] = builtinFunc{
+		fType: ast.FuncTypeScalar,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			var f float64
+			switch v := args[0].(type) {
+			case float64:
+				f = v
+			case float32:
+				f = float64(v)
+			default:
+				return v, true
+			}
+			if math.IsNaN(f) || math.IsInf(f, 0) {
+				if len(args) > 1 {
+					return args[1], true
+				}
+				return nil, true
+			}
+			return args[0], true
+		},
+		val: func(_ api.FunctionContext, args []ast.Expr) error {
+			if len(args) != 1 && len(args) != 2 {
+				return errors.New("Expect 1 or 2 arguments only")
+			}
+			return nil
+		},
+		// Only a nil value is skipped. The default value can be nil.
+		check: func(args []interface{}) (interface{}, bool) {
+			if args[0] == nil {
+				return nil, true
+			}
+			return nil, false
+		},
+	}
+	builtins["geo_bearing"] = builtinFunc{
+		fType: ast.FuncTypeScalar,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			coords := make([]float64, 4)
+			for i, arg := range args {
+				v, err := cast.ToFloat64(arg, cast.CONVERT_SAMEKIND)
+				if err != nil {
+					return err, false
+				}
+				coords[i] = v
+			}
+			return geoBearing(coords[0], coords[1], coords[2], coords[3]), true
+		},
+		val: func(_ api.FunctionContext, args []ast.Expr) error {
+			if err := ValidateLen(4, len(args)); err != nil {
+				return err
+			}
+			for i, arg := range args {
+				if ast.IsStringArg(arg) || ast.IsTimeArg(arg) || ast.IsBooleanArg(arg) {
+					return ProduceErrInfo(i, "number - float or int")
+				}
+			}
+			return nil
+		},
+		check: returnNilIfHasAnyNil,
+	}
+}
+
+// geoBearing returns the initial compass bearing in degrees [0, 360) from point 1 to point 2
+func geoBearing(lat1, lon1, lat2, lon2 float64) float64 {
+	phi1, phi2 := radians(lat1), radians(lat2)
+	dLambda := radians(lon2 - lon1)
+	y := math.Sin(dLambda) * math.Cos(phi2)
+	x := math.Cos(phi1)*math.Sin(phi2) - math.Sin(phi1)*math.Cos(phi2)*math.Cos(dLambda)
+	return math.Mod(degrees(math.Atan2(y, x))+360, 360)
 }
 
 func radians(degrees float64) float64 {
@@ -27,6 +27,7 @@ import (
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
 	kctx "github.com/lf-edge/ekuiper/v2/internal/topo/context"
 	"github.com/lf-edge/ekuiper/v2/internal/topo/state"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
 )
 
 func TestFuncMath(t *testing.T) {
@@ -568,3 +569,103 @@ func TestConvFunc(t *testing.T) {
 		}
 	}
 }
+
+func TestGeoBearing(t *testing.T) {
+	f, ok := builtins["geo_bearing"]
+	require.True(t, ok)
+	contextLogger := conf.Log.WithField("rule", "testExec")
+	ctx := kctx.WithValue(kctx.Background(), kctx.LoggerKey, contextLogger)
+	tempStore, _ := state.CreateStore("mockRule0", def.AtMostOnce)
+	fctx := kctx.NewDefaultFuncContext(ctx.WithMeta("mockRule0", "test", tempStore), 2)
+	tests := []struct {
+		args []interface{}
+		want float64
+	}{
+		{ // due north
+			args: []interface{}{0, 0, 10, 0},
+			want: 0,
+		},
+		{ // due east along the equator
+			args: []interface{}{0, int64(0), 0, 10},
+			want: 90,
+		},
+		{ // due south
+			args: []interface{}{10, 0, 0, 0},
+			want: 180,
+		},
+		{ // due west along the equator
+			args: []interface{}{0, 10, 0, 0},
+			want: 270,
+		},
+		{ // London to Paris
+			args: []interface{}{51.5074, -0.1278, 48.8566, 2.3522},
+			want: 148.1,
+		},
+		{ // New York to Los Angeles
+			args: []interface{}{40.7128, -74.006, 34.0522, float32(-118.2437)},
+			want: 273.7,
+		},
+	}
+	for i, tt := range tests {
+		r, ok := f.exec(fctx, tt.args)
+		require.True(t, ok, i)
+		require.InDelta(t, tt.want, r, 0.1, i)
+	}
+	r, ok := f.exec(fctx, []interface{}{"abc", 0, 0, 0})
+	require.False(t, ok)
+	require.EqualError(t, r.(error), "cannot convert string(abc) to float64")
+	err := f.val(fctx, []ast.Expr{&ast.IntegerLiteral{Val: 1}, &ast.IntegerLiteral{Val: 1}, &ast.StringLiteral{Val: "a"}, &ast.IntegerLiteral{Val: 1}})
+	require.EqualError(t, err, "Expect number - float or int type for parameter 3")
+}
+
+func TestSanitizeFloat(t *testing.T) {
+	f, ok := builtins["sanitize_float"]
+	require.True(t, ok)
+	contextLogger := conf.Log.WithField("rule", "testExec")
+	ctx := kctx.WithValue(kctx.Background(), kctx.LoggerKey, contextLogger)
+	tempStore, _ := state.CreateStore("mockRule0", def.AtMostOnce)
+	fctx := kctx.NewDefaultFuncContext(ctx.WithMeta("mockRule0", "test", tempStore), 2)
+	tests := []struct {
+		args []interface{}
+		want interface{}
+	}{
+		{
+			args: []interface{}{math.NaN()},
+			want: nil,
+		},
+		{
+			args: []interface{}{math.Inf(1), 0},
+			want: 0,
+		},
+		{
+			args: []interface{}{math.Inf(-1), -1.5},
+			want: -1.5,
+		},
+		{
+			args: []interface{}{float32(math.NaN()), nil},
+			want: nil,
+		},
+		{
+			args: []interface{}{1.5, nil},
+			want: 1.5,
+		},
+		{
+			args: []interface{}{int64(3), 0},
+			want: int64(3),
+		},
+		{
+			args: []interface{}{"abc", 0},
+			want: "abc",
+		},
+	}
+	for i, tt := range tests {
+		r, b := f.check(tt.args)
+		require.False(t, b, i)
+		require.Nil(t, r, i)
+		r, ok := f.exec(fctx, tt.args)
+		require.True(t, ok, i)
+		require.Equal(t, tt.want, r, i)
+	}
+	err := f.val(fctx, []ast.Expr{})
+	require.EqualError(t, err, "Expect 1 or 2 arguments only")
+}
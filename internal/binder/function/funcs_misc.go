@@ -22,6 +22,7 @@ import (
 	b64 "encoding/base64"
 	"encoding/gob"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"hash/crc32"
 	"io"
@@ -31,6 +32,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/cespare/xxhash/v2"
 	"github.com/google/uuid"
 	"github.com/lf-edge/ekuiper/contract/v2/api"
 
@@ -74,6 +76,11 @@ func registerMiscFunc() {
 		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
 			value := args[0]
 			newType := args[1]
+			if newType == "string" {
+				if r, ok := formatFloatPrecision(ctx, value); ok {
+					return r, true
+				}
+			}
 			return cast.ToType(value, newType)
 		},
 		val: func(_ api.FunctionContext, args []ast.Expr) error {
@@ -84,15 +91,37 @@ func registerMiscFunc() {
 			if ast.IsNumericArg(a) || ast.IsTimeArg(a) || ast.IsBooleanArg(a) {
 				return ProduceErrInfo(0, "string")
 			}
-			if av, ok := a.(*ast.StringLiteral); ok {
-				if !(av.Val == "bigint" || av.Val == "float" || av.Val == "string" || av.Val == "boolean" || av.Val == "datetime" || av.Val == "bytea") {
-					return fmt.Errorf("Expect one of following value for the 2nd parameter: bigint, float, string, boolean, datetime, bytea.")
-				}
-			}
-			return nil
+			return validateCastType(a)
 		},
 		check: returnNilIfHasAnyNil,
 	}
+	builtins["default_for_type"] = builtinFunc{
+		fType: ast.FuncTypeScalar,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			if args[0] != nil {
+				return cast.ToType(args[0], args[1])
+			}
+			t, ok := args[1].(string)
+			if !ok {
+				return fmt.Errorf("expect string type for type parameter"), false
+			}
+			v, ok := typeZeroValues[t]
+			if !ok {
+				return fmt.Errorf("unknown type %s", t), false
+			}
+			return v, true
+		},
+		val: func(_ api.FunctionContext, args []ast.Expr) error {
+			if err := ValidateLen(2, len(args)); err != nil {
+				return err
+			}
+			a := args[1]
+			if ast.IsNumericArg(a) || ast.IsTimeArg(a) || ast.IsBooleanArg(a) {
+				return ProduceErrInfo(1, "string")
+			}
+			return validateCastType(a)
+		},
+	}
 	builtins["convert_tz"] = builtinFunc{
 		fType: ast.FuncTypeScalar,
 		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
@@ -138,7 +167,7 @@ func registerMiscFunc() {
 		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
 			rr, err := json.Marshal(args[0])
 			if err != nil {
-				return fmt.Errorf("fail to convert %v to json", args[0]), false
+				return fmt.Errorf("fail to convert %v to json: %v", args[0], err), false
 			}
 			return string(rr), true
 		},
@@ -387,6 +416,21 @@ func registerMiscFunc() {
 		val:   ValidateOneStrArg,
 		check: returnNilIfHasAnyNil,
 	}
+	builtins["xxhash"] = builtinFunc{
+		fType: ast.FuncTypeScalar,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			// json marshals the map keys in sorted order, so the same maps and arrays always have the same hash
+			b, err := json.Marshal(args[0])
+			if err != nil {
+				return fmt.Errorf("fail to canonicalize %v: %v", args[0], err), false
+			}
+			return int64(xxhash.Sum64(b)), true
+		},
+		val: func(_ api.FunctionContext, args []ast.Expr) error {
+			return ValidateLen(1, len(args))
+		},
+		check: returnNilIfHasAnyNil,
+	}
 	builtinStatfulFuncs["compress"] = func() api.Function {
 		conf.Log.Infof("initializing compress function")
 		return &compressFunc{}
@@ -412,6 +456,13 @@ func registerMiscFunc() {
 		},
 		val: ValidateOneArg,
 	}
+	builtins["typeof"] = builtinFunc{
+		fType: ast.FuncTypeScalar,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			return typeOf(args[0]), true
+		},
+		val: ValidateOneArg,
+	}
 	builtins["coalesce"] = builtinFunc{
 		fType: ast.FuncTypeScalar,
 		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
@@ -429,6 +480,28 @@ func registerMiscFunc() {
 			return nil
 		},
 	}
+	builtins["coalesce_field"] = builtinFunc{
+		fType: ast.FuncTypeScalar,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			for _, arg := range args {
+				if arg != nil {
+					return arg, true
+				}
+			}
+			return nil, true
+		},
+		val: func(_ api.FunctionContext, args []ast.Expr) error {
+			if err := ValidateAtLeast(1, len(args)); err != nil {
+				return err
+			}
+			for i, arg := range args {
+				if _, ok := arg.(*ast.FieldRef); !ok {
+					return ProduceErrInfo(i, "field reference")
+				}
+			}
+			return nil
+		},
+	}
 	builtins["newuuid"] = builtinFunc{
 		fType: ast.FuncTypeScalar,
 		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
@@ -512,6 +585,24 @@ func registerMiscFunc() {
 			return ProduceErrInfo(0, "meta reference")
 		},
 	}
+	builtins["coalesce_meta"] = builtinFunc{
+		fType: ast.FuncTypeScalar,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			if args[0] != nil {
+				return args[0], true
+			}
+			return args[1], true
+		},
+		val: func(_ api.FunctionContext, args []ast.Expr) error {
+			if err := ValidateLen(2, len(args)); err != nil {
+				return err
+			}
+			if _, ok := args[1].(*ast.MetaRef); !ok {
+				return ProduceErrInfo(1, "meta key")
+			}
+			return nil
+		},
+	}
 	builtins["cardinality"] = builtinFunc{
 		fType: ast.FuncTypeScalar,
 		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
@@ -579,9 +670,14 @@ func registerMiscFunc() {
 	builtins["window_start"] = builtinFunc{
 		fType: ast.FuncTypeScalar,
 		exec:  nil, // directly return in the valuer
-		val:   ValidateNoArg,
+		val:   validateWindowBoundary,
 	}
 	builtins["window_end"] = builtinFunc{
+		fType: ast.FuncTypeScalar,
+		exec:  nil, // directly return in the valuer
+		val:   validateWindowBoundary,
+	}
+	builtins["window_index"] = builtinFunc{
 		fType: ast.FuncTypeScalar,
 		exec:  nil, // directly return in the valuer
 		val:   ValidateNoArg,
@@ -591,6 +687,11 @@ func registerMiscFunc() {
 		exec:  nil, // directly return in the valuer
 		val:   ValidateNoArg,
 	}
+	builtins["row"] = builtinFunc{
+		fType: ast.FuncTypeScalar,
+		exec:  nil, // directly return in the valuer
+		val:   ValidateNoArg,
+	}
 
 	builtins["delay"] = builtinFunc{
 		fType: ast.FuncTypeScalar,
@@ -677,6 +778,77 @@ func registerMiscFunc() {
 		val:   ValidateOneStrArg,
 		check: returnNilIfHasAnyNil,
 	}
+	builtins["to_bool"] = builtinFunc{
+		fType: ast.FuncTypeScalar,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			return toBool(args[0]), true
+		},
+		val:   ValidateOneArg,
+		check: returnNilIfHasAnyNil,
+	}
+}
+
+// typeZeroValues are the values of each cast type to fill the nil by default_for_type
+var typeZeroValues = map[string]interface{}{
+	"bigint":   0,
+	"float":    0.0,
+	"string":   "",
+	"boolean":  false,
+	"datetime": time.Time{},
+	"bytea":    []byte{},
+}
+
+// validateCastType validates the literal type name of the cast functions
+func validateCastType(a ast.Expr) error {
+	if av, ok := a.(*ast.StringLiteral); ok {
+		if _, ok := typeZeroValues[av.Val]; !ok {
+			return fmt.Errorf("Expect one of following value for the 2nd parameter: bigint, float, string, boolean, datetime, bytea.")
+		}
+	}
+	return nil
+}
+
+// validateWindowBoundary validates window_start and window_end which accept an optional layout to format the time
+func validateWindowBoundary(_ api.FunctionContext, args []ast.Expr) error {
+	if len(args) > 1 {
+		return errors.New("Expect 0 or 1 arguments only")
+	}
+	if len(args) == 1 && (ast.IsNumericArg(args[0]) || ast.IsTimeArg(args[0]) || ast.IsBooleanArg(args[0])) {
+		return ProduceErrInfo(0, "string")
+	}
+	return nil
+}
+
+// toBool converts the boolean-ish value to bool. It returns nil if the value is not recognized.
+func toBool(v interface{}) interface{} {
+	switch t := v.(type) {
+	case bool:
+		return t
+	case string:
+		switch strings.ToLower(strings.TrimSpace(t)) {
+		case "true", "1", "yes", "on":
+			return true
+		case "false", "0", "no", "off":
+			return false
+		default:
+			return nil
+		}
+	case float64:
+		if math.IsNaN(t) {
+			return nil
+		}
+		return t != 0
+	case float32:
+		if math.IsNaN(float64(t)) {
+			return nil
+		}
+		return t != 0
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		i, _ := cast.ToFloat64(t, cast.CONVERT_SAMEKIND)
+		return i != 0
+	default:
+		return nil
+	}
 }
 
 func round(num float64) int {
@@ -764,3 +936,24 @@ func (p *ringqueue) peek() (interface{}, bool) {
 func (p *ringqueue) isFull() bool {
 	return p.L == p.Size
 }
+
+// typeOf returns the name of the runtime type of the value. The maps and slices of any element type are reported
+// as map and array, while the other values are reported by their go type name.
+func typeOf(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case time.Time:
+		return "datetime"
+	case []byte:
+		return "bytea"
+	}
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.Map:
+		return "map"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
@@ -17,6 +17,7 @@ package function
 import (
 	"errors"
 	"fmt"
+	"math"
 	"reflect"
 	"strconv"
 	"testing"
@@ -101,6 +102,17 @@ func TestCoalesceExec(t *testing.T) {
 	}
 }
 
+func TestCoalesceFieldVal(t *testing.T) {
+	f, ok := builtins["coalesce_field"]
+	require.True(t, ok)
+	err := f.val(nil, []ast.Expr{&ast.FieldRef{StreamName: "src1", Name: "id"}, &ast.FieldRef{StreamName: "src2", Name: "id"}})
+	require.NoError(t, err)
+	err = f.val(nil, []ast.Expr{})
+	require.EqualError(t, err, "At least has 1 argument but found 0.")
+	err = f.val(nil, []ast.Expr{&ast.FieldRef{StreamName: "src1", Name: "id"}, &ast.IntegerLiteral{Val: 1}})
+	require.EqualError(t, err, "Expect field reference type for parameter 2")
+}
+
 func TestToSeconds(t *testing.T) {
 	f, ok := builtins["to_seconds"]
 	if !ok {
@@ -496,6 +508,48 @@ func TestHexIntFunctions(t *testing.T) {
 	}
 }
 
+func TestToBool(t *testing.T) {
+	f, ok := builtins["to_bool"]
+	require.True(t, ok)
+	contextLogger := conf.Log.WithField("rule", "testExec")
+	ctx := kctx.WithValue(kctx.Background(), kctx.LoggerKey, contextLogger)
+	tempStore, _ := state.CreateStore("mockRule0", def.AtMostOnce)
+	fctx := kctx.NewDefaultFuncContext(ctx.WithMeta("mockRule0", "test", tempStore), 2)
+	tests := []struct {
+		arg    interface{}
+		result interface{}
+	}{
+		{arg: "true", result: true},
+		{arg: "TRUE", result: true},
+		{arg: "1", result: true},
+		{arg: "Yes", result: true},
+		{arg: " on ", result: true},
+		{arg: "false", result: false},
+		{arg: "False", result: false},
+		{arg: "0", result: false},
+		{arg: "NO", result: false},
+		{arg: "off", result: false},
+		{arg: "maybe", result: nil},
+		{arg: "", result: nil},
+		{arg: true, result: true},
+		{arg: false, result: false},
+		{arg: 1, result: true},
+		{arg: int64(-3), result: true},
+		{arg: 0, result: false},
+		{arg: 0.5, result: true},
+		{arg: 0.0, result: false},
+		{arg: math.NaN(), result: nil},
+		{arg: []interface{}{1}, result: nil},
+	}
+	for i, tt := range tests {
+		result, ok := f.exec(fctx, []interface{}{tt.arg})
+		require.True(t, ok, i)
+		require.Equal(t, tt.result, result, "%d: %v", i, tt.arg)
+	}
+	err := f.val(fctx, []ast.Expr{})
+	require.EqualError(t, err, "Expect 1 arguments but found 0.")
+}
+
 func TestMiscFuncNil(t *testing.T) {
 	contextLogger := conf.Log.WithField("rule", "testExec")
 	ctx := kctx.WithValue(kctx.Background(), kctx.LoggerKey, contextLogger)
@@ -509,13 +563,28 @@ func TestMiscFuncNil(t *testing.T) {
 	registerMiscFunc()
 	for name, function := range builtins {
 		switch name {
-		case "compress", "decompress", "newuuid", "tstamp", "rule_id", "rule_start", "window_start", "window_end", "window_trigger", "event_time",
-			"json_path_query", "json_path_query_first", "coalesce", "meta", "json_path_exists", "bypass", "get_keyed_state":
+		case "compress", "decompress", "newuuid", "tstamp", "rule_id", "rule_start", "window_start", "window_end", "window_trigger", "event_time", "row", "window_index",
+			"json_path_query", "json_path_query_first", "coalesce", "coalesce_field", "meta", "json_path_exists", "bypass", "get_keyed_state":
 			continue
 		case "isnull":
 			v, b := function.exec(fctx, []interface{}{nil})
 			require.True(t, b)
 			require.Equal(t, v, true)
+		case "typeof":
+			v, b := function.exec(fctx, []interface{}{nil})
+			require.True(t, b)
+			require.Equal(t, "null", v)
+		case "coalesce_meta":
+			v, b := function.exec(fctx, []interface{}{nil, nil})
+			require.True(t, b)
+			require.Nil(t, v)
+			v, b = function.exec(fctx, []interface{}{nil, "m"})
+			require.True(t, b)
+			require.Equal(t, v, "m")
+		case "default_for_type":
+			v, b := function.exec(fctx, []interface{}{nil, "string"})
+			require.True(t, b)
+			require.Equal(t, v, "")
 		case "cardinality":
 			v, b := function.check([]interface{}{nil})
 			require.True(t, b)
@@ -651,6 +720,75 @@ func TestCast(t *testing.T) {
 	}
 }
 
+func TestDefaultForType(t *testing.T) {
+	f, ok := builtins["default_for_type"]
+	require.True(t, ok)
+	contextLogger := conf.Log.WithField("rule", "testExec")
+	ctx := kctx.WithValue(kctx.Background(), kctx.LoggerKey, contextLogger)
+	tempStore, _ := state.CreateStore("mockRule0", def.AtMostOnce)
+	fctx := kctx.NewDefaultFuncContext(ctx.WithMeta("mockRule0", "test", tempStore), 2)
+	tests := []struct {
+		args   []interface{}
+		result interface{}
+	}{
+		{
+			args:   []interface{}{nil, "bigint"},
+			result: 0,
+		},
+		{
+			args:   []interface{}{"12", "bigint"},
+			result: 12,
+		},
+		{
+			args:   []interface{}{nil, "float"},
+			result: 0.0,
+		},
+		{
+			args:   []interface{}{3, "float"},
+			result: 3.0,
+		},
+		{
+			args:   []interface{}{nil, "string"},
+			result: "",
+		},
+		{
+			args:   []interface{}{true, "string"},
+			result: "true",
+		},
+		{
+			args:   []interface{}{nil, "boolean"},
+			result: false,
+		},
+		{
+			args:   []interface{}{nil, "test"},
+			result: fmt.Errorf("unknown type test"),
+		},
+	}
+	for i, tt := range tests {
+		r, _ := f.exec(fctx, tt.args)
+		require.Equal(t, tt.result, r, "case %d", i)
+	}
+	vtests := []struct {
+		args []ast.Expr
+		err  error
+	}{
+		{
+			args: []ast.Expr{&ast.FieldRef{Name: "foo"}, &ast.StringLiteral{Val: "bigint"}},
+		},
+		{
+			args: []ast.Expr{&ast.FieldRef{Name: "foo"}, &ast.StringLiteral{Val: "test"}},
+			err:  fmt.Errorf("Expect one of following value for the 2nd parameter: bigint, float, string, boolean, datetime, bytea."),
+		},
+		{
+			args: []ast.Expr{&ast.FieldRef{Name: "foo"}, &ast.IntegerLiteral{Val: 1}},
+			err:  fmt.Errorf("Expect string type for parameter 2"),
+		},
+	}
+	for i, vtt := range vtests {
+		require.Equal(t, vtt.err, f.val(fctx, vtt.args), "case %d", i)
+	}
+}
+
 func TestProps(t *testing.T) {
 	f, ok := builtins["props"]
 	if !ok {
@@ -670,3 +808,71 @@ func TestProps(t *testing.T) {
 	require.True(t, ok)
 	require.Equal(t, strconv.FormatInt(tt, 10), et)
 }
+
+func TestXXHash(t *testing.T) {
+	f, ok := builtins["xxhash"]
+	require.True(t, ok)
+	contextLogger := conf.Log.WithField("rule", "testExec")
+	ctx := kctx.WithValue(kctx.Background(), kctx.LoggerKey, contextLogger)
+	tempStore, _ := state.CreateStore("mockRule0", def.AtMostOnce)
+	fctx := kctx.NewDefaultFuncContext(ctx.WithMeta("mockRule0", "test", tempStore), 2)
+	hash := func(arg interface{}) interface{} {
+		r, ok := f.exec(fctx, []interface{}{arg})
+		require.True(t, ok)
+		require.IsType(t, int64(0), r)
+		return r
+	}
+	// identical inputs hash equal regardless of the map insertion order
+	m1 := map[string]interface{}{"a": 1, "b": []interface{}{"x", map[string]interface{}{"c": true, "d": 2.5}}}
+	m2 := map[string]interface{}{}
+	m2["b"] = []interface{}{"x", map[string]interface{}{"d": 2.5, "c": true}}
+	m2["a"] = 1
+	require.Equal(t, hash(m1), hash(m2))
+	require.Equal(t, hash("The quick brown fox"), hash("The quick brown fox"))
+	// the int and float of the same value are the same number
+	require.Equal(t, hash(1), hash(1.0))
+	// different inputs differ
+	require.NotEqual(t, hash(m1), hash(map[string]interface{}{"a": 2, "b": []interface{}{"x", map[string]interface{}{"c": true, "d": 2.5}}}))
+	require.NotEqual(t, hash([]interface{}{1, 2}), hash([]interface{}{2, 1}))
+	require.NotEqual(t, hash("1"), hash(1))
+	require.NotEqual(t, hash("a"), hash("b"))
+
+	r, ok := f.exec(fctx, []interface{}{math.NaN()})
+	require.False(t, ok)
+	require.EqualError(t, r.(error), "fail to canonicalize NaN: json: unsupported value: NaN")
+	r, ok = f.check([]interface{}{nil})
+	require.True(t, ok)
+	require.Nil(t, r)
+	require.EqualError(t, f.val(fctx, []ast.Expr{}), "Expect 1 arguments but found 0.")
+}
+
+func TestTypeof(t *testing.T) {
+	f, ok := builtins["typeof"]
+	require.True(t, ok)
+	contextLogger := conf.Log.WithField("rule", "testExec")
+	ctx := kctx.WithValue(kctx.Background(), kctx.LoggerKey, contextLogger)
+	tempStore, _ := state.CreateStore("mockRule0", def.AtMostOnce)
+	fctx := kctx.NewDefaultFuncContext(ctx.WithMeta("mockRule0", "test", tempStore), 2)
+	tests := []struct {
+		arg    interface{}
+		result string
+	}{
+		{arg: "a", result: "string"},
+		{arg: 1.5, result: "float64"},
+		{arg: int64(1), result: "int64"},
+		{arg: 1, result: "int"},
+		{arg: true, result: "bool"},
+		{arg: map[string]interface{}{"a": 1}, result: "map"},
+		{arg: []interface{}{1, "a"}, result: "array"},
+		{arg: []map[string]interface{}{{"a": 1}}, result: "array"},
+		{arg: []byte("a"), result: "bytea"},
+		{arg: time.UnixMilli(0), result: "datetime"},
+		{arg: nil, result: "null"},
+	}
+	for _, tt := range tests {
+		r, ok := f.exec(fctx, []interface{}{tt.arg})
+		require.True(t, ok)
+		require.Equal(t, tt.result, r)
+	}
+	require.EqualError(t, f.val(fctx, []ast.Expr{}), "Expect 1 arguments but found 0.")
+}
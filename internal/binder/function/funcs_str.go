@@ -19,6 +19,7 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 	"unicode"
 	"unicode/utf8"
@@ -28,17 +29,39 @@ import (
 	"golang.org/x/text/message"
 	"golang.org/x/text/number"
 
+	"github.com/lf-edge/ekuiper/v2/internal/topo/context"
 	"github.com/lf-edge/ekuiper/v2/pkg/ast"
 	"github.com/lf-edge/ekuiper/v2/pkg/cast"
 )
 
+// toStringWithPrecision coerces the value to string. The float is formatted by the rule option floatPrecision if set.
+func toStringWithPrecision(ctx api.FunctionContext, v interface{}) string {
+	if r, ok := formatFloatPrecision(ctx, v); ok {
+		return r
+	}
+	return cast.ToStringAlways(v)
+}
+
+// formatFloatPrecision formats the float by the rule option floatPrecision. Returns false if the value is not a float or the option is not set.
+func formatFloatPrecision(ctx api.FunctionContext, v interface{}) (string, bool) {
+	if p, ok := ctx.Value(context.FloatPrecisionKey).(int); ok && p >= 0 {
+		switch f := v.(type) {
+		case float64:
+			return strconv.FormatFloat(f, 'f', p, 64), true
+		case float32:
+			return strconv.FormatFloat(float64(f), 'f', p, 32), true
+		}
+	}
+	return "", false
+}
+
 func registerStrFunc() {
 	builtins["concat"] = builtinFunc{
 		fType: ast.FuncTypeScalar,
 		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
 			var b bytes.Buffer
 			for _, arg := range args {
-				b.WriteString(cast.ToStringAlways(arg))
+				b.WriteString(toStringWithPrecision(ctx, arg))
 			}
 			return b.String(), true
 		},
@@ -296,6 +319,9 @@ func registerStrFunc() {
 	builtins["split_value"] = builtinFunc{
 		fType: ast.FuncTypeScalar,
 		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			if len(args) == 2 {
+				return splitToArray(args)
+			}
 			arg0, arg1 := cast.ToStringAlways(args[0]), cast.ToStringAlways(args[1])
 			ss := strings.Split(arg0, arg1)
 			v, _ := cast.ToInt(args[2], cast.STRICT)
@@ -318,8 +344,8 @@ func registerStrFunc() {
 		},
 		val: func(_ api.FunctionContext, args []ast.Expr) error {
 			l := len(args)
-			if l != 3 {
-				return fmt.Errorf("the arguments for split_value should be 3")
+			if l != 2 && l != 3 {
+				return fmt.Errorf("the arguments for split_value should be 2 or 3")
 			}
 			if ast.IsNumericArg(args[0]) || ast.IsTimeArg(args[0]) || ast.IsBooleanArg(args[0]) {
 				return ProduceErrInfo(0, "string")
@@ -327,6 +353,9 @@ func registerStrFunc() {
 			if ast.IsNumericArg(args[1]) || ast.IsTimeArg(args[1]) || ast.IsBooleanArg(args[1]) {
 				return ProduceErrInfo(1, "string")
 			}
+			if l == 2 {
+				return nil
+			}
 			if ast.IsFloatArg(args[2]) || ast.IsTimeArg(args[2]) || ast.IsBooleanArg(args[2]) || ast.IsStringArg(args[2]) {
 				return ProduceErrInfo(2, "int")
 			}
@@ -339,6 +368,25 @@ func registerStrFunc() {
 		},
 		check: returnNilIfHasAnyNil,
 	}
+	builtins["string_to_array"] = builtinFunc{
+		fType: ast.FuncTypeScalar,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			return splitToArray(args)
+		},
+		val: func(_ api.FunctionContext, args []ast.Expr) error {
+			if err := ValidateLen(2, len(args)); err != nil {
+				return err
+			}
+			if ast.IsNumericArg(args[0]) || ast.IsTimeArg(args[0]) || ast.IsBooleanArg(args[0]) {
+				return ProduceErrInfo(0, "string")
+			}
+			if ast.IsNumericArg(args[1]) || ast.IsTimeArg(args[1]) || ast.IsBooleanArg(args[1]) {
+				return ProduceErrInfo(1, "string")
+			}
+			return nil
+		},
+		check: returnNilIfHasAnyNil,
+	}
 	builtins["trim"] = builtinFunc{
 		fType: ast.FuncTypeScalar,
 		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
@@ -401,3 +449,20 @@ func registerStrFunc() {
 		check: returnNilIfHasAnyNil,
 	}
 }
+
+// splitToArray splits the string by the separator into an array. An empty string returns an empty array.
+func splitToArray(args []interface{}) (interface{}, bool) {
+	s, err := cast.ToString(args[0], cast.STRICT)
+	if err != nil {
+		return err, false
+	}
+	if s == "" {
+		return []interface{}{}, true
+	}
+	ss := strings.Split(s, cast.ToStringAlways(args[1]))
+	result := make([]interface{}, len(ss))
+	for i, v := range ss {
+		result[i] = v
+	}
+	return result, true
+}
@@ -101,6 +101,21 @@ func TestSplitValueFunctions(t *testing.T) {
 			result: errors.New("-4 out of index array (size = 3)"),
 			ok:     false,
 		},
+		{
+			args:   []interface{}{"a,b,,c", ","},
+			result: []interface{}{"a", "b", "", "c"},
+			ok:     true,
+		},
+		{
+			args:   []interface{}{"", ","},
+			result: []interface{}{},
+			ok:     true,
+		},
+		{
+			args:   []interface{}{12, ","},
+			result: errors.New("cannot convert int(12) to string"),
+			ok:     false,
+		},
 	}
 	for _, tt := range tests {
 		result, ok := f.exec(fctx, tt.args)
@@ -109,6 +124,32 @@ func TestSplitValueFunctions(t *testing.T) {
 	}
 }
 
+func TestStringToArray(t *testing.T) {
+	f, ok := builtins["string_to_array"]
+	require.True(t, ok)
+	contextLogger := conf.Log.WithField("rule", "testExec")
+	ctx := kctx.WithValue(kctx.Background(), kctx.LoggerKey, contextLogger)
+	tempStore, _ := state.CreateStore("mockRule0", def.AtMostOnce)
+	fctx := kctx.NewDefaultFuncContext(ctx.WithMeta("mockRule0", "test", tempStore), 2)
+	r, ok := f.exec(fctx, []interface{}{"1;2;3", ";"})
+	require.True(t, ok)
+	require.Equal(t, []interface{}{"1", "2", "3"}, r)
+	r, ok = f.exec(fctx, []interface{}{"abc", ""})
+	require.True(t, ok)
+	require.Equal(t, []interface{}{"a", "b", "c"}, r)
+	r, ok = f.exec(fctx, []interface{}{"", ";"})
+	require.True(t, ok)
+	require.Equal(t, []interface{}{}, r)
+	r, ok = f.exec(fctx, []interface{}{true, ";"})
+	require.False(t, ok)
+	require.EqualError(t, r.(error), "cannot convert bool(true) to string")
+	r, ok = f.check([]interface{}{nil, ";"})
+	require.True(t, ok)
+	require.Nil(t, r)
+	require.EqualError(t, f.val(fctx, []ast.Expr{&ast.StringLiteral{Val: "a"}}), "Expect 2 arguments but found 1.")
+	require.EqualError(t, f.val(fctx, []ast.Expr{&ast.IntegerLiteral{Val: 1}, &ast.StringLiteral{Val: ","}}), "Expect string type for parameter 1")
+}
+
 func TestStrFunc(t *testing.T) {
 	contextLogger := conf.Log.WithField("rule", "testExec")
 	ctx := kctx.WithValue(kctx.Background(), kctx.LoggerKey, contextLogger)
@@ -125,6 +166,31 @@ func TestStrFunc(t *testing.T) {
 	testFormatLocale(t, fctx)
 }
 
+func TestConcatFloatPrecision(t *testing.T) {
+	contextLogger := conf.Log.WithField("rule", "testExec")
+	ctx := kctx.WithValue(kctx.Background(), kctx.LoggerKey, contextLogger)
+	tempStore, _ := state.CreateStore("mockRule0", def.AtMostOnce)
+	f := builtins["concat"]
+	args := []interface{}{"t:", 73.499, "/", 2.5, "/", 3}
+	// default preserves the shortest representation
+	fctx := kctx.NewDefaultFuncContext(ctx.WithMeta("mockRule0", "test", tempStore), 2)
+	r, ok := f.exec(fctx, args)
+	require.True(t, ok)
+	require.Equal(t, "t:73.499/2.5/3", r)
+	pctx := kctx.WithValue(ctx, kctx.FloatPrecisionKey, 2)
+	fctx = kctx.NewDefaultFuncContext(pctx.WithMeta("mockRule0", "test", tempStore), 2)
+	r, ok = f.exec(fctx, args)
+	require.True(t, ok)
+	require.Equal(t, "t:73.50/2.50/3", r)
+	c := builtins["cast"]
+	r, ok = c.exec(fctx, []interface{}{73.499, "string"})
+	require.True(t, ok)
+	require.Equal(t, "73.50", r)
+	r, ok = c.exec(fctx, []interface{}{73.499, "bigint"})
+	require.True(t, ok)
+	require.Equal(t, 73, r)
+}
+
 func testFormat(t *testing.T, fctx *kctx.DefaultFuncContext) {
 	fFormat := builtins["format"]
 	cases := []struct {
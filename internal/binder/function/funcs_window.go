@@ -15,6 +15,8 @@
 package function
 
 import (
+	"fmt"
+
 	"github.com/lf-edge/ekuiper/contract/v2/api"
 
 	"github.com/lf-edge/ekuiper/v2/pkg/ast"
@@ -29,4 +31,157 @@ func registerWindowFunc() {
 		},
 		val: ValidateNoArg,
 	}
+	builtins["zscore"] = builtinFunc{
+		fType: ast.FuncTypeWindow,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			return nil, true
+		},
+		val: ValidateOneNumberArg,
+	}
+	builtins["is_outlier"] = builtinFunc{
+		fType: ast.FuncTypeWindow,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			return nil, true
+		},
+		val: ValidateTwoNumberArg,
+	}
+	builtins["cumulative_product"] = builtinFunc{
+		fType: ast.FuncTypeWindow,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			return nil, true
+		},
+		val: ValidateOneNumberArg,
+	}
+	builtins["min_max_scale"] = builtinFunc{
+		fType: ast.FuncTypeWindow,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			return nil, true
+		},
+		val: ValidateOneNumberArg,
+	}
+	builtins["wma"] = builtinFunc{
+		fType: ast.FuncTypeWindow,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			return nil, true
+		},
+		val: func(ctx api.FunctionContext, args []ast.Expr) error {
+			if err := ValidateLen(2, len(args)); err != nil {
+				return err
+			}
+			if ast.IsStringArg(args[0]) || ast.IsTimeArg(args[0]) || ast.IsBooleanArg(args[0]) {
+				return ProduceErrInfo(0, "number - float or int")
+			}
+			if ast.IsNumericArg(args[1]) || ast.IsStringArg(args[1]) || ast.IsTimeArg(args[1]) || ast.IsBooleanArg(args[1]) {
+				return ProduceErrInfo(1, "array")
+			}
+			if c, ok := args[1].(*ast.Call); ok && c.Name == "array_create" {
+				if len(c.Args) == 0 {
+					return fmt.Errorf("the weights must not be empty")
+				}
+				for _, a := range c.Args {
+					if ast.IsStringArg(a) || ast.IsTimeArg(a) || ast.IsBooleanArg(a) {
+						return fmt.Errorf("the weights must be numbers but found %s", a)
+					}
+				}
+			}
+			return nil
+		},
+	}
+	builtins["ema"] = builtinFunc{
+		fType: ast.FuncTypeWindow,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			return nil, true
+		},
+		val: func(ctx api.FunctionContext, args []ast.Expr) error {
+			if err := ValidateTwoNumberArg(ctx, args); err != nil {
+				return err
+			}
+			var alpha float64
+			switch a := args[1].(type) {
+			case *ast.NumberLiteral:
+				alpha = a.Val
+			case *ast.IntegerLiteral:
+				alpha = float64(a.Val)
+			default:
+				return nil
+			}
+			if alpha <= 0 || alpha > 1 {
+				return fmt.Errorf("the smoothing factor alpha must be in (0, 1] but found %v", alpha)
+			}
+			return nil
+		},
+	}
+	builtins["sliding_sum"] = builtinFunc{
+		fType: ast.FuncTypeWindow,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			return nil, true
+		},
+		val: func(ctx api.FunctionContext, args []ast.Expr) error {
+			if err := ValidateLen(2, len(args)); err != nil {
+				return err
+			}
+			if ast.IsStringArg(args[0]) || ast.IsTimeArg(args[0]) || ast.IsBooleanArg(args[0]) {
+				return ProduceErrInfo(0, "number - float or int")
+			}
+			if ast.IsFloatArg(args[1]) || ast.IsStringArg(args[1]) || ast.IsTimeArg(args[1]) || ast.IsBooleanArg(args[1]) {
+				return ProduceErrInfo(1, "int")
+			}
+			if n, ok := args[1].(*ast.IntegerLiteral); ok && n.Val <= 0 {
+				return fmt.Errorf("the window size must be positive but found %d", n.Val)
+			}
+			return nil
+		},
+	}
+	builtins["rolling_rate"] = builtinFunc{
+		fType: ast.FuncTypeWindow,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			return nil, true
+		},
+		val: func(ctx api.FunctionContext, args []ast.Expr) error {
+			if err := ValidateLen(2, len(args)); err != nil {
+				return err
+			}
+			if ast.IsNumericArg(args[0]) || ast.IsStringArg(args[0]) || ast.IsBooleanArg(args[0]) {
+				return ProduceErrInfo(0, "datetime")
+			}
+			if ast.IsFloatArg(args[1]) || ast.IsStringArg(args[1]) || ast.IsTimeArg(args[1]) || ast.IsBooleanArg(args[1]) {
+				return ProduceErrInfo(1, "int")
+			}
+			if n, ok := args[1].(*ast.IntegerLiteral); ok && n.Val < 2 {
+				return fmt.Errorf("the window size must be at least 2 but found %d", n.Val)
+			}
+			return nil
+		},
+	}
+	builtins["ntile_label"] = builtinFunc{
+		fType: ast.FuncTypeWindow,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			return nil, true
+		},
+		val: func(ctx api.FunctionContext, args []ast.Expr) error {
+			if err := ValidateLen(3, len(args)); err != nil {
+				return err
+			}
+			if ast.IsStringArg(args[0]) || ast.IsTimeArg(args[0]) || ast.IsBooleanArg(args[0]) {
+				return ProduceErrInfo(0, "number - float or int")
+			}
+			if ast.IsFloatArg(args[1]) || ast.IsStringArg(args[1]) || ast.IsTimeArg(args[1]) || ast.IsBooleanArg(args[1]) {
+				return ProduceErrInfo(1, "int")
+			}
+			if ast.IsNumericArg(args[2]) || ast.IsStringArg(args[2]) || ast.IsTimeArg(args[2]) || ast.IsBooleanArg(args[2]) {
+				return ProduceErrInfo(2, "array")
+			}
+			n, ok := args[1].(*ast.IntegerLiteral)
+			if !ok {
+				return nil
+			}
+			if n.Val <= 0 {
+				return fmt.Errorf("the number of buckets must be positive but found %d", n.Val)
+			}
+			if c, ok := args[2].(*ast.Call); ok && c.Name == "array_create" && int64(len(c.Args)) != n.Val {
+				return fmt.Errorf("the labels length %d does not match the number of buckets %d", len(c.Args), n.Val)
+			}
+			return nil
+		},
+	}
 }
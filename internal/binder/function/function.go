@@ -82,7 +82,16 @@ var analyticFuncs = map[string]struct{}{
 }
 
 var windowFuncs = map[string]struct{}{
-	"row_number": {},
+	"row_number":         {},
+	"zscore":             {},
+	"is_outlier":         {},
+	"cumulative_product": {},
+	"min_max_scale":      {},
+	"wma":                {},
+	"ema":                {},
+	"ntile_label":        {},
+	"sliding_sum":        {},
+	"rolling_rate":       {},
 }
 
 const AnalyticPrefix = "$$a"
@@ -208,3 +217,13 @@ func return0IfHasAnyNil(args []interface{}) (returned interface{}, skipExec bool
 	}
 	return nil, false
 }
+
+// returnNilIfAnyArgNil only checks the nil args. Different from returnNilIfHasAnyNil, empty array is still executed.
+func returnNilIfAnyArgNil(args []interface{}) (returned interface{}, skipExec bool) {
+	for _, arg := range args {
+		if arg == nil {
+			return nil, true
+		}
+	}
+	return nil, false
+}
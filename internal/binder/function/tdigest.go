@@ -0,0 +1,132 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package function
+
+import (
+	"math"
+	"sort"
+)
+
+// defaultCompression bounds the number of centroids of the t-digest to about 2*compression
+const defaultCompression = 100
+
+type centroid struct {
+	mean   float64
+	weight float64
+}
+
+// tDigest is a merging t-digest to estimate the quantiles with bounded memory.
+// The centroids near the tails are kept small so that the estimation is accurate at the extreme quantiles.
+type tDigest struct {
+	compression float64
+	centroids   []centroid
+	buffer      []centroid
+	count       float64
+	min         float64
+	max         float64
+}
+
+func newTDigest(compression float64) *tDigest {
+	return &tDigest{
+		compression: compression,
+		buffer:      make([]centroid, 0, int(compression)*5),
+		min:         math.Inf(1),
+		max:         math.Inf(-1),
+	}
+}
+
+func (td *tDigest) add(x float64) {
+	if math.IsNaN(x) {
+		return
+	}
+	td.buffer = append(td.buffer, centroid{mean: x, weight: 1})
+	td.count++
+	if x < td.min {
+		td.min = x
+	}
+	if x > td.max {
+		td.max = x
+	}
+	if len(td.buffer) == cap(td.buffer) {
+		td.compress()
+	}
+}
+
+// compress merges the buffered values into the centroids. Adjacent centroids are merged as long as the merged
+// weight is within the size limit of its quantile, which is smaller at the tails.
+func (td *tDigest) compress() {
+	if len(td.buffer) == 0 {
+		return
+	}
+	all := append(td.centroids, td.buffer...)
+	sort.Slice(all, func(i, j int) bool { return all[i].mean < all[j].mean })
+	merged := make([]centroid, 0, len(td.centroids)+1)
+	cur := all[0]
+	sofar := 0.0
+	for _, c := range all[1:] {
+		w := cur.weight + c.weight
+		q := (sofar + w/2) / td.count
+		if w <= 4*td.count*q*(1-q)/td.compression {
+			cur.mean += (c.mean - cur.mean) * c.weight / w
+			cur.weight = w
+		} else {
+			merged = append(merged, cur)
+			sofar += cur.weight
+			cur = c
+		}
+	}
+	td.centroids = append(merged, cur)
+	td.buffer = td.buffer[:0]
+}
+
+// quantile returns the estimated value at q which ranges from 0 to 1 by interpolating between the centroids
+func (td *tDigest) quantile(q float64) float64 {
+	td.compress()
+	if len(td.centroids) == 0 {
+		return math.NaN()
+	}
+	if len(td.centroids) == 1 || q <= 0 {
+		if q >= 1 {
+			return td.max
+		}
+		if q <= 0 {
+			return td.min
+		}
+		return td.centroids[0].mean
+	}
+	if q >= 1 {
+		return td.max
+	}
+	target := q * td.count
+	first := td.centroids[0]
+	if target < first.weight/2 {
+		return td.min + (first.mean-td.min)*target/(first.weight/2)
+	}
+	last := td.centroids[len(td.centroids)-1]
+	if target > td.count-last.weight/2 {
+		return last.mean + (td.max-last.mean)*(target-(td.count-last.weight/2))/(last.weight/2)
+	}
+	// the cumulative weight at the center of the current centroid
+	center := first.weight / 2
+	for i := 1; i < len(td.centroids); i++ {
+		prev, c := td.centroids[i-1], td.centroids[i]
+		next := center + (prev.weight+c.weight)/2
+		if target <= next {
+			return prev.mean + (c.mean-prev.mean)*(target-center)/(next-center)
+		}
+		center = next
+	}
+	return last.mean
+}
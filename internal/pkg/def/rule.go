@@ -46,6 +46,20 @@ type RuleOption struct {
 	EnableSaveStateBeforeStop bool                     `json:"enableSaveStateBeforeStop,omitempty" yaml:"enableSaveStateBeforeStop,omitempty"`
 	ForceExitTimeout          cast.DurationConf        `json:"forceExitTimeout,omitempty" yaml:"forceExitTimeout,omitempty"`
 	Experiment                *ExpOpts                 `json:"experiment,omitempty" yaml:"experiment,omitempty"`
+	// FloatPrecision is the decimal places when coercing float to string such as in concat. Nil means the shortest representation.
+	FloatPrecision *int `json:"floatPrecision,omitempty" yaml:"floatPrecision,omitempty"`
+	// OutputSchema restricts and orders the output fields of the project to the listed names. The missing ones are filled with nil.
+	OutputSchema []string `json:"outputSchema,omitempty" yaml:"outputSchema,omitempty"`
+	// DedupKeys are the output field names to deduplicate the rows of a non-aggregate window. Only the first row of each key is kept.
+	DedupKeys []string `json:"dedupKeys,omitempty" yaml:"dedupKeys,omitempty"`
+	// LenientIndex makes an out of range array index such as a[n] return nil instead of an error
+	LenientIndex bool `json:"lenientIndex,omitempty" yaml:"lenientIndex,omitempty"`
+	// PreferIntResults converts the integral float results of the aggregate fields such as sum(a) to int64
+	PreferIntResults bool `json:"preferIntResults,omitempty" yaml:"preferIntResults,omitempty"`
+	// KeyCase converts all output keys to lower or upper case for the case-insensitive sinks. The values are none, lower and upper
+	KeyCase string `json:"keyCase,omitempty" yaml:"keyCase,omitempty"`
+	// RequireFields are the input field names which must be present with a non-nil value. The rows missing any of them are dropped
+	RequireFields []string `json:"requireFields,omitempty" yaml:"requireFields,omitempty"`
 }
 
 type ExpOpts struct {
@@ -35,10 +35,11 @@ import (
 )
 
 const (
-	LoggerKey        = "$$logger"
-	RuleStartKey     = "$$ruleStart"
-	RuleWaitGroupKey = "$$ruleWaitGroup"
-	TraceStrategyKey = "$$TraceStrategyKey"
+	LoggerKey         = "$$logger"
+	RuleStartKey      = "$$ruleStart"
+	RuleWaitGroupKey  = "$$ruleWaitGroup"
+	TraceStrategyKey  = "$$TraceStrategyKey"
+	FloatPrecisionKey = "$$floatPrecision"
 )
 
 const (
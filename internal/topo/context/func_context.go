@@ -52,6 +52,14 @@ func (c *DefaultFuncContext) DeleteState(key string) error {
 	return c.StreamContext.DeleteState(c.convertKey(key))
 }
 
+// Value returns nil if the function runs without a stream context such as in the operator unit tests
+func (c *DefaultFuncContext) Value(key any) any {
+	if c.StreamContext == nil {
+		return nil
+	}
+	return c.StreamContext.Value(key)
+}
+
 func (c *DefaultFuncContext) GetFuncId() int {
 	return c.funcId
 }
@@ -221,6 +221,59 @@ func TestIncAggWindow(t *testing.T) {
 	op.Close()
 }
 
+func TestIncAggLastValue(t *testing.T) {
+	o := &def.RuleOption{
+		BufferLength: 10,
+	}
+	kv, err := store.GetKV("stream")
+	require.NoError(t, err)
+	require.NoError(t, prepareStream())
+	sql := "select last_value(a) from stream group by countwindow(3)"
+	stmt, err := xsql.NewParser(strings.NewReader(sql)).Parse()
+	require.NoError(t, err)
+	p, err := planner.CreateLogicalPlan(stmt, &def.RuleOption{
+		PlanOptimizeStrategy: &def.PlanOptimizeStrategy{
+			EnableIncrementalWindow: true,
+		},
+		Qos: 0,
+	}, kv)
+	require.NoError(t, err)
+	require.NotNil(t, p)
+	incPlan := extractIncWindowPlan(p)
+	require.NotNil(t, incPlan)
+	op, err := node.NewWindowIncAggOp("1", &node.WindowConfig{
+		Type:        incPlan.WType,
+		CountLength: incPlan.Length,
+	}, incPlan.Dimensions, incPlan.IncAggFuncs, o)
+	require.NoError(t, err)
+	require.NotNil(t, op)
+	input, _ := op.GetInput()
+	output := make(chan any, 10)
+	op.AddOutput(output, "output")
+	errCh := make(chan error, 10)
+	ctx, cancel := mockContext.NewMockContext("1", "2").WithCancel()
+	op.Exec(ctx, errCh)
+	time.Sleep(10 * time.Millisecond)
+	input <- &xsql.Tuple{Message: map[string]any{"a": int64(1)}}
+	input <- &xsql.Tuple{Message: map[string]any{"a": int64(2)}}
+	// the null value is ignored by default
+	input <- &xsql.Tuple{Message: map[string]any{"a": nil}}
+	got := <-output
+	wt, ok := got.(*xsql.WindowTuples)
+	require.True(t, ok)
+	require.NotNil(t, wt)
+	d := wt.ToMaps()
+	require.Equal(t, []map[string]any{
+		{
+			"a":             nil,
+			"inc_agg_col_1": int64(2),
+		},
+	}, d)
+	cancel()
+	time.Sleep(10 * time.Millisecond)
+	op.Close()
+}
+
 func TestIncAggAlignTumblingWindow(t *testing.T) {
 	conf.IsTesting = true
 	node.EnableAlignWindow = true
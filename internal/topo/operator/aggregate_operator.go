@@ -40,6 +40,9 @@ func (p *AggregateOp) Apply(ctx api.StreamContext, data interface{}, fv *xsql.Fu
 		case error:
 			return input
 		case xsql.Collection:
+			if sets := groupingSets(p.Dimensions); len(sets) > 0 {
+				return p.applyGroupingSets(input, sets, fv)
+			}
 			wr := input.GetWindowRange()
 			result := make(map[string]*xsql.GroupedTuples)
 			err := input.Range(func(i int, ir xsql.ReadonlyRow) (bool, error) {
@@ -80,3 +83,116 @@ func (p *AggregateOp) Apply(ctx api.StreamContext, data interface{}, fv *xsql.Fu
 	}
 	return grouped
 }
+
+// groupingSets returns the grouping sets of ROLLUP or GROUPING SETS as the indexes of the used dimensions.
+// For GROUP BY a, ROLLUP(b, c), the sets are (a, b, c), (a, b) and (a).
+// For GROUP BY a, GROUPING SETS((b), (b, c), ()), the sets are (a, b), (a, b, c) and (a).
+// Returns nil if there is neither ROLLUP nor GROUPING SETS.
+func groupingSets(dimensions ast.Dimensions) [][]int {
+	var (
+		fixed  []int
+		rollup []int
+		// the columns of each set in GROUPING SETS
+		grouping [][]int
+	)
+	for i, d := range dimensions {
+		switch {
+		case d.Rollup:
+			rollup = append(rollup, i)
+		case len(d.GroupingSets) > 0:
+			if grouping == nil {
+				grouping = make([][]int, len(d.GroupingSets))
+			}
+			for si, in := range d.GroupingSets {
+				if in {
+					grouping[si] = append(grouping[si], i)
+				}
+			}
+		default:
+			fixed = append(fixed, i)
+		}
+	}
+	if grouping != nil {
+		sets := make([][]int, 0, len(grouping))
+		for _, cols := range grouping {
+			set := make([]int, 0, len(fixed)+len(cols))
+			set = append(set, fixed...)
+			set = append(set, cols...)
+			sets = append(sets, set)
+		}
+		return sets
+	}
+	if len(rollup) == 0 {
+		return nil
+	}
+	sets := make([][]int, 0, len(rollup)+1)
+	for i := len(rollup); i >= 0; i-- {
+		set := make([]int, 0, len(fixed)+i)
+		set = append(set, fixed...)
+		set = append(set, rollup[:i]...)
+		sets = append(sets, set)
+	}
+	return sets
+}
+
+// applyGroupingSets groups the input by each grouping set. The groups are output in the order of the sets. For ROLLUP,
+// the detail groups are output first, then the subtotal groups from the lowest level to the grand total. The rolled up
+// columns which are not in the set are nil.
+func (p *AggregateOp) applyGroupingSets(input xsql.Collection, sets [][]int, fv *xsql.FunctionValuer) interface{} {
+	wr := input.GetWindowRange()
+	rollupCols := make([][]*ast.FieldRef, len(sets))
+	for si, set := range sets {
+		used := make(map[int]struct{}, len(set))
+		for _, i := range set {
+			used[i] = struct{}{}
+		}
+		for i, d := range p.Dimensions {
+			if _, ok := used[i]; !ok && (d.Rollup || len(d.GroupingSets) > 0) {
+				rollupCols[si] = append(rollupCols[si], d.Expr.(*ast.FieldRef))
+			}
+		}
+	}
+	results := make([]map[string]*xsql.GroupedTuples, len(sets))
+	keys := make([][]string, len(sets))
+	for si := range sets {
+		results[si] = make(map[string]*xsql.GroupedTuples)
+	}
+	values := make([]interface{}, len(p.Dimensions))
+	err := input.Range(func(i int, ir xsql.ReadonlyRow) (bool, error) {
+		tr := ir.(xsql.Row)
+		ve := &xsql.ValuerEval{Valuer: xsql.MultiValuer(tr, &xsql.WindowRangeValuer{WindowRange: wr}, fv)}
+		for di, d := range p.Dimensions {
+			r := ve.Eval(d.Expr)
+			if _, ok := r.(error); ok {
+				return false, fmt.Errorf("run Group By error: %v", r)
+			}
+			values[di] = r
+		}
+		for si, set := range sets {
+			var name string
+			for _, di := range set {
+				name += fmt.Sprintf("%v,", values[di])
+			}
+			if ts, ok := results[si][name]; !ok {
+				keys[si] = append(keys[si], name)
+				results[si][name] = &xsql.GroupedTuples{Content: []xsql.Row{tr}, WindowRange: wr, RollupCols: rollupCols[si]}
+			} else {
+				ts.Content = append(ts.Content, tr)
+			}
+		}
+		return true, nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(keys[0]) == 0 {
+		return nil
+	}
+	var g []*xsql.GroupedTuples
+	for si := range sets {
+		for _, key := range keys[si] {
+			g = append(g, results[si][key])
+		}
+	}
+	return &xsql.GroupedTuplesSet{Groups: g}
+}
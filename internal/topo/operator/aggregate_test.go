@@ -21,6 +21,8 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/stretchr/testify/require"
+
 	"github.com/lf-edge/ekuiper/v2/internal/conf"
 	"github.com/lf-edge/ekuiper/v2/internal/topo/context"
 	"github.com/lf-edge/ekuiper/v2/internal/xsql"
@@ -501,3 +503,111 @@ func TestAggregatePlanError(t *testing.T) {
 		}
 	}
 }
+
+func TestAggregatePlan_Rollup(t *testing.T) {
+	data := &xsql.WindowTuples{
+		Content: []xsql.Row{
+			&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"region": "east", "device": "d1", "v": 1}},
+			&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"region": "east", "device": "d2", "v": 2}},
+			&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"region": "west", "device": "d3", "v": 4}},
+			&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"region": "east", "device": "d1", "v": 8}},
+		},
+		WindowRange: xsql.NewWindowRange(1541152486013, 1541152487013, 1541152487013),
+	}
+	tests := []struct {
+		sql    string
+		result []map[string]interface{}
+	}{
+		{
+			sql: "SELECT region, device, sum(v) AS s FROM src1 GROUP BY TUMBLINGWINDOW(ss, 10), ROLLUP(region, device)",
+			result: []map[string]interface{}{
+				{"region": "east", "device": "d1", "s": int64(9)},
+				{"region": "east", "device": "d2", "s": int64(2)},
+				{"region": "west", "device": "d3", "s": int64(4)},
+				{"region": "east", "device": nil, "s": int64(11)},
+				{"region": "west", "device": nil, "s": int64(4)},
+				{"region": nil, "device": nil, "s": int64(15)},
+			},
+		},
+		{
+			sql: "SELECT region, device AS d, count(*) AS c FROM src1 GROUP BY TUMBLINGWINDOW(ss, 10), region, ROLLUP(device)",
+			result: []map[string]interface{}{
+				{"region": "east", "d": "d1", "c": 2},
+				{"region": "east", "d": "d2", "c": 1},
+				{"region": "west", "d": "d3", "c": 1},
+				{"region": "east", "d": nil, "c": 3},
+				{"region": "west", "d": nil, "c": 1},
+			},
+		},
+	}
+	contextLogger := conf.Log.WithField("rule", "TestAggregatePlan_Rollup")
+	ctx := context.WithValue(context.Background(), context.LoggerKey, contextLogger)
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			stmt, err := xsql.NewParser(strings.NewReader(tt.sql)).Parse()
+			require.NoError(t, err)
+			fv, afv := xsql.NewFunctionValuersForOp(nil)
+			ap := &AggregateOp{Dimensions: stmt.Dimensions.GetGroups()}
+			grouped := ap.Apply(ctx, data, fv, afv)
+			pp := &ProjectOp{SendNil: true, IsAggregate: true}
+			parseStmt(pp, stmt.Fields)
+			result, err := parseResult(pp.Apply(ctx, grouped, fv, afv), pp.IsAggregate)
+			require.NoError(t, err)
+			require.Equal(t, tt.result, result)
+		})
+	}
+}
+
+func TestAggregatePlan_GroupingSets(t *testing.T) {
+	data := &xsql.WindowTuples{
+		Content: []xsql.Row{
+			&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"device": "d1", "color": "red", "v": 1}},
+			&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"device": "d1", "color": "blue", "v": 2}},
+			&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"device": "d2", "color": "red", "v": 4}},
+			&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"device": "d1", "color": "red", "v": 8}},
+		},
+		WindowRange: xsql.NewWindowRange(1541152486013, 1541152487013, 1541152487013),
+	}
+	tests := []struct {
+		sql    string
+		result []map[string]interface{}
+	}{
+		{
+			sql: "SELECT device, color, sum(v) AS s FROM src1 GROUP BY TUMBLINGWINDOW(ss, 10), GROUPING SETS ((device), (device, color), ())",
+			result: []map[string]interface{}{
+				{"device": "d1", "color": nil, "s": int64(11)},
+				{"device": "d2", "color": nil, "s": int64(4)},
+				{"device": "d1", "color": "red", "s": int64(9)},
+				{"device": "d1", "color": "blue", "s": int64(2)},
+				{"device": "d2", "color": "red", "s": int64(4)},
+				{"device": nil, "color": nil, "s": int64(15)},
+			},
+		},
+		{
+			sql: "SELECT device, color AS c, count(*) AS n FROM src1 GROUP BY TUMBLINGWINDOW(ss, 10), device, GROUPING SETS (color, ())",
+			result: []map[string]interface{}{
+				{"device": "d1", "c": "red", "n": 2},
+				{"device": "d1", "c": "blue", "n": 1},
+				{"device": "d2", "c": "red", "n": 1},
+				{"device": "d1", "c": nil, "n": 3},
+				{"device": "d2", "c": nil, "n": 1},
+			},
+		},
+	}
+	contextLogger := conf.Log.WithField("rule", "TestAggregatePlan_GroupingSets")
+	ctx := context.WithValue(context.Background(), context.LoggerKey, contextLogger)
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			stmt, err := xsql.NewParser(strings.NewReader(tt.sql)).Parse()
+			require.NoError(t, err)
+			fv, afv := xsql.NewFunctionValuersForOp(nil)
+			ap := &AggregateOp{Dimensions: stmt.Dimensions.GetGroups()}
+			grouped := ap.Apply(ctx, data, fv, afv)
+			pp := &ProjectOp{SendNil: true, IsAggregate: true}
+			parseStmt(pp, stmt.Fields)
+			result, err := parseResult(pp.Apply(ctx, grouped, fv, afv), pp.IsAggregate)
+			require.NoError(t, err)
+			require.Equal(t, tt.result, result)
+		})
+	}
+}
@@ -229,3 +229,48 @@ func TestAnalyticFuncs(t *testing.T) {
 		})
 	}
 }
+
+func TestChangedColAcrossWindows(t *testing.T) {
+	contextLogger := conf.Log.WithField("rule", "TestChangedColAcrossWindows")
+	tempStore, _ := state.CreateStore("mockRuleWindow", def.AtMostOnce)
+	ctx := context.WithValue(context.Background(), context.LoggerKey, contextLogger).WithMeta("mockRuleWindow", "project", tempStore)
+	pp := &AnalyticFuncsOp{Funcs: []*ast.Call{
+		{
+			Name: "changed_col",
+			Args: []ast.Expr{
+				&ast.BooleanLiteral{Val: true},
+				&ast.FieldRef{Name: "a"},
+			},
+			FuncId:      0,
+			CachedField: "$$a_changed_col_0",
+		},
+	}}
+	fv, afv := xsql.NewFunctionValuersForOp(ctx)
+	windows := []*xsql.WindowTuples{
+		{
+			Content: []xsql.Row{
+				&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 1}},
+				&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 1}},
+				&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 2}},
+			},
+		},
+		{
+			Content: []xsql.Row{
+				&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 2}},
+				&xsql.Tuple{Emitter: "test", Message: xsql.Message{}},
+				&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 3}},
+			},
+		},
+	}
+	// The state is kept per rule and function, so the first row of the second window compares to the last row of the first window
+	expects := [][]any{{1, nil, 2}, {nil, nil, 3}}
+	for i, w := range windows {
+		opResult := pp.Apply(ctx, w, fv, afv)
+		require.IsType(t, &xsql.WindowTuples{}, opResult)
+		r := make([]any, 0, w.Len())
+		for _, row := range opResult.(*xsql.WindowTuples).Content {
+			r = append(r, row.(*xsql.Tuple).CalCols["$$a_changed_col_0"])
+		}
+		require.Equal(t, expects[i], r)
+	}
+}
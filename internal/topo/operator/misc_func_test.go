@@ -132,6 +132,20 @@ func TestMiscFunc_Apply1(t *testing.T) {
 				"a": strings.ToLower("414FA339"),
 			}},
 		},
+		{
+			sql: "SELECT xxhash(a) AS a, xxhash(obj) AS b FROM test",
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"a":   "The quick brown fox jumps over the lazy dog",
+					"obj": map[string]interface{}{"c": "myc", "b": "myb"},
+				},
+			},
+			result: []map[string]interface{}{{
+				"a": int64(6413903450483454728),
+				"b": int64(8965392345695928834),
+			}},
+		},
 
 		{
 			sql: "SELECT mqtt(topic) AS a FROM test",
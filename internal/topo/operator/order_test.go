@@ -100,6 +100,39 @@ func TestOrderPlan_Apply(t *testing.T) {
 			},
 		},
 
+		{
+			sql: "SELECT id1 FROM src1 WHERE f1 = \"v1\" GROUP BY TUMBLINGWINDOW(ss, 10) ORDER BY id1 DESC NULLS FIRST",
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{
+						Emitter: "src1",
+						Message: xsql.Message{"id1": 1, "f1": "v1"},
+					}, &xsql.Tuple{
+						Emitter: "src1",
+						Message: xsql.Message{"f1": "v2"},
+					}, &xsql.Tuple{
+						Emitter: "src1",
+						Message: xsql.Message{"id1": 3, "f1": "v1"},
+					},
+				},
+				WindowRange: xsql.NewWindowRange(1541152486013, 1541152487013, 1541152487013),
+			},
+			result: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{
+						Emitter: "src1",
+						Message: xsql.Message{"f1": "v2"},
+					}, &xsql.Tuple{
+						Emitter: "src1",
+						Message: xsql.Message{"id1": 3, "f1": "v1"},
+					}, &xsql.Tuple{
+						Emitter: "src1",
+						Message: xsql.Message{"id1": 1, "f1": "v1"},
+					},
+				},
+				WindowRange: xsql.NewWindowRange(1541152486013, 1541152487013, 1541152487013),
+			},
+		},
 		{
 			sql: "SELECT id1 FROM src1 WHERE f1 = \"v1\" GROUP BY TUMBLINGWINDOW(ss, 10) ORDER BY id1 DESC",
 			data: &xsql.WindowTuples{
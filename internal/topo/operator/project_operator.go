@@ -15,7 +15,14 @@
 package operator
 
 import (
+	"encoding/json"
 	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/lf-edge/ekuiper/contract/v2/api"
 
@@ -25,9 +32,17 @@ import (
 	"github.com/lf-edge/ekuiper/v2/pkg/message"
 )
 
+// The supported values of ProjectOp.KeyCase
+const (
+	KeyCaseNone  = "none"
+	KeyCaseLower = "lower"
+	KeyCaseUpper = "upper"
+)
+
 type ProjectOp struct {
-	ColNames         [][]string // list of [col, table]
-	ExceptNames      []string   // list of except name
+	ColNames         [][]string       // list of [col, table]
+	ExceptNames      []string         // list of except name
+	ExceptMatching   []*regexp.Regexp // list of except name patterns, matched for each row as the stream may be schemaless
 	AllWildcard      bool
 	WildcardEmitters map[string]bool
 	AliasFields      ast.Fields
@@ -41,9 +56,54 @@ type ProjectOp struct {
 
 	SendMeta bool
 	SendNil  bool
+	// Profile enables recording the evaluation duration of each field to find the expensive expressions
+	Profile bool
+	// OutputSchema is the ordered output field names. If set, the output only contains these fields in this order.
+	// The missing fields are filled with nil.
+	OutputSchema []string
+	// Distinct drops the duplicated projected rows of a collection for SELECT DISTINCT. The rows are compared by
+	// the whole output map and only the first one is kept. The limit is applied after dropping the duplications.
+	Distinct bool
+	// DedupKeys are the output field names to deduplicate the rows of a non-aggregate collection.
+	// Only the first row of each distinct key values is kept and the order is preserved. The limit is applied after
+	// deduplication. It is set by the rule option dedupKeys.
+	DedupKeys []string
+	// RequireFields are the input field names which must present with a non-nil value. A non-aggregate row missing
+	// any of them is dropped silently instead of emitting a partial row. For a non-aggregate collection, the rows
+	// missing them are filtered out and the limit is applied to the kept rows. It is set by the rule option requireFields.
+	RequireFields []string
+	// LenientIndex makes an out of range array index such as a[n] return nil instead of an error
+	LenientIndex bool
+	// PreferIntResults converts the integral float results of the aggregate fields such as sum(a) to int64.
+	// It is useful when the values are decoded from JSON where all numbers are float64. It is set by the rule option
+	// preferIntResults.
+	PreferIntResults bool
+	// KeyCase converts all output keys to lower case if it is "lower" or upper case if it is "upper" for the
+	// case-insensitive sinks. The default "none" keeps the keys. It is set by the rule option keyCase and checked by
+	// ValidateKeyCase when planning.
+	KeyCase string
+	// Ordered records the output field names in the SELECT order so that sinks can emit the columns in that order.
+	// The fields expanded by a wildcard are placed at the wildcard position in sorted key order. Ignored if OutputSchema is set.
+	// It is internal only: the planner does not set it and no built-in sink reads xsql.OrderedRow yet, so it is only
+	// useful for the callers which build the ProjectOp and consume the rows directly.
+	Ordered bool
+
+	schemaCols [][]string
 
 	kvs   []interface{}
 	alias []interface{}
+	// the columns of the expand functions which are set after all other fields
+	expanded []*function.ExpandedCols
+	// the values of the nested alias fields which are set to the nested path
+	nested []interface{}
+	// the cached paths of the nested alias names
+	aliasPaths map[string][]string
+	except     []string
+	// the reused values of the dedup keys of a row
+	dedupVals []interface{}
+	// the accumulated evaluation duration in nanoseconds by field name, only recorded when Profile is on
+	timings     map[string]int64
+	timingsLock sync.Mutex
 }
 
 // Apply
@@ -61,16 +121,20 @@ func (pp *ProjectOp) Apply(ctx api.StreamContext, data interface{}, fv *xsql.Fun
 	case error:
 		return input
 	case xsql.Row:
-		ve := pp.getRowVE(input, nil, fv, afv)
-		if err := pp.project(input, ve); err != nil {
-			return fmt.Errorf("run Select error: %s", err)
-		} else {
-			if pp.SendMeta {
-				if md, ok := input.(xsql.MetaData); ok {
-					metadata := md.MetaData()
-					if metadata != nil {
-						input.Set(message.MetaKey, md.MetaData())
-					}
+		if !pp.IsAggregate && !pp.hasRequired(input) {
+			return []xsql.Row{}
+		}
+		if !pp.pickWildcard(input) {
+			ve := pp.getRowVE(input, nil, fv, afv)
+			if err := pp.project(ctx, input, ve); err != nil {
+				return fmt.Errorf("run Select error: %s", err)
+			}
+		}
+		if pp.SendMeta {
+			if md, ok := input.(xsql.MetaData); ok {
+				metadata := md.MetaData()
+				if metadata != nil {
+					input.Set(message.MetaKey, md.MetaData())
 				}
 			}
 		}
@@ -79,65 +143,179 @@ func (pp *ProjectOp) Apply(ctx api.StreamContext, data interface{}, fv *xsql.Fun
 		if pp.IsAggregate {
 			input.SetIsAgg(true)
 			err = input.GroupRange(func(i int, aggRow xsql.CollectionRow) (bool, error) {
-				if pp.EnableLimit && pp.LimitCount > 0 && i >= pp.LimitCount {
+				if pp.EnableLimit && pp.LimitCount > 0 && i >= pp.LimitCount && !pp.Distinct {
 					return false, nil
 				}
-				ve := pp.getVE(aggRow, aggRow, input.GetWindowRange(), fv, afv)
-				if err := pp.project(aggRow, ve); err != nil {
+				// window_index() returns the index of the group for an aggregate query
+				ve := pp.getVE(aggRow, aggRow, input.GetWindowRange(), i, fv, afv)
+				if err := pp.project(ctx, aggRow, ve); err != nil {
 					return false, fmt.Errorf("run Select error: %s", err)
 				}
 				return true, nil
 			})
 		} else {
+			var (
+				kept []int
+				seen map[string]struct{}
+			)
+			if len(pp.DedupKeys) > 0 {
+				seen = make(map[string]struct{})
+			}
+			filter := seen != nil || len(pp.RequireFields) > 0
 			err = input.RangeSet(func(i int, row xsql.Row) (bool, error) {
-				if pp.EnableLimit && pp.LimitCount > 0 && i >= pp.LimitCount {
-					return false, nil
+				if pp.EnableLimit && pp.LimitCount > 0 && !pp.Distinct {
+					// the limit applies to the rows kept after deduplication and dropping the ones missing required fields
+					n := i
+					if filter {
+						n = len(kept)
+					}
+					if n >= pp.LimitCount {
+						return false, nil
+					}
+				}
+				if !pp.hasRequired(row) {
+					return true, nil
+				}
+				if seen == nil && pp.pickWildcard(row) {
+					if filter {
+						kept = append(kept, i)
+					}
+					return true, nil
 				}
 				aggData, ok := input.(xsql.AggregateData)
 				if !ok {
 					return false, fmt.Errorf("unexpected type, cannot find aggregate data")
 				}
-				ve := pp.getVE(row, aggData, input.GetWindowRange(), fv, afv)
-				if err := pp.project(row, ve); err != nil {
+				ve := pp.getVE(row, aggData, input.GetWindowRange(), i, fv, afv)
+				if err := pp.project(ctx, row, ve); err != nil {
 					return false, fmt.Errorf("run Select error: %s", err)
 				}
+				if seen != nil {
+					key, err := pp.dedupKey(row)
+					if err != nil {
+						return false, err
+					}
+					if _, ok := seen[key]; !ok {
+						seen[key] = struct{}{}
+						kept = append(kept, i)
+					}
+				} else if filter {
+					kept = append(kept, i)
+				}
 				return true, nil
 			})
+			if err == nil && filter {
+				input.Filter(kept)
+			}
 		}
 		if err != nil {
 			return err
 		}
+		if pp.Distinct {
+			pp.distinct(input)
+		}
 	default:
 		return fmt.Errorf("run Select error: invalid input %[1]T(%[1]v)", input)
 	}
 	return data
 }
 
-func (pp *ProjectOp) getVE(tuple xsql.RawRow, agg xsql.AggregateData, wr *xsql.WindowRange, fv *xsql.FunctionValuer, afv *xsql.AggregateFunctionValuer) *xsql.ValuerEval {
+// TypeAnnotations returns the types annotated by :: of the output fields such as a::bigint.
+// The key is the output field name. It can be used to generate the schema for the sink.
+func (pp *ProjectOp) TypeAnnotations() map[string]ast.DataType {
+	result := make(map[string]ast.DataType)
+	for _, f := range pp.AliasFields {
+		if f.Invisible {
+			continue
+		}
+		expr := f.Expr
+		if ref, ok := expr.(*ast.FieldRef); ok && ref.IsAlias() {
+			expr = ref.AliasRef.Expression
+		}
+		if c, ok := expr.(*ast.CastExpr); ok {
+			result[f.AName] = c.Type
+		}
+	}
+	for _, f := range pp.ExprFields {
+		if f.Invisible {
+			continue
+		}
+		if c, ok := f.Expr.(*ast.CastExpr); ok {
+			result[f.Name] = c.Type
+		}
+	}
+	return result
+}
+
+// FieldTimings returns a copy of the accumulated evaluation duration in nanoseconds of each field keyed by
+// the field name or alias. It is only recorded when Profile is enabled.
+func (pp *ProjectOp) FieldTimings() map[string]int64 {
+	pp.timingsLock.Lock()
+	defer pp.timingsLock.Unlock()
+	result := make(map[string]int64, len(pp.timings))
+	for k, v := range pp.timings {
+		result[k] = v
+	}
+	return result
+}
+
+func (pp *ProjectOp) recordTiming(name string, start time.Time) {
+	d := time.Since(start).Nanoseconds()
+	pp.timingsLock.Lock()
+	defer pp.timingsLock.Unlock()
+	if pp.timings == nil {
+		pp.timings = make(map[string]int64)
+	}
+	pp.timings[name] += d
+}
+
+// evalField evaluates the field expression and records the duration if profiling
+func (pp *ProjectOp) evalField(ve *xsql.ValuerEval, name string, expr ast.Expr) interface{} {
+	if !pp.Profile {
+		return ve.Eval(expr)
+	}
+	start := time.Now()
+	vi := ve.Eval(expr)
+	pp.recordTiming(name, start)
+	return vi
+}
+
+// getVE creates the valuer of a row. The index is the position of the row in the window, or the position of the group
+// for an aggregate query, which is returned by window_index()
+func (pp *ProjectOp) getVE(tuple xsql.RawRow, agg xsql.AggregateData, wr *xsql.WindowRange, index int, fv *xsql.FunctionValuer, afv *xsql.AggregateFunctionValuer) *xsql.ValuerEval {
 	afv.SetData(agg)
+	var valuer xsql.Valuer
+	iv := &xsql.WindowIndexValuer{Index: index}
 	if pp.IsAggregate {
-		return &xsql.ValuerEval{Valuer: xsql.MultiAggregateValuer(agg, fv, tuple, fv, afv, &xsql.WildcardValuer{Data: tuple})}
+		// The group may have its own window range which takes precedence over the range of the whole collection
+		if wr != nil {
+			valuer = xsql.MultiAggregateValuer(agg, fv, tuple, &xsql.WindowRangeValuer{WindowRange: wr}, iv, fv, afv, &xsql.WildcardValuer{Data: tuple})
+		} else {
+			valuer = xsql.MultiAggregateValuer(agg, fv, tuple, iv, fv, afv, &xsql.WildcardValuer{Data: tuple})
+		}
 	} else {
 		if wr != nil {
-			return &xsql.ValuerEval{Valuer: xsql.MultiValuer(tuple, &xsql.WindowRangeValuer{WindowRange: wr}, fv, &xsql.WildcardValuer{Data: tuple})}
+			valuer = xsql.MultiValuer(tuple, &xsql.WindowRangeValuer{WindowRange: wr}, iv, fv, &xsql.WildcardValuer{Data: tuple})
+		} else {
+			valuer = xsql.MultiValuer(tuple, iv, fv, &xsql.WildcardValuer{Data: tuple})
 		}
-		return &xsql.ValuerEval{Valuer: xsql.MultiValuer(tuple, fv, &xsql.WildcardValuer{Data: tuple})}
 	}
+	return &xsql.ValuerEval{Valuer: valuer, LenientIndex: pp.LenientIndex}
 }
 
 func (pp *ProjectOp) getRowVE(tuple xsql.Row, wr *xsql.WindowRange, fv *xsql.FunctionValuer, afv *xsql.AggregateFunctionValuer) *xsql.ValuerEval {
 	if ag, ok := tuple.(xsql.AggregateData); ok {
-		return pp.getVE(tuple, ag, wr, fv, afv)
+		return pp.getVE(tuple, ag, wr, 0, fv, afv)
 	} else {
-		return pp.getVE(tuple, nil, wr, fv, afv)
+		return pp.getVE(tuple, nil, wr, 0, fv, afv)
 	}
 }
 
-func (pp *ProjectOp) project(row xsql.RawRow, ve *xsql.ValuerEval) error {
+func (pp *ProjectOp) project(ctx api.StreamContext, row xsql.RawRow, ve *xsql.ValuerEval) error {
 	switch rt := row.(type) {
 	case *xsql.SliceTuple:
 		for _, f := range pp.AliasFields {
-			vi := ve.Eval(f.Expr)
+			vi := pp.evalField(ve, f.AName, f.Expr)
 			if e, ok := vi.(error); ok {
 				return fmt.Errorf("expr: %s meet error, err:%v", f.Expr.String(), e)
 			}
@@ -146,7 +324,7 @@ func (pp *ProjectOp) project(row xsql.RawRow, ve *xsql.ValuerEval) error {
 		}
 		for _, f := range pp.Fields {
 			if f.AName == "" {
-				vi := ve.Eval(f.Expr)
+				vi := pp.evalField(ve, f.Name, f.Expr)
 				if e, ok := vi.(error); ok {
 					return fmt.Errorf("expr: %s meet error, err:%v", f.Expr.String(), e)
 				}
@@ -165,23 +343,27 @@ func (pp *ProjectOp) project(row xsql.RawRow, ve *xsql.ValuerEval) error {
 			if f.Invisible {
 				continue
 			}
-			vi := ve.Eval(f.Expr)
+			vi := pp.evalField(ve, f.Name, f.Expr)
 			if e, ok := vi.(error); ok {
 				return fmt.Errorf("expr: %s meet error, err:%v", f.Expr.String(), e)
 			}
+			vi = pp.preferInt(f.Expr, vi)
+			vi = pp.metaNil(f.Expr, vi)
 			if vi != nil {
 				switch vt := vi.(type) {
 				case function.ResultCols:
 					for k, v := range vt {
 						pp.kvs = append(pp.kvs, k, v)
 					}
+				case *function.ExpandedCols:
+					pp.expanded = append(pp.expanded, vt)
 				default:
 					pp.kvs = append(pp.kvs, f.Name, vi)
 				}
 			}
 		}
 		for _, f := range pp.AliasFields {
-			vi := ve.Eval(f.Expr)
+			vi := pp.evalField(ve, f.AName, f.Expr)
 			if e, ok := vi.(error); ok {
 				if ref, ok := f.Expr.(*ast.FieldRef); ok {
 					s := ref.AliasRef.Expression.String()
@@ -189,11 +371,21 @@ func (pp *ProjectOp) project(row xsql.RawRow, ve *xsql.ValuerEval) error {
 				}
 				return fmt.Errorf("alias: %v expr: %v meet error, err:%v", f.AName, f.Expr.String(), e)
 			}
+			vi = pp.preferInt(f.Expr, vi)
+			vi = pp.metaNil(f.Expr, vi)
 			if !f.Invisible && (vi != nil || pp.SendNil) {
-				pp.alias = append(pp.alias, f.AName, vi)
+				if f.NestedAlias {
+					pp.nested = append(pp.nested, pp.aliasPath(f.AName), vi)
+				} else {
+					pp.alias = append(pp.alias, f.AName, vi)
+				}
 			}
 		}
-		row.Pick(pp.AllWildcard, pp.ColNames, pp.WildcardEmitters, pp.ExceptNames, pp.SendNil)
+		except := pp.ExceptNames
+		if pp.AllWildcard && len(pp.ExceptMatching) > 0 {
+			except = pp.matchExcept(row)
+		}
+		row.Pick(pp.AllWildcard, pp.ColNames, pp.WildcardEmitters, except, pp.SendNil)
 		for i := 0; i < len(pp.kvs); i += 2 {
 			row.Set(pp.kvs[i].(string), pp.kvs[i+1])
 		}
@@ -202,6 +394,389 @@ func (pp *ProjectOp) project(row xsql.RawRow, ve *xsql.ValuerEval) error {
 			row.AppendAlias(pp.alias[i].(string), pp.alias[i+1])
 		}
 		pp.alias = pp.alias[:0]
+		if len(pp.expanded) > 0 {
+			err := pp.setExpanded(row)
+			pp.expanded = pp.expanded[:0]
+			if err != nil {
+				return err
+			}
+		}
+		if len(pp.nested) > 0 {
+			err := pp.setNested(row)
+			pp.nested = pp.nested[:0]
+			if err != nil {
+				return err
+			}
+		}
+		if len(pp.OutputSchema) > 0 {
+			pp.applySchema(row)
+		} else if pp.Ordered {
+			pp.applyOrder(row)
+		}
+		if pp.KeyCase == KeyCaseLower || pp.KeyCase == KeyCaseUpper {
+			pp.applyKeyCase(ctx, row)
+		}
+	}
+	return nil
+}
+
+// pickWildcard forwards the row without evaluation if the fields are a pure SELECT * which has no except, replace
+// or other fields. The message is kept as is to avoid creating the valuers and reallocating for each row.
+// Returns false if the row needs to be projected.
+func (pp *ProjectOp) pickWildcard(row xsql.RawRow) bool {
+	if !pp.AllWildcard || pp.IsAggregate || pp.Profile || pp.Ordered {
+		return false
+	}
+	if len(pp.ExceptNames) > 0 || len(pp.ExceptMatching) > 0 || len(pp.AliasFields) > 0 || len(pp.ExprFields) > 0 || len(pp.ColNames) > 0 || len(pp.OutputSchema) > 0 {
+		return false
+	}
+	if pp.KeyCase == KeyCaseLower || pp.KeyCase == KeyCaseUpper {
+		return false
+	}
+	if _, ok := row.(*xsql.SliceTuple); ok {
+		return false
+	}
+	row.Pick(true, nil, nil, nil, pp.SendNil)
+	return true
+}
+
+// hasRequired checks if the row has all the required fields with non-nil values
+func (pp *ProjectOp) hasRequired(row xsql.RawRow) bool {
+	for _, f := range pp.RequireFields {
+		if v, ok := row.Value(f, ""); !ok || v == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// dedupKey encodes the values of the dedup keys as JSON so that the values of different types or containing
+// the separator do not collide
+func (pp *ProjectOp) dedupKey(row xsql.Row) (string, error) {
+	if pp.dedupVals == nil {
+		pp.dedupVals = make([]interface{}, len(pp.DedupKeys))
+	}
+	for i, k := range pp.DedupKeys {
+		pp.dedupVals[i], _ = row.Value(k, "")
+	}
+	b, err := json.Marshal(pp.dedupVals)
+	if err != nil {
+		return "", fmt.Errorf("fail to build the dedup key: %v", err)
+	}
+	return string(b), nil
+}
+
+// distinct keeps the first row of each distinct projected output and applies the limit. The aggregate result of a
+// collection without groups is a single row, so it is kept as is.
+func (pp *ProjectOp) distinct(input xsql.Collection) {
+	if _, ok := input.(*xsql.GroupedTuplesSet); pp.IsAggregate && !ok {
+		return
+	}
+	maps := input.ToMaps()
+	kept := make([]int, 0, len(maps))
+	seen := make(map[string]struct{}, len(maps))
+	for i, m := range maps {
+		if pp.EnableLimit && pp.LimitCount > 0 && len(kept) >= pp.LimitCount {
+			break
+		}
+		// fmt prints the map keys in sorted order, so the same maps have the same key
+		key := fmt.Sprintf("%#v", m)
+		if _, ok := seen[key]; !ok {
+			seen[key] = struct{}{}
+			kept = append(kept, i)
+		}
+	}
+	input.Filter(kept)
+}
+
+// preferInt converts the integral float64 result of an aggregate field to int64 if PreferIntResults is set
+func (pp *ProjectOp) preferInt(expr ast.Expr, vi interface{}) interface{} {
+	if !pp.PreferIntResults || !pp.IsAggregate {
+		return vi
+	}
+	f, ok := vi.(float64)
+	if !ok || f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+		return vi
+	}
+	if !xsql.IsAggregate(expr) {
+		return vi
+	}
+	return int64(f)
+}
+
+// metaNil returns nil for meta(*) of a row without metadata if SendNil is on so that the sink receives a nil value
+// instead of an empty object
+func (pp *ProjectOp) metaNil(expr ast.Expr, vi interface{}) interface{} {
+	if !pp.SendNil {
+		return vi
+	}
+	if m, ok := vi.(map[string]interface{}); !ok || len(m) > 0 {
+		return vi
+	}
+	if f, ok := expr.(*ast.FieldRef); ok && f.IsAlias() && f.AliasRef != nil {
+		expr = f.AliasRef.Expression
+	}
+	c, ok := expr.(*ast.Call)
+	if !ok || c.Name != "meta" || len(c.Args) != 1 {
+		return vi
+	}
+	if r, ok := c.Args[0].(*ast.MetaRef); ok && r.Name == "*" {
+		return nil
+	}
+	return vi
+}
+
+// matchExcept returns the except names including the field names of the row which match the except patterns
+func (pp *ProjectOp) matchExcept(row xsql.RawRow) []string {
+	pp.except = append(pp.except[:0], pp.ExceptNames...)
+	all, _ := row.All("")
+	for k := range all {
+		for _, re := range pp.ExceptMatching {
+			if re.MatchString(k) {
+				pp.except = append(pp.except, k)
+				break
+			}
+		}
+	}
+	return pp.except
+}
+
+// setExpanded sets the expanded columns to the row and resolves the collisions with the other selected fields by the policy
+func (pp *ProjectOp) setExpanded(row xsql.RawRow) error {
+	for _, ec := range pp.expanded {
+		for k, v := range ec.Cols {
+			if _, ok := row.Value(k, ""); ok {
+				switch ec.Policy {
+				case function.ExpandKeep:
+					continue
+				case function.ExpandError:
+					return fmt.Errorf("expanded column %s conflicts with the selected field", k)
+				}
+				// overwrite the selected field even if it is an alias
+				row.AppendAlias(k, v)
+				continue
+			}
+			row.Set(k, v)
+		}
 	}
 	return nil
 }
+
+// aliasPath returns the path of a nested alias name like payload.temp
+func (pp *ProjectOp) aliasPath(name string) []string {
+	if pp.aliasPaths == nil {
+		pp.aliasPaths = make(map[string][]string)
+	}
+	path, ok := pp.aliasPaths[name]
+	if !ok {
+		path = strings.Split(name, ".")
+		pp.aliasPaths[name] = path
+	}
+	return path
+}
+
+// setNested builds the nested objects of the dotted alias fields and sets them to the row by the root key
+func (pp *ProjectOp) setNested(row xsql.RawRow) error {
+	roots := make(map[string]map[string]interface{})
+	var keys []string
+	for i := 0; i < len(pp.nested); i += 2 {
+		path := pp.nested[i].([]string)
+		root, ok := roots[path[0]]
+		if !ok {
+			if _, exists := row.Value(path[0], ""); exists {
+				return fmt.Errorf("alias %s conflicts with the field %s", strings.Join(path, "."), path[0])
+			}
+			root = make(map[string]interface{})
+			roots[path[0]] = root
+			keys = append(keys, path[0])
+		}
+		m := root
+		for j := 1; j < len(path)-1; j++ {
+			child, ok := m[path[j]]
+			if !ok {
+				cm := make(map[string]interface{})
+				m[path[j]] = cm
+				m = cm
+				continue
+			}
+			cm, ok := child.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("alias %s conflicts with the field %s", strings.Join(path, "."), strings.Join(path[:j+1], "."))
+			}
+			m = cm
+		}
+		if _, ok := m[path[len(path)-1]]; ok {
+			return fmt.Errorf("alias %s conflicts with the field %s", strings.Join(path, "."), strings.Join(path, "."))
+		}
+		m[path[len(path)-1]] = pp.nested[i+1]
+	}
+	for _, k := range keys {
+		row.AppendAlias(k, roots[k])
+	}
+	return nil
+}
+
+// applyOrder records the output field order following the SELECT fields. The fields of the row which are not
+// selected explicitly are expanded at the position of the wildcard in sorted order.
+func (pp *ProjectOp) applyOrder(row xsql.RawRow) {
+	or, ok := row.(xsql.OrderedRow)
+	if !ok {
+		return
+	}
+	all := row.ToMap()
+	order := make([]string, 0, len(all))
+	seen := make(map[string]bool, len(all))
+	wildcardPos := -1
+	for _, f := range pp.Fields {
+		if f.Invisible {
+			continue
+		}
+		if isWildcardField(f) {
+			if wildcardPos < 0 {
+				wildcardPos = len(order)
+			}
+			continue
+		}
+		name := f.AName
+		if f.NestedAlias {
+			name = pp.aliasPath(f.AName)[0]
+		} else if name == "" {
+			name = f.Name
+		}
+		if _, ok := all[name]; ok && !seen[name] {
+			seen[name] = true
+			order = append(order, name)
+		}
+	}
+	// The rest includes the wildcard fields and the fields produced by multiple columns functions
+	rest := make([]string, 0, len(all)-len(order))
+	for k := range all {
+		if !seen[k] {
+			rest = append(rest, k)
+		}
+	}
+	sort.Strings(rest)
+	if wildcardPos < 0 {
+		wildcardPos = len(order)
+	}
+	order = append(order[:wildcardPos], append(rest, order[wildcardPos:]...)...)
+	or.SetFieldOrder(order)
+}
+
+func isWildcardField(f ast.Field) bool {
+	switch ft := f.Expr.(type) {
+	case *ast.Wildcard:
+		return true
+	case *ast.FieldRef:
+		return ft.Name == "*"
+	}
+	return false
+}
+
+// ValidateKeyCase checks the KeyCase and whether the output field names collide after the case conversion.
+// The collisions of the fields expanded by the wildcard can only be found at runtime.
+func (pp *ProjectOp) ValidateKeyCase() error {
+	switch pp.KeyCase {
+	case "", KeyCaseNone:
+		return nil
+	case KeyCaseLower, KeyCaseUpper:
+	default:
+		return fmt.Errorf("invalid key case %q, expect none, lower or upper", pp.KeyCase)
+	}
+	names := pp.OutputSchema
+	if len(names) == 0 {
+		for _, f := range pp.Fields {
+			if f.Invisible || isWildcardField(f) {
+				continue
+			}
+			name := f.AName
+			if f.NestedAlias {
+				name = pp.aliasPath(name)[0]
+			} else if name == "" {
+				name = f.Name
+			}
+			names = append(names, name)
+		}
+	}
+	folded := make(map[string]string, len(names))
+	for _, name := range names {
+		k := pp.foldKey(name)
+		if other, ok := folded[k]; ok && other != name {
+			return fmt.Errorf("output fields %s and %s collide after converting to %s case", other, name, pp.KeyCase)
+		}
+		folded[k] = name
+	}
+	return nil
+}
+
+func (pp *ProjectOp) foldKey(key string) string {
+	switch pp.KeyCase {
+	case KeyCaseLower:
+		return strings.ToLower(key)
+	case KeyCaseUpper:
+		return strings.ToUpper(key)
+	default:
+		return key
+	}
+}
+
+// applyKeyCase converts the keys of the row by the KeyCase. If several keys are the same after the conversion,
+// the last one in the sorted key order wins.
+func (pp *ProjectOp) applyKeyCase(ctx api.StreamContext, row xsql.RawRow) {
+	all := row.ToMap()
+	keys := make([]string, 0, len(all))
+	changed := false
+	for k := range all {
+		keys = append(keys, k)
+		if pp.foldKey(k) != k {
+			changed = true
+		}
+	}
+	if !changed {
+		return
+	}
+	sort.Strings(keys)
+	folded := make(map[string]interface{}, len(all))
+	for _, k := range keys {
+		nk := pp.foldKey(k)
+		if _, ok := folded[nk]; ok {
+			ctx.GetLogger().Warnf("output key %s overwrites the value of %s after converting to %s case", k, nk, pp.KeyCase)
+		}
+		folded[nk] = all[k]
+	}
+	row.Pick(false, nil, nil, nil, false)
+	for k, v := range folded {
+		row.Set(k, v)
+	}
+	if or, ok := row.(xsql.OrderedRow); ok && len(or.FieldOrder()) > 0 {
+		order := make([]string, 0, len(or.FieldOrder()))
+		seen := make(map[string]bool, len(or.FieldOrder()))
+		for _, k := range or.FieldOrder() {
+			nk := pp.foldKey(k)
+			if !seen[nk] {
+				seen[nk] = true
+				order = append(order, nk)
+			}
+		}
+		or.SetFieldOrder(order)
+	}
+}
+
+// applySchema restricts the row to the fields in the output schema and records the order
+func (pp *ProjectOp) applySchema(row xsql.RawRow) {
+	if pp.schemaCols == nil {
+		pp.schemaCols = make([][]string, len(pp.OutputSchema))
+		for i, name := range pp.OutputSchema {
+			pp.schemaCols[i] = []string{name, ""}
+		}
+	}
+	row.Pick(false, pp.schemaCols, nil, nil, false)
+	for _, name := range pp.OutputSchema {
+		if _, ok := row.Value(name, ""); !ok {
+			row.Set(name, nil)
+		}
+	}
+	if or, ok := row.(xsql.OrderedRow); ok {
+		or.SetFieldOrder(pp.OutputSchema)
+	}
+}
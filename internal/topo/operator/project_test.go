@@ -17,12 +17,15 @@ package operator
 import (
 	"errors"
 	"fmt"
+	"math"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/lf-edge/ekuiper/v2/internal/binder/function"
 	"github.com/lf-edge/ekuiper/v2/internal/conf"
 	"github.com/lf-edge/ekuiper/v2/internal/topo/context"
 	"github.com/lf-edge/ekuiper/v2/internal/xsql"
@@ -74,6 +77,7 @@ func parseStmtWithSlice(p *ProjectOp, fields ast.Fields, hasIndex bool) {
 			case *ast.Wildcard:
 				p.AllWildcard = true
 				p.ExceptNames = ft.Except
+				p.ExceptMatching = ft.ExceptMatching
 				for _, replace := range ft.Replace {
 					p.AliasFields = append(p.AliasFields, replace)
 				}
@@ -326,6 +330,37 @@ func TestProjectPlan_Apply1(t *testing.T) {
 				"ab": "hello1",
 			}},
 		},
+		{
+			sql: `SELECT a[-1]->b AS ab, a[-2] AS a2 FROM test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"a": []interface{}{
+						map[string]interface{}{"b": "hello1"},
+						map[string]interface{}{"b": "hello2"},
+					},
+				},
+			},
+			result: []map[string]interface{}{{
+				"ab": "hello2",
+				"a2": map[string]interface{}{"b": "hello1"},
+			}},
+		},
+		{
+			sql: `SELECT a[-1]->b AS ab FROM test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"a": []map[string]interface{}{
+						{"b": "hello1"},
+						{"b": "hello2"},
+					},
+				},
+			},
+			result: []map[string]interface{}{{
+				"ab": "hello2",
+			}},
+		},
 		// 16
 		{
 			sql: `SELECT a[2:4] AS ab FROM test`,
@@ -714,1122 +749,845 @@ func TestProjectPlan_Apply1(t *testing.T) {
 			},
 		},
 		{
-			sql: `SELECT a, a+b+c as sum invisible, b invisible FROM test`,
+			sql: `SELECT * REPLACE(a->b as d) from test`,
 			data: &xsql.Tuple{
 				Emitter: "test",
 				Message: xsql.Message{
-					"a": 1,
-					"b": 2,
-					"c": 3,
+					"a": map[string]interface{}{
+						"b": "test",
+					},
+					"b": "b",
+				},
+			},
+			result: []map[string]interface{}{
+				{
+					"a": map[string]interface{}{
+						"b": "test",
+					},
+					"b": "b",
+					"d": "test",
 				},
 			},
-			result: []map[string]interface{}{{
-				"a": 1,
-			}},
 		},
-	}
-	contextLogger := conf.Log.WithField("rule", "TestProjectPlan_Apply1")
-	ctx := context.WithValue(context.Background(), context.LoggerKey, contextLogger)
-	for i, tt := range tests {
-		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
-			stmt, err := xsql.NewParser(strings.NewReader(tt.sql)).Parse()
-			require.NoError(t, err)
-			pp := &ProjectOp{SendMeta: true, IsAggregate: xsql.WithAggFields(stmt)}
-			parseStmt(pp, stmt.Fields)
-			fv, afv := xsql.NewFunctionValuersForOp(nil)
-			opResult := pp.Apply(ctx, tt.data, fv, afv)
-			result, err := parseResult(opResult, pp.IsAggregate)
-			require.NoError(t, err)
-			require.Equal(t, tt.result, result)
-		})
-	}
-}
-
-func TestProjectPlan_MultiInput(t *testing.T) {
-	tests := []struct {
-		sql    string
-		data   interface{}
-		result []map[string]interface{}
-	}{ // 0
 		{
-			sql: "SELECT * FROM tbl WHERE abc*2+3 > 12 AND abc < 20",
+			sql: `SELECT * EXCEPT(c) REPLACE(c * 2 as c) from test`,
 			data: &xsql.Tuple{
-				Emitter: "tbl",
+				Emitter: "test",
 				Message: xsql.Message{
-					"abc": int64(6),
+					"b": "b",
+					"c": 1,
+				},
+			},
+			result: []map[string]interface{}{
+				{
+					"b": "b",
+					"c": int64(2),
+				},
+			},
+		},
+		{
+			sql: `SELECT * REPLACE(CASE WHEN a IS NULL THEN 0 ELSE a END as a) from test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"a": nil,
+					"b": "b",
+				},
+			},
+			result: []map[string]interface{}{
+				{
+					"a": int64(0),
+					"b": "b",
+				},
+			},
+		},
+		{
+			sql: `SELECT * EXCEPT(c) REPLACE(CASE WHEN a IS NULL THEN 0 ELSE a * 2 END as a) from test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"a": 5,
+					"b": "b",
+					"c": "c",
+				},
+			},
+			result: []map[string]interface{}{
+				{
+					"a": int64(10),
+					"b": "b",
+				},
+			},
+		},
+		{
+			sql: `SELECT a[0:5:2] AS s1, a[::3] AS s2, a[1::4] AS s3, a[:] AS s4, a[-3::2] AS s5, b[0:4:2] AS s6 FROM test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"a": []interface{}{0, 1, 2, 3, 4, 5, 6, 7},
+					"b": []float64{0.5, 1.5, 2.5, 3.5},
 				},
 			},
 			result: []map[string]interface{}{{
-				"abc": int64(6),
+				"s1": []interface{}{0, 2, 4},
+				"s2": []interface{}{0, 3, 6},
+				"s3": []interface{}{1, 5},
+				"s4": []interface{}{0, 1, 2, 3, 4, 5, 6, 7},
+				"s5": []interface{}{5, 7},
+				"s6": []float64{0.5, 2.5},
 			}},
 		},
-		// 1
 		{
-			sql: "SELECT abc FROM tbl WHERE abc*2+3 > 12 OR def = \"hello\"",
+			sql: `SELECT a, a+b+c as sum invisible, b invisible FROM test`,
 			data: &xsql.Tuple{
-				Emitter: "tbl",
+				Emitter: "test",
 				Message: xsql.Message{
-					"abc": int64(34),
-					"def": "hello",
+					"a": 1,
+					"b": 2,
+					"c": 3,
 				},
 			},
 			result: []map[string]interface{}{{
-				"abc": int64(34),
+				"a": 1,
 			}},
 		},
-		// 2
 		{
-			sql: "SELECT id1 FROM src1 WHERE f1 = \"v1\" GROUP BY TUMBLINGWINDOW(ss, 10)",
-			data: &xsql.WindowTuples{
-				Content: []xsql.Row{
-					&xsql.Tuple{
-						Emitter: "src1",
-						Message: xsql.Message{"id1": 1, "f1": "v1"},
-					}, &xsql.Tuple{
-						Emitter: "src1",
-						Message: xsql.Message{"id1": 2, "f1": "v2"},
-					}, &xsql.Tuple{
-						Emitter: "src1",
-						Message: xsql.Message{"id1": 3, "f1": "v1"},
-					},
+			sql: `SELECT a, NULL as placeholder FROM test WHERE b IS NULL`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"a": 1,
 				},
 			},
 			result: []map[string]interface{}{{
-				"id1": 1,
-			}, {
-				"id1": 2,
-			}, {
-				"id1": 3,
+				"a": 1,
 			}},
 		},
-		// 3
 		{
-			sql: "SELECT id1 FROM src1 WHERE f1 = \"v1\" GROUP BY TUMBLINGWINDOW(ss, 10)",
-			data: &xsql.WindowTuples{
-				Content: []xsql.Row{
-					&xsql.Tuple{
-						Emitter: "src1",
-						Message: xsql.Message{"id1": 1, "f1": "v1"},
-					}, &xsql.Tuple{
-						Emitter: "src1",
-						Message: xsql.Message{"id2": 2, "f1": "v2"},
-					}, &xsql.Tuple{
-						Emitter: "src1",
-						Message: xsql.Message{"id1": 3, "f1": "v1"},
+			sql: `SELECT a[*]->temp * 2 AS doubled, a[*]->temp + a[*]->hum AS total FROM test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"a": []interface{}{
+						map[string]interface{}{"temp": 10, "hum": 1},
+						map[string]interface{}{"hum": 2},
+						map[string]interface{}{"temp": 2.5, "hum": 3},
+						nil,
 					},
 				},
 			},
-
 			result: []map[string]interface{}{{
-				"id1": 1,
-			}, {}, {
-				"id1": 3,
+				"doubled": []interface{}{int64(20), nil, float64(5), nil},
+				"total":   []interface{}{int64(11), nil, float64(5.5), nil},
 			}},
 		},
-		// 4
 		{
-			sql: "SELECT * FROM src1 WHERE f1 = \"v1\" GROUP BY TUMBLINGWINDOW(ss, 10)",
-			data: &xsql.WindowTuples{
-				Content: []xsql.Row{
-					&xsql.Tuple{
-						Emitter: "src1",
-						Message: xsql.Message{"id1": 1, "f1": "v1"},
-					}, &xsql.Tuple{
-						Emitter: "src1",
-						Message: xsql.Message{"id1": 2, "f1": "v2"},
-					}, &xsql.Tuple{
-						Emitter: "src1",
-						Message: xsql.Message{"id1": 3, "f1": "v1"},
-					},
+			sql: `SELECT a * 1e3 AS x, a + 0xFF AS y, 1.5e-3 * 2 AS z, 0x10 AS h FROM test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"a": 2,
 				},
 			},
 			result: []map[string]interface{}{{
-				"id1": 1,
-				"f1":  "v1",
-			}, {
-				"id1": 2,
-				"f1":  "v2",
-			}, {
-				"id1": 3,
-				"f1":  "v1",
+				"x": float64(2000),
+				"y": int64(257),
+				"z": 0.003,
+				"h": int64(16),
 			}},
 		},
-		// 5
 		{
-			sql: "SELECT * FROM src1 WHERE f1 = \"v1\" GROUP BY TUMBLINGWINDOW(ss, 10)",
-			data: &xsql.WindowTuples{
-				Content: []xsql.Row{
-					&xsql.Tuple{
-						Emitter: "src1",
-						Message: xsql.Message{"id1": 1, "f1": "v1"},
-					}, &xsql.Tuple{
-						Emitter: "src1",
-						Message: xsql.Message{"id2": 2, "f2": "v2"},
-					}, &xsql.Tuple{
-						Emitter: "src1",
-						Message: xsql.Message{"id1": 3, "f1": "v1"},
+			sql: `SELECT head(a) AS h, last(a) AS l, tail(a) AS t, head(tail(a))->b AS second FROM test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"a": []map[string]interface{}{
+						{"b": "hello1"},
+						{"b": "hello2"},
+						{"b": "hello3"},
 					},
 				},
 			},
 			result: []map[string]interface{}{{
-				"id1": 1,
-				"f1":  "v1",
-			}, {
-				"id2": 2,
-				"f2":  "v2",
-			}, {
-				"id1": 3,
-				"f1":  "v1",
+				"h": map[string]interface{}{"b": "hello1"},
+				"l": map[string]interface{}{"b": "hello3"},
+				"t": []map[string]interface{}{
+					{"b": "hello2"},
+					{"b": "hello3"},
+				},
+				"second": "hello2",
 			}},
 		},
-		// 6
 		{
-			sql: "SELECT src1.* FROM src1 WHERE f1 = \"v1\" GROUP BY TUMBLINGWINDOW(ss, 10)",
-			data: &xsql.WindowTuples{
-				Content: []xsql.Row{
-					&xsql.Tuple{
-						Emitter: "src1",
-						Message: xsql.Message{"id1": 1, "f1": "v1"},
-					}, &xsql.Tuple{
-						Emitter: "src1",
-						Message: xsql.Message{"id1": 2, "f1": "v2"},
-					}, &xsql.Tuple{
-						Emitter: "src1",
-						Message: xsql.Message{"id1": 3, "f1": "v1"},
-					},
+			sql: `SELECT head(a) AS h, last(a) AS l, tail(a) AS t FROM test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"a": []interface{}{},
 				},
 			},
 			result: []map[string]interface{}{{
-				"id1": 1,
-				"f1":  "v1",
-			}, {
-				"id1": 2,
-				"f1":  "v2",
-			}, {
-				"id1": 3,
-				"f1":  "v1",
+				"t": []interface{}{},
 			}},
 		},
-		// 7
 		{
-			sql: "SELECT id1 FROM src1 left join src2 on src1.id1 = src2.id2 WHERE src1.f1 = \"v1\" GROUP BY TUMBLINGWINDOW(ss, 10)",
-			data: &xsql.JoinTuples{
-				Content: []*xsql.JoinTuple{
-					{
-						Tuples: []xsql.Row{
-							&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id1": 1, "f1": "v1"}},
-							&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id2": 2, "f2": "w2"}},
-						},
-					},
-					{
-						Tuples: []xsql.Row{
-							&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id1": 2, "f1": "v2"}},
-							&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id2": 4, "f2": "w3"}},
-						},
-					},
-					{
-						Tuples: []xsql.Row{
-							&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id1": 3, "f1": "v1"}},
-						},
-					},
+			sql: `SELECT coalesce_meta(device, device) AS d, coalesce_meta(a, "id") AS a, coalesce_meta(b, other) AS b FROM test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"a": "val_a",
+				},
+				Metadata: xsql.Metadata{
+					"id":     45,
+					"device": "devicea",
 				},
 			},
 			result: []map[string]interface{}{{
-				"id1": 1,
-			}, {
-				"id1": 2,
-			}, {
-				"id1": 3,
+				"d": "devicea",
+				"a": "val_a",
+				"__meta": xsql.Metadata{
+					"id":     45,
+					"device": "devicea",
+				},
 			}},
 		},
-		// 8
 		{
-			sql: "SELECT id1 FROM src1 left join src2 on src1.id1 = src2.id2 WHERE src1.f1 = \"v1\" GROUP BY TUMBLINGWINDOW(ss, 10)",
-			data: &xsql.JoinTuples{
-				Content: []*xsql.JoinTuple{
-					{
-						Tuples: []xsql.Row{
-							&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id1": 1, "f1": "v1"}},
-							&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id2": 2, "f2": "w2"}},
-						},
-					},
-					{
-						Tuples: []xsql.Row{
-							&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id1": 2, "f1": "v2"}},
-							&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id2": 4, "f2": "w3"}},
-						},
-					},
-					{
-						Tuples: []xsql.Row{
-							&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id2": 3, "f1": "v1"}},
-						},
-					},
+			sql: `SELECT sanitize_float(ln(a), 0) AS l, sanitize_float(b) AS n, sanitize_float(b, -1) AS d, sanitize_float(c, 0) AS c FROM test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"a": 0,
+					"b": math.NaN(),
+					"c": 1.5,
 				},
 			},
 			result: []map[string]interface{}{{
-				"id1": 1,
-			}, {
-				"id1": 2,
-			}, {}},
+				"l": int64(0),
+				"d": int64(-1),
+				"c": 1.5,
+			}},
 		},
-		// 9
 		{
-			sql: "SELECT abc FROM tbl group by abc",
-			data: &xsql.GroupedTuplesSet{
-				Groups: []*xsql.GroupedTuples{
-					{
-						Content: []xsql.Row{
-							&xsql.Tuple{
-								Emitter: "tbl",
-								Message: xsql.Message{
-									"abc": int64(6),
-									"def": "hello",
-								},
-							},
+			sql: `SELECT a?->c?->d AS f1, a?->b?->d AS f2, a?->c?->e AS f3, x?->y?->z AS f4 FROM test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"a": map[string]interface{}{
+						"b": "hello",
+						"c": map[string]interface{}{
+							"e": 35.2,
 						},
 					},
 				},
 			},
 			result: []map[string]interface{}{{
-				"abc": int64(6),
+				"f3": 35.2,
 			}},
 		},
-		// 10
 		{
-			sql: "SELECT abc FROM tbl group by abc",
-			data: &xsql.GroupedTuplesSet{
-				Groups: []*xsql.GroupedTuples{
-					{
-						Content: []xsql.Row{
-							&xsql.Tuple{
-								Emitter: "tbl",
-								Message: xsql.Message{
-									"def": "hello",
-								},
-							},
-						},
+			sql: `SELECT a?->c?->d AS f1, a?->b AS f2 FROM test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"a": map[string]interface{}{
+						"b": "hello",
 					},
 				},
 			},
+			result: []map[string]interface{}{{
+				"f2": "hello",
+			}},
+		},
+		{
+			sql: `SELECT a?->b AS f1, a?->b?->c AS f2, b?->c AS f3 FROM test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"a": "hello",
+					"b": []interface{}{1, 2},
+				},
+			},
 			result: []map[string]interface{}{{}},
 		},
-		// 11
 		{
-			sql: "SELECT id1 FROM src1 GROUP BY TUMBLINGWINDOW(ss, 10), f1",
-			data: &xsql.GroupedTuplesSet{
-				Groups: []*xsql.GroupedTuples{
-					{
-						Content: []xsql.Row{
-							&xsql.Tuple{
-								Emitter: "src1",
-								Message: xsql.Message{"id1": 1, "f1": "v1"},
-							},
-							&xsql.Tuple{
-								Emitter: "src1",
-								Message: xsql.Message{"id1": 3, "f1": "v1"},
-							},
-						},
-					},
-					{
-						Content: []xsql.Row{
-							&xsql.Tuple{
-								Emitter: "src1",
-								Message: xsql.Message{"id1": 2, "f1": "v2"},
-							},
+			sql: `SELECT a?->c?->d DEFAULT 0 AS f1, a?->b DEFAULT "none" AS f2, x?->y?->z DEFAULT a?->b AS f3, (a?->c?->e DEFAULT 0) + 1 AS f4, a?->c?->x DEFAULT a?->c?->e + 1 AS f5 FROM test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"a": map[string]interface{}{
+						"b": "hello",
+						"c": map[string]interface{}{
+							"e": int64(35),
 						},
 					},
 				},
 			},
 			result: []map[string]interface{}{{
-				"id1": 1,
-			}, {
-				"id1": 2,
+				"f1": int64(0),
+				"f2": "hello",
+				"f3": "hello",
+				"f4": int64(36),
+				"f5": int64(36),
 			}},
 		},
-		// 12
 		{
-			sql: "SELECT id1 FROM src1 GROUP BY TUMBLINGWINDOW(ss, 10), f1",
-			data: &xsql.GroupedTuplesSet{
-				Groups: []*xsql.GroupedTuples{
-					{
-						Content: []xsql.Row{
-							&xsql.Tuple{
-								Emitter: "src1",
-								Message: xsql.Message{"id1": 1, "f1": "v1"},
-							},
-							&xsql.Tuple{
-								Emitter: "src1",
-								Message: xsql.Message{"id1": 3, "f1": "v1"},
-							},
-						},
-					},
-					{
-						Content: []xsql.Row{
-							&xsql.Tuple{
-								Emitter: "src1",
-								Message: xsql.Message{"id2": 2, "f1": "v2"},
-							},
-						},
-					},
+			// The default is only evaluated when the navigation fails
+			sql: `SELECT a?->b DEFAULT b->c AS f1 FROM test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"a": map[string]interface{}{"b": "hello"},
+					"b": []interface{}{1, 2},
 				},
 			},
 			result: []map[string]interface{}{{
-				"id1": 1,
-			}, {}},
+				"f1": "hello",
+			}},
 		},
-		// 13
 		{
-			sql: "SELECT src2.id2 FROM src1 left join src2 on src1.id1 = src2.id2 GROUP BY src2.f2, TUMBLINGWINDOW(ss, 10)",
-			data: &xsql.GroupedTuplesSet{
-				Groups: []*xsql.GroupedTuples{
-					{
-						Content: []xsql.Row{
-							&xsql.JoinTuple{
-								Tuples: []xsql.Row{
-									&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id1": 1, "f1": "v1"}},
-									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id2": 2, "f2": "w2"}},
-								},
-							},
-						},
-					},
-					{
-						Content: []xsql.Row{
-							&xsql.JoinTuple{
-								Tuples: []xsql.Row{
-									&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id1": 2, "f1": "v2"}},
-									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id2": 4, "f2": "w3"}},
-								},
-							},
-						},
-					},
-					{
-						Content: []xsql.Row{
-							&xsql.JoinTuple{
-								Tuples: []xsql.Row{
-									&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id1": 3, "f1": "v1"}},
-								},
-							},
-						},
-					},
+			sql: `SELECT * EXCEPT MATCHING('sensor_.*') from test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"id":          1,
+					"sensor_temp": 20.5,
+					"sensor_hum":  60,
+					"my_sensor_x": "x",
 				},
 			},
 			result: []map[string]interface{}{{
-				"id2": 2,
-			}, {
-				"id2": 4,
-			}, {}},
+				"id":          1,
+				"my_sensor_x": "x",
+			}},
 		},
-		// 14
 		{
-			sql: "SELECT src1.*, f2 FROM src1 left join src2 GROUP BY TUMBLINGWINDOW(ss, 10)",
-			data: &xsql.JoinTuples{
-				Content: []*xsql.JoinTuple{
-					{
-						Tuples: []xsql.Row{
-							&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id1": 1, "f1": "v1"}},
-							&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id2": 2, "f2": "w2"}},
-						},
-					},
-					{
-						Tuples: []xsql.Row{
-							&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id1": 2, "f1": "v2"}},
-							&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id2": 4, "f2": "w3"}},
-						},
-					},
-					{
-						Tuples: []xsql.Row{
-							&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id1": 3, "f1": "v1"}},
-						},
-					},
+			sql: `SELECT * EXCEPT MATCHING('sensor_.*') from test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"id":   1,
+					"temp": 20.5,
 				},
 			},
 			result: []map[string]interface{}{{
-				"id1": 1,
-				"f1":  "v1",
-				"f2":  "w2",
-			}, {
-				"id1": 2,
-				"f1":  "v2",
-				"f2":  "w3",
-			}, {
-				"id1": 3,
-				"f1":  "v1",
+				"id":   1,
+				"temp": 20.5,
 			}},
 		},
-		// 15
 		{
-			sql: "SELECT * FROM src1 left join src2 GROUP BY TUMBLINGWINDOW(ss, 10)",
-			data: &xsql.JoinTuples{
-				Content: []*xsql.JoinTuple{
-					{
-						Tuples: []xsql.Row{
-							&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id": 1, "f1": "v1"}},
-							&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 2, "f2": "w2"}},
-						},
-					},
-					{
-						Tuples: []xsql.Row{
-							&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id": 2, "f1": "v2"}},
-							&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 4, "f2": "w3"}},
-						},
-					},
-					{
-						Tuples: []xsql.Row{
-							&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id": 3, "f1": "v1"}},
-						},
-					},
+			sql: `SELECT * EXCEPT(id) EXCEPT MATCHING('sensor_.*', "raw") REPLACE(sensor_temp * 10 AS sensor_temp, raw AS id) from test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"id":          1,
+					"raw":         "r",
+					"sensor_temp": 2,
+					"sensor_hum":  60,
+					"name":        "n",
 				},
 			},
 			result: []map[string]interface{}{{
-				"id": 1,
-				"f1": "v1",
-				"f2": "w2",
-			}, {
-				"id": 2,
-				"f1": "v2",
-				"f2": "w3",
-			}, {
-				"id": 3,
-				"f1": "v1",
+				"id":          "r",
+				"sensor_temp": int64(20),
+				"name":        "n",
 			}},
 		},
-		// 16
 		{
-			sql: "SELECT src1.* FROM src1 GROUP BY TUMBLINGWINDOW(ss, 10), f1",
-			data: &xsql.GroupedTuplesSet{
-				Groups: []*xsql.GroupedTuples{
-					{
-						Content: []xsql.Row{
-							&xsql.Tuple{
-								Emitter: "src1",
-								Message: xsql.Message{"id1": 1, "f1": "v1"},
-							},
-							&xsql.Tuple{
-								Emitter: "src1",
-								Message: xsql.Message{"id1": 3, "f1": "v1"},
-							},
-						},
-					},
-					{
-						Content: []xsql.Row{
-							&xsql.Tuple{
-								Emitter: "src1",
-								Message: xsql.Message{"id1": 2, "f1": "v2"},
-							},
-						},
-					},
+			sql: "SELECT id, a AS payload.temp, b AS payload.hum, a * 2 AS payload.stat.double, b AS `payload.raw` FROM test",
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"id": 1,
+					"a":  20,
+					"b":  60,
 				},
 			},
 			result: []map[string]interface{}{{
-				"id1": 1,
-				"f1":  "v1",
-			}, {
-				"id1": 2,
-				"f1":  "v2",
+				"id": 1,
+				"payload": map[string]interface{}{
+					"temp": 20,
+					"hum":  60,
+					"stat": map[string]interface{}{
+						"double": int64(40),
+					},
+				},
+				"payload.raw": 60,
 			}},
 		},
-		// 17
 		{
-			sql: "SELECT src2.id2, src1.* FROM src1 left join src2 on src1.id1 = src2.id2 GROUP BY src2.f2, TUMBLINGWINDOW(ss, 10)",
-			data: &xsql.GroupedTuplesSet{
-				Groups: []*xsql.GroupedTuples{
-					{
-						Content: []xsql.Row{
-							&xsql.JoinTuple{
-								Tuples: []xsql.Row{
-									&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id1": 1, "f1": "v1"}},
-									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id2": 2, "f2": "w2"}},
-								},
-							},
-						},
-					},
-					{
-						Content: []xsql.Row{
-							&xsql.JoinTuple{
-								Tuples: []xsql.Row{
-									&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id1": 2, "f1": "v2"}},
-									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id2": 4, "f2": "w3"}},
-								},
-							},
-						},
-					},
-					{
-						Content: []xsql.Row{
-							&xsql.JoinTuple{
-								Tuples: []xsql.Row{
-									&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id1": 3, "f1": "v1"}},
-								},
-							},
-						},
-					},
+			sql: "SELECT a AS payload.temp, c AS payload.hum FROM test",
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"a": 20,
 				},
 			},
 			result: []map[string]interface{}{{
-				"id2": 2,
-				"id1": 1,
-				"f1":  "v1",
-			}, {
-				"id2": 4,
-				"id1": 2,
-				"f1":  "v2",
-			}, {
-				"id1": 3,
-				"f1":  "v1",
+				"payload": map[string]interface{}{
+					"temp": 20,
+				},
 			}},
 		},
-		// 18
 		{
-			sql: "SELECT src2.id2, src1.* FROM src1 left join src2 on src1.id1 = src2.id2 GROUP BY src2.f2, TUMBLINGWINDOW(ss, 10)",
-			data: &xsql.GroupedTuplesSet{
-				Groups: []*xsql.GroupedTuples{
-					{
-						Content: []xsql.Row{
-							&xsql.JoinTuple{
-								Tuples: []xsql.Row{
-									&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id1": 1, "f1": "v1"}},
-									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id2": 2, "f2": "w2"}},
-								},
-							},
-						},
-					},
-					{
-						Content: []xsql.Row{
-							&xsql.JoinTuple{
-								Tuples: []xsql.Row{
-									&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id1": 2, "f1": "v2"}},
-									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id2": 4, "f2": "w3"}},
-								},
-							},
-						},
-					},
-					{
-						Content: []xsql.Row{
-							&xsql.JoinTuple{
-								Tuples: []xsql.Row{
-									&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id1": 3, "f1": "v1"}},
-								},
-							},
-						},
+			sql: `SELECT to_json(a) AS j, parse_json(s) AS p, to_json(f1), parse_json(to_json(a))->b AS b FROM test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"a": map[string]interface{}{
+						"b": "test",
+						"c": []interface{}{1, 2},
 					},
+					"s":  `[1,"x",{"y":true}]`,
+					"f1": -12,
 				},
 			},
 			result: []map[string]interface{}{{
-				"id2": 2,
-				"id1": 1,
-				"f1":  "v1",
-			}, {
-				"id2": 4,
-				"id1": 2,
-				"f1":  "v2",
-			}, {
-				"id1": 3,
-				"f1":  "v1",
+				"j":       `{"b":"test","c":[1,2]}`,
+				"p":       []interface{}{float64(1), "x", map[string]interface{}{"y": true}},
+				"to_json": "-12",
+				"b":       "test",
 			}},
 		},
-		// 19
 		{
-			sql: `SELECT * EXCEPT(a, b) from test WHERE f1 = "v1" GROUP BY TUMBLINGWINDOW(ss, 10)`,
-			data: &xsql.WindowTuples{
-				Content: []xsql.Row{
-					&xsql.Tuple{
-						Emitter: "src1",
-						Message: xsql.Message{"id1": 1, "f1": "v1", "a": map[string]interface{}{"b": "test"}, "b": "b", "c": "c"},
-					}, &xsql.Tuple{
-						Emitter: "src1",
-						Message: xsql.Message{"id1": 2, "f1": "v2", "a": map[string]interface{}{"b": "test"}, "b": "b", "c": "c"},
-					}, &xsql.Tuple{
-						Emitter: "src1",
-						Message: xsql.Message{"id1": 3, "f1": "v1", "a": map[string]interface{}{"b": "test"}, "b": "b", "c": "c"},
+			sql: `SELECT row() AS r, CASE WHEN row()->status = "ok" THEN a->b ELSE 0 END AS v, to_json(row()) AS j FROM test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"status": "ok",
+					"a": map[string]interface{}{
+						"b": 3,
+						"c": []interface{}{1, 2},
 					},
 				},
 			},
 			result: []map[string]interface{}{{
-				"id1": 1, "c": "c", "f1": "v1",
-			}, {
-				"id1": 2, "c": "c", "f1": "v2",
-			}, {
-				"id1": 3, "c": "c", "f1": "v1",
+				"r": map[string]interface{}{
+					"status": "ok",
+					"a": map[string]interface{}{
+						"b": 3,
+						"c": []interface{}{1, 2},
+					},
+				},
+				"v": 3,
+				"j": `{"a":{"b":3,"c":[1,2]},"status":"ok"}`,
 			}},
 		},
-		// 20
 		{
-			sql: `SELECT * REPLACE(a->b as a) from test WHERE f1 = "v1" GROUP BY TUMBLINGWINDOW(ss, 10)`,
-			data: &xsql.WindowTuples{
-				Content: []xsql.Row{
-					&xsql.Tuple{
-						Emitter: "src1",
-						Message: xsql.Message{"id1": 1, "f1": "v1", "a": map[string]interface{}{"b": "test"}, "b": "b", "c": "c"},
-					}, &xsql.Tuple{
-						Emitter: "src1",
-						Message: xsql.Message{"id1": 2, "f1": "v2", "a": map[string]interface{}{"b": "test"}, "b": "b", "c": "c"},
-					}, &xsql.Tuple{
-						Emitter: "src1",
-						Message: xsql.Message{"id1": 3, "f1": "v1", "a": map[string]interface{}{"b": "test"}, "b": "b", "c": "c"},
+			sql: `SELECT merge_by_key(updates, "id") AS merged FROM test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"updates": []interface{}{
+						map[string]interface{}{"id": "d1", "temp": 20.5},
+						map[string]interface{}{"id": "d2", "temp": 18.0},
+						map[string]interface{}{"id": "d1", "hum": 60.0},
 					},
 				},
 			},
 			result: []map[string]interface{}{{
-				"id1": 1, "c": "c", "a": "test", "b": "b", "f1": "v1",
-			}, {
-				"id1": 2, "c": "c", "a": "test", "b": "b", "f1": "v2",
-			}, {
-				"id1": 3, "c": "c", "a": "test", "b": "b", "f1": "v1",
+				"merged": []interface{}{
+					map[string]interface{}{"id": "d1", "temp": 20.5, "hum": 60.0},
+					map[string]interface{}{"id": "d2", "temp": 18.0},
+				},
 			}},
 		},
-		// 21
 		{
-			sql: `SELECT * EXCEPT(c) REPLACE("test" as b, a->b as a) from test WHERE f1 = "v1" GROUP BY TUMBLINGWINDOW(ss, 10)`,
-			data: &xsql.WindowTuples{
-				Content: []xsql.Row{
-					&xsql.Tuple{
-						Emitter: "src1",
-						Message: xsql.Message{"id1": 1, "f1": "v1", "a": map[string]interface{}{"b": "test"}, "b": "test"},
-					}, &xsql.Tuple{
-						Emitter: "src1",
-						Message: xsql.Message{"id1": 2, "f1": "v2", "a": map[string]interface{}{"b": "test"}, "b": "test"},
-					}, &xsql.Tuple{
-						Emitter: "src1",
-						Message: xsql.Message{"id1": 3, "f1": "v1", "a": map[string]interface{}{"b": "test"}, "b": "test"},
-					},
+			sql: `SELECT typeof(a) AS ta, typeof(b) AS tb, typeof(c) AS tc, typeof(d) AS td, typeof(e) AS te, typeof(f) AS tf FROM test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"a": "v",
+					"b": 1.5,
+					"c": int64(2),
+					"d": map[string]interface{}{"x": 1},
+					"e": []interface{}{1},
 				},
 			},
 			result: []map[string]interface{}{{
-				"id1": 1, "a": "test", "b": "test", "f1": "v1",
-			}, {
-				"id1": 2, "a": "test", "b": "test", "f1": "v2",
-			}, {
-				"id1": 3, "a": "test", "b": "test", "f1": "v1",
+				"ta": "string",
+				"tb": "float64",
+				"tc": "int64",
+				"td": "map",
+				"te": "array",
+				"tf": "null",
 			}},
 		},
-	}
-
-	fmt.Printf("The test bucket size is %d.\n\n", len(tests))
-	contextLogger := conf.Log.WithField("rule", "TestProjectPlan_MultiInput")
-	ctx := context.WithValue(context.Background(), context.LoggerKey, contextLogger)
-	for i, tt := range tests {
-		stmt, _ := xsql.NewParser(strings.NewReader(tt.sql)).Parse()
-
-		pp := &ProjectOp{SendMeta: true, IsAggregate: xsql.WithAggFields(stmt)}
-		parseStmt(pp, stmt.Fields)
-		fv, afv := xsql.NewFunctionValuersForOp(nil)
-		opResult := pp.Apply(ctx, tt.data, fv, afv)
-		result, err := parseResult(opResult, pp.IsAggregate)
-		if err != nil {
-			t.Errorf("parse result error： %s", err)
-			continue
-		}
-		if !reflect.DeepEqual(tt.result, result) {
-			t.Errorf("%d. %q\n\nresult mismatch:\n\nexp=%#v\n\ngot=%#v\n\n", i, tt.sql, tt.result, result)
-		}
-	}
-}
-
-func TestProjectPlan_Funcs(t *testing.T) {
-	tests := []struct {
-		sql    string
-		data   interface{}
-		result []map[string]interface{}
-	}{
-		// 0
 		{
-			sql: "SELECT round(a) as r FROM test",
+			sql: `SELECT split_value(a, ",") AS arr, split_value(a, ",")[1] AS second, split_value(a, ",")[1:] AS rest, string_to_array(e, ",") AS empty FROM test`,
 			data: &xsql.Tuple{
 				Emitter: "test",
 				Message: xsql.Message{
-					"a": 47.5,
+					"a": "x,y,z",
+					"e": "",
 				},
 			},
 			result: []map[string]interface{}{{
-				"r": float64(48),
+				"arr":    []interface{}{"x", "y", "z"},
+				"second": "y",
+				"rest":   []interface{}{"y", "z"},
+				"empty":  []interface{}{},
+			}},
+		},
+	}
+	contextLogger := conf.Log.WithField("rule", "TestProjectPlan_Apply1")
+	ctx := context.WithValue(context.Background(), context.LoggerKey, contextLogger)
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			stmt, err := xsql.NewParser(strings.NewReader(tt.sql)).Parse()
+			require.NoError(t, err)
+			pp := &ProjectOp{SendMeta: true, IsAggregate: xsql.WithAggFields(stmt)}
+			parseStmt(pp, stmt.Fields)
+			fv, afv := xsql.NewFunctionValuersForOp(nil)
+			opResult := pp.Apply(ctx, tt.data, fv, afv)
+			result, err := parseResult(opResult, pp.IsAggregate)
+			require.NoError(t, err)
+			require.Equal(t, tt.result, result)
+		})
+	}
+}
+
+func TestProjectPlan_MultiInput(t *testing.T) {
+	tests := []struct {
+		sql    string
+		data   interface{}
+		result []map[string]interface{}
+	}{ // 0
+		{
+			sql: "SELECT * FROM tbl WHERE abc*2+3 > 12 AND abc < 20",
+			data: &xsql.Tuple{
+				Emitter: "tbl",
+				Message: xsql.Message{
+					"abc": int64(6),
+				},
+			},
+			result: []map[string]interface{}{{
+				"abc": int64(6),
 			}},
 		},
 		// 1
 		{
-			sql: "SELECT round(a) as r FROM test GROUP BY TumblingWindow(ss, 10)",
+			sql: "SELECT abc FROM tbl WHERE abc*2+3 > 12 OR def = \"hello\"",
+			data: &xsql.Tuple{
+				Emitter: "tbl",
+				Message: xsql.Message{
+					"abc": int64(34),
+					"def": "hello",
+				},
+			},
+			result: []map[string]interface{}{{
+				"abc": int64(34),
+			}},
+		},
+		// 2
+		{
+			sql: "SELECT id1 FROM src1 WHERE f1 = \"v1\" GROUP BY TUMBLINGWINDOW(ss, 10)",
 			data: &xsql.WindowTuples{
 				Content: []xsql.Row{
 					&xsql.Tuple{
 						Emitter: "src1",
-						Message: xsql.Message{"a": 53.1},
+						Message: xsql.Message{"id1": 1, "f1": "v1"},
 					}, &xsql.Tuple{
 						Emitter: "src1",
-						Message: xsql.Message{"a": 27.4},
+						Message: xsql.Message{"id1": 2, "f1": "v2"},
 					}, &xsql.Tuple{
 						Emitter: "src1",
-						Message: xsql.Message{"a": 123123.7},
+						Message: xsql.Message{"id1": 3, "f1": "v1"},
 					},
 				},
 			},
-
 			result: []map[string]interface{}{{
-				"r": float64(53),
+				"id1": 1,
 			}, {
-				"r": float64(27),
+				"id1": 2,
 			}, {
-				"r": float64(123124),
+				"id1": 3,
 			}},
 		},
-		// 2
+		// 3
 		{
-			sql: "SELECT round(a) as r FROM test GROUP BY TumblingWindow(ss, 10)",
+			sql: "SELECT id1 FROM src1 WHERE f1 = \"v1\" GROUP BY TUMBLINGWINDOW(ss, 10)",
 			data: &xsql.WindowTuples{
 				Content: []xsql.Row{
 					&xsql.Tuple{
 						Emitter: "src1",
-						Message: xsql.Message{"a": 53.1},
+						Message: xsql.Message{"id1": 1, "f1": "v1"},
 					}, &xsql.Tuple{
 						Emitter: "src1",
-						Message: xsql.Message{"a": 27.4},
+						Message: xsql.Message{"id2": 2, "f1": "v2"},
 					}, &xsql.Tuple{
 						Emitter: "src1",
-						Message: xsql.Message{"a": 123123.7},
+						Message: xsql.Message{"id1": 3, "f1": "v1"},
 					},
 				},
 			},
 
 			result: []map[string]interface{}{{
-				"r": float64(53),
+				"id1": 1,
+			}, {}, {
+				"id1": 3,
+			}},
+		},
+		// 4
+		{
+			sql: "SELECT * FROM src1 WHERE f1 = \"v1\" GROUP BY TUMBLINGWINDOW(ss, 10)",
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{
+						Emitter: "src1",
+						Message: xsql.Message{"id1": 1, "f1": "v1"},
+					}, &xsql.Tuple{
+						Emitter: "src1",
+						Message: xsql.Message{"id1": 2, "f1": "v2"},
+					}, &xsql.Tuple{
+						Emitter: "src1",
+						Message: xsql.Message{"id1": 3, "f1": "v1"},
+					},
+				},
+			},
+			result: []map[string]interface{}{{
+				"id1": 1,
+				"f1":  "v1",
 			}, {
-				"r": float64(27),
+				"id1": 2,
+				"f1":  "v2",
 			}, {
-				"r": float64(123124),
+				"id1": 3,
+				"f1":  "v1",
 			}},
 		},
-		// 3
+		// 5
 		{
-			sql: "SELECT round(a) as r FROM test Inner Join test1 on test.id = test1.id GROUP BY TumblingWindow(ss, 10)",
-			data: &xsql.JoinTuples{
-				Content: []*xsql.JoinTuple{
-					{
-						Tuples: []xsql.Row{
-							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1, "a": 65.55}},
-							&xsql.Tuple{Emitter: "test1", Message: xsql.Message{"id": 1, "b": 12}},
-						},
-					},
-					{
-						Tuples: []xsql.Row{
-							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 2, "a": 73.499}},
-							&xsql.Tuple{Emitter: "test1", Message: xsql.Message{"id": 2, "b": 34}},
-						},
+			sql: "SELECT * FROM src1 WHERE f1 = \"v1\" GROUP BY TUMBLINGWINDOW(ss, 10)",
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{
+						Emitter: "src1",
+						Message: xsql.Message{"id1": 1, "f1": "v1"},
+					}, &xsql.Tuple{
+						Emitter: "src1",
+						Message: xsql.Message{"id2": 2, "f2": "v2"},
+					}, &xsql.Tuple{
+						Emitter: "src1",
+						Message: xsql.Message{"id1": 3, "f1": "v1"},
 					},
-					{
-						Tuples: []xsql.Row{
-							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 3, "a": 88.88}},
-							&xsql.Tuple{Emitter: "test1", Message: xsql.Message{"id": 3, "b": 6}},
-						},
+				},
+			},
+			result: []map[string]interface{}{{
+				"id1": 1,
+				"f1":  "v1",
+			}, {
+				"id2": 2,
+				"f2":  "v2",
+			}, {
+				"id1": 3,
+				"f1":  "v1",
+			}},
+		},
+		// 6
+		{
+			sql: "SELECT src1.* FROM src1 WHERE f1 = \"v1\" GROUP BY TUMBLINGWINDOW(ss, 10)",
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{
+						Emitter: "src1",
+						Message: xsql.Message{"id1": 1, "f1": "v1"},
+					}, &xsql.Tuple{
+						Emitter: "src1",
+						Message: xsql.Message{"id1": 2, "f1": "v2"},
+					}, &xsql.Tuple{
+						Emitter: "src1",
+						Message: xsql.Message{"id1": 3, "f1": "v1"},
 					},
 				},
 			},
 			result: []map[string]interface{}{{
-				"r": float64(66),
+				"id1": 1,
+				"f1":  "v1",
 			}, {
-				"r": float64(73),
+				"id1": 2,
+				"f1":  "v2",
 			}, {
-				"r": float64(89),
+				"id1": 3,
+				"f1":  "v1",
 			}},
 		},
-		// 4
+		// 7
 		{
-			sql: "SELECT CONCAT(test.id, test.a, test1.b) as concat FROM test Inner Join test1 on test.id = test1.id GROUP BY TumblingWindow(ss, 10)",
+			sql: "SELECT id1 FROM src1 left join src2 on src1.id1 = src2.id2 WHERE src1.f1 = \"v1\" GROUP BY TUMBLINGWINDOW(ss, 10)",
 			data: &xsql.JoinTuples{
 				Content: []*xsql.JoinTuple{
 					{
 						Tuples: []xsql.Row{
-							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1, "a": 65.55}},
-							&xsql.Tuple{Emitter: "test1", Message: xsql.Message{"id": 1, "b": 12}},
+							&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id1": 1, "f1": "v1"}},
+							&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id2": 2, "f2": "w2"}},
 						},
 					},
 					{
 						Tuples: []xsql.Row{
-							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 2, "a": 73.499}},
-							&xsql.Tuple{Emitter: "test1", Message: xsql.Message{"id": 2, "b": 34}},
+							&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id1": 2, "f1": "v2"}},
+							&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id2": 4, "f2": "w3"}},
 						},
 					},
 					{
 						Tuples: []xsql.Row{
-							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 3, "a": 88.88}},
-							&xsql.Tuple{Emitter: "test1", Message: xsql.Message{"id": 3, "b": 6}},
+							&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id1": 3, "f1": "v1"}},
 						},
 					},
 				},
 			},
 			result: []map[string]interface{}{{
-				"concat": "165.5512",
+				"id1": 1,
 			}, {
-				"concat": "273.49934",
+				"id1": 2,
 			}, {
-				"concat": "388.886",
-			}},
-		},
-		// 5
-		{
-			sql: "SELECT count(a) as r FROM test",
-			data: &xsql.Tuple{
-				Emitter: "test",
-				Message: xsql.Message{
-					"a": 47.5,
-				},
-			},
-			result: []map[string]interface{}{{
-				"r": 1,
+				"id1": 3,
 			}},
 		},
-		// 6
+		// 8
 		{
-			sql: "SELECT meta(test.device) as d FROM test Inner Join test1 on test.id = test1.id GROUP BY TumblingWindow(ss, 10)",
+			sql: "SELECT id1 FROM src1 left join src2 on src1.id1 = src2.id2 WHERE src1.f1 = \"v1\" GROUP BY TUMBLINGWINDOW(ss, 10)",
 			data: &xsql.JoinTuples{
 				Content: []*xsql.JoinTuple{
 					{
 						Tuples: []xsql.Row{
-							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1, "a": 65.55}, Metadata: xsql.Metadata{"device": "devicea"}},
-							&xsql.Tuple{Emitter: "test1", Message: xsql.Message{"id": 1, "b": 12}},
+							&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id1": 1, "f1": "v1"}},
+							&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id2": 2, "f2": "w2"}},
 						},
 					},
 					{
 						Tuples: []xsql.Row{
-							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 2, "a": 73.499}, Metadata: xsql.Metadata{"device": "deviceb"}},
-							&xsql.Tuple{Emitter: "test1", Message: xsql.Message{"id": 2, "b": 34}},
+							&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id1": 2, "f1": "v2"}},
+							&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id2": 4, "f2": "w3"}},
 						},
 					},
 					{
 						Tuples: []xsql.Row{
-							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 3, "a": 88.88}, Metadata: xsql.Metadata{"device": "devicec"}},
-							&xsql.Tuple{Emitter: "test1", Message: xsql.Message{"id": 3, "b": 6}},
+							&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id2": 3, "f1": "v1"}},
 						},
 					},
 				},
 			},
 			result: []map[string]interface{}{{
-				"d": "devicea",
-			}, {
-				"d": "deviceb",
+				"id1": 1,
 			}, {
-				"d": "devicec",
-			}},
+				"id1": 2,
+			}, {}},
 		},
+		// 9
 		{
-			sql: "SELECT count(a) invisible, a FROM test",
-			data: &xsql.Tuple{
-				Emitter: "test",
-				Message: xsql.Message{
-					"a": 47.5,
+			sql: "SELECT abc FROM tbl group by abc",
+			data: &xsql.GroupedTuplesSet{
+				Groups: []*xsql.GroupedTuples{
+					{
+						Content: []xsql.Row{
+							&xsql.Tuple{
+								Emitter: "tbl",
+								Message: xsql.Message{
+									"abc": int64(6),
+									"def": "hello",
+								},
+							},
+						},
+					},
 				},
 			},
 			result: []map[string]interface{}{{
-				"a": 47.5,
+				"abc": int64(6),
 			}},
 		},
-	}
-
-	fmt.Printf("The test bucket size is %d.\n\n", len(tests))
-	contextLogger := conf.Log.WithField("rule", "TestProjectPlan_Funcs")
-	ctx := context.WithValue(context.Background(), context.LoggerKey, contextLogger)
-	for i, tt := range tests {
-		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
-			stmt, err := xsql.NewParser(strings.NewReader(tt.sql)).Parse()
-			require.NoError(t, err)
-			pp := &ProjectOp{SendMeta: true, IsAggregate: xsql.WithAggFields(stmt)}
-			parseStmt(pp, stmt.Fields)
-			fv, afv := xsql.NewFunctionValuersForOp(nil)
-			opResult := pp.Apply(ctx, tt.data, fv, afv)
-			result, err := parseResult(opResult, pp.IsAggregate)
-			require.NoError(t, err)
-			require.Equal(t, tt.result, result)
-		})
-	}
-}
-
-func TestProjectPlan_AggFuncs(t *testing.T) {
-	tests := []struct {
-		sql    string
-		data   interface{}
-		result []map[string]interface{}
-	}{
-		{ // 0
-			sql: "SELECT count(*) as c, round(a) as r, window_start() as ws, window_end() as we FROM test Inner Join test1 on test.id = test1.id GROUP BY TumblingWindow(ss, 10), test1.color",
+		// 10
+		{
+			sql: "SELECT abc FROM tbl group by abc",
 			data: &xsql.GroupedTuplesSet{
 				Groups: []*xsql.GroupedTuples{
 					{
 						Content: []xsql.Row{
-							&xsql.JoinTuple{
-								Tuples: []xsql.Row{
-									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1, "a": 122.33, "c": 2, "r": 122}},
-									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 1, "color": "w2"}},
-								},
-							},
-							&xsql.JoinTuple{
-								Tuples: []xsql.Row{
-									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 5, "a": 177.51}},
-									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 5, "color": "w2"}},
-								},
-							},
-						},
-						WindowRange: xsql.NewWindowRange(1541152486013, 1541152487013, 1541152487013),
-					},
-					{
-						Content: []xsql.Row{
-							&xsql.JoinTuple{
-								Tuples: []xsql.Row{
-									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 2, "a": 89.03, "c": 2, "r": 89}},
-									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 2, "color": "w1"}},
-								},
-							},
-							&xsql.JoinTuple{
-								Tuples: []xsql.Row{
-									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 4, "a": 14.6}},
-									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 4, "color": "w1"}},
+							&xsql.Tuple{
+								Emitter: "tbl",
+								Message: xsql.Message{
+									"def": "hello",
 								},
 							},
 						},
-						WindowRange: xsql.NewWindowRange(1541152486013, 1541152487013, 1541152487013),
 					},
 				},
 			},
-			result: []map[string]interface{}{{
-				"c":  2,
-				"r":  float64(122),
-				"ws": int64(1541152486013),
-				"we": int64(1541152487013),
-			}, {
-				"c":  2,
-				"r":  float64(89),
-				"ws": int64(1541152486013),
-				"we": int64(1541152487013),
-			}},
+			result: []map[string]interface{}{{}},
 		},
-		// 1
+		// 11
 		{
-			sql: "SELECT count(a) as c, avg(a) as a, sum(a) as s, min(a) as min, max(a) as max FROM test Inner Join test1 on test.id = test1.id GROUP BY TumblingWindow(ss, 10), test1.color",
+			sql: "SELECT id1 FROM src1 GROUP BY TUMBLINGWINDOW(ss, 10), f1",
 			data: &xsql.GroupedTuplesSet{
 				Groups: []*xsql.GroupedTuples{
 					{
 						Content: []xsql.Row{
-							&xsql.JoinTuple{
-								Tuples: []xsql.Row{
-									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1, "a": 122.33, "c": 2, "r": 122}},
-									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 1, "color": "w2"}},
-								},
+							&xsql.Tuple{
+								Emitter: "src1",
+								Message: xsql.Message{"id1": 1, "f1": "v1"},
 							},
-							&xsql.JoinTuple{
-								Tuples: []xsql.Row{
-									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 5}},
-									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 5, "color": "w2"}},
-								},
+							&xsql.Tuple{
+								Emitter: "src1",
+								Message: xsql.Message{"id1": 3, "f1": "v1"},
 							},
 						},
 					},
 					{
 						Content: []xsql.Row{
-							&xsql.JoinTuple{
-								Tuples: []xsql.Row{
-									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 2, "a": 89.03, "c": 2, "r": 89}},
-									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 2, "color": "w1"}},
-								},
-							},
-							&xsql.JoinTuple{
-								Tuples: []xsql.Row{
-									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 4, "a": 14.6}},
-									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 4, "color": "w1"}},
-								},
+							&xsql.Tuple{
+								Emitter: "src1",
+								Message: xsql.Message{"id1": 2, "f1": "v2"},
 							},
 						},
 					},
 				},
 			},
 			result: []map[string]interface{}{{
-				"c":   1,
-				"a":   122.33,
-				"s":   122.33,
-				"min": 122.33,
-				"max": 122.33,
+				"id1": 1,
 			}, {
-				"c":   2,
-				"s":   103.63,
-				"a":   51.815,
-				"min": 14.6,
-				"max": 89.03,
+				"id1": 2,
 			}},
 		},
-		// 2
+		// 12
 		{
-			sql: "SELECT avg(a) FROM test Inner Join test1 on test.id = test1.id GROUP BY TumblingWindow(ss, 10), test1.color",
+			sql: "SELECT id1 FROM src1 GROUP BY TUMBLINGWINDOW(ss, 10), f1",
 			data: &xsql.GroupedTuplesSet{
 				Groups: []*xsql.GroupedTuples{
 					{
 						Content: []xsql.Row{
-							&xsql.JoinTuple{
-								Tuples: []xsql.Row{
-									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1, "a": 122.33}},
-									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 1, "color": "w2"}},
-								},
-							},
-							&xsql.JoinTuple{
-								Tuples: []xsql.Row{
-									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1, "a": 68.54}},
-									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 1, "color": "w2"}},
-								},
-							},
-							&xsql.JoinTuple{
-								Tuples: []xsql.Row{
-									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 4, "a": 98.31}},
-									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 4, "color": "w2"}},
-								},
+							&xsql.Tuple{
+								Emitter: "src1",
+								Message: xsql.Message{"id1": 1, "f1": "v1"},
 							},
-							&xsql.JoinTuple{
-								Tuples: []xsql.Row{
-									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 5, "a": 177.54}},
-									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 5, "color": "w2"}},
-								},
+							&xsql.Tuple{
+								Emitter: "src1",
+								Message: xsql.Message{"id1": 3, "f1": "v1"},
 							},
 						},
 					},
 					{
 						Content: []xsql.Row{
-							&xsql.JoinTuple{
-								Tuples: []xsql.Row{
-									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 2, "a": 89.03}},
-									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 2, "color": "w1"}},
-								},
-							},
-							&xsql.JoinTuple{
-								Tuples: []xsql.Row{
-									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 4, "a": 14.6}},
-									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 4, "color": "w1"}},
-								},
+							&xsql.Tuple{
+								Emitter: "src1",
+								Message: xsql.Message{"id2": 2, "f1": "v2"},
 							},
 						},
 					},
 				},
 			},
 			result: []map[string]interface{}{{
-				"avg": 116.68,
-			}, {
-				"avg": 51.815,
-			}},
+				"id1": 1,
+			}, {}},
 		},
-		// 3
+		// 13
 		{
-			sql: "SELECT max(a) FROM test Inner Join test1 on test.id = test1.id GROUP BY TumblingWindow(ss, 10), test1.color",
+			sql: "SELECT src2.id2 FROM src1 left join src2 on src1.id1 = src2.id2 GROUP BY src2.f2, TUMBLINGWINDOW(ss, 10)",
 			data: &xsql.GroupedTuplesSet{
 				Groups: []*xsql.GroupedTuples{
 					{
 						Content: []xsql.Row{
 							&xsql.JoinTuple{
 								Tuples: []xsql.Row{
-									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1, "a": 122.33}},
-									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 1, "color": "w2"}},
-								},
-							},
-							&xsql.JoinTuple{
-								Tuples: []xsql.Row{
-									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1, "a": 68.55}},
-									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 1, "color": "w2"}},
-								},
-							},
-							&xsql.JoinTuple{
-								Tuples: []xsql.Row{
-									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 5, "a": 177.51}},
-									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 5, "color": "w2"}},
+									&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id1": 1, "f1": "v1"}},
+									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id2": 2, "f2": "w2"}},
 								},
 							},
 						},
@@ -1838,14 +1596,17 @@ func TestProjectPlan_AggFuncs(t *testing.T) {
 						Content: []xsql.Row{
 							&xsql.JoinTuple{
 								Tuples: []xsql.Row{
-									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 2, "a": 89.03}},
-									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 2, "color": "w1"}},
+									&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id1": 2, "f1": "v2"}},
+									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id2": 4, "f2": "w3"}},
 								},
 							},
+						},
+					},
+					{
+						Content: []xsql.Row{
 							&xsql.JoinTuple{
 								Tuples: []xsql.Row{
-									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 4, "a": 14.6}},
-									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 4, "color": "w1"}},
+									&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id1": 3, "f1": "v1"}},
 								},
 							},
 						},
@@ -1853,236 +1614,131 @@ func TestProjectPlan_AggFuncs(t *testing.T) {
 				},
 			},
 			result: []map[string]interface{}{{
-				"max": 177.51,
+				"id2": 2,
 			}, {
-				"max": 89.03,
-			}},
+				"id2": 4,
+			}, {}},
 		},
-		// 4
+		// 14
 		{
-			sql: "SELECT min(a), window_start(), window_end() FROM test Inner Join test1 on test.id = test1.id GROUP BY TumblingWindow(ss, 10)",
+			sql: "SELECT src1.*, f2 FROM src1 left join src2 GROUP BY TUMBLINGWINDOW(ss, 10)",
 			data: &xsql.JoinTuples{
 				Content: []*xsql.JoinTuple{
 					{
 						Tuples: []xsql.Row{
-							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1, "a": 122.33}},
-							&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 1, "color": "w2"}},
+							&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id1": 1, "f1": "v1"}},
+							&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id2": 2, "f2": "w2"}},
 						},
 					},
 					{
 						Tuples: []xsql.Row{
-							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1, "a": 68.55}},
-							&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 1, "color": "w2"}},
+							&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id1": 2, "f1": "v2"}},
+							&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id2": 4, "f2": "w3"}},
 						},
 					},
 					{
 						Tuples: []xsql.Row{
-							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 5, "a": 177.51}},
-							&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 5, "color": "w2"}},
+							&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id1": 3, "f1": "v1"}},
 						},
 					},
 				},
-				WindowRange: xsql.NewWindowRange(1541152486013, 1541152487013, 1541152487013),
 			},
-
 			result: []map[string]interface{}{{
-				"min":          68.55,
-				"window_start": int64(1541152486013),
-				"window_end":   int64(1541152487013),
+				"id1": 1,
+				"f1":  "v1",
+				"f2":  "w2",
+			}, {
+				"id1": 2,
+				"f1":  "v2",
+				"f2":  "w3",
+			}, {
+				"id1": 3,
+				"f1":  "v1",
 			}},
 		},
-		// 5
+		// 15
 		{
-			sql: "SELECT count(*) as all, count(a) as c, avg(a) as a, sum(a) as s, min(a) as min, max(a) as max FROM test Inner Join test1 on test.id = test1.id GROUP BY TumblingWindow(ss, 10)",
+			sql: "SELECT * FROM src1 left join src2 GROUP BY TUMBLINGWINDOW(ss, 10)",
 			data: &xsql.JoinTuples{
 				Content: []*xsql.JoinTuple{
 					{
 						Tuples: []xsql.Row{
-							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1}},
-							&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 1, "color": "w2"}},
+							&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id": 1, "f1": "v1"}},
+							&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 2, "f2": "w2"}},
 						},
 					},
 					{
 						Tuples: []xsql.Row{
-							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1, "a": 68.55}},
-							&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 1, "color": "w2"}},
+							&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id": 2, "f1": "v2"}},
+							&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 4, "f2": "w3"}},
 						},
 					},
 					{
 						Tuples: []xsql.Row{
-							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 5, "a": 177.51}},
-							&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 5, "color": "w2"}},
+							&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id": 3, "f1": "v1"}},
 						},
 					},
 				},
 			},
-
 			result: []map[string]interface{}{{
-				"all": 3,
-				"c":   2,
-				"a":   123.03,
-				"s":   246.06,
-				"min": 68.55,
-				"max": 177.51,
-			}},
-		},
-		// 6
-		{
-			sql: "SELECT sum(a), window_start() as ws, window_end() FROM test GROUP BY TumblingWindow(ss, 10)",
-			data: &xsql.WindowTuples{
-				Content: []xsql.Row{
-					&xsql.Tuple{
-						Emitter: "src1",
-						Message: xsql.Message{"a": 53},
-					}, &xsql.Tuple{
-						Emitter: "src1",
-						Message: xsql.Message{"a": 27},
-					}, &xsql.Tuple{
-						Emitter: "src1",
-						Message: xsql.Message{"a": 123123},
-					},
-				},
-				WindowRange: xsql.NewWindowRange(1541152486013, 1541152487013, 1541152487013),
-			},
-			result: []map[string]interface{}{{
-				"sum":        int64(123203),
-				"ws":         int64(1541152486013),
-				"window_end": int64(1541152487013),
-			}},
-		},
-		// 7
-		{
-			sql: "SELECT sum(a) as s FROM test GROUP BY TumblingWindow(ss, 10)",
-			data: &xsql.WindowTuples{
-				Content: []xsql.Row{
-					&xsql.Tuple{
-						Emitter: "src1",
-						Message: xsql.Message{"a": 53, "s": 123203},
-					}, &xsql.Tuple{
-						Emitter: "src1",
-						Message: xsql.Message{"a": 27},
-					}, &xsql.Tuple{
-						Emitter: "src1",
-						Message: xsql.Message{"a": 123123},
-					},
-				},
-			},
-
-			result: []map[string]interface{}{{
-				"s": int64(123203),
-			}},
-		},
-		// 8
-		{
-			sql: "SELECT sum(a) FROM test GROUP BY TumblingWindow(ss, 10)",
-			data: &xsql.WindowTuples{
-				Content: []xsql.Row{
-					&xsql.Tuple{
-						Emitter: "src1",
-						Message: xsql.Message{"a": 53},
-					}, &xsql.Tuple{
-						Emitter: "src1",
-						Message: xsql.Message{"a": 27},
-					}, &xsql.Tuple{
-						Emitter: "src1",
-						Message: xsql.Message{"a": 123123},
-					},
-				},
-			},
-			result: []map[string]interface{}{{
-				"sum": int64(123203),
-			}},
-		},
-		// 9
-		{
-			sql: "SELECT count(*) as all, count(a) as c, avg(a) as a, sum(a) as s, min(a) as min, max(a) as max  FROM test GROUP BY TumblingWindow(ss, 10)",
-			data: &xsql.WindowTuples{
-				Content: []xsql.Row{
-					&xsql.Tuple{
-						Emitter: "src1",
-						Message: xsql.Message{"a": 53},
-					}, &xsql.Tuple{
-						Emitter: "src1",
-						Message: xsql.Message{"a": 27},
-					}, &xsql.Tuple{
-						Emitter: "src1",
-						Message: xsql.Message{"s": 123123},
-					},
-				},
-			},
-			result: []map[string]interface{}{{
-				"all": 3,
-				"c":   2,
-				"a":   int64(40),
-				"s":   int64(80),
-				"min": int64(27),
-				"max": int64(53),
+				"id": 1,
+				"f1": "v1",
+				"f2": "w2",
+			}, {
+				"id": 2,
+				"f1": "v2",
+				"f2": "w3",
+			}, {
+				"id": 3,
+				"f1": "v1",
 			}},
 		},
-		// 10
+		// 16
 		{
-			sql: "SELECT count(*), meta(test1.device) FROM test Inner Join test1 on test.id = test1.id GROUP BY TumblingWindow(ss, 10), test1.color",
+			sql: "SELECT src1.* FROM src1 GROUP BY TUMBLINGWINDOW(ss, 10), f1",
 			data: &xsql.GroupedTuplesSet{
 				Groups: []*xsql.GroupedTuples{
 					{
 						Content: []xsql.Row{
-							&xsql.JoinTuple{
-								Tuples: []xsql.Row{
-									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1, "a": 122.33}},
-									&xsql.Tuple{Emitter: "test1", Message: xsql.Message{"id": 1, "color": "w2"}, Metadata: xsql.Metadata{"device": "devicea"}},
-								},
+							&xsql.Tuple{
+								Emitter: "src1",
+								Message: xsql.Message{"id1": 1, "f1": "v1"},
 							},
-							&xsql.JoinTuple{
-								Tuples: []xsql.Row{
-									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 5, "a": 177.51}},
-									&xsql.Tuple{Emitter: "test1", Message: xsql.Message{"id": 5, "color": "w2"}, Metadata: xsql.Metadata{"device": "deviceb"}},
-								},
+							&xsql.Tuple{
+								Emitter: "src1",
+								Message: xsql.Message{"id1": 3, "f1": "v1"},
 							},
 						},
 					},
 					{
 						Content: []xsql.Row{
-							&xsql.JoinTuple{
-								Tuples: []xsql.Row{
-									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 2, "a": 89.03}},
-									&xsql.Tuple{Emitter: "test1", Message: xsql.Message{"id": 2, "color": "w1"}, Metadata: xsql.Metadata{"device": "devicec"}},
-								},
-							},
-							&xsql.JoinTuple{
-								Tuples: []xsql.Row{
-									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 4, "a": 14.6}},
-									&xsql.Tuple{Emitter: "test1", Message: xsql.Message{"id": 4, "color": "w1"}, Metadata: xsql.Metadata{"device": "deviced"}},
-								},
+							&xsql.Tuple{
+								Emitter: "src1",
+								Message: xsql.Message{"id1": 2, "f1": "v2"},
 							},
 						},
 					},
 				},
 			},
 			result: []map[string]interface{}{{
-				"count": 2,
-				"meta":  "devicea",
+				"id1": 1,
+				"f1":  "v1",
 			}, {
-				"count": 2,
-				"meta":  "devicec",
+				"id1": 2,
+				"f1":  "v2",
 			}},
 		},
-		// 11
+		// 17
 		{
-			sql: "SELECT count(*) as c, meta(test1.device) as d FROM test Inner Join test1 on test.id = test1.id GROUP BY TumblingWindow(ss, 10), test1.color",
+			sql: "SELECT src2.id2, src1.* FROM src1 left join src2 on src1.id1 = src2.id2 GROUP BY src2.f2, TUMBLINGWINDOW(ss, 10)",
 			data: &xsql.GroupedTuplesSet{
 				Groups: []*xsql.GroupedTuples{
 					{
 						Content: []xsql.Row{
 							&xsql.JoinTuple{
 								Tuples: []xsql.Row{
-									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1, "a": 122.33, "c": 2, "d": "devicea"}},
-									&xsql.Tuple{Emitter: "test1", Message: xsql.Message{"id": 1, "color": "w2"}, Metadata: xsql.Metadata{"device": "devicea"}},
-								},
-							},
-							&xsql.JoinTuple{
-								Tuples: []xsql.Row{
-									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 5, "a": 177.51}},
-									&xsql.Tuple{Emitter: "test1", Message: xsql.Message{"id": 5, "color": "w2"}, Metadata: xsql.Metadata{"device": "deviceb"}},
+									&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id1": 1, "f1": "v1"}},
+									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id2": 2, "f2": "w2"}},
 								},
 							},
 						},
@@ -2091,14 +1747,17 @@ func TestProjectPlan_AggFuncs(t *testing.T) {
 						Content: []xsql.Row{
 							&xsql.JoinTuple{
 								Tuples: []xsql.Row{
-									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 2, "a": 89.03, "c": 2, "d": "devicec"}},
-									&xsql.Tuple{Emitter: "test1", Message: xsql.Message{"id": 2, "color": "w1"}, Metadata: xsql.Metadata{"device": "devicec"}},
+									&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id1": 2, "f1": "v2"}},
+									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id2": 4, "f2": "w3"}},
 								},
 							},
+						},
+					},
+					{
+						Content: []xsql.Row{
 							&xsql.JoinTuple{
 								Tuples: []xsql.Row{
-									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 4, "a": 14.6}},
-									&xsql.Tuple{Emitter: "test1", Message: xsql.Message{"id": 4, "color": "w1"}, Metadata: xsql.Metadata{"device": "deviced"}},
+									&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id1": 3, "f1": "v1"}},
 								},
 							},
 						},
@@ -2106,1188 +1765,4795 @@ func TestProjectPlan_AggFuncs(t *testing.T) {
 				},
 			},
 			result: []map[string]interface{}{{
-				"c": 2,
-				"d": "devicea",
+				"id2": 2,
+				"id1": 1,
+				"f1":  "v1",
 			}, {
-				"c": 2,
-				"d": "devicec",
+				"id2": 4,
+				"id1": 2,
+				"f1":  "v2",
+			}, {
+				"id1": 3,
+				"f1":  "v1",
 			}},
 		},
-		// 12
+		// 18
 		{
-			sql: "SELECT * FROM test Inner Join test1 on test.id = test1.id GROUP BY TumblingWindow(ss, 10), test1.color",
+			sql: "SELECT src2.id2, src1.* FROM src1 left join src2 on src1.id1 = src2.id2 GROUP BY src2.f2, TUMBLINGWINDOW(ss, 10)",
 			data: &xsql.GroupedTuplesSet{
 				Groups: []*xsql.GroupedTuples{
 					{
 						Content: []xsql.Row{
 							&xsql.JoinTuple{
 								Tuples: []xsql.Row{
-									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1, "a": 122.33, "c": 2, "r": 122}},
-									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 1, "color": "w2"}},
-								},
-							},
-							&xsql.JoinTuple{
-								Tuples: []xsql.Row{
-									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 5, "a": 177.51}},
-									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 5, "color": "w2"}},
+									&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id1": 1, "f1": "v1"}},
+									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id2": 2, "f2": "w2"}},
 								},
 							},
 						},
-						WindowRange: xsql.NewWindowRange(1541152486013, 1541152487013, 1541152487013),
 					},
 					{
 						Content: []xsql.Row{
 							&xsql.JoinTuple{
 								Tuples: []xsql.Row{
-									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 2, "a": 89.03, "c": 2, "r": 89}},
-									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 2, "color": "w1"}},
+									&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id1": 2, "f1": "v2"}},
+									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id2": 4, "f2": "w3"}},
 								},
 							},
+						},
+					},
+					{
+						Content: []xsql.Row{
 							&xsql.JoinTuple{
 								Tuples: []xsql.Row{
-									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 4, "a": 14.6}},
-									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 4, "color": "w1"}},
+									&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id1": 3, "f1": "v1"}},
 								},
 							},
 						},
-						WindowRange: xsql.NewWindowRange(1541152486013, 1541152487013, 1541152487013),
 					},
 				},
 			},
 			result: []map[string]interface{}{{
-				"a":     122.33,
-				"c":     2,
-				"color": "w2",
-				"id":    1,
-				"r":     122,
+				"id2": 2,
+				"id1": 1,
+				"f1":  "v1",
 			}, {
-				"a":     89.03,
-				"c":     2,
-				"color": "w1",
-				"id":    2,
-				"r":     89,
+				"id2": 4,
+				"id1": 2,
+				"f1":  "v2",
+			}, {
+				"id1": 3,
+				"f1":  "v1",
 			}},
 		},
-		// 13
+		// 19
 		{
-			sql: "SELECT collect(a) as r1 FROM test Inner Join test1 on test.id = test1.id GROUP BY TumblingWindow(ss, 10), test1.color",
-			data: &xsql.GroupedTuplesSet{
-				Groups: []*xsql.GroupedTuples{
-					{
-						Content: []xsql.Row{
-							&xsql.JoinTuple{
-								Tuples: []xsql.Row{
-									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1, "a": 122.33, "c": 2, "r": 122}},
-									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 1, "color": "w2"}},
-								},
-							},
-							&xsql.JoinTuple{
-								Tuples: []xsql.Row{
-									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 5, "a": 177.51}},
-									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 5, "color": "w2"}},
-								},
-							},
-						},
-					},
-					{
-						Content: []xsql.Row{
-							&xsql.JoinTuple{
-								Tuples: []xsql.Row{
-									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 2, "a": 89.03, "c": 2, "r": 89}},
-									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 2, "color": "w1"}},
-								},
-							},
-							&xsql.JoinTuple{
-								Tuples: []xsql.Row{
-									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 4, "a": 14.6}},
-									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 4, "color": "w1"}},
-								},
-							},
-						},
-					},
-				},
-			},
-			result: []map[string]interface{}{{
-				"r1": []interface{}{122.33, 177.51},
-			}, {"r1": []interface{}{89.03, 14.6}}},
-		},
-		// 14
-		{
-			sql: "SELECT collect(*)[1] as c1 FROM test GROUP BY TumblingWindow(ss, 10)",
+			sql: `SELECT * EXCEPT(a, b) from test WHERE f1 = "v1" GROUP BY TUMBLINGWINDOW(ss, 10)`,
 			data: &xsql.WindowTuples{
 				Content: []xsql.Row{
 					&xsql.Tuple{
 						Emitter: "src1",
-						Message: xsql.Message{"a": 53, "s": 123203},
+						Message: xsql.Message{"id1": 1, "f1": "v1", "a": map[string]interface{}{"b": "test"}, "b": "b", "c": "c"},
 					}, &xsql.Tuple{
 						Emitter: "src1",
-						Message: xsql.Message{"a": 27},
+						Message: xsql.Message{"id1": 2, "f1": "v2", "a": map[string]interface{}{"b": "test"}, "b": "b", "c": "c"},
 					}, &xsql.Tuple{
 						Emitter: "src1",
-						Message: xsql.Message{"a": 123123},
+						Message: xsql.Message{"id1": 3, "f1": "v1", "a": map[string]interface{}{"b": "test"}, "b": "b", "c": "c"},
 					},
 				},
-
-				WindowRange: xsql.NewWindowRange(1541152486013, 1541152487013, 1541152487013),
 			},
 			result: []map[string]interface{}{{
-				"c1": map[string]interface{}{
-					"a": 27,
-				},
+				"id1": 1, "c": "c", "f1": "v1",
+			}, {
+				"id1": 2, "c": "c", "f1": "v2",
+			}, {
+				"id1": 3, "c": "c", "f1": "v1",
 			}},
 		},
-		// 15
+		// 20
 		{
-			sql: "SELECT collect(*)[1]->a as c1 FROM test GROUP BY TumblingWindow(ss, 10)",
+			sql: `SELECT * REPLACE(a->b as a) from test WHERE f1 = "v1" GROUP BY TUMBLINGWINDOW(ss, 10)`,
 			data: &xsql.WindowTuples{
 				Content: []xsql.Row{
 					&xsql.Tuple{
 						Emitter: "src1",
-						Message: xsql.Message{"a": 53, "s": 123203},
+						Message: xsql.Message{"id1": 1, "f1": "v1", "a": map[string]interface{}{"b": "test"}, "b": "b", "c": "c"},
 					}, &xsql.Tuple{
 						Emitter: "src1",
-						Message: xsql.Message{"a": 27},
+						Message: xsql.Message{"id1": 2, "f1": "v2", "a": map[string]interface{}{"b": "test"}, "b": "b", "c": "c"},
 					}, &xsql.Tuple{
 						Emitter: "src1",
-						Message: xsql.Message{"a": 123123},
+						Message: xsql.Message{"id1": 3, "f1": "v1", "a": map[string]interface{}{"b": "test"}, "b": "b", "c": "c"},
 					},
 				},
 			},
-
 			result: []map[string]interface{}{{
-				"c1": 27,
+				"id1": 1, "c": "c", "a": "test", "b": "b", "f1": "v1",
+			}, {
+				"id1": 2, "c": "c", "a": "test", "b": "b", "f1": "v2",
+			}, {
+				"id1": 3, "c": "c", "a": "test", "b": "b", "f1": "v1",
 			}},
 		},
-		// 16
+		// 21
 		{
-			sql: "SELECT collect(*)[1]->sl[0] as c1 FROM test GROUP BY TumblingWindow(ss, 10)",
+			sql: `SELECT * EXCEPT(c) REPLACE("test" as b, a->b as a) from test WHERE f1 = "v1" GROUP BY TUMBLINGWINDOW(ss, 10)`,
 			data: &xsql.WindowTuples{
 				Content: []xsql.Row{
 					&xsql.Tuple{
 						Emitter: "src1",
-						Message: xsql.Message{"a": 53, "sl": []string{"hello", "world"}},
+						Message: xsql.Message{"id1": 1, "f1": "v1", "a": map[string]interface{}{"b": "test"}, "b": "test"},
 					}, &xsql.Tuple{
 						Emitter: "src1",
-						Message: xsql.Message{"a": 27, "sl": []string{"new", "horizon"}},
+						Message: xsql.Message{"id1": 2, "f1": "v2", "a": map[string]interface{}{"b": "test"}, "b": "test"},
 					}, &xsql.Tuple{
 						Emitter: "src1",
-						Message: xsql.Message{"a": 123123, "sl": []string{"south", "africa"}},
+						Message: xsql.Message{"id1": 3, "f1": "v1", "a": map[string]interface{}{"b": "test"}, "b": "test"},
 					},
 				},
 			},
-
 			result: []map[string]interface{}{{
-				"c1": "new",
+				"id1": 1, "a": "test", "b": "test", "f1": "v1",
+			}, {
+				"id1": 2, "a": "test", "b": "test", "f1": "v2",
+			}, {
+				"id1": 3, "a": "test", "b": "test", "f1": "v1",
 			}},
 		},
-		// 17
+		// 22
 		{
-			sql: "SELECT deduplicate(id, true) as r1 FROM test Inner Join test1 on test.id = test1.id GROUP BY TumblingWindow(ss, 10), test1.color",
-			data: &xsql.GroupedTuplesSet{
-				Groups: []*xsql.GroupedTuples{
+			sql: "SELECT coalesce_field(src1.id, src2.id) AS id, f1, f2 FROM src1 left join src2 on src1.id = src2.id GROUP BY TUMBLINGWINDOW(ss, 10)",
+			data: &xsql.JoinTuples{
+				Content: []*xsql.JoinTuple{
 					{
-						Content: []xsql.Row{
-							&xsql.JoinTuple{
-								Tuples: []xsql.Row{
-									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1, "a": 122.33, "c": 2, "r": 122}},
-									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 1, "color": "w2"}},
-								},
-							},
-							&xsql.JoinTuple{
-								Tuples: []xsql.Row{
-									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 5, "a": 177.51}},
-									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 5, "color": "w2"}},
-								},
-							},
+						Tuples: []xsql.Row{
+							&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id": 1, "f1": "v1"}},
+							&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 2, "f2": "w2"}},
 						},
 					},
 					{
-						Content: []xsql.Row{
-							&xsql.JoinTuple{
-								Tuples: []xsql.Row{
-									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 2, "a": 89.03, "c": 2, "r": 89}},
-									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 2, "color": "w1"}},
-								},
-							},
-							&xsql.JoinTuple{
-								Tuples: []xsql.Row{
-									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 4, "a": 14.6}},
-									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 4, "color": "w1"}},
-								},
-							},
+						Tuples: []xsql.Row{
+							&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"f1": "v2"}},
+							&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 4, "f2": "w3"}},
 						},
 					},
-				},
-			},
-			result: []map[string]interface{}{
-				{
-					"r1": []interface{}{
-						map[string]interface{}{"a": 122.33, "c": 2, "color": "w2", "id": 1, "r": 122},
-						map[string]interface{}{"a": 177.51, "color": "w2", "id": 5},
-					},
-				}, {
-					"r1": []interface{}{
-						map[string]interface{}{"a": 89.03, "c": 2, "color": "w1", "id": 2, "r": 89},
-						map[string]interface{}{"a": 14.6, "color": "w1", "id": 4},
+					{
+						Tuples: []xsql.Row{
+							&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"f1": "v3"}},
+						},
 					},
 				},
 			},
+			result: []map[string]interface{}{{
+				"id": 1, "f1": "v1", "f2": "w2",
+			}, {
+				"id": 4, "f1": "v2", "f2": "w3",
+			}, {
+				"f1": "v3",
+			}},
 		},
-		// 18
+		// 23
 		{
-			sql: "SELECT deduplicate(a, false)->a as c1 FROM test GROUP BY TumblingWindow(ss, 10)",
+			sql: "SELECT DISTINCT f1 FROM src1 GROUP BY TUMBLINGWINDOW(ss, 10)",
 			data: &xsql.WindowTuples{
 				Content: []xsql.Row{
 					&xsql.Tuple{
 						Emitter: "src1",
-						Message: xsql.Message{"a": 53, "s": 123203},
+						Message: xsql.Message{"id1": 1, "f1": "v1"},
 					}, &xsql.Tuple{
 						Emitter: "src1",
-						Message: xsql.Message{"a": 27},
+						Message: xsql.Message{"id1": 2, "f1": "v2"},
 					}, &xsql.Tuple{
 						Emitter: "src1",
-						Message: xsql.Message{"a": 123123},
+						Message: xsql.Message{"id1": 3, "f1": "v1"},
+					}, &xsql.Tuple{
+						Emitter: "src1",
+						Message: xsql.Message{"id1": 4},
+					}, &xsql.Tuple{
+						Emitter: "src1",
+						Message: xsql.Message{"id1": 5},
 					},
 				},
 			},
-
 			result: []map[string]interface{}{{
-				"c1": 123123,
-			}},
+				"f1": "v1",
+			}, {
+				"f1": "v2",
+			}, {}},
 		},
-		// 19
+		// 24
 		{
-			sql: "SELECT deduplicate(a, false) as c1 FROM test GROUP BY TumblingWindow(ss, 10)",
+			sql: "SELECT DISTINCT f1, id1 % 2 AS odd FROM src1 GROUP BY TUMBLINGWINDOW(ss, 10)",
 			data: &xsql.WindowTuples{
 				Content: []xsql.Row{
 					&xsql.Tuple{
 						Emitter: "src1",
-						Message: xsql.Message{"a": 53, "s": 123203},
+						Message: xsql.Message{"id1": 1, "f1": "v1"},
 					}, &xsql.Tuple{
 						Emitter: "src1",
-						Message: xsql.Message{"a": 27},
+						Message: xsql.Message{"id1": 2, "f1": "v1"},
 					}, &xsql.Tuple{
 						Emitter: "src1",
-						Message: xsql.Message{"a": 53},
-					},
-				},
-			},
-
-			result: []map[string]interface{}{{}},
-		},
-		// 20
-		{
-			sql: "SELECT deduplicate(a, false) as c1 FROM test GROUP BY TumblingWindow(ss, 10)",
-			data: &xsql.WindowTuples{
-				Content: []xsql.Row{
-					&xsql.Tuple{
-						Emitter: "src1",
-						Message: xsql.Message{"a": 53, "s": 123203},
+						Message: xsql.Message{"id1": 3, "f1": "v1"},
 					}, &xsql.Tuple{
 						Emitter: "src1",
-						Message: xsql.Message{"a": 27},
+						Message: xsql.Message{"id1": 4, "f1": "1"},
 					}, &xsql.Tuple{
 						Emitter: "src1",
-						Message: xsql.Message{"a": 53},
-					},
-				},
-			},
-
-			result: []map[string]interface{}{{}},
-		},
-		// 21  when got column after group by operation, return the first tuple's column
-		{
-			sql: "SELECT A.module, A.topic , max(A.value), B.topic as var2, max(B.value) as max2, C.topic as var3, max(C.value) as max3 FROM A FULL JOIN B on A.module=B.module FULL JOIN C on A.module=C.module GROUP BY A.module, TUMBLINGWINDOW(ss, 10)",
-			data: &xsql.GroupedTuplesSet{
-				Groups: []*xsql.GroupedTuples{
-					{
-						Content: []xsql.Row{
-							&xsql.JoinTuple{
-								Tuples: []xsql.Row{
-									&xsql.Tuple{Emitter: "B", Message: xsql.Message{"module": 1, "topic": "moduleB topic", "value": 1}},
-								},
-							},
-							&xsql.JoinTuple{
-								Tuples: []xsql.Row{
-									&xsql.Tuple{Emitter: "C", Message: xsql.Message{"module": 1, "topic": "moduleC topic", "value": 100}},
-								},
-							},
-						},
+						Message: xsql.Message{"id1": 6, "f1": 1},
 					},
 				},
 			},
 			result: []map[string]interface{}{{
-				"var2": "moduleB topic",
-				"max2": int64(1),
-				"max3": int64(100),
+				"f1": "v1", "odd": int64(1),
+			}, {
+				"f1": "v1", "odd": int64(0),
+			}, {
+				"f1": "1", "odd": int64(0),
+			}, {
+				"f1": 1, "odd": int64(0),
 			}},
 		},
-		// 22
+		// 25
 		{
-			sql: "SELECT count(* EXCEPT(a, b)) as all  FROM test Inner Join test1 on test.id = test1.id GROUP BY TumblingWindow(ss, 10)",
+			sql: "SELECT DISTINCT src1.f1, f2 FROM src1 left join src2 on src1.id1 = src2.id2 GROUP BY TUMBLINGWINDOW(ss, 10)",
 			data: &xsql.JoinTuples{
 				Content: []*xsql.JoinTuple{
 					{
 						Tuples: []xsql.Row{
-							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1, "a": "a", "b": "b"}},
-							&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 1, "color": "w2"}},
+							&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id1": 1, "f1": "v1"}},
+							&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id2": 1, "f2": "w1"}},
 						},
 					},
 					{
 						Tuples: []xsql.Row{
-							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1, "a": "a", "b": "b"}},
-							&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 1, "color": "w2"}},
+							&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id1": 2, "f1": "v1"}},
+							&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id2": 2, "f2": "w1"}},
 						},
 					},
 					{
 						Tuples: []xsql.Row{
-							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 5, "a": "a", "b": "b"}},
-							&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 5, "color": "w2"}},
+							&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id1": 3, "f1": "v1"}},
 						},
 					},
 				},
 			},
-
 			result: []map[string]interface{}{{
-				"all": 3,
+				"f1": "v1", "f2": "w1",
+			}, {
+				"f1": "v1",
 			}},
 		},
-		// 23
+		// 26
 		{
-			sql: "SELECT count(* EXCEPT(a, b)) invisible, a invisible, b as d invisible, b FROM test Inner Join test1 on test.id = test1.id GROUP BY TumblingWindow(ss, 10)",
-			data: &xsql.JoinTuples{
-				Content: []*xsql.JoinTuple{
+			sql: "SELECT DISTINCT count(*) AS c FROM src1 GROUP BY TUMBLINGWINDOW(ss, 10), f1",
+			data: &xsql.GroupedTuplesSet{
+				Groups: []*xsql.GroupedTuples{
 					{
-						Tuples: []xsql.Row{
-							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1, "a": "a", "b": "b"}},
-							&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 1, "color": "w2"}},
+						Content: []xsql.Row{
+							&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id1": 1, "f1": "v1"}},
+							&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id1": 2, "f1": "v1"}},
 						},
 					},
 					{
-						Tuples: []xsql.Row{
-							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1, "a": "a", "b": "b"}},
-							&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 1, "color": "w2"}},
+						Content: []xsql.Row{
+							&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id1": 3, "f1": "v2"}},
 						},
 					},
 					{
-						Tuples: []xsql.Row{
-							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 5, "a": "a", "b": "b"}},
-							&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 5, "color": "w2"}},
+						Content: []xsql.Row{
+							&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id1": 4, "f1": "v3"}},
+							&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id1": 5, "f1": "v3"}},
 						},
 					},
 				},
 			},
-
 			result: []map[string]interface{}{{
-				"b": "b",
+				"c": 2,
+			}, {
+				"c": 1,
 			}},
 		},
-	}
-	fmt.Printf("The test bucket size is %d.\n\n", len(tests))
-	contextLogger := conf.Log.WithField("rule", "TestProjectPlan_AggFuncs")
-	ctx := context.WithValue(context.Background(), context.LoggerKey, contextLogger)
-	for i, tt := range tests {
-		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
-			stmt, err := xsql.NewParser(strings.NewReader(tt.sql)).Parse()
-			require.NoError(t, err)
-			pp := &ProjectOp{SendMeta: true, IsAggregate: true}
-			parseStmt(pp, stmt.Fields)
-			fv, afv := xsql.NewFunctionValuersForOp(nil)
-			opResult := pp.Apply(ctx, tt.data, fv, afv)
-			result, err := parseResult(opResult, pp.IsAggregate)
-			require.NoError(t, err)
-			require.Equal(t, tt.result, result)
-		})
+		// 27
+		{
+			sql: "SELECT DISTINCT abc FROM tbl",
+			data: &xsql.Tuple{
+				Emitter: "tbl",
+				Message: xsql.Message{
+					"abc": int64(6),
+				},
+			},
+			result: []map[string]interface{}{{
+				"abc": int64(6),
+			}},
+		},
+	}
+
+	fmt.Printf("The test bucket size is %d.\n\n", len(tests))
+	contextLogger := conf.Log.WithField("rule", "TestProjectPlan_MultiInput")
+	ctx := context.WithValue(context.Background(), context.LoggerKey, contextLogger)
+	for i, tt := range tests {
+		stmt, _ := xsql.NewParser(strings.NewReader(tt.sql)).Parse()
+
+		pp := &ProjectOp{SendMeta: true, IsAggregate: xsql.WithAggFields(stmt), Distinct: stmt.Distinct}
+		parseStmt(pp, stmt.Fields)
+		fv, afv := xsql.NewFunctionValuersForOp(nil)
+		opResult := pp.Apply(ctx, tt.data, fv, afv)
+		result, err := parseResult(opResult, pp.IsAggregate)
+		if err != nil {
+			t.Errorf("parse result error： %s", err)
+			continue
+		}
+		if !reflect.DeepEqual(tt.result, result) {
+			t.Errorf("%d. %q\n\nresult mismatch:\n\nexp=%#v\n\ngot=%#v\n\n", i, tt.sql, tt.result, result)
+		}
 	}
 }
 
-func TestProjectPlanError(t *testing.T) {
+func TestProjectPlan_Funcs(t *testing.T) {
 	tests := []struct {
 		sql    string
 		data   interface{}
-		result interface{}
+		result []map[string]interface{}
 	}{
 		// 0
 		{
-			sql:    "SELECT a FROM test",
-			data:   errors.New("an error from upstream"),
-			result: errors.New("an error from upstream"),
-		},
-		// 1
-		{
-			sql: "SELECT a * 5 FROM test",
+			sql: "SELECT round(a) as r FROM test",
 			data: &xsql.Tuple{
 				Emitter: "test",
 				Message: xsql.Message{
-					"a": "val_a",
+					"a": 47.5,
 				},
 			},
-			result: errors.New("run Select error: expr: binaryExpr:{ $$default.a * 5 } meet error, err:invalid operation string(val_a) * int64(5)"),
+			result: []map[string]interface{}{{
+				"r": float64(48),
+			}},
 		},
-		// 2
+		// 1
 		{
-			sql: `SELECT a[0]->b AS ab FROM test`,
-			data: &xsql.Tuple{
-				Emitter: "test",
-				Message: xsql.Message{
-					"a": "common string",
+			sql: "SELECT round(a) as r FROM test GROUP BY TumblingWindow(ss, 10)",
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{
+						Emitter: "src1",
+						Message: xsql.Message{"a": 53.1},
+					}, &xsql.Tuple{
+						Emitter: "src1",
+						Message: xsql.Message{"a": 27.4},
+					}, &xsql.Tuple{
+						Emitter: "src1",
+						Message: xsql.Message{"a": 123123.7},
+					},
 				},
 			},
-			result: errors.New("run Select error: alias: ab expr: binaryExpr:{ binaryExpr:{ $$default.a[0] } -> jsonFieldName:b } meet error, err:invalid operation string(common string) [] *xsql.BracketEvalResult(&{0 0})"),
+
+			result: []map[string]interface{}{{
+				"r": float64(53),
+			}, {
+				"r": float64(27),
+			}, {
+				"r": float64(123124),
+			}},
 		},
-		// 3
+		// 2
 		{
-			sql: `SELECT round(a) as r FROM test`,
-			data: &xsql.Tuple{
-				Emitter: "test",
-				Message: xsql.Message{
-					"a": "common string",
+			sql: "SELECT round(a) as r FROM test GROUP BY TumblingWindow(ss, 10)",
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{
+						Emitter: "src1",
+						Message: xsql.Message{"a": 53.1},
+					}, &xsql.Tuple{
+						Emitter: "src1",
+						Message: xsql.Message{"a": 27.4},
+					}, &xsql.Tuple{
+						Emitter: "src1",
+						Message: xsql.Message{"a": 123123.7},
+					},
 				},
 			},
-			result: errors.New("run Select error: alias: r expr: Call:{ name:round, args:[$$default.a] } meet error, err:call func round error: cannot convert string(common string) to float64"),
+
+			result: []map[string]interface{}{{
+				"r": float64(53),
+			}, {
+				"r": float64(27),
+			}, {
+				"r": float64(123124),
+			}},
 		},
-		// 5
+		// 3
 		{
-			sql: "SELECT avg(a) as avg FROM test Inner Join test1 on test.id = test1.id GROUP BY TumblingWindow(ss, 10), test1.color",
-			data: &xsql.GroupedTuplesSet{
-				Groups: []*xsql.GroupedTuples{
+			sql: "SELECT round(a) as r FROM test Inner Join test1 on test.id = test1.id GROUP BY TumblingWindow(ss, 10)",
+			data: &xsql.JoinTuples{
+				Content: []*xsql.JoinTuple{
 					{
-						Content: []xsql.Row{
-							&xsql.JoinTuple{
-								Tuples: []xsql.Row{
-									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1, "a": 122.33}},
-									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 1, "color": "w2"}},
-								},
-							},
-							&xsql.JoinTuple{
-								Tuples: []xsql.Row{
-									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1, "a": 68.54}},
-									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 1, "color": "w2"}},
-								},
-							},
-							&xsql.JoinTuple{
-								Tuples: []xsql.Row{
-									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 4, "a": "dde"}},
-									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 4, "color": "w2"}},
-								},
-							},
-							&xsql.JoinTuple{
-								Tuples: []xsql.Row{
-									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 5, "a": 177.54}},
-									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 5, "color": "w2"}},
-								},
-							},
+						Tuples: []xsql.Row{
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1, "a": 65.55}},
+							&xsql.Tuple{Emitter: "test1", Message: xsql.Message{"id": 1, "b": 12}},
 						},
 					},
 					{
-						Content: []xsql.Row{
-							&xsql.JoinTuple{
-								Tuples: []xsql.Row{
-									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 2, "a": 89.03}},
-									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 2, "color": "w1"}},
-								},
-							},
-							&xsql.JoinTuple{
-								Tuples: []xsql.Row{
-									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 4, "a": 14.6}},
-									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 4, "color": "w1"}},
-								},
-							},
+						Tuples: []xsql.Row{
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 2, "a": 73.499}},
+							&xsql.Tuple{Emitter: "test1", Message: xsql.Message{"id": 2, "b": 34}},
+						},
+					},
+					{
+						Tuples: []xsql.Row{
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 3, "a": 88.88}},
+							&xsql.Tuple{Emitter: "test1", Message: xsql.Message{"id": 3, "b": 6}},
 						},
 					},
 				},
 			},
-			result: errors.New("run Select error: alias: avg expr: Call:{ name:avg, args:[$$default.a] } meet error, err:call func avg error: requires float64 but found string(dde)"),
+			result: []map[string]interface{}{{
+				"r": float64(66),
+			}, {
+				"r": float64(73),
+			}, {
+				"r": float64(89),
+			}},
 		},
-		// 6
+		// 4
 		{
-			sql: "SELECT sum(a) as sum FROM test GROUP BY TumblingWindow(ss, 10)",
-			data: &xsql.WindowTuples{
-				Content: []xsql.Row{
-					&xsql.Tuple{
-						Emitter: "src1",
-						Message: xsql.Message{"a": 53},
-					}, &xsql.Tuple{
-						Emitter: "src1",
-						Message: xsql.Message{"a": "ddd"},
-					}, &xsql.Tuple{
-						Emitter: "src1",
-						Message: xsql.Message{"a": 123123},
+			sql: "SELECT CONCAT(test.id, test.a, test1.b) as concat FROM test Inner Join test1 on test.id = test1.id GROUP BY TumblingWindow(ss, 10)",
+			data: &xsql.JoinTuples{
+				Content: []*xsql.JoinTuple{
+					{
+						Tuples: []xsql.Row{
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1, "a": 65.55}},
+							&xsql.Tuple{Emitter: "test1", Message: xsql.Message{"id": 1, "b": 12}},
+						},
+					},
+					{
+						Tuples: []xsql.Row{
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 2, "a": 73.499}},
+							&xsql.Tuple{Emitter: "test1", Message: xsql.Message{"id": 2, "b": 34}},
+						},
+					},
+					{
+						Tuples: []xsql.Row{
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 3, "a": 88.88}},
+							&xsql.Tuple{Emitter: "test1", Message: xsql.Message{"id": 3, "b": 6}},
+						},
 					},
 				},
 			},
-
-			result: errors.New("run Select error: alias: sum expr: Call:{ name:sum, args:[$$default.a] } meet error, err:call func sum error: requires int but found string(ddd)"),
+			result: []map[string]interface{}{{
+				"concat": "165.5512",
+			}, {
+				"concat": "273.49934",
+			}, {
+				"concat": "388.886",
+			}},
 		},
-		// 7
+		// 5
 		{
-			sql: `SELECT a[0]->b AS ab FROM test`,
+			sql: "SELECT count(a) as r FROM test",
 			data: &xsql.Tuple{
 				Emitter: "test",
 				Message: xsql.Message{
-					"a": []map[string]interface{}(nil),
+					"a": 47.5,
 				},
 			},
-			result: errors.New("run Select error: alias: ab expr: binaryExpr:{ binaryExpr:{ $$default.a[0] } -> jsonFieldName:b } meet error, err:out of index: 0 of 0"),
-		},
-	}
-	fmt.Printf("The test bucket size is %d.\n\n", len(tests))
-	contextLogger := conf.Log.WithField("rule", "TestProjectPlanError")
-	ctx := context.WithValue(context.Background(), context.LoggerKey, contextLogger)
-	for i, tt := range tests {
-		stmt, _ := xsql.NewParser(strings.NewReader(tt.sql)).Parse()
-		pp := &ProjectOp{SendMeta: true, IsAggregate: xsql.WithAggFields(stmt)}
-		parseStmt(pp, stmt.Fields)
-		fv, afv := xsql.NewFunctionValuersForOp(nil)
-		opResult := pp.Apply(ctx, tt.data, fv, afv)
-		if !reflect.DeepEqual(tt.result, opResult) {
-			t.Errorf("%d. %q\n\nresult mismatch:\n\nexp=%#v\n\ngot=%#v\n\n", i, tt.sql, tt.result, opResult)
-		}
-	}
-}
-
-func TestProjectPlan_SendNil(t *testing.T) {
-	tests := []struct {
-		sql    string
-		data   *xsql.Tuple
-		result []map[string]interface{}
-	}{
-		{ // 0
-			sql: "SELECT a FROM test",
-			data: &xsql.Tuple{
-				Emitter: "test",
-				Message: xsql.Message{
-					"a": "val_a",
-				},
-				Metadata: xsql.Metadata{
-					"id":    45,
-					"other": "mock",
-				},
-			},
-			result: []map[string]interface{}{{
-				"a": "val_a",
-				"__meta": xsql.Metadata{
-					"id":    45,
-					"other": "mock",
-				},
-			}},
-		},
-		{ // 1
-			sql: "SELECT b FROM test",
-			data: &xsql.Tuple{
-				Emitter: "test",
-				Message: xsql.Message{
-					"a": "val_a",
-				},
-			},
-			result: []map[string]interface{}{{"b": nil}},
-		},
-		// Schemaless may return a message without selecting column
-		{ // 3
-			sql: "SELECT ts FROM test",
-			data: &xsql.Tuple{
-				Emitter: "test",
-				Message: xsql.Message{
-					"a":   "val_a",
-					"ts2": cast.TimeFromUnixMilli(1568854573431),
-				},
-			},
-			result: []map[string]interface{}{{"ts": nil}},
-		},
-		{ // 4
-			sql: "SELECT A FROM test",
-			data: &xsql.Tuple{
-				Emitter: "test",
-				Message: xsql.Message{
-					"a": "val_a",
-				},
-			},
-			result: []map[string]interface{}{{
-				"A": "val_a",
-			}},
-		},
-		// 5
-		{
-			sql: `SELECT "value" FROM test`,
-			data: &xsql.Tuple{
-				Emitter: "test",
-				Message: xsql.Message{},
-			},
-			result: []map[string]interface{}{{
-				"kuiper_field_0": "value",
-			}},
+			result: []map[string]interface{}{{
+				"r": 1,
+			}},
 		},
 		// 6
 		{
-			sql: `SELECT 3.4 FROM test`,
-			data: &xsql.Tuple{
-				Emitter: "test",
-				Message: xsql.Message{},
+			sql: "SELECT meta(test.device) as d FROM test Inner Join test1 on test.id = test1.id GROUP BY TumblingWindow(ss, 10)",
+			data: &xsql.JoinTuples{
+				Content: []*xsql.JoinTuple{
+					{
+						Tuples: []xsql.Row{
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1, "a": 65.55}, Metadata: xsql.Metadata{"device": "devicea"}},
+							&xsql.Tuple{Emitter: "test1", Message: xsql.Message{"id": 1, "b": 12}},
+						},
+					},
+					{
+						Tuples: []xsql.Row{
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 2, "a": 73.499}, Metadata: xsql.Metadata{"device": "deviceb"}},
+							&xsql.Tuple{Emitter: "test1", Message: xsql.Message{"id": 2, "b": 34}},
+						},
+					},
+					{
+						Tuples: []xsql.Row{
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 3, "a": 88.88}, Metadata: xsql.Metadata{"device": "devicec"}},
+							&xsql.Tuple{Emitter: "test1", Message: xsql.Message{"id": 3, "b": 6}},
+						},
+					},
+				},
 			},
 			result: []map[string]interface{}{{
-				"kuiper_field_0": 3.4,
+				"d": "devicea",
+			}, {
+				"d": "deviceb",
+			}, {
+				"d": "devicec",
 			}},
 		},
-		// 7
 		{
-			sql: `SELECT 5 FROM test`,
-			data: &xsql.Tuple{
-				Emitter: "test",
-				Message: xsql.Message{},
+			sql: "SELECT meta(*) AS m, meta(test1.*) AS m1 FROM test Inner Join test1 on test.id = test1.id GROUP BY TumblingWindow(ss, 10)",
+			data: &xsql.JoinTuples{
+				Content: []*xsql.JoinTuple{
+					{
+						Tuples: []xsql.Row{
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1, "a": 65.55}, Metadata: xsql.Metadata{"device": "devicea", "topic": "t1"}},
+							&xsql.Tuple{Emitter: "test1", Message: xsql.Message{"id": 1, "b": 12}, Metadata: xsql.Metadata{"device": "dev1"}},
+						},
+					},
+					{
+						Tuples: []xsql.Row{
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 2, "a": 73.499}, Metadata: xsql.Metadata{"device": "deviceb"}},
+							&xsql.Tuple{Emitter: "test1", Message: xsql.Message{"id": 2, "b": 34}},
+						},
+					},
+				},
 			},
 			result: []map[string]interface{}{{
-				"kuiper_field_0": int64(5),
+				"m":  map[string]interface{}{"device": "devicea", "topic": "t1"},
+				"m1": map[string]interface{}{"device": "dev1"},
+			}, {
+				"m":  map[string]interface{}{"device": "deviceb"},
+				"m1": map[string]interface{}{},
 			}},
 		},
-		// 8
 		{
-			sql: `SELECT a, "value" AS b FROM test`,
-			data: &xsql.Tuple{
-				Emitter: "test",
-				Message: xsql.Message{
-					"a": "val_a",
+			sql: "SELECT meta(*) AS m, count(*) AS c FROM test GROUP BY TumblingWindow(ss, 10)",
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 1}, Metadata: xsql.Metadata{"topic": "t1"}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 2}, Metadata: xsql.Metadata{"topic": "t2"}},
 				},
 			},
 			result: []map[string]interface{}{{
-				"a": "val_a",
-				"b": "value",
+				"m": map[string]interface{}{"topic": "t1"},
+				"c": 2,
 			}},
 		},
-		// 9
 		{
-			sql: `SELECT a, "value" AS b, 3.14 as Pi, 0 as Zero FROM test`,
+			sql: "SELECT a, meta(*) AS m FROM test",
 			data: &xsql.Tuple{
 				Emitter: "test",
-				Message: xsql.Message{
-					"a": "val_a",
-				},
+				Message: xsql.Message{"a": 47.5},
 			},
 			result: []map[string]interface{}{{
-				"a":    "val_a",
-				"b":    "value",
-				"Pi":   3.14,
-				"Zero": int64(0),
+				"a": 47.5,
+				"m": map[string]interface{}{},
 			}},
 		},
-		// 11
 		{
-			sql: `SELECT a->b AS ab FROM test`,
+			sql: "SELECT count(a) invisible, a FROM test",
 			data: &xsql.Tuple{
 				Emitter: "test",
 				Message: xsql.Message{
-					"a": map[string]interface{}(nil),
+					"a": 47.5,
 				},
 			},
-			result: []map[string]interface{}{{"ab": nil}},
+			result: []map[string]interface{}{{
+				"a": 47.5,
+			}},
 		},
-		// 14
 		{
-			sql: `SELECT a[0]->b AS ab FROM test`,
-			data: &xsql.Tuple{
-				Emitter: "test",
-				Message: xsql.Message{
-					"a": []interface{}{
-						map[string]interface{}{"b": "hello1"},
-						map[string]interface{}{"b": "hello2"},
+			sql: "SELECT round(a) as r, window_index() as i FROM test GROUP BY TumblingWindow(ss, 10)",
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{
+						Emitter: "src1",
+						Message: xsql.Message{"a": 53.1},
+					}, &xsql.Tuple{
+						Emitter: "src1",
+						Message: xsql.Message{"a": 27.4},
+					}, &xsql.Tuple{
+						Emitter: "src1",
+						Message: xsql.Message{"a": 123123.7},
 					},
 				},
 			},
+
 			result: []map[string]interface{}{{
-				"ab": "hello1",
+				"r": float64(53),
+				"i": int64(0),
+			}, {
+				"r": float64(27),
+				"i": int64(1),
+			}, {
+				"r": float64(123124),
+				"i": int64(2),
 			}},
 		},
-		// 15
 		{
-			sql: `SELECT a[0]->b AS ab FROM test`,
+			sql: "SELECT round(a) as r, window_index() as i FROM test",
 			data: &xsql.Tuple{
 				Emitter: "test",
 				Message: xsql.Message{
-					"a": []map[string]interface{}{
-						{"b": "hello1"},
-						{"b": "hello2"},
-					},
+					"a": 47.5,
 				},
 			},
 			result: []map[string]interface{}{{
-				"ab": "hello1",
+				"r": float64(48),
+				"i": int64(0),
 			}},
 		},
-		// 16
 		{
-			sql: `SELECT a[2:4] AS ab FROM test`,
-			data: &xsql.Tuple{
-				Emitter: "test",
-				Message: xsql.Message{
-					"a": []map[string]interface{}{
-						{"b": "hello1"},
-						{"b": "hello2"},
-						{"b": "hello3"},
-						{"b": "hello4"},
-						{"b": "hello5"},
+			// window_index() is the index of the group in an aggregate query
+			sql: "SELECT b, window_index() as i, count(*) as c FROM test GROUP BY TumblingWindow(ss, 10), b",
+			data: &xsql.GroupedTuplesSet{
+				Groups: []*xsql.GroupedTuples{
+					{
+						Content: []xsql.Row{
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 1, "b": "x"}},
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 2, "b": "x"}},
+						},
+					},
+					{
+						Content: []xsql.Row{
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 3, "b": "y"}},
+						},
 					},
 				},
 			},
 			result: []map[string]interface{}{{
-				"ab": []map[string]interface{}{
-					{"b": "hello3"},
-					{"b": "hello4"},
-				},
+				"b": "x",
+				"i": int64(0),
+				"c": 2,
+			}, {
+				"b": "y",
+				"i": int64(1),
+				"c": 1,
 			}},
 		},
-		// 17
+	}
+
+	fmt.Printf("The test bucket size is %d.\n\n", len(tests))
+	contextLogger := conf.Log.WithField("rule", "TestProjectPlan_Funcs")
+	ctx := context.WithValue(context.Background(), context.LoggerKey, contextLogger)
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			stmt, err := xsql.NewParser(strings.NewReader(tt.sql)).Parse()
+			require.NoError(t, err)
+			pp := &ProjectOp{SendMeta: true, IsAggregate: xsql.WithAggFields(stmt)}
+			parseStmt(pp, stmt.Fields)
+			fv, afv := xsql.NewFunctionValuersForOp(nil)
+			opResult := pp.Apply(ctx, tt.data, fv, afv)
+			result, err := parseResult(opResult, pp.IsAggregate)
+			require.NoError(t, err)
+			require.Equal(t, tt.result, result)
+		})
+	}
+}
+
+func TestProjectWindowIndex(t *testing.T) {
+	contextLogger := conf.Log.WithField("rule", "TestProjectWindowIndex")
+	ctx := context.WithValue(context.Background(), context.LoggerKey, contextLogger)
+	stmt, err := xsql.NewParser(strings.NewReader("SELECT a, window_index() AS i FROM test GROUP BY TumblingWindow(ss, 10)")).Parse()
+	require.NoError(t, err)
+	pp := &ProjectOp{}
+	parseStmt(pp, stmt.Fields)
+	fv, afv := xsql.NewFunctionValuersForOp(nil)
+	// the index restarts from 0 for each window
+	windows := []*xsql.WindowTuples{
 		{
-			sql: `SELECT a[2:] AS ab FROM test`,
-			data: &xsql.Tuple{
-				Emitter: "test",
-				Message: xsql.Message{
-					"a": []map[string]interface{}{
-						{"b": "hello1"},
-						{"b": "hello2"},
-						{"b": "hello3"},
-						{"b": "hello4"},
-						{"b": "hello5"},
-					},
-				},
+			Content: []xsql.Row{
+				&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 1}},
+				&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 2}},
 			},
-			result: []map[string]interface{}{{
-				"ab": []map[string]interface{}{
-					{"b": "hello3"},
-					{"b": "hello4"},
-					{"b": "hello5"},
-				},
-			}},
 		},
-		// 18
 		{
-			sql: `SELECT a[2:] AS ab FROM test`,
-			data: &xsql.Tuple{
-				Emitter: "test",
-				Message: xsql.Message{
-					"a": []interface{}{
-						true, false, true, false, true, true,
-					},
-				},
+			Content: []xsql.Row{
+				&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 3}},
 			},
-			result: []map[string]interface{}{{
+		},
+	}
+	expected := [][]map[string]interface{}{
+		{{"a": 1, "i": int64(0)}, {"a": 2, "i": int64(1)}},
+		{{"a": 3, "i": int64(0)}},
+	}
+	for i, w := range windows {
+		result, err := parseResult(pp.Apply(ctx, w, fv, afv), pp.IsAggregate)
+		require.NoError(t, err)
+		require.Equal(t, expected[i], result)
+	}
+}
+
+func TestProjectPlan_AggFuncs(t *testing.T) {
+	tests := []struct {
+		sql       string
+		data      interface{}
+		preferInt bool
+		result    []map[string]interface{}
+	}{
+		{ // 0
+			sql: "SELECT count(*) as c, round(a) as r, window_start() as ws, window_end() as we FROM test Inner Join test1 on test.id = test1.id GROUP BY TumblingWindow(ss, 10), test1.color",
+			data: &xsql.GroupedTuplesSet{
+				Groups: []*xsql.GroupedTuples{
+					{
+						Content: []xsql.Row{
+							&xsql.JoinTuple{
+								Tuples: []xsql.Row{
+									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1, "a": 122.33, "c": 2, "r": 122}},
+									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 1, "color": "w2"}},
+								},
+							},
+							&xsql.JoinTuple{
+								Tuples: []xsql.Row{
+									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 5, "a": 177.51}},
+									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 5, "color": "w2"}},
+								},
+							},
+						},
+						WindowRange: xsql.NewWindowRange(1541152486013, 1541152487013, 1541152487013),
+					},
+					{
+						Content: []xsql.Row{
+							&xsql.JoinTuple{
+								Tuples: []xsql.Row{
+									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 2, "a": 89.03, "c": 2, "r": 89}},
+									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 2, "color": "w1"}},
+								},
+							},
+							&xsql.JoinTuple{
+								Tuples: []xsql.Row{
+									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 4, "a": 14.6}},
+									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 4, "color": "w1"}},
+								},
+							},
+						},
+						WindowRange: xsql.NewWindowRange(1541152486013, 1541152487013, 1541152487013),
+					},
+				},
+			},
+			result: []map[string]interface{}{{
+				"c":  2,
+				"r":  float64(122),
+				"ws": int64(1541152486013),
+				"we": int64(1541152487013),
+			}, {
+				"c":  2,
+				"r":  float64(89),
+				"ws": int64(1541152486013),
+				"we": int64(1541152487013),
+			}},
+		},
+		// 1
+		{
+			sql: "SELECT count(a) as c, avg(a) as a, sum(a) as s, min(a) as min, max(a) as max FROM test Inner Join test1 on test.id = test1.id GROUP BY TumblingWindow(ss, 10), test1.color",
+			data: &xsql.GroupedTuplesSet{
+				Groups: []*xsql.GroupedTuples{
+					{
+						Content: []xsql.Row{
+							&xsql.JoinTuple{
+								Tuples: []xsql.Row{
+									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1, "a": 122.33, "c": 2, "r": 122}},
+									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 1, "color": "w2"}},
+								},
+							},
+							&xsql.JoinTuple{
+								Tuples: []xsql.Row{
+									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 5}},
+									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 5, "color": "w2"}},
+								},
+							},
+						},
+					},
+					{
+						Content: []xsql.Row{
+							&xsql.JoinTuple{
+								Tuples: []xsql.Row{
+									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 2, "a": 89.03, "c": 2, "r": 89}},
+									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 2, "color": "w1"}},
+								},
+							},
+							&xsql.JoinTuple{
+								Tuples: []xsql.Row{
+									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 4, "a": 14.6}},
+									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 4, "color": "w1"}},
+								},
+							},
+						},
+					},
+				},
+			},
+			result: []map[string]interface{}{{
+				"c":   1,
+				"a":   122.33,
+				"s":   122.33,
+				"min": 122.33,
+				"max": 122.33,
+			}, {
+				"c":   2,
+				"s":   103.63,
+				"a":   51.815,
+				"min": 14.6,
+				"max": 89.03,
+			}},
+		},
+		// 2
+		{
+			sql: "SELECT avg(a) FROM test Inner Join test1 on test.id = test1.id GROUP BY TumblingWindow(ss, 10), test1.color",
+			data: &xsql.GroupedTuplesSet{
+				Groups: []*xsql.GroupedTuples{
+					{
+						Content: []xsql.Row{
+							&xsql.JoinTuple{
+								Tuples: []xsql.Row{
+									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1, "a": 122.33}},
+									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 1, "color": "w2"}},
+								},
+							},
+							&xsql.JoinTuple{
+								Tuples: []xsql.Row{
+									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1, "a": 68.54}},
+									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 1, "color": "w2"}},
+								},
+							},
+							&xsql.JoinTuple{
+								Tuples: []xsql.Row{
+									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 4, "a": 98.31}},
+									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 4, "color": "w2"}},
+								},
+							},
+							&xsql.JoinTuple{
+								Tuples: []xsql.Row{
+									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 5, "a": 177.54}},
+									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 5, "color": "w2"}},
+								},
+							},
+						},
+					},
+					{
+						Content: []xsql.Row{
+							&xsql.JoinTuple{
+								Tuples: []xsql.Row{
+									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 2, "a": 89.03}},
+									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 2, "color": "w1"}},
+								},
+							},
+							&xsql.JoinTuple{
+								Tuples: []xsql.Row{
+									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 4, "a": 14.6}},
+									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 4, "color": "w1"}},
+								},
+							},
+						},
+					},
+				},
+			},
+			result: []map[string]interface{}{{
+				"avg": 116.68,
+			}, {
+				"avg": 51.815,
+			}},
+		},
+		// 3
+		{
+			sql: "SELECT max(a) FROM test Inner Join test1 on test.id = test1.id GROUP BY TumblingWindow(ss, 10), test1.color",
+			data: &xsql.GroupedTuplesSet{
+				Groups: []*xsql.GroupedTuples{
+					{
+						Content: []xsql.Row{
+							&xsql.JoinTuple{
+								Tuples: []xsql.Row{
+									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1, "a": 122.33}},
+									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 1, "color": "w2"}},
+								},
+							},
+							&xsql.JoinTuple{
+								Tuples: []xsql.Row{
+									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1, "a": 68.55}},
+									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 1, "color": "w2"}},
+								},
+							},
+							&xsql.JoinTuple{
+								Tuples: []xsql.Row{
+									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 5, "a": 177.51}},
+									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 5, "color": "w2"}},
+								},
+							},
+						},
+					},
+					{
+						Content: []xsql.Row{
+							&xsql.JoinTuple{
+								Tuples: []xsql.Row{
+									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 2, "a": 89.03}},
+									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 2, "color": "w1"}},
+								},
+							},
+							&xsql.JoinTuple{
+								Tuples: []xsql.Row{
+									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 4, "a": 14.6}},
+									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 4, "color": "w1"}},
+								},
+							},
+						},
+					},
+				},
+			},
+			result: []map[string]interface{}{{
+				"max": 177.51,
+			}, {
+				"max": 89.03,
+			}},
+		},
+		// 4
+		{
+			sql: "SELECT min(a), window_start(), window_end() FROM test Inner Join test1 on test.id = test1.id GROUP BY TumblingWindow(ss, 10)",
+			data: &xsql.JoinTuples{
+				Content: []*xsql.JoinTuple{
+					{
+						Tuples: []xsql.Row{
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1, "a": 122.33}},
+							&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 1, "color": "w2"}},
+						},
+					},
+					{
+						Tuples: []xsql.Row{
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1, "a": 68.55}},
+							&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 1, "color": "w2"}},
+						},
+					},
+					{
+						Tuples: []xsql.Row{
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 5, "a": 177.51}},
+							&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 5, "color": "w2"}},
+						},
+					},
+				},
+				WindowRange: xsql.NewWindowRange(1541152486013, 1541152487013, 1541152487013),
+			},
+
+			result: []map[string]interface{}{{
+				"min":          68.55,
+				"window_start": int64(1541152486013),
+				"window_end":   int64(1541152487013),
+			}},
+		},
+		// 5
+		{
+			sql: "SELECT count(*) as all, count(a) as c, avg(a) as a, sum(a) as s, min(a) as min, max(a) as max FROM test Inner Join test1 on test.id = test1.id GROUP BY TumblingWindow(ss, 10)",
+			data: &xsql.JoinTuples{
+				Content: []*xsql.JoinTuple{
+					{
+						Tuples: []xsql.Row{
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1}},
+							&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 1, "color": "w2"}},
+						},
+					},
+					{
+						Tuples: []xsql.Row{
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1, "a": 68.55}},
+							&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 1, "color": "w2"}},
+						},
+					},
+					{
+						Tuples: []xsql.Row{
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 5, "a": 177.51}},
+							&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 5, "color": "w2"}},
+						},
+					},
+				},
+			},
+
+			result: []map[string]interface{}{{
+				"all": 3,
+				"c":   2,
+				"a":   123.03,
+				"s":   246.06,
+				"min": 68.55,
+				"max": 177.51,
+			}},
+		},
+		// 6
+		{
+			sql: "SELECT sum(a), window_start() as ws, window_end() FROM test GROUP BY TumblingWindow(ss, 10)",
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{
+						Emitter: "src1",
+						Message: xsql.Message{"a": 53},
+					}, &xsql.Tuple{
+						Emitter: "src1",
+						Message: xsql.Message{"a": 27},
+					}, &xsql.Tuple{
+						Emitter: "src1",
+						Message: xsql.Message{"a": 123123},
+					},
+				},
+				WindowRange: xsql.NewWindowRange(1541152486013, 1541152487013, 1541152487013),
+			},
+			result: []map[string]interface{}{{
+				"sum":        int64(123203),
+				"ws":         int64(1541152486013),
+				"window_end": int64(1541152487013),
+			}},
+		},
+		// 7
+		{
+			sql: "SELECT sum(a) as s FROM test GROUP BY TumblingWindow(ss, 10)",
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{
+						Emitter: "src1",
+						Message: xsql.Message{"a": 53, "s": 123203},
+					}, &xsql.Tuple{
+						Emitter: "src1",
+						Message: xsql.Message{"a": 27},
+					}, &xsql.Tuple{
+						Emitter: "src1",
+						Message: xsql.Message{"a": 123123},
+					},
+				},
+			},
+
+			result: []map[string]interface{}{{
+				"s": int64(123203),
+			}},
+		},
+		// 8
+		{
+			sql: "SELECT sum(a) FROM test GROUP BY TumblingWindow(ss, 10)",
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{
+						Emitter: "src1",
+						Message: xsql.Message{"a": 53},
+					}, &xsql.Tuple{
+						Emitter: "src1",
+						Message: xsql.Message{"a": 27},
+					}, &xsql.Tuple{
+						Emitter: "src1",
+						Message: xsql.Message{"a": 123123},
+					},
+				},
+			},
+			result: []map[string]interface{}{{
+				"sum": int64(123203),
+			}},
+		},
+		// 9
+		{
+			sql: "SELECT count(*) as all, count(a) as c, avg(a) as a, sum(a) as s, min(a) as min, max(a) as max  FROM test GROUP BY TumblingWindow(ss, 10)",
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{
+						Emitter: "src1",
+						Message: xsql.Message{"a": 53},
+					}, &xsql.Tuple{
+						Emitter: "src1",
+						Message: xsql.Message{"a": 27},
+					}, &xsql.Tuple{
+						Emitter: "src1",
+						Message: xsql.Message{"s": 123123},
+					},
+				},
+			},
+			result: []map[string]interface{}{{
+				"all": 3,
+				"c":   2,
+				"a":   int64(40),
+				"s":   int64(80),
+				"min": int64(27),
+				"max": int64(53),
+			}},
+		},
+		// 10
+		{
+			sql: "SELECT count(*), meta(test1.device) FROM test Inner Join test1 on test.id = test1.id GROUP BY TumblingWindow(ss, 10), test1.color",
+			data: &xsql.GroupedTuplesSet{
+				Groups: []*xsql.GroupedTuples{
+					{
+						Content: []xsql.Row{
+							&xsql.JoinTuple{
+								Tuples: []xsql.Row{
+									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1, "a": 122.33}},
+									&xsql.Tuple{Emitter: "test1", Message: xsql.Message{"id": 1, "color": "w2"}, Metadata: xsql.Metadata{"device": "devicea"}},
+								},
+							},
+							&xsql.JoinTuple{
+								Tuples: []xsql.Row{
+									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 5, "a": 177.51}},
+									&xsql.Tuple{Emitter: "test1", Message: xsql.Message{"id": 5, "color": "w2"}, Metadata: xsql.Metadata{"device": "deviceb"}},
+								},
+							},
+						},
+					},
+					{
+						Content: []xsql.Row{
+							&xsql.JoinTuple{
+								Tuples: []xsql.Row{
+									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 2, "a": 89.03}},
+									&xsql.Tuple{Emitter: "test1", Message: xsql.Message{"id": 2, "color": "w1"}, Metadata: xsql.Metadata{"device": "devicec"}},
+								},
+							},
+							&xsql.JoinTuple{
+								Tuples: []xsql.Row{
+									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 4, "a": 14.6}},
+									&xsql.Tuple{Emitter: "test1", Message: xsql.Message{"id": 4, "color": "w1"}, Metadata: xsql.Metadata{"device": "deviced"}},
+								},
+							},
+						},
+					},
+				},
+			},
+			result: []map[string]interface{}{{
+				"count": 2,
+				"meta":  "devicea",
+			}, {
+				"count": 2,
+				"meta":  "devicec",
+			}},
+		},
+		// 11
+		{
+			sql: "SELECT count(*) as c, meta(test1.device) as d FROM test Inner Join test1 on test.id = test1.id GROUP BY TumblingWindow(ss, 10), test1.color",
+			data: &xsql.GroupedTuplesSet{
+				Groups: []*xsql.GroupedTuples{
+					{
+						Content: []xsql.Row{
+							&xsql.JoinTuple{
+								Tuples: []xsql.Row{
+									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1, "a": 122.33, "c": 2, "d": "devicea"}},
+									&xsql.Tuple{Emitter: "test1", Message: xsql.Message{"id": 1, "color": "w2"}, Metadata: xsql.Metadata{"device": "devicea"}},
+								},
+							},
+							&xsql.JoinTuple{
+								Tuples: []xsql.Row{
+									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 5, "a": 177.51}},
+									&xsql.Tuple{Emitter: "test1", Message: xsql.Message{"id": 5, "color": "w2"}, Metadata: xsql.Metadata{"device": "deviceb"}},
+								},
+							},
+						},
+					},
+					{
+						Content: []xsql.Row{
+							&xsql.JoinTuple{
+								Tuples: []xsql.Row{
+									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 2, "a": 89.03, "c": 2, "d": "devicec"}},
+									&xsql.Tuple{Emitter: "test1", Message: xsql.Message{"id": 2, "color": "w1"}, Metadata: xsql.Metadata{"device": "devicec"}},
+								},
+							},
+							&xsql.JoinTuple{
+								Tuples: []xsql.Row{
+									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 4, "a": 14.6}},
+									&xsql.Tuple{Emitter: "test1", Message: xsql.Message{"id": 4, "color": "w1"}, Metadata: xsql.Metadata{"device": "deviced"}},
+								},
+							},
+						},
+					},
+				},
+			},
+			result: []map[string]interface{}{{
+				"c": 2,
+				"d": "devicea",
+			}, {
+				"c": 2,
+				"d": "devicec",
+			}},
+		},
+		// 12
+		{
+			sql: "SELECT * FROM test Inner Join test1 on test.id = test1.id GROUP BY TumblingWindow(ss, 10), test1.color",
+			data: &xsql.GroupedTuplesSet{
+				Groups: []*xsql.GroupedTuples{
+					{
+						Content: []xsql.Row{
+							&xsql.JoinTuple{
+								Tuples: []xsql.Row{
+									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1, "a": 122.33, "c": 2, "r": 122}},
+									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 1, "color": "w2"}},
+								},
+							},
+							&xsql.JoinTuple{
+								Tuples: []xsql.Row{
+									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 5, "a": 177.51}},
+									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 5, "color": "w2"}},
+								},
+							},
+						},
+						WindowRange: xsql.NewWindowRange(1541152486013, 1541152487013, 1541152487013),
+					},
+					{
+						Content: []xsql.Row{
+							&xsql.JoinTuple{
+								Tuples: []xsql.Row{
+									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 2, "a": 89.03, "c": 2, "r": 89}},
+									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 2, "color": "w1"}},
+								},
+							},
+							&xsql.JoinTuple{
+								Tuples: []xsql.Row{
+									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 4, "a": 14.6}},
+									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 4, "color": "w1"}},
+								},
+							},
+						},
+						WindowRange: xsql.NewWindowRange(1541152486013, 1541152487013, 1541152487013),
+					},
+				},
+			},
+			result: []map[string]interface{}{{
+				"a":     122.33,
+				"c":     2,
+				"color": "w2",
+				"id":    1,
+				"r":     122,
+			}, {
+				"a":     89.03,
+				"c":     2,
+				"color": "w1",
+				"id":    2,
+				"r":     89,
+			}},
+		},
+		// 13
+		{
+			sql: "SELECT collect(a) as r1 FROM test Inner Join test1 on test.id = test1.id GROUP BY TumblingWindow(ss, 10), test1.color",
+			data: &xsql.GroupedTuplesSet{
+				Groups: []*xsql.GroupedTuples{
+					{
+						Content: []xsql.Row{
+							&xsql.JoinTuple{
+								Tuples: []xsql.Row{
+									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1, "a": 122.33, "c": 2, "r": 122}},
+									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 1, "color": "w2"}},
+								},
+							},
+							&xsql.JoinTuple{
+								Tuples: []xsql.Row{
+									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 5, "a": 177.51}},
+									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 5, "color": "w2"}},
+								},
+							},
+						},
+					},
+					{
+						Content: []xsql.Row{
+							&xsql.JoinTuple{
+								Tuples: []xsql.Row{
+									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 2, "a": 89.03, "c": 2, "r": 89}},
+									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 2, "color": "w1"}},
+								},
+							},
+							&xsql.JoinTuple{
+								Tuples: []xsql.Row{
+									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 4, "a": 14.6}},
+									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 4, "color": "w1"}},
+								},
+							},
+						},
+					},
+				},
+			},
+			result: []map[string]interface{}{{
+				"r1": []interface{}{122.33, 177.51},
+			}, {"r1": []interface{}{89.03, 14.6}}},
+		},
+		// 14
+		{
+			sql: "SELECT collect(*)[1] as c1 FROM test GROUP BY TumblingWindow(ss, 10)",
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{
+						Emitter: "src1",
+						Message: xsql.Message{"a": 53, "s": 123203},
+					}, &xsql.Tuple{
+						Emitter: "src1",
+						Message: xsql.Message{"a": 27},
+					}, &xsql.Tuple{
+						Emitter: "src1",
+						Message: xsql.Message{"a": 123123},
+					},
+				},
+
+				WindowRange: xsql.NewWindowRange(1541152486013, 1541152487013, 1541152487013),
+			},
+			result: []map[string]interface{}{{
+				"c1": map[string]interface{}{
+					"a": 27,
+				},
+			}},
+		},
+		// 15
+		{
+			sql: "SELECT collect(*)[1]->a as c1 FROM test GROUP BY TumblingWindow(ss, 10)",
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{
+						Emitter: "src1",
+						Message: xsql.Message{"a": 53, "s": 123203},
+					}, &xsql.Tuple{
+						Emitter: "src1",
+						Message: xsql.Message{"a": 27},
+					}, &xsql.Tuple{
+						Emitter: "src1",
+						Message: xsql.Message{"a": 123123},
+					},
+				},
+			},
+
+			result: []map[string]interface{}{{
+				"c1": 27,
+			}},
+		},
+		// 16
+		{
+			sql: "SELECT collect(*)[1]->sl[0] as c1 FROM test GROUP BY TumblingWindow(ss, 10)",
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{
+						Emitter: "src1",
+						Message: xsql.Message{"a": 53, "sl": []string{"hello", "world"}},
+					}, &xsql.Tuple{
+						Emitter: "src1",
+						Message: xsql.Message{"a": 27, "sl": []string{"new", "horizon"}},
+					}, &xsql.Tuple{
+						Emitter: "src1",
+						Message: xsql.Message{"a": 123123, "sl": []string{"south", "africa"}},
+					},
+				},
+			},
+
+			result: []map[string]interface{}{{
+				"c1": "new",
+			}},
+		},
+		// 17
+		{
+			sql: "SELECT deduplicate(id, true) as r1 FROM test Inner Join test1 on test.id = test1.id GROUP BY TumblingWindow(ss, 10), test1.color",
+			data: &xsql.GroupedTuplesSet{
+				Groups: []*xsql.GroupedTuples{
+					{
+						Content: []xsql.Row{
+							&xsql.JoinTuple{
+								Tuples: []xsql.Row{
+									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1, "a": 122.33, "c": 2, "r": 122}},
+									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 1, "color": "w2"}},
+								},
+							},
+							&xsql.JoinTuple{
+								Tuples: []xsql.Row{
+									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 5, "a": 177.51}},
+									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 5, "color": "w2"}},
+								},
+							},
+						},
+					},
+					{
+						Content: []xsql.Row{
+							&xsql.JoinTuple{
+								Tuples: []xsql.Row{
+									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 2, "a": 89.03, "c": 2, "r": 89}},
+									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 2, "color": "w1"}},
+								},
+							},
+							&xsql.JoinTuple{
+								Tuples: []xsql.Row{
+									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 4, "a": 14.6}},
+									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 4, "color": "w1"}},
+								},
+							},
+						},
+					},
+				},
+			},
+			result: []map[string]interface{}{
+				{
+					"r1": []interface{}{
+						map[string]interface{}{"a": 122.33, "c": 2, "color": "w2", "id": 1, "r": 122},
+						map[string]interface{}{"a": 177.51, "color": "w2", "id": 5},
+					},
+				}, {
+					"r1": []interface{}{
+						map[string]interface{}{"a": 89.03, "c": 2, "color": "w1", "id": 2, "r": 89},
+						map[string]interface{}{"a": 14.6, "color": "w1", "id": 4},
+					},
+				},
+			},
+		},
+		// 18
+		{
+			sql: "SELECT deduplicate(a, false)->a as c1 FROM test GROUP BY TumblingWindow(ss, 10)",
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{
+						Emitter: "src1",
+						Message: xsql.Message{"a": 53, "s": 123203},
+					}, &xsql.Tuple{
+						Emitter: "src1",
+						Message: xsql.Message{"a": 27},
+					}, &xsql.Tuple{
+						Emitter: "src1",
+						Message: xsql.Message{"a": 123123},
+					},
+				},
+			},
+
+			result: []map[string]interface{}{{
+				"c1": 123123,
+			}},
+		},
+		// 19
+		{
+			sql: "SELECT deduplicate(a, false) as c1 FROM test GROUP BY TumblingWindow(ss, 10)",
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{
+						Emitter: "src1",
+						Message: xsql.Message{"a": 53, "s": 123203},
+					}, &xsql.Tuple{
+						Emitter: "src1",
+						Message: xsql.Message{"a": 27},
+					}, &xsql.Tuple{
+						Emitter: "src1",
+						Message: xsql.Message{"a": 53},
+					},
+				},
+			},
+
+			result: []map[string]interface{}{{}},
+		},
+		// 20
+		{
+			sql: "SELECT deduplicate(a, false) as c1 FROM test GROUP BY TumblingWindow(ss, 10)",
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{
+						Emitter: "src1",
+						Message: xsql.Message{"a": 53, "s": 123203},
+					}, &xsql.Tuple{
+						Emitter: "src1",
+						Message: xsql.Message{"a": 27},
+					}, &xsql.Tuple{
+						Emitter: "src1",
+						Message: xsql.Message{"a": 53},
+					},
+				},
+			},
+
+			result: []map[string]interface{}{{}},
+		},
+		// 21  when got column after group by operation, return the first tuple's column
+		{
+			sql: "SELECT A.module, A.topic , max(A.value), B.topic as var2, max(B.value) as max2, C.topic as var3, max(C.value) as max3 FROM A FULL JOIN B on A.module=B.module FULL JOIN C on A.module=C.module GROUP BY A.module, TUMBLINGWINDOW(ss, 10)",
+			data: &xsql.GroupedTuplesSet{
+				Groups: []*xsql.GroupedTuples{
+					{
+						Content: []xsql.Row{
+							&xsql.JoinTuple{
+								Tuples: []xsql.Row{
+									&xsql.Tuple{Emitter: "B", Message: xsql.Message{"module": 1, "topic": "moduleB topic", "value": 1}},
+								},
+							},
+							&xsql.JoinTuple{
+								Tuples: []xsql.Row{
+									&xsql.Tuple{Emitter: "C", Message: xsql.Message{"module": 1, "topic": "moduleC topic", "value": 100}},
+								},
+							},
+						},
+					},
+				},
+			},
+			result: []map[string]interface{}{{
+				"var2": "moduleB topic",
+				"max2": int64(1),
+				"max3": int64(100),
+			}},
+		},
+		// 22
+		{
+			sql: "SELECT count(* EXCEPT(a, b)) as all  FROM test Inner Join test1 on test.id = test1.id GROUP BY TumblingWindow(ss, 10)",
+			data: &xsql.JoinTuples{
+				Content: []*xsql.JoinTuple{
+					{
+						Tuples: []xsql.Row{
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1, "a": "a", "b": "b"}},
+							&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 1, "color": "w2"}},
+						},
+					},
+					{
+						Tuples: []xsql.Row{
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1, "a": "a", "b": "b"}},
+							&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 1, "color": "w2"}},
+						},
+					},
+					{
+						Tuples: []xsql.Row{
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 5, "a": "a", "b": "b"}},
+							&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 5, "color": "w2"}},
+						},
+					},
+				},
+			},
+
+			result: []map[string]interface{}{{
+				"all": 3,
+			}},
+		},
+		// 23
+		{
+			sql: "SELECT count(* EXCEPT(a, b)) invisible, a invisible, b as d invisible, b FROM test Inner Join test1 on test.id = test1.id GROUP BY TumblingWindow(ss, 10)",
+			data: &xsql.JoinTuples{
+				Content: []*xsql.JoinTuple{
+					{
+						Tuples: []xsql.Row{
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1, "a": "a", "b": "b"}},
+							&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 1, "color": "w2"}},
+						},
+					},
+					{
+						Tuples: []xsql.Row{
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1, "a": "a", "b": "b"}},
+							&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 1, "color": "w2"}},
+						},
+					},
+					{
+						Tuples: []xsql.Row{
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 5, "a": "a", "b": "b"}},
+							&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 5, "color": "w2"}},
+						},
+					},
+				},
+			},
+
+			result: []map[string]interface{}{{
+				"b": "b",
+			}},
+		},
+		// 24
+		{
+			sql: "SELECT sum(CASE WHEN color = 'w1' THEN a ELSE 0 END) AS w1, sum(CASE WHEN color = 'w2' THEN a ELSE 0 END) AS w2, count(*) AS c FROM test Inner Join test1 on test.id = test1.id GROUP BY TumblingWindow(ss, 10), test.b",
+			data: &xsql.GroupedTuplesSet{
+				Groups: []*xsql.GroupedTuples{
+					{
+						Content: []xsql.Row{
+							&xsql.JoinTuple{
+								Tuples: []xsql.Row{
+									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1, "a": 10, "b": "x"}},
+									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 1, "color": "w1"}},
+								},
+							},
+							&xsql.JoinTuple{
+								Tuples: []xsql.Row{
+									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 2, "a": 20, "b": "x"}},
+									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 2, "color": "w2"}},
+								},
+							},
+							&xsql.JoinTuple{
+								Tuples: []xsql.Row{
+									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 3, "a": 5, "b": "x"}},
+									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 3, "color": "w1"}},
+								},
+							},
+						},
+					},
+					{
+						Content: []xsql.Row{
+							&xsql.JoinTuple{
+								Tuples: []xsql.Row{
+									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 4, "a": 7, "b": "y"}},
+									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 4, "color": "w2"}},
+								},
+							},
+							&xsql.JoinTuple{
+								Tuples: []xsql.Row{
+									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 5, "a": 8, "b": "y"}},
+									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 5, "color": "w2"}},
+								},
+							},
+						},
+					},
+				},
+			},
+			result: []map[string]interface{}{{
+				"w1": int64(15),
+				"w2": int64(20),
+				"c":  3,
+			}, {
+				"w1": int64(0),
+				"w2": int64(15),
+				"c":  2,
+			}},
+		},
+		// 25
+		{
+			sql: "SELECT mode_freq(color) AS m FROM test Inner Join test1 on test.id = test1.id GROUP BY TumblingWindow(ss, 10)",
+			data: &xsql.JoinTuples{
+				Content: []*xsql.JoinTuple{
+					{
+						Tuples: []xsql.Row{
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1, "a": 122.33}},
+							&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 1, "color": "w1"}},
+						},
+					},
+					{
+						Tuples: []xsql.Row{
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 2, "a": 89.03}},
+							&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 2, "color": "w2"}},
+						},
+					},
+					{
+						Tuples: []xsql.Row{
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 3, "a": 14.6}},
+							&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 3, "color": "w2"}},
+						},
+					},
+					{
+						Tuples: []xsql.Row{
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 4, "a": 7.1}},
+							&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 4, "color": "w2"}},
+						},
+					},
+				},
+			},
+			result: []map[string]interface{}{{
+				"m": map[string]interface{}{
+					"value": "w2",
+					"count": 3,
+				},
+			}},
+		},
+		// 26
+		{
+			sql: "SELECT min_time() AS mn, max_time() AS mx FROM test GROUP BY TumblingWindow(ss, 10)",
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 1}, Timestamp: cast.TimeFromUnixMilli(1568854573431)},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 2}, Timestamp: cast.TimeFromUnixMilli(1568854571431)},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 3}, Timestamp: cast.TimeFromUnixMilli(1568854579431)},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 4}, Timestamp: cast.TimeFromUnixMilli(1568854575431)},
+				},
+				WindowRange: xsql.NewWindowRange(1568854570000, 1568854580000, 1568854580000),
+			},
+			result: []map[string]interface{}{{
+				"mn": cast.TimeFromUnixMilli(1568854571431),
+				"mx": cast.TimeFromUnixMilli(1568854579431),
+			}},
+		},
+		// 27
+		{
+			sql: "SELECT collect_concat(a->tags) AS tags FROM test GROUP BY TumblingWindow(ss, 10)",
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": map[string]interface{}{"tags": []interface{}{"x", "y"}}}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": map[string]interface{}{"name": "no tags"}}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": map[string]interface{}{"tags": []interface{}{}}}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"b": 1}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": map[string]interface{}{"tags": []interface{}{"y", "z"}}}},
+				},
+			},
+			result: []map[string]interface{}{{
+				"tags": []interface{}{"x", "y", "y", "z"},
+			}},
+		},
+		// 28
+		{
+			sql: "SELECT nth_value(a, 2) AS second, nth_value(a, 4) AS fourth FROM test GROUP BY TumblingWindow(ss, 10) ORDER BY a DESC",
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 68.55}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 65.55}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 12.3}},
+				},
+			},
+			result: []map[string]interface{}{{
+				"second": 65.55,
+			}},
+		},
+		// 29
+		{
+			sql: "SELECT chunk(*, 2) AS c FROM test GROUP BY TumblingWindow(ss, 10)",
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 1}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 2}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 3}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 4}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 5}},
+				},
+			},
+			result: []map[string]interface{}{{
+				"c": []interface{}{
+					[]interface{}{
+						map[string]interface{}{"a": 1},
+						map[string]interface{}{"a": 2},
+						map[string]interface{}{"a": 3},
+					},
+					[]interface{}{
+						map[string]interface{}{"a": 4},
+						map[string]interface{}{"a": 5},
+					},
+				},
+			}},
+		},
+		// 30
+		{
+			sql: "SELECT chunk(*, 2) AS c FROM test GROUP BY TumblingWindow(ss, 10)",
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{},
+			},
+			result: []map[string]interface{}{{
+				"c": []interface{}{[]interface{}{}, []interface{}{}},
+			}},
+		},
+		// 31
+		{
+			sql: "SELECT collect(a ORDER BY ts) AS last, collect(a ORDER BY ts DESC NULLS FIRST) AS first FROM test GROUP BY TumblingWindow(ss, 10)",
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": "n1"}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": "t3", "ts": 3}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": "n2"}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": "t1", "ts": 1}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": "t2", "ts": 2}},
+				},
+			},
+			result: []map[string]interface{}{{
+				"last":  []interface{}{"t1", "t2", "t3", "n1", "n2"},
+				"first": []interface{}{"n1", "n2", "t3", "t2", "t1"},
+			}},
+		},
+		// 32
+		{
+			sql: "SELECT regr_slope(y, x) AS slope, regr_intercept(y, x) AS intercept FROM test GROUP BY TumblingWindow(ss, 10)",
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"x": 1, "y": 5.0}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"x": 2, "y": 7.0}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"x": 3}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"x": 4, "y": 11.0}},
+				},
+			},
+			result: []map[string]interface{}{{
+				"slope":     2.0,
+				"intercept": 3.0,
+			}},
+		},
+		// 33
+		{
+			sql: "SELECT regr_slope(y, x) AS slope, regr_intercept(y, x) AS intercept FROM test GROUP BY TumblingWindow(ss, 10)",
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"x": 1, "y": 5.0}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"x": 2}},
+				},
+			},
+			result: []map[string]interface{}{{}},
+		},
+		// 34
+		{
+			sql: "SELECT count(*) as c, window_start() as ws, window_end() as we FROM test GROUP BY TumblingWindow(ss, 10), color",
+			data: &xsql.GroupedTuplesSet{
+				Groups: []*xsql.GroupedTuples{
+					{
+						Content: []xsql.Row{
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 1, "color": "w1"}},
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 2, "color": "w1"}},
+						},
+						WindowRange: xsql.NewWindowRange(1541152486000, 1541152487000, 1541152487000),
+					},
+					{
+						Content: []xsql.Row{
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 3, "color": "w2"}},
+						},
+						WindowRange: xsql.NewWindowRange(1541152488000, 1541152489000, 1541152489000),
+					},
+					{
+						Content: []xsql.Row{
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 4, "color": "w3"}},
+						},
+					},
+				},
+				WindowRange: xsql.NewWindowRange(1541152480000, 1541152490000, 1541152490000),
+			},
+			result: []map[string]interface{}{{
+				"c":  2,
+				"ws": int64(1541152486000),
+				"we": int64(1541152487000),
+			}, {
+				"c":  1,
+				"ws": int64(1541152488000),
+				"we": int64(1541152489000),
+			}, {
+				"c":  1,
+				"ws": int64(1541152480000),
+				"we": int64(1541152490000),
+			}},
+		},
+		// 35
+		{
+			sql: "SELECT rle(a) AS runs FROM test GROUP BY TumblingWindow(ss, 10)",
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": "v1"}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": "v1"}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": "v2"}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": "v1"}},
+				},
+			},
+			result: []map[string]interface{}{{
+				"runs": []interface{}{
+					map[string]interface{}{"value": "v1", "count": 2},
+					map[string]interface{}{"value": "v2", "count": 1},
+					map[string]interface{}{"value": "v1", "count": 1},
+				},
+			}},
+		},
+		// 36
+		{
+			sql: "SELECT concat(color, ':', count(*), '/', upper(color)) AS label FROM test GROUP BY TumblingWindow(ss, 10), color",
+			data: &xsql.GroupedTuplesSet{
+				Groups: []*xsql.GroupedTuples{
+					{
+						Content: []xsql.Row{
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 1, "color": "w1"}},
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 2, "color": "w1"}},
+						},
+					},
+					{
+						Content: []xsql.Row{
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 3, "color": "w2"}},
+						},
+					},
+				},
+			},
+			result: []map[string]interface{}{{
+				"label": "w1:2/W1",
+			}, {
+				"label": "w2:1/W2",
+			}},
+		},
+		// 37
+		{
+			sql: "SELECT avg(test.a) AS ta, avg(src2.a) AS sa, count(src2.a) AS sc FROM test Inner Join src2 on test.id = src2.id GROUP BY TumblingWindow(ss, 10), src2.color",
+			data: &xsql.GroupedTuplesSet{
+				Groups: []*xsql.GroupedTuples{
+					{
+						Content: []xsql.Row{
+							&xsql.JoinTuple{
+								Tuples: []xsql.Row{
+									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1, "a": 10}},
+									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 1, "a": 100, "color": "w2"}},
+								},
+							},
+							&xsql.JoinTuple{
+								Tuples: []xsql.Row{
+									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 5, "a": 20}},
+									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 5, "a": 300, "color": "w2"}},
+								},
+							},
+						},
+					},
+					{
+						Content: []xsql.Row{
+							&xsql.JoinTuple{
+								Tuples: []xsql.Row{
+									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 2, "a": 30}},
+									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 2, "color": "w1"}},
+								},
+							},
+						},
+					},
+				},
+			},
+			result: []map[string]interface{}{{
+				"ta": int64(15),
+				"sa": int64(200),
+				"sc": 2,
+			}, {
+				"ta": int64(30),
+				"sc": 0,
+			}},
+		},
+		// 38
+		{
+			sql: "SELECT dot(collect(a), collect(b)) AS d FROM test GROUP BY TumblingWindow(ss, 10)",
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 1, "b": 4}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 2, "b": 5.5}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 3, "b": 6}},
+				},
+			},
+			result: []map[string]interface{}{{
+				"d": 33.0,
+			}},
+		},
+		// 39
+		{
+			sql: "SELECT approx_percentile(a, 0.5) AS p50, approx_percentile(a, 1) AS p100 FROM test GROUP BY TumblingWindow(ss, 10)",
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 5}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 1}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 3}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 2}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 4}},
+				},
+			},
+			result: []map[string]interface{}{{
+				"p50":  3.0,
+				"p100": 5.0,
+			}},
+		},
+		// 40
+		{
+			sql: "SELECT array_diff(collect(a)) AS deltas FROM test GROUP BY TumblingWindow(ss, 10)",
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 10}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 12}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 11.5}},
+				},
+			},
+			result: []map[string]interface{}{{
+				"deltas": []interface{}{int64(2), -0.5},
+			}},
+		},
+		// 41
+		{
+			sql: "SELECT weighted_median(a, w) AS wm FROM test GROUP BY TumblingWindow(ss, 10)",
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 40, "w": 5}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 10, "w": 1}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 30.5, "w": 1}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"w": 10}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 20, "w": 1}},
+				},
+			},
+			// total weight 8, the cumulative weights by value are 1, 2, 3, 8
+			result: []map[string]interface{}{{
+				"wm": 40.0,
+			}},
+		},
+		// 42
+		{
+			sql: "SELECT weighted_median(a, w) AS wm FROM test GROUP BY TumblingWindow(ss, 10), b",
+			data: &xsql.GroupedTuplesSet{
+				Groups: []*xsql.GroupedTuples{
+					{
+						Content: []xsql.Row{
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 1, "w": 2, "b": "x"}},
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 2, "w": 1, "b": "x"}},
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 3, "w": 1, "b": "x"}},
+						},
+					},
+					{
+						Content: []xsql.Row{
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 1, "w": 0, "b": "y"}},
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 2, "w": 0, "b": "y"}},
+						},
+					},
+				},
+			},
+			// the zero total weight returns nil
+			result: []map[string]interface{}{{
+				"wm": 1.0,
+			}, {}},
+		},
+		// 43
+		{
+			sql: "SELECT first_value(a) AS f, last_value(a) AS l FROM test GROUP BY TumblingWindow(ss, 10)",
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"b": 1}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": "x"}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": "y"}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": nil}},
+				},
+			},
+			result: []map[string]interface{}{{
+				"f": "x",
+				"l": "y",
+			}},
+		},
+		// 44
+		{
+			sql: "SELECT first_value(a) AS f, last_value(a) AS l FROM test GROUP BY TumblingWindow(ss, 10), b",
+			data: &xsql.GroupedTuplesSet{
+				Groups: []*xsql.GroupedTuples{
+					{
+						Content: []xsql.Row{
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 1, "b": "x"}},
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"b": "x"}},
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 3, "b": "x"}},
+						},
+					},
+					{
+						Content: []xsql.Row{
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"b": "y"}},
+						},
+					},
+				},
+			},
+			// the all-null group returns nil
+			result: []map[string]interface{}{{
+				"f": 1,
+				"l": 3,
+			}, {}},
+		},
+		// 45
+		{
+			sql: "SELECT first_value(src2.f2) AS f, last_value(src2.f2) AS l FROM src1 left join src2 on src1.id1 = src2.id2 GROUP BY TUMBLINGWINDOW(ss, 10)",
+			data: &xsql.JoinTuples{
+				Content: []*xsql.JoinTuple{
+					{
+						Tuples: []xsql.Row{
+							&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id1": 1, "f1": "v1"}},
+						},
+					},
+					{
+						Tuples: []xsql.Row{
+							&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id1": 2, "f1": "v2"}},
+							&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id2": 2, "f2": "w2"}},
+						},
+					},
+					{
+						Tuples: []xsql.Row{
+							&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id1": 3, "f1": "v3"}},
+							&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id2": 3, "f2": "w3"}},
+						},
+					},
+				},
+			},
+			result: []map[string]interface{}{{
+				"f": "w2",
+				"l": "w3",
+			}},
+		},
+		// 46
+		{
+			sql: "SELECT deduplicate(concat(a->id, b), true) as c1 FROM test GROUP BY TumblingWindow(ss, 10)",
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": map[string]interface{}{"id": "d1"}, "b": "x", "v": 1}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": map[string]interface{}{"id": "d1"}, "b": "y", "v": 2}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": map[string]interface{}{"id": "d1"}, "b": "x", "v": 3}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": map[string]interface{}{"id": "d2"}, "b": "x", "v": 4}},
+				},
+			},
+			result: []map[string]interface{}{{
+				"c1": []interface{}{
+					map[string]interface{}{"a": map[string]interface{}{"id": "d1"}, "b": "x", "v": 1},
+					map[string]interface{}{"a": map[string]interface{}{"id": "d1"}, "b": "y", "v": 2},
+					map[string]interface{}{"a": map[string]interface{}{"id": "d2"}, "b": "x", "v": 4},
+				},
+			}},
+		},
+		// 47
+		{
+			sql: "SELECT deduplicate(concat(a->id, b), false) as c1 FROM test GROUP BY TumblingWindow(ss, 10)",
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": map[string]interface{}{"id": "d1"}, "b": "x"}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": map[string]interface{}{"id": "d1"}, "b": "x"}},
+				},
+			},
+			result: []map[string]interface{}{{}},
+		},
+		// 48
+		{
+			sql: "SELECT window_start(\"2006-01-02T15:04:05.000Z07:00\") as ws, window_end(\"2006-01-02T15:04:05.000Z07:00\") as we, window_end() as wem FROM test GROUP BY TumblingWindow(ss, 10)",
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 1}},
+				},
+				WindowRange: xsql.NewWindowRange(1541152486013, 1541152487013, 1541152487013),
+			},
+			result: []map[string]interface{}{{
+				"ws":  cast.TimeFromUnixMilli(1541152486013).Format("2006-01-02T15:04:05.000Z07:00"),
+				"we":  cast.TimeFromUnixMilli(1541152487013).Format("2006-01-02T15:04:05.000Z07:00"),
+				"wem": int64(1541152487013),
+			}},
+		},
+		// 49
+		{
+			sql: "SELECT geomean(a) AS g FROM test GROUP BY TumblingWindow(ss, 10), b",
+			data: &xsql.GroupedTuplesSet{
+				Groups: []*xsql.GroupedTuples{
+					{
+						Content: []xsql.Row{
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 1, "b": "x"}},
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 4.0, "b": "x"}},
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"b": "x"}},
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 16, "b": "x"}},
+						},
+					},
+					{
+						Content: []xsql.Row{
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"b": "y"}},
+						},
+					},
+				},
+			},
+			// the group without values returns nil
+			result: []map[string]interface{}{{
+				"g": 4.0,
+			}, {}},
+		},
+		// 50
+		{
+			sql: "SELECT percentile(a, 0.95) AS p95, percentile(a, 0.5) AS p50, median(a) AS m FROM test GROUP BY TumblingWindow(ss, 10), b",
+			data: &xsql.GroupedTuplesSet{
+				Groups: []*xsql.GroupedTuples{
+					{
+						Content: []xsql.Row{
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 30, "b": "x"}},
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 10, "b": "x"}},
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 50.0, "b": "x"}},
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"b": "x"}},
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 20, "b": "x"}},
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 40, "b": "x"}},
+						},
+					},
+					{
+						Content: []xsql.Row{
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"b": "y"}},
+						},
+					},
+				},
+			},
+			// the group without values returns nil
+			result: []map[string]interface{}{{
+				"p95": 48.0,
+				"p50": 30.0,
+				"m":   30.0,
+			}, {}},
+		},
+		// 51
+		{
+			sql: "SELECT sum(a) AS s, max(a) AS m, avg(a) AS av, sum(a) + 1 AS s1, a FROM test GROUP BY TumblingWindow(ss, 10)",
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 1.0}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 2.0}},
+				},
+			},
+			result: []map[string]interface{}{{
+				"s":  3.0,
+				"m":  2.0,
+				"av": 1.5,
+				"s1": 4.0,
+				"a":  1.0,
+			}},
+		},
+		// 52
+		{
+			sql: "SELECT sum(a) AS s, max(a) AS m, avg(a) AS av, sum(a) + 1 AS s1, a FROM test GROUP BY TumblingWindow(ss, 10)",
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 1.0}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 2.0}},
+				},
+			},
+			preferInt: true,
+			// the non-integral result and the non-aggregate field are not converted
+			result: []map[string]interface{}{{
+				"s":  int64(3),
+				"m":  int64(2),
+				"av": 1.5,
+				"s1": int64(4),
+				"a":  1.0,
+			}},
+		},
+		// 53
+		{
+			sql: "SELECT harmmean(a) AS h FROM test GROUP BY TumblingWindow(ss, 10), b",
+			data: &xsql.GroupedTuplesSet{
+				Groups: []*xsql.GroupedTuples{
+					{
+						Content: []xsql.Row{
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 40, "b": "x"}},
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 60.0, "b": "x"}},
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"b": "x"}},
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 120, "b": "x"}},
+						},
+					},
+					{
+						Content: []xsql.Row{
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"b": "y"}},
+						},
+					},
+				},
+			},
+			// 3 / (1/40 + 1/60 + 1/120) and the group without values returns nil
+			result: []map[string]interface{}{{
+				"h": 60.0,
+			}, {}},
+		},
+		// 54
+		{
+			sql: "SELECT mode_binned(a, 0.5) AS m FROM test GROUP BY TumblingWindow(ss, 10)",
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 20.1}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 21.6}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 21.7}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 21.9}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"b": "x"}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 22}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 25.3}},
+				},
+			},
+			// the values cluster in the bin [21.5, 22)
+			result: []map[string]interface{}{{
+				"m": 21.75,
+			}},
+		},
+		// 55
+		{
+			sql: "SELECT count(*) as all, count(*) FILTER (WHERE test.id = 1) as c1, sum(a) FILTER (WHERE a > 100) as s, avg(a) FILTER (WHERE color = 'w2') as a FROM test Inner Join test1 on test.id = test1.id GROUP BY TumblingWindow(ss, 10)",
+			data: &xsql.JoinTuples{
+				Content: []*xsql.JoinTuple{
+					{
+						Tuples: []xsql.Row{
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1}},
+							&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 1, "color": "w2"}},
+						},
+					},
+					{
+						Tuples: []xsql.Row{
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1, "a": 68.55}},
+							&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 1, "color": "w1"}},
+						},
+					},
+					{
+						Tuples: []xsql.Row{
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 5, "a": 177.51}},
+							&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 5, "color": "w2"}},
+						},
+					},
+				},
+			},
+			result: []map[string]interface{}{{
+				"all": 3,
+				"c1":  2,
+				"s":   177.51,
+				"a":   177.51,
+			}},
+		},
+		// 56
+		{
+			sql: "SELECT b, count(*) FILTER (WHERE ok) as c, collect(a ORDER BY a DESC) FILTER (WHERE ok) as l, max_time() FILTER (WHERE ok) as mt FROM test GROUP BY TumblingWindow(ss, 10), b",
+			data: &xsql.GroupedTuplesSet{
+				Groups: []*xsql.GroupedTuples{
+					{
+						Content: []xsql.Row{
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 1, "b": "x", "ok": true}, Timestamp: time.UnixMilli(1000)},
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 2, "b": "x", "ok": false}, Timestamp: time.UnixMilli(3000)},
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 3, "b": "x", "ok": true}, Timestamp: time.UnixMilli(2000)},
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 4, "b": "x"}, Timestamp: time.UnixMilli(4000)},
+						},
+					},
+					{
+						Content: []xsql.Row{
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 5, "b": "y", "ok": false}, Timestamp: time.UnixMilli(5000)},
+						},
+					},
+				},
+			},
+			// the rows failing the filter are not counted and the null condition is treated as false
+			result: []map[string]interface{}{{
+				"b":  "x",
+				"c":  2,
+				"l":  []interface{}{3, 1},
+				"mt": time.UnixMilli(2000),
+			}, {
+				"b": "y",
+				"c": 0,
+				"l": []interface{}{},
+			}},
+		},
+		// 57
+		{
+			sql: "SELECT b, iqr(a) AS r FROM test GROUP BY TumblingWindow(ss, 10), b",
+			data: &xsql.GroupedTuplesSet{
+				Groups: []*xsql.GroupedTuples{
+					{
+						Content: []xsql.Row{
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 3, "b": "x"}},
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 100, "b": "x"}},
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 1, "b": "x"}},
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 5.0, "b": "x"}},
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"b": "x"}},
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 2, "b": "x"}},
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 4, "b": "x"}},
+						},
+					},
+					{
+						Content: []xsql.Row{
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 7, "b": "y"}},
+						},
+					},
+				},
+			},
+			// the group with a single value is too small to compute
+			result: []map[string]interface{}{{
+				"b": "x",
+				"r": 2.5,
+			}, {
+				"b": "y",
+			}},
+		},
+		// 58
+		{
+			sql: "SELECT b, collect(DISTINCT a) AS d, collect(a ORDER BY ts DESC) AS l, collect(DISTINCT a ORDER BY ts DESC) AS o, count(DISTINCT a) AS c FROM test GROUP BY TumblingWindow(ss, 10), b",
+			data: &xsql.GroupedTuplesSet{
+				Groups: []*xsql.GroupedTuples{
+					{
+						Content: []xsql.Row{
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 3, "b": "x", "ts": 1}},
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 1, "b": "x", "ts": 2}},
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 3, "b": "x", "ts": 3}},
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 2, "b": "x", "ts": 4}},
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 1, "b": "x", "ts": 4}},
+						},
+					},
+					{
+						Content: []xsql.Row{
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 5, "b": "y", "ts": 1}},
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 5, "b": "y", "ts": 2}},
+						},
+					},
+				},
+			},
+			// the rows of the same ts keep the arrival order, and the first seen values are kept after sorting
+			result: []map[string]interface{}{{
+				"b": "x",
+				"d": []interface{}{3, 1, 2},
+				"l": []interface{}{2, 1, 3, 1, 3},
+				"o": []interface{}{2, 1, 3},
+				"c": 3,
+			}, {
+				"b": "y",
+				"d": []interface{}{5},
+				"l": []interface{}{5, 5},
+				"o": []interface{}{5},
+				"c": 1,
+			}},
+		},
+		// 59
+		{
+			sql: "SELECT b, skewness(a) AS s, kurtosis(a) AS k FROM test GROUP BY TumblingWindow(ss, 10), b",
+			data: &xsql.GroupedTuplesSet{
+				Groups: []*xsql.GroupedTuples{
+					{
+						Content: []xsql.Row{
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 1, "b": "x"}},
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 6, "b": "x"}},
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 1.0, "b": "x"}},
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"b": "x"}},
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 1, "b": "x"}},
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 1, "b": "x"}},
+						},
+					},
+					{
+						Content: []xsql.Row{
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 3, "b": "y"}},
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 3, "b": "y"}},
+						},
+					},
+				},
+			},
+			// the values without variance have no skewness or kurtosis
+			result: []map[string]interface{}{{
+				"b": "x",
+				"s": 1.5,
+				"k": 0.25,
+			}, {
+				"b": "y",
+			}},
+		},
+		// 60
+		{
+			sql: "SELECT interpolate_at(ts, v, 1500) AS mid FROM test GROUP BY TumblingWindow(ss, 10)",
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"ts": 1000, "v": 10}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"ts": 2000, "v": 20.0}},
+				},
+			},
+			result: []map[string]interface{}{{
+				"mid": 15.0,
+			}},
+		},
+		// 61
+		{
+			sql: "SELECT find_gaps(seq, 1) AS gaps FROM test GROUP BY TumblingWindow(ss, 10)",
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"seq": 1}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"seq": 2}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"seq": 4}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"seq": 5}},
+				},
+			},
+			result: []map[string]interface{}{{
+				"gaps": []interface{}{int64(3)},
+			}},
+		},
+		// 62
+		{
+			sql: "SELECT count(DISTINCT id) AS c, count(DISTINCT id, b) AS cc, count(id) AS n FROM test GROUP BY TumblingWindow(ss, 10)",
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": "d1", "b": 1}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": "d2", "b": 1}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": "d1", "b": 2}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": "d1", "b": 1}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"b": 3}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": "d3"}},
+				},
+			},
+			// the rows with any null column are excluded from the distinct set
+			result: []map[string]interface{}{{
+				"c":  3,
+				"cc": 3,
+				"n":  5,
+			}},
+		},
+		// 63
+		{
+			sql: "SELECT b, count(DISTINCT id) AS c, count(DISTINCT id, f) AS cc FROM test GROUP BY TumblingWindow(ss, 10), b",
+			data: &xsql.GroupedTuplesSet{
+				Groups: []*xsql.GroupedTuples{
+					{
+						Content: []xsql.Row{
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": "d1", "b": "x", "f": 1}},
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": "d1", "b": "x", "f": 1}},
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": "d1", "b": "x", "f": 2}},
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": "d2", "b": "x"}},
+						},
+					},
+					{
+						Content: []xsql.Row{
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"b": "y", "f": 1}},
+						},
+					},
+				},
+			},
+			result: []map[string]interface{}{{
+				"b":  "x",
+				"c":  2,
+				"cc": 2,
+			}, {
+				"b":  "y",
+				"c":  0,
+				"cc": 0,
+			}},
+		},
+		// 64
+		{
+			sql: "SELECT autocorr(v, 3) AS period FROM test GROUP BY TumblingWindow(ss, 10)",
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"v": 1}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"v": 2}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"v": 3}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"v": 1}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"v": 2}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"v": 3}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"v": 1}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"v": 2}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"v": 3}},
+				},
+			},
+			result: []map[string]interface{}{{
+				"period": 1.0,
+			}},
+		},
+		// 65
+		{
+			sql: "SELECT bit_or(flags) AS o, bit_and(flags) AS a, bit_xor(flags) AS x FROM test GROUP BY TumblingWindow(ss, 10)",
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"flags": 7}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"flags": 5}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"flags": 12}},
+				},
+			},
+			result: []map[string]interface{}{{
+				"o": int64(15),
+				"a": int64(4),
+				"x": int64(14),
+			}},
+		},
+		// 66
+		{
+			sql: "SELECT bit_or(test.flags) AS o, bit_and(src2.flags) AS a FROM test Inner Join src2 on test.id = src2.id GROUP BY TumblingWindow(ss, 10)",
+			data: &xsql.JoinTuples{
+				Content: []*xsql.JoinTuple{
+					{
+						Tuples: []xsql.Row{
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1, "flags": 1}},
+							&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 1, "flags": 3}},
+						},
+					},
+					{
+						Tuples: []xsql.Row{
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 2, "flags": 4}},
+							&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 2, "flags": 6}},
+						},
+					},
+				},
+			},
+			result: []map[string]interface{}{{
+				"o": int64(5),
+				"a": int64(2),
+			}},
+		},
+		// 67
+		{
+			sql: "SELECT time_in_state(status, ts) AS last, time_in_state(status, ts, window_end()) AS dur FROM test GROUP BY TumblingWindow(ss, 10)",
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"status": "run", "ts": 1541152486013}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"status": "run", "ts": 1541152488013}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"status": "stop", "ts": 1541152490013}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"status": "stop", "ts": 1541152492013}},
+				},
+				WindowRange: xsql.NewWindowRange(1541152486013, 1541152496013, 1541152496013),
+			},
+			result: []map[string]interface{}{{
+				"last": int64(2000),
+				"dur":  int64(6000),
+			}},
+		},
+		// 68
+		{
+			sql: "SELECT histogram_pct(v, array_create(10, 20, 30)) AS h FROM test GROUP BY TumblingWindow(ss, 10)",
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"v": 5}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"v": 12}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"v": 18.5}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"v": 20}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"v": 22}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"v": 25}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"v": 29}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"v": 100}},
+				},
+			},
+			result: []map[string]interface{}{{
+				"h": []interface{}{0.125, 0.25, 0.5, 0.125},
+			}},
+		},
+	}
+	fmt.Printf("The test bucket size is %d.\n\n", len(tests))
+	contextLogger := conf.Log.WithField("rule", "TestProjectPlan_AggFuncs")
+	ctx := context.WithValue(context.Background(), context.LoggerKey, contextLogger)
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			stmt, err := xsql.NewParser(strings.NewReader(tt.sql)).Parse()
+			require.NoError(t, err)
+			pp := &ProjectOp{SendMeta: true, IsAggregate: true, PreferIntResults: tt.preferInt}
+			parseStmt(pp, stmt.Fields)
+			fv, afv := xsql.NewFunctionValuersForOp(nil)
+			opResult := pp.Apply(ctx, tt.data, fv, afv)
+			result, err := parseResult(opResult, pp.IsAggregate)
+			require.NoError(t, err)
+			require.Equal(t, tt.result, result)
+		})
+	}
+}
+
+func TestProjectPlan_FirstLastValueSendNil(t *testing.T) {
+	sql := "SELECT b, first_value(a) AS f, last_value(a) AS l FROM test GROUP BY TumblingWindow(ss, 10), b"
+	data := &xsql.GroupedTuplesSet{
+		Groups: []*xsql.GroupedTuples{
+			{
+				Content: []xsql.Row{
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 1, "b": "x"}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 2, "b": "x"}},
+				},
+			},
+			{
+				Content: []xsql.Row{
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": nil, "b": "y"}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"b": "y"}},
+				},
+			},
+		},
+	}
+	contextLogger := conf.Log.WithField("rule", "TestProjectPlan_FirstLastValueSendNil")
+	ctx := context.WithValue(context.Background(), context.LoggerKey, contextLogger)
+	stmt, err := xsql.NewParser(strings.NewReader(sql)).Parse()
+	require.NoError(t, err)
+	pp := &ProjectOp{SendNil: true, IsAggregate: true}
+	parseStmt(pp, stmt.Fields)
+	fv, afv := xsql.NewFunctionValuersForOp(nil)
+	result, err := parseResult(pp.Apply(ctx, data, fv, afv), pp.IsAggregate)
+	require.NoError(t, err)
+	require.Equal(t, []map[string]interface{}{
+		{"b": "x", "f": 1, "l": 2},
+		{"b": "y", "f": nil, "l": nil},
+	}, result)
+}
+
+func TestProjectPlanError(t *testing.T) {
+	tests := []struct {
+		sql    string
+		data   interface{}
+		result interface{}
+	}{
+		// 0
+		{
+			sql:    "SELECT a FROM test",
+			data:   errors.New("an error from upstream"),
+			result: errors.New("an error from upstream"),
+		},
+		// 1
+		{
+			sql: "SELECT a * 5 FROM test",
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"a": "val_a",
+				},
+			},
+			result: errors.New("run Select error: expr: binaryExpr:{ $$default.a * 5 } meet error, err:invalid operation string(val_a) * int64(5)"),
+		},
+		// 2
+		{
+			sql: `SELECT a[0]->b AS ab FROM test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"a": "common string",
+				},
+			},
+			result: errors.New("run Select error: alias: ab expr: binaryExpr:{ binaryExpr:{ $$default.a[0] } -> jsonFieldName:b } meet error, err:invalid operation string(common string) [] *xsql.BracketEvalResult(&{0 0})"),
+		},
+		// 3
+		{
+			sql: `SELECT round(a) as r FROM test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"a": "common string",
+				},
+			},
+			result: errors.New("run Select error: alias: r expr: Call:{ name:round, args:[$$default.a] } meet error, err:call func round error: cannot convert string(common string) to float64"),
+		},
+		// 5
+		{
+			sql: "SELECT avg(a) as avg FROM test Inner Join test1 on test.id = test1.id GROUP BY TumblingWindow(ss, 10), test1.color",
+			data: &xsql.GroupedTuplesSet{
+				Groups: []*xsql.GroupedTuples{
+					{
+						Content: []xsql.Row{
+							&xsql.JoinTuple{
+								Tuples: []xsql.Row{
+									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1, "a": 122.33}},
+									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 1, "color": "w2"}},
+								},
+							},
+							&xsql.JoinTuple{
+								Tuples: []xsql.Row{
+									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1, "a": 68.54}},
+									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 1, "color": "w2"}},
+								},
+							},
+							&xsql.JoinTuple{
+								Tuples: []xsql.Row{
+									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 4, "a": "dde"}},
+									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 4, "color": "w2"}},
+								},
+							},
+							&xsql.JoinTuple{
+								Tuples: []xsql.Row{
+									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 5, "a": 177.54}},
+									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 5, "color": "w2"}},
+								},
+							},
+						},
+					},
+					{
+						Content: []xsql.Row{
+							&xsql.JoinTuple{
+								Tuples: []xsql.Row{
+									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 2, "a": 89.03}},
+									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 2, "color": "w1"}},
+								},
+							},
+							&xsql.JoinTuple{
+								Tuples: []xsql.Row{
+									&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 4, "a": 14.6}},
+									&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 4, "color": "w1"}},
+								},
+							},
+						},
+					},
+				},
+			},
+			result: errors.New("run Select error: alias: avg expr: Call:{ name:avg, args:[$$default.a] } meet error, err:call func avg error: requires float64 but found string(dde)"),
+		},
+		// 6
+		{
+			sql: "SELECT sum(a) as sum FROM test GROUP BY TumblingWindow(ss, 10)",
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{
+						Emitter: "src1",
+						Message: xsql.Message{"a": 53},
+					}, &xsql.Tuple{
+						Emitter: "src1",
+						Message: xsql.Message{"a": "ddd"},
+					}, &xsql.Tuple{
+						Emitter: "src1",
+						Message: xsql.Message{"a": 123123},
+					},
+				},
+			},
+
+			result: errors.New("run Select error: alias: sum expr: Call:{ name:sum, args:[$$default.a] } meet error, err:call func sum error: requires int but found string(ddd)"),
+		},
+		// 7
+		{
+			sql: `SELECT a[0]->b AS ab FROM test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"a": []map[string]interface{}(nil),
+				},
+			},
+			result: errors.New("run Select error: alias: ab expr: binaryExpr:{ binaryExpr:{ $$default.a[0] } -> jsonFieldName:b } meet error, err:out of index: 0 of 0"),
+		},
+		// 8
+		{
+			sql: `SELECT a[*]->temp * 2 AS doubled FROM test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"a": []interface{}{
+						map[string]interface{}{"temp": 10},
+						map[string]interface{}{"temp": "hot"},
+					},
+				},
+			},
+			result: errors.New("run Select error: alias: doubled expr: arrayMapExpr:{ array:{ $$default.a }, expr:{ binaryExpr:{ binaryExpr:{ [*] -> jsonFieldName:temp } * 2 } } } meet error, err:evaluate array element 1 error: invalid operation string(hot) * int64(2)"),
+		},
+		// 9
+		{
+			sql: `SELECT a[*]->temp AS temps FROM test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"a": "common string",
+				},
+			},
+			result: errors.New("run Select error: alias: temps expr: arrayMapExpr:{ array:{ $$default.a }, expr:{ binaryExpr:{ [*] -> jsonFieldName:temp } } } meet error, err:[*] requires array but found string(common string)"),
+		},
+		// 10
+		{
+			sql: "SELECT collect_concat(a) AS c FROM test GROUP BY TumblingWindow(ss, 10)",
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"a": []interface{}{1, 2}}},
+					&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"a": 3}},
+				},
+			},
+			result: errors.New("run Select error: alias: c expr: Call:{ name:collect_concat, args:[$$default.a] } meet error, err:call func collect_concat error: requires array but found int(3)"),
+		},
+		// 11
+		{
+			sql: "SELECT regr_slope(y, x) AS s FROM test GROUP BY TumblingWindow(ss, 10)",
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"x": 1, "y": 2}},
+					&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"x": "a", "y": 3}},
+				},
+			},
+			result: errors.New("run Select error: alias: s expr: Call:{ name:regr_slope, args:[$$default.y, $$default.x] } meet error, err:call func regr_slope error: requires number but found string(a)"),
+		},
+		// 12
+		{
+			sql: `SELECT a?->b->c AS abc FROM test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"a": map[string]interface{}{
+						"b": []interface{}{1, 2},
+					},
+				},
+			},
+			result: errors.New("run Select error: alias: abc expr: binaryExpr:{ binaryExpr:{ $$default.a ?-> jsonFieldName:b } -> jsonFieldName:c } meet error, err:the result [1 2] is not a type of map[string]interface{}"),
+		},
+		// 13
+		{
+			sql: "SELECT deduplicate(a * 2, false) as c1 FROM test GROUP BY TumblingWindow(ss, 10)",
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 1}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": "x"}},
+				},
+			},
+			result: errors.New("run Select error: alias: c1 expr: Call:{ name:deduplicate, args:[*, binaryExpr:{ $$default.a * 2 }, false] } meet error, err:call func deduplicate error: invalid operation string(x) * int64(2)"),
+		},
+		// 14
+		{
+			sql: "SELECT window_start(\"start\") as ws FROM test GROUP BY TumblingWindow(ss, 10)",
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 1}},
+				},
+				WindowRange: xsql.NewWindowRange(1541152486013, 1541152487013, 1541152487013),
+			},
+			result: errors.New("run Select error: alias: ws expr: Call:{ name:window_start, args:[start] } meet error, err:invalid layout \"start\" which has no time element"),
+		},
+		// 15
+		{
+			sql: `SELECT a[-3]->b AS ab FROM test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"a": []interface{}{
+						map[string]interface{}{"b": "hello1"},
+						map[string]interface{}{"b": "hello2"},
+					},
+				},
+			},
+			result: errors.New("run Select error: alias: ab expr: binaryExpr:{ binaryExpr:{ $$default.a[-3] } -> jsonFieldName:b } meet error, err:out of index: -3 of 2"),
+		},
+		// 16
+		{
+			sql: "SELECT percentile(a, 0.95) as p95 FROM test GROUP BY TumblingWindow(ss, 10)",
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 122.33}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": "dde"}},
+				},
+			},
+			result: errors.New("run Select error: alias: p95 expr: Call:{ name:percentile, args:[$$default.a, 0.950000] } meet error, err:call func percentile error: requires float64 but found string(dde)"),
+		},
+		// 17
+		{
+			sql: "SELECT median(a) as m FROM test GROUP BY TumblingWindow(ss, 10)",
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 122.33}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": "dde"}},
+				},
+			},
+			result: errors.New("run Select error: alias: m expr: Call:{ name:median, args:[$$default.a] } meet error, err:call func median error: requires float64 but found string(dde)"),
+		},
+		// 18
+		{
+			sql: `SELECT a AS payload, b AS payload.hum FROM test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{"a": 20, "b": 60},
+			},
+			result: errors.New("run Select error: alias payload.hum conflicts with the field payload"),
+		},
+		// 19
+		{
+			sql: `SELECT a AS payload.temp, b AS payload.temp.hum FROM test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{"a": 20, "b": 60},
+			},
+			result: errors.New("run Select error: alias payload.temp.hum conflicts with the field payload.temp"),
+		},
+		// 20
+		{
+			sql: `SELECT a AS payload.temp.hum, b AS payload.temp FROM test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{"a": 20, "b": 60},
+			},
+			result: errors.New("run Select error: alias payload.temp conflicts with the field payload.temp"),
+		},
+		// 21
+		{
+			sql: "SELECT mode_binned(a, 5) AS m FROM test GROUP BY TumblingWindow(ss, 10)",
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 122.33}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": "dde"}},
+				},
+			},
+			result: errors.New("run Select error: alias: m expr: Call:{ name:mode_binned, args:[$$default.a, 5] } meet error, err:call func mode_binned error: requires number but found string(dde)"),
+		},
+		// 22
+		{
+			sql: "SELECT count(*) FILTER (WHERE a) AS c FROM test GROUP BY TumblingWindow(ss, 10)",
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": true}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": "dde"}},
+				},
+			},
+			result: errors.New("run Select error: alias: c expr: Call:{ name:count, args:[*], filter:{ $$default.a } } meet error, err:call count filter error: invalid condition that returns non-bool value string(dde)"),
+		},
+		// 23
+		{
+			sql: `SELECT parse_json(s) AS p FROM test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{"s": `{"a":`},
+			},
+			result: errors.New("run Select error: alias: p expr: Call:{ name:parse_json, args:[$$default.s] } meet error, err:call func parse_json error: fail to parse json: unexpected end of JSON input"),
+		},
+		// 24
+		{
+			sql: `SELECT to_json(a) AS j FROM test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{"a": map[string]interface{}{"b": math.NaN()}},
+			},
+			result: errors.New("run Select error: alias: j expr: Call:{ name:to_json, args:[$$default.a] } meet error, err:call func to_json error: fail to convert map[b:NaN] to json: json: unsupported value: NaN"),
+		},
+		// 25
+		{
+			sql: "SELECT collect(DISTINCT a ORDER BY ts) AS l FROM test GROUP BY TumblingWindow(ss, 10)",
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 1, "ts": 1}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 2, "ts": "dde"}},
+				},
+			},
+			result: errors.New("run Select error: alias: l expr: Call:{ name:collect, args:[$$default.a], distinct:true } meet error, err:call collect order by error: incompatible types for comparison: int and string"),
+		},
+		// 26
+		{
+			sql: `SELECT split_value(a, ",") as r FROM test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"a": 12.5,
+				},
+			},
+			result: errors.New("run Select error: alias: r expr: Call:{ name:split_value, args:[$$default.a, ,] } meet error, err:call func split_value error: cannot convert float64(12.5) to string"),
+		},
+		// 27
+		{
+			sql: `SELECT a[0:4:k] AS ab FROM test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"a": []interface{}{1, 2, 3, 4},
+					"k": 0,
+				},
+			},
+			result: errors.New("run Select error: alias: ab expr: binaryExpr:{ $$default.a[ColonExpr:{ start:{ 0 }, end:{ 4 }, step:{ $$default.k } }] } meet error, err:colon step must be a positive integer but found 0"),
+		},
+		// 28
+		{
+			sql: "SELECT bit_or(a) as flags FROM test GROUP BY TumblingWindow(ss, 10)",
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{
+						Emitter: "src1",
+						Message: xsql.Message{"a": 53},
+					}, &xsql.Tuple{
+						Emitter: "src1",
+						Message: xsql.Message{"a": "ddd"},
+					},
+				},
+			},
+
+			result: errors.New("run Select error: alias: flags expr: Call:{ name:bit_or, args:[$$default.a] } meet error, err:call func bit_or error: requires int but found string(ddd)"),
+		},
+	}
+	fmt.Printf("The test bucket size is %d.\n\n", len(tests))
+	contextLogger := conf.Log.WithField("rule", "TestProjectPlanError")
+	ctx := context.WithValue(context.Background(), context.LoggerKey, contextLogger)
+	for i, tt := range tests {
+		stmt, _ := xsql.NewParser(strings.NewReader(tt.sql)).Parse()
+		pp := &ProjectOp{SendMeta: true, IsAggregate: xsql.WithAggFields(stmt)}
+		parseStmt(pp, stmt.Fields)
+		fv, afv := xsql.NewFunctionValuersForOp(nil)
+		opResult := pp.Apply(ctx, tt.data, fv, afv)
+		if !reflect.DeepEqual(tt.result, opResult) {
+			t.Errorf("%d. %q\n\nresult mismatch:\n\nexp=%#v\n\ngot=%#v\n\n", i, tt.sql, tt.result, opResult)
+		}
+	}
+}
+
+func TestProjectPlan_SendNil(t *testing.T) {
+	tests := []struct {
+		sql    string
+		data   *xsql.Tuple
+		result []map[string]interface{}
+	}{
+		{ // 0
+			sql: "SELECT a FROM test",
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"a": "val_a",
+				},
+				Metadata: xsql.Metadata{
+					"id":    45,
+					"other": "mock",
+				},
+			},
+			result: []map[string]interface{}{{
+				"a": "val_a",
+				"__meta": xsql.Metadata{
+					"id":    45,
+					"other": "mock",
+				},
+			}},
+		},
+		{ // 1
+			sql: "SELECT b FROM test",
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"a": "val_a",
+				},
+			},
+			result: []map[string]interface{}{{"b": nil}},
+		},
+		// Schemaless may return a message without selecting column
+		{ // 3
+			sql: "SELECT ts FROM test",
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"a":   "val_a",
+					"ts2": cast.TimeFromUnixMilli(1568854573431),
+				},
+			},
+			result: []map[string]interface{}{{"ts": nil}},
+		},
+		{ // 4
+			sql: "SELECT A FROM test",
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"a": "val_a",
+				},
+			},
+			result: []map[string]interface{}{{
+				"A": "val_a",
+			}},
+		},
+		// 5
+		{
+			sql: `SELECT "value" FROM test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{},
+			},
+			result: []map[string]interface{}{{
+				"kuiper_field_0": "value",
+			}},
+		},
+		// 6
+		{
+			sql: `SELECT 3.4 FROM test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{},
+			},
+			result: []map[string]interface{}{{
+				"kuiper_field_0": 3.4,
+			}},
+		},
+		// 7
+		{
+			sql: `SELECT 5 FROM test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{},
+			},
+			result: []map[string]interface{}{{
+				"kuiper_field_0": int64(5),
+			}},
+		},
+		// 8
+		{
+			sql: `SELECT a, "value" AS b FROM test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"a": "val_a",
+				},
+			},
+			result: []map[string]interface{}{{
+				"a": "val_a",
+				"b": "value",
+			}},
+		},
+		// 9
+		{
+			sql: `SELECT a, "value" AS b, 3.14 as Pi, 0 as Zero FROM test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"a": "val_a",
+				},
+			},
+			result: []map[string]interface{}{{
+				"a":    "val_a",
+				"b":    "value",
+				"Pi":   3.14,
+				"Zero": int64(0),
+			}},
+		},
+		// 11
+		{
+			sql: `SELECT a->b AS ab FROM test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"a": map[string]interface{}(nil),
+				},
+			},
+			result: []map[string]interface{}{{"ab": nil}},
+		},
+		// 14
+		{
+			sql: `SELECT a[0]->b AS ab FROM test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"a": []interface{}{
+						map[string]interface{}{"b": "hello1"},
+						map[string]interface{}{"b": "hello2"},
+					},
+				},
+			},
+			result: []map[string]interface{}{{
+				"ab": "hello1",
+			}},
+		},
+		// 15
+		{
+			sql: `SELECT a[0]->b AS ab FROM test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"a": []map[string]interface{}{
+						{"b": "hello1"},
+						{"b": "hello2"},
+					},
+				},
+			},
+			result: []map[string]interface{}{{
+				"ab": "hello1",
+			}},
+		},
+		// 16
+		{
+			sql: `SELECT a[2:4] AS ab FROM test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"a": []map[string]interface{}{
+						{"b": "hello1"},
+						{"b": "hello2"},
+						{"b": "hello3"},
+						{"b": "hello4"},
+						{"b": "hello5"},
+					},
+				},
+			},
+			result: []map[string]interface{}{{
+				"ab": []map[string]interface{}{
+					{"b": "hello3"},
+					{"b": "hello4"},
+				},
+			}},
+		},
+		// 17
+		{
+			sql: `SELECT a[2:] AS ab FROM test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"a": []map[string]interface{}{
+						{"b": "hello1"},
+						{"b": "hello2"},
+						{"b": "hello3"},
+						{"b": "hello4"},
+						{"b": "hello5"},
+					},
+				},
+			},
+			result: []map[string]interface{}{{
+				"ab": []map[string]interface{}{
+					{"b": "hello3"},
+					{"b": "hello4"},
+					{"b": "hello5"},
+				},
+			}},
+		},
+		// 18
+		{
+			sql: `SELECT a[2:] AS ab FROM test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"a": []interface{}{
+						true, false, true, false, true, true,
+					},
+				},
+			},
+			result: []map[string]interface{}{{
 				"ab": []interface{}{
 					true, false, true, true,
 				},
-			}},
+			}},
+		},
+		// 19
+		{
+			sql: `SELECT a[:4] AS ab FROM test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"a": []interface{}{
+						true, false, true, false, true, true,
+					},
+				},
+			},
+			result: []map[string]interface{}{{
+				"ab": []interface{}{
+					true, false, true, false,
+				},
+			}},
+		},
+		// 20
+		{
+			sql: `SELECT a[:4] AS ab FROM test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"a": []interface{}{
+						3.14, 3.141, 3.1415, 3.14159, 3.141592, 3.1415926,
+					},
+				},
+			},
+			result: []map[string]interface{}{{
+				"ab": []interface{}{
+					3.14, 3.141, 3.1415, 3.14159,
+				},
+			}},
+		},
+		// 21
+		{
+			sql: `SELECT a->b[:4] AS ab FROM test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"a": map[string]interface{}{
+						"b": []float64{3.14, 3.141, 3.1415, 3.14159, 3.141592, 3.1415926},
+					},
+				},
+			},
+			result: []map[string]interface{}{{
+				"ab": []float64{
+					3.14, 3.141, 3.1415, 3.14159,
+				},
+			}},
+		},
+		// 22
+		{
+			sql: `SELECT a->b[0:1] AS ab FROM test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"a": map[string]interface{}{
+						"b": []float64{3.14, 3.141, 3.1415, 3.14159, 3.141592, 3.1415926},
+					},
+				},
+			},
+			result: []map[string]interface{}{{
+				"ab": []float64{
+					3.14,
+				},
+			}},
+		},
+		// 23
+		{
+			sql: `SELECT a->c->d AS f1 FROM test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"a": map[string]interface{}{
+						"b": "hello",
+						"c": map[string]interface{}{
+							"d": 35.2,
+						},
+					},
+				},
+			},
+			result: []map[string]interface{}{{
+				"f1": 35.2,
+			}},
+		},
+		// 24
+		{
+			sql: `SELECT a->c->d AS f1 FROM test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"a": map[string]interface{}{
+						"b": "hello",
+						"c": map[string]interface{}{
+							"e": 35.2,
+						},
+					},
+				},
+			},
+			result: []map[string]interface{}{{"f1": nil}},
+		},
+		// 25
+		{
+			sql: `SELECT a->c->d AS f1 FROM test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"a": map[string]interface{}{
+						"b": "hello",
+					},
+				},
+			},
+			result: []map[string]interface{}{{"f1": nil}},
+		},
+		// 26
+		// The int type is not supported yet, the json parser returns float64 for int values
+		{
+			sql: `SELECT a->c->d AS f1 FROM test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"a": map[string]interface{}{
+						"b": "hello",
+						"c": map[string]interface{}{
+							"d": float64(35),
+						},
+					},
+				},
+			},
+			result: []map[string]interface{}{{
+				"f1": float64(35),
+			}},
+		},
+		// 27
+		{
+			sql: "SELECT a FROM test",
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{},
+			},
+			result: []map[string]interface{}{
+				{"a": nil},
+			},
+		},
+		// 28
+		{
+			sql: "SELECT * FROM test",
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{},
+			},
+			result: []map[string]interface{}{
+				{},
+			},
+		},
+		// 29
+		{
+			sql: `SELECT * FROM test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"a": map[string]interface{}{
+						"b": "hello",
+						"c": map[string]interface{}{
+							"d": 35.2,
+						},
+					},
+				},
+			},
+			result: []map[string]interface{}{{
+				"a": map[string]interface{}{
+					"b": "hello",
+					"c": map[string]interface{}{
+						"d": 35.2,
+					},
+				},
+			}},
+		},
+		// 30
+		{
+			sql: `SELECT * FROM test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"a": "val1",
+					"b": 3.14,
+				},
+			},
+			result: []map[string]interface{}{{
+				"a": "val1",
+				"b": 3.14,
+			}},
+		},
+		// 31
+		{
+			sql: `SELECT 3*4 AS f1 FROM test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{},
+			},
+			result: []map[string]interface{}{{
+				"f1": int64(12),
+			}},
+		},
+		// 32
+		{
+			sql: `SELECT 4.5*2 AS f1 FROM test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{},
+			},
+			result: []map[string]interface{}{{
+				"f1": float64(9),
+			}},
+		},
+		// 33
+		{
+			sql: "SELECT `a.b.c` FROM test",
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"a.b.c": "val_a",
+				},
+			},
+			result: []map[string]interface{}{{
+				"a.b.c": "val_a",
+			}},
+		},
+		// 34
+		{
+			sql: `SELECT CASE a WHEN 10 THEN "true" END AS b FROM test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"a": int64(10),
+				},
+			},
+			result: []map[string]interface{}{{
+				"b": "true",
+			}},
+		},
+		// 35
+		{
+			sql: `SELECT a->b AS ab, *, abs(f1) FROM test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"a": map[string]interface{}{
+						"b": "test",
+					},
+					"b":  "b",
+					"f1": -12,
+				},
+			},
+			result: []map[string]interface{}{{
+				"a": map[string]interface{}{
+					"b": "test",
+				},
+				"ab":  "test",
+				"abs": 12,
+				"b":   "b",
+				"f1":  -12,
+			}},
+		},
+		// 36
+		{
+			sql: `SELECT * EXCEPT(a, b) from test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"a": map[string]interface{}{
+						"b": "test",
+					},
+					"b": "b",
+					"c": "c",
+				},
+			},
+			result: []map[string]interface{}{
+				{
+					"c": "c",
+				},
+			},
+		},
+		// 37
+		{
+			sql: `SELECT * REPLACE(a->b as a) from test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"a": map[string]interface{}{
+						"b": "test",
+					},
+					"b": "b",
+					"c": "c",
+				},
+			},
+			result: []map[string]interface{}{
+				{
+					"a": "test",
+					"b": "b",
+					"c": "c",
+				},
+			},
+		},
+		// 38
+		{
+			sql: `SELECT * EXCEPT(c) REPLACE("test" as b, a->b as a) from test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"a": map[string]interface{}{
+						"b": "test",
+					},
+					"b": "b",
+					"c": 1,
+				},
+			},
+			result: []map[string]interface{}{
+				{
+					"a": "test",
+					"b": "test",
+				},
+			},
+		},
+		{
+			sql: `SELECT a, NULL as placeholder, a IS NULL as n, a IS NOT NULL as nn FROM test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"a": 1,
+				},
+			},
+			result: []map[string]interface{}{{
+				"a":           1,
+				"placeholder": nil,
+				"n":           false,
+				"nn":          true,
+			}},
+		},
+		{
+			sql: `SELECT a, meta(*) AS m FROM test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"a": 1,
+				},
+			},
+			result: []map[string]interface{}{{
+				"a": 1,
+				"m": nil,
+			}},
+		},
+		{
+			sql: `SELECT a, meta(*) AS m FROM test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"a": 1,
+				},
+				Metadata: xsql.Metadata{"topic": "t1"},
+			},
+			result: []map[string]interface{}{{
+				"a":      1,
+				"m":      map[string]interface{}{"topic": "t1"},
+				"__meta": xsql.Metadata{"topic": "t1"},
+			}},
+		},
+	}
+
+	contextLogger := conf.Log.WithField("rule", "TestProjectPlan_Apply1")
+	ctx := context.WithValue(context.Background(), context.LoggerKey, contextLogger)
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			stmt, err := xsql.NewParser(strings.NewReader(tt.sql)).Parse()
+			require.NoError(t, err)
+			pp := &ProjectOp{SendMeta: true, SendNil: true, IsAggregate: xsql.WithAggFields(stmt)}
+			parseStmt(pp, stmt.Fields)
+			fv, afv := xsql.NewFunctionValuersForOp(nil)
+			opResult := pp.Apply(ctx, tt.data, fv, afv)
+			result, err := parseResult(opResult, pp.IsAggregate)
+			require.NoError(t, err)
+			require.Equal(t, tt.result, result)
+		})
+	}
+}
+
+func TestProjectSlice(t *testing.T) {
+	tests := []struct {
+		name   string
+		sql    string
+		data   any
+		result any
+	}{
+		{
+			name: "normal",
+			sql:  `SELECT c, a FROM test`,
+			data: &xsql.SliceTuple{
+				SourceContent: model.SliceVal{"a0", "b0", "c0"},
+			},
+			result: &xsql.SliceTuple{
+				SourceContent: model.SliceVal{"c0", "a0"},
+			},
+		},
+		{
+			name: "agg",
+			sql:  `SELECT b FROM test`,
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.SliceTuple{
+						SourceContent: model.SliceVal{"a0", "b0", "c0"},
+					},
+					&xsql.SliceTuple{
+						SourceContent: model.SliceVal{"a1", "b1", "c1"},
+					},
+					&xsql.SliceTuple{
+						SourceContent: model.SliceVal{"a2", "b2", "c2"},
+					},
+				},
+			},
+			result: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.SliceTuple{
+						SourceContent: model.SliceVal{"b0"},
+					},
+					&xsql.SliceTuple{
+						SourceContent: model.SliceVal{"b1"},
+					},
+					&xsql.SliceTuple{
+						SourceContent: model.SliceVal{"b2"},
+					},
+				},
+			},
 		},
-		// 19
 		{
-			sql: `SELECT a[:4] AS ab FROM test`,
+			name: "alias",
+			sql:  `SELECT concat(a,b) as ab, c FROM test`,
+			data: &xsql.SliceTuple{
+				SourceContent: model.SliceVal{"a0", "b0", "c0"},
+			},
+			result: &xsql.SliceTuple{
+				SourceContent: model.SliceVal{"a0b0", "c0"},
+			},
+		},
+	}
+	contextLogger := conf.Log.WithField("rule", "TestProjectPlan_Apply1")
+	ctx := context.WithValue(context.Background(), context.LoggerKey, contextLogger)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt, err := xsql.NewParser(strings.NewReader(tt.sql)).Parse()
+			require.NoError(t, err)
+			pp := &ProjectOp{IsAggregate: xsql.WithAggFields(stmt)}
+			parseStmtWithSlice(pp, stmt.Fields, true)
+			fv, afv := xsql.NewFunctionValuersForOp(nil)
+			opResult := pp.Apply(ctx, tt.data, fv, afv)
+			require.NoError(t, err)
+			require.Equal(t, tt.result, opResult)
+		})
+	}
+}
+
+func TestProjectTypeAnnotations(t *testing.T) {
+	contextLogger := conf.Log.WithField("rule", "TestProjectTypeAnnotations")
+	ctx := context.WithValue(context.Background(), context.LoggerKey, contextLogger)
+	stmt, err := xsql.NewParser(strings.NewReader(`SELECT a::bigint, b::float AS bf, c, d->e::string AS de, c::boolean AS hidden INVISIBLE FROM test`)).Parse()
+	require.NoError(t, err)
+	pp := &ProjectOp{IsAggregate: xsql.WithAggFields(stmt)}
+	parseStmt(pp, stmt.Fields)
+	require.Equal(t, map[string]ast.DataType{
+		"a":  ast.BIGINT,
+		"bf": ast.FLOAT,
+		"de": ast.STRINGS,
+	}, pp.TypeAnnotations())
+
+	fv, afv := xsql.NewFunctionValuersForOp(nil)
+	opResult := pp.Apply(ctx, &xsql.Tuple{
+		Emitter: "test",
+		Message: xsql.Message{
+			"a": "12",
+			"b": 3,
+			"c": 1,
+			"d": map[string]interface{}{"e": 5},
+		},
+	}, fv, afv)
+	result, err := parseResult(opResult, pp.IsAggregate)
+	require.NoError(t, err)
+	require.Equal(t, []map[string]interface{}{{
+		"a":  12,
+		"bf": float64(3),
+		"c":  1,
+		"de": "5",
+	}}, result)
+
+	opResult = pp.Apply(ctx, &xsql.Tuple{
+		Emitter: "test",
+		Message: xsql.Message{
+			"a": "abc",
+		},
+	}, fv, afv)
+	require.EqualError(t, opResult.(error), "run Select error: expr: castExpr:{ $$default.a::bigint } meet error, err:not supported type conversion, got error cannot convert string(abc) to int")
+}
+
+func TestProjectProfile(t *testing.T) {
+	contextLogger := conf.Log.WithField("rule", "TestProjectProfile")
+	ctx := context.WithValue(context.Background(), context.LoggerKey, contextLogger)
+	stmt, err := xsql.NewParser(strings.NewReader(`SELECT a + 1 AS x, upper(b) AS y, concat(b, "!"), c FROM test`)).Parse()
+	require.NoError(t, err)
+	data := &xsql.Tuple{
+		Emitter: "test",
+		Message: xsql.Message{
+			"a": 1,
+			"b": "hello",
+			"c": 2,
+		},
+	}
+	fv, afv := xsql.NewFunctionValuersForOp(nil)
+
+	pp := &ProjectOp{IsAggregate: xsql.WithAggFields(stmt)}
+	parseStmt(pp, stmt.Fields)
+	pp.Apply(ctx, data.Clone(), fv, afv)
+	require.Empty(t, pp.FieldTimings())
+
+	pp = &ProjectOp{IsAggregate: xsql.WithAggFields(stmt), Profile: true}
+	parseStmt(pp, stmt.Fields)
+	opResult := pp.Apply(ctx, data.Clone(), fv, afv)
+	result, err := parseResult(opResult, pp.IsAggregate)
+	require.NoError(t, err)
+	require.Equal(t, []map[string]interface{}{{
+		"x":      int64(2),
+		"y":      "HELLO",
+		"concat": "hello!",
+		"c":      2,
+	}}, result)
+	timings := pp.FieldTimings()
+	require.Len(t, timings, 3)
+	for _, name := range []string{"x", "y", "concat"} {
+		require.Greater(t, timings[name], int64(0), name)
+	}
+	// timings are accumulated
+	pp.Apply(ctx, data.Clone(), fv, afv)
+	require.Greater(t, pp.FieldTimings()["x"], timings["x"])
+}
+
+func TestProjectOutputSchema(t *testing.T) {
+	contextLogger := conf.Log.WithField("rule", "TestProjectOutputSchema")
+	ctx := context.WithValue(context.Background(), context.LoggerKey, contextLogger)
+	schema := []string{"total", "missing", "b", "a"}
+	tests := []struct {
+		name   string
+		sql    string
+		data   interface{}
+		result []map[string]interface{}
+	}{
+		{
+			name: "row",
+			sql:  `SELECT a, b, c, a + c AS total FROM test`,
 			data: &xsql.Tuple{
 				Emitter: "test",
 				Message: xsql.Message{
-					"a": []interface{}{
-						true, false, true, false, true, true,
-					},
+					"a": 1,
+					"b": "hello",
+					"c": 2,
 				},
 			},
 			result: []map[string]interface{}{{
-				"ab": []interface{}{
-					true, false, true, false,
-				},
+				"total":   int64(3),
+				"missing": nil,
+				"b":       "hello",
+				"a":       1,
 			}},
 		},
-		// 20
 		{
-			sql: `SELECT a[:4] AS ab FROM test`,
+			name: "wildcard",
+			sql:  `SELECT *, a + c AS total FROM test`,
 			data: &xsql.Tuple{
 				Emitter: "test",
 				Message: xsql.Message{
-					"a": []interface{}{
-						3.14, 3.141, 3.1415, 3.14159, 3.141592, 3.1415926,
-					},
+					"a": 1,
+					"c": 2,
+					"d": 4,
 				},
 			},
 			result: []map[string]interface{}{{
-				"ab": []interface{}{
-					3.14, 3.141, 3.1415, 3.14159,
+				"total":   int64(3),
+				"missing": nil,
+				"b":       nil,
+				"a":       1,
+			}},
+		},
+		{
+			name: "agg",
+			sql:  `SELECT sum(a) AS total, count(*) AS a FROM test GROUP BY TumblingWindow(ss, 10)`,
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 1, "b": "x"}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 2, "b": "y"}},
 				},
+			},
+			result: []map[string]interface{}{{
+				"total":   int64(3),
+				"missing": nil,
+				"b":       nil,
+				"a":       2,
 			}},
 		},
-		// 21
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt, err := xsql.NewParser(strings.NewReader(tt.sql)).Parse()
+			require.NoError(t, err)
+			pp := &ProjectOp{IsAggregate: xsql.WithAggFields(stmt), OutputSchema: schema}
+			parseStmt(pp, stmt.Fields)
+			fv, afv := xsql.NewFunctionValuersForOp(nil)
+			opResult := pp.Apply(ctx, tt.data, fv, afv)
+			result, err := parseResult(opResult, pp.IsAggregate)
+			require.NoError(t, err)
+			require.Equal(t, tt.result, result)
+			or, ok := opResult.(xsql.OrderedRow)
+			require.True(t, ok)
+			require.Equal(t, []string{"total", "missing", "b", "a"}, or.FieldOrder())
+			require.Len(t, result[0], len(or.FieldOrder()))
+		})
+	}
+
+	// Without the schema, no order is declared
+	stmt, err := xsql.NewParser(strings.NewReader(`SELECT a FROM test`)).Parse()
+	require.NoError(t, err)
+	pp := &ProjectOp{}
+	parseStmt(pp, stmt.Fields)
+	fv, afv := xsql.NewFunctionValuersForOp(nil)
+	opResult := pp.Apply(ctx, &xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 1}}, fv, afv)
+	require.Nil(t, opResult.(xsql.OrderedRow).FieldOrder())
+}
+
+func TestProjectOrdered(t *testing.T) {
+	contextLogger := conf.Log.WithField("rule", "TestProjectOrdered")
+	ctx := context.WithValue(context.Background(), context.LoggerKey, contextLogger)
+	tests := []struct {
+		name  string
+		sql   string
+		data  interface{}
+		order []string
+	}{
 		{
-			sql: `SELECT a->b[:4] AS ab FROM test`,
+			name: "fields",
+			sql:  `SELECT c, a + c AS total, b, a FROM test`,
 			data: &xsql.Tuple{
 				Emitter: "test",
-				Message: xsql.Message{
-					"a": map[string]interface{}{
-						"b": []float64{3.14, 3.141, 3.1415, 3.14159, 3.141592, 3.1415926},
+				Message: xsql.Message{"a": 1, "b": "hello", "c": 2},
+			},
+			order: []string{"c", "total", "b", "a"},
+		},
+		{
+			name: "wildcard",
+			sql:  `SELECT a + c AS total, *, abs(c) AS abc FROM test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{"d": 4, "c": 2, "a": 1, "b": "hello"},
+			},
+			order: []string{"total", "a", "b", "c", "d", "abc"},
+		},
+		{
+			name: "missing",
+			sql:  `SELECT b, x, a FROM test`,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{"a": 1, "b": "hello"},
+			},
+			order: []string{"b", "a"},
+		},
+		{
+			name: "agg",
+			sql:  `SELECT count(*) AS c, sum(a) AS total FROM test GROUP BY TumblingWindow(ss, 10)`,
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 1}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 2}},
+				},
+			},
+			order: []string{"c", "total"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt, err := xsql.NewParser(strings.NewReader(tt.sql)).Parse()
+			require.NoError(t, err)
+			pp := &ProjectOp{IsAggregate: xsql.WithAggFields(stmt), Ordered: true}
+			parseStmt(pp, stmt.Fields)
+			fv, afv := xsql.NewFunctionValuersForOp(nil)
+			opResult := pp.Apply(ctx, tt.data, fv, afv)
+			result, err := parseResult(opResult, pp.IsAggregate)
+			require.NoError(t, err)
+			or, ok := opResult.(xsql.OrderedRow)
+			require.True(t, ok)
+			require.Equal(t, tt.order, or.FieldOrder())
+			require.Len(t, result[0], len(tt.order))
+		})
+	}
+}
+
+func TestProjectKeyCase(t *testing.T) {
+	contextLogger := conf.Log.WithField("rule", "TestProjectKeyCase")
+	ctx := context.WithValue(context.Background(), context.LoggerKey, contextLogger)
+	tests := []struct {
+		name    string
+		sql     string
+		keyCase string
+		data    interface{}
+		result  []map[string]interface{}
+	}{
+		{
+			name:    "lower",
+			sql:     `SELECT Temp, humidity, a + 1 AS OutA, abs(a) FROM test`,
+			keyCase: KeyCaseLower,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{"Temp": 20.5, "humidity": 60, "a": 1},
+			},
+			result: []map[string]interface{}{{
+				"temp":     20.5,
+				"humidity": 60,
+				"outa":     int64(2),
+				"abs":      1,
+			}},
+		},
+		{
+			name:    "upper wildcard",
+			sql:     `SELECT *, a AS Alias FROM test`,
+			keyCase: KeyCaseUpper,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{"Temp": 20.5, "a": 1},
+			},
+			result: []map[string]interface{}{{
+				"TEMP":  20.5,
+				"A":     1,
+				"ALIAS": 1,
+			}},
+		},
+		{
+			name:    "runtime collision",
+			sql:     `SELECT * FROM test`,
+			keyCase: KeyCaseLower,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{"a": 1, "A": 2},
+			},
+			// the last key in the sorted order wins
+			result: []map[string]interface{}{{
+				"a": 1,
+			}},
+		},
+		{
+			name:    "none",
+			sql:     `SELECT Temp FROM test`,
+			keyCase: KeyCaseNone,
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{"Temp": 20.5},
+			},
+			result: []map[string]interface{}{{
+				"Temp": 20.5,
+			}},
+		},
+		{
+			name:    "agg",
+			sql:     `SELECT Color, count(*) AS Total FROM test GROUP BY TumblingWindow(ss, 10), Color`,
+			keyCase: KeyCaseLower,
+			data: &xsql.GroupedTuplesSet{
+				Groups: []*xsql.GroupedTuples{
+					{
+						Content: []xsql.Row{
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"Color": "w1"}},
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"Color": "w1"}},
+						},
 					},
 				},
 			},
-			result: []map[string]interface{}{{
-				"ab": []float64{
-					3.14, 3.141, 3.1415, 3.14159,
-				},
-			}},
+			result: []map[string]interface{}{{
+				"color": "w1",
+				"total": 2,
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt, err := xsql.NewParser(strings.NewReader(tt.sql)).Parse()
+			require.NoError(t, err)
+			pp := &ProjectOp{IsAggregate: xsql.WithAggFields(stmt), KeyCase: tt.keyCase}
+			parseStmt(pp, stmt.Fields)
+			require.NoError(t, pp.ValidateKeyCase())
+			fv, afv := xsql.NewFunctionValuersForOp(nil)
+			opResult := pp.Apply(ctx, tt.data, fv, afv)
+			result, err := parseResult(opResult, pp.IsAggregate)
+			require.NoError(t, err)
+			require.Equal(t, tt.result, result)
+		})
+	}
+
+	// The field order is converted too
+	stmt, err := xsql.NewParser(strings.NewReader(`SELECT B, a AS A1 FROM test`)).Parse()
+	require.NoError(t, err)
+	pp := &ProjectOp{Ordered: true, KeyCase: KeyCaseLower}
+	parseStmt(pp, stmt.Fields)
+	fv, afv := xsql.NewFunctionValuersForOp(nil)
+	opResult := pp.Apply(ctx, &xsql.Tuple{Emitter: "test", Message: xsql.Message{"B": 2, "a": 1}}, fv, afv)
+	require.Equal(t, []string{"b", "a1"}, opResult.(xsql.OrderedRow).FieldOrder())
+	require.Equal(t, map[string]interface{}{"b": 2, "a1": 1}, opResult.(xsql.Row).ToMap())
+}
+
+func TestProjectValidateKeyCase(t *testing.T) {
+	tests := []struct {
+		sql     string
+		keyCase string
+		err     string
+	}{
+		{
+			sql:     `SELECT Temp, temp FROM test`,
+			keyCase: KeyCaseUpper,
+			err:     "output fields Temp and temp collide after converting to upper case",
+		},
+		{
+			sql:     `SELECT a AS Temp, b AS payload.x, c AS Payload FROM test`,
+			keyCase: KeyCaseLower,
+			err:     "output fields payload and Payload collide after converting to lower case",
+		},
+		{
+			sql:     `SELECT Temp, temp FROM test`,
+			keyCase: KeyCaseNone,
+		},
+		{
+			sql:     `SELECT *, a AS A FROM test`,
+			keyCase: KeyCaseLower,
+		},
+		{
+			sql:     `SELECT a FROM test`,
+			keyCase: "camel",
+			err:     `invalid key case "camel", expect none, lower or upper`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			stmt, err := xsql.NewParser(strings.NewReader(tt.sql)).Parse()
+			require.NoError(t, err)
+			pp := &ProjectOp{KeyCase: tt.keyCase}
+			parseStmt(pp, stmt.Fields)
+			err = pp.ValidateKeyCase()
+			if tt.err == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestProjectDedupKeys(t *testing.T) {
+	contextLogger := conf.Log.WithField("rule", "TestProjectDedupKeys")
+	ctx := context.WithValue(context.Background(), context.LoggerKey, contextLogger)
+	tests := []struct {
+		name   string
+		sql    string
+		keys   []string
+		limit  int
+		data   xsql.Collection
+		result []map[string]interface{}
+	}{
+		{
+			name: "single key",
+			sql:  `SELECT id, v FROM test`,
+			keys: []string{"id"},
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 2, "v": "a"}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1, "v": "b"}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 2, "v": "c"}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 3, "v": "d"}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1, "v": "e"}},
+				},
+			},
+			result: []map[string]interface{}{
+				{"id": 2, "v": "a"},
+				{"id": 1, "v": "b"},
+				{"id": 3, "v": "d"},
+			},
 		},
-		// 22
 		{
-			sql: `SELECT a->b[0:1] AS ab FROM test`,
-			data: &xsql.Tuple{
-				Emitter: "test",
-				Message: xsql.Message{
-					"a": map[string]interface{}{
-						"b": []float64{3.14, 3.141, 3.1415, 3.14159, 3.141592, 3.1415926},
-					},
+			name: "multiple keys with alias",
+			sql:  `SELECT id, upper(v) AS uv, ts FROM test`,
+			keys: []string{"id", "uv"},
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1, "v": "a", "ts": 1}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1, "v": "A", "ts": 2}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1, "v": "b", "ts": 3}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"v": "b", "ts": 4}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"v": "b", "ts": 5}},
 				},
 			},
-			result: []map[string]interface{}{{
-				"ab": []float64{
-					3.14,
-				},
-			}},
+			result: []map[string]interface{}{
+				{"id": 1, "uv": "A", "ts": 1},
+				{"id": 1, "uv": "B", "ts": 3},
+				{"uv": "B", "ts": 4},
+			},
 		},
-		// 23
 		{
-			sql: `SELECT a->c->d AS f1 FROM test`,
-			data: &xsql.Tuple{
-				Emitter: "test",
-				Message: xsql.Message{
-					"a": map[string]interface{}{
-						"b": "hello",
-						"c": map[string]interface{}{
-							"d": 35.2,
-						},
-					},
+			name:  "limit after dedup",
+			sql:   `SELECT id, v FROM test`,
+			keys:  []string{"id"},
+			limit: 2,
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1, "v": "a"}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1, "v": "b"}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 2, "v": "c"}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 3, "v": "d"}},
 				},
 			},
-			result: []map[string]interface{}{{
-				"f1": 35.2,
-			}},
+			result: []map[string]interface{}{
+				{"id": 1, "v": "a"},
+				{"id": 2, "v": "c"},
+			},
 		},
-		// 24
 		{
-			sql: `SELECT a->c->d AS f1 FROM test`,
-			data: &xsql.Tuple{
-				Emitter: "test",
-				Message: xsql.Message{
-					"a": map[string]interface{}{
-						"b": "hello",
-						"c": map[string]interface{}{
-							"e": 35.2,
-						},
-					},
+			name: "typed keys",
+			sql:  `SELECT a, b FROM test`,
+			keys: []string{"a", "b"},
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": "a,b", "b": "c"}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": "a", "b": "b,c"}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 1, "b": "c"}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": "1", "b": "c"}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": "1", "b": "c"}},
 				},
 			},
-			result: []map[string]interface{}{{"f1": nil}},
+			result: []map[string]interface{}{
+				{"a": "a,b", "b": "c"},
+				{"a": "a", "b": "b,c"},
+				{"a": 1, "b": "c"},
+				{"a": "1", "b": "c"},
+			},
 		},
-		// 25
 		{
-			sql: `SELECT a->c->d AS f1 FROM test`,
-			data: &xsql.Tuple{
-				Emitter: "test",
-				Message: xsql.Message{
-					"a": map[string]interface{}{
-						"b": "hello",
-					},
+			name: "no keys",
+			sql:  `SELECT id FROM test`,
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1}},
 				},
 			},
-			result: []map[string]interface{}{{"f1": nil}},
+			result: []map[string]interface{}{
+				{"id": 1},
+				{"id": 1},
+			},
 		},
-		// 26
-		// The int type is not supported yet, the json parser returns float64 for int values
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt, err := xsql.NewParser(strings.NewReader(tt.sql)).Parse()
+			require.NoError(t, err)
+			pp := &ProjectOp{DedupKeys: tt.keys, EnableLimit: tt.limit > 0, LimitCount: tt.limit}
+			parseStmt(pp, stmt.Fields)
+			fv, afv := xsql.NewFunctionValuersForOp(nil)
+			opResult := pp.Apply(ctx, tt.data, fv, afv)
+			result, err := parseResult(opResult, pp.IsAggregate)
+			require.NoError(t, err)
+			require.Equal(t, tt.result, result)
+		})
+	}
+}
+
+func TestProjectRequireFields(t *testing.T) {
+	contextLogger := conf.Log.WithField("rule", "TestProjectRequireFields")
+	ctx := context.WithValue(context.Background(), context.LoggerKey, contextLogger)
+	tests := []struct {
+		name   string
+		sql    string
+		fields []string
+		limit  int
+		data   interface{}
+		result []map[string]interface{}
+	}{
 		{
-			sql: `SELECT a->c->d AS f1 FROM test`,
-			data: &xsql.Tuple{
-				Emitter: "test",
-				Message: xsql.Message{
-					"a": map[string]interface{}{
-						"b": "hello",
-						"c": map[string]interface{}{
-							"d": float64(35),
-						},
-					},
+			name:   "complete tuple",
+			sql:    `SELECT id, temp * 2 AS t FROM test`,
+			fields: []string{"id", "temp"},
+			data:   &xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1, "temp": 20}},
+			result: []map[string]interface{}{{"id": 1, "t": int64(40)}},
+		},
+		{
+			name:   "missing field",
+			sql:    `SELECT id, temp * 2 AS t FROM test`,
+			fields: []string{"id", "temp"},
+			data:   &xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1}},
+			result: nil,
+		},
+		{
+			name:   "nil field",
+			sql:    `SELECT * FROM test`,
+			fields: []string{"temp"},
+			data:   &xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1, "temp": nil}},
+			result: nil,
+		},
+		{
+			name:   "window",
+			sql:    `SELECT id, temp FROM test`,
+			fields: []string{"temp"},
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1, "temp": 20}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 2}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 3, "temp": 22}},
 				},
 			},
-			result: []map[string]interface{}{{
-				"f1": float64(35),
-			}},
+			result: []map[string]interface{}{
+				{"id": 1, "temp": 20},
+				{"id": 3, "temp": 22},
+			},
 		},
-		// 27
 		{
-			sql: "SELECT a FROM test",
-			data: &xsql.Tuple{
-				Emitter: "test",
-				Message: xsql.Message{},
+			name:   "limit after dropping",
+			sql:    `SELECT id, temp FROM test`,
+			fields: []string{"temp"},
+			limit:  2,
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1, "temp": 20}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 2}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 3, "temp": 22}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 4, "temp": 23}},
+				},
 			},
 			result: []map[string]interface{}{
-				{"a": nil},
+				{"id": 1, "temp": 20},
+				{"id": 3, "temp": 22},
 			},
 		},
-		// 28
 		{
-			sql: "SELECT * FROM test",
-			data: &xsql.Tuple{
-				Emitter: "test",
-				Message: xsql.Message{},
+			name:   "window wildcard",
+			sql:    `SELECT * FROM test`,
+			fields: []string{"temp"},
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1}},
+					&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 2, "temp": 21}},
+				},
 			},
 			result: []map[string]interface{}{
-				{},
+				{"id": 2, "temp": 21},
 			},
 		},
-		// 29
 		{
-			sql: `SELECT * FROM test`,
-			data: &xsql.Tuple{
-				Emitter: "test",
-				Message: xsql.Message{
-					"a": map[string]interface{}{
-						"b": "hello",
-						"c": map[string]interface{}{
-							"d": 35.2,
+			name:   "join",
+			sql:    `SELECT test.id, src2.color FROM test Inner Join src2 on test.id = src2.id`,
+			fields: []string{"color"},
+			data: &xsql.JoinTuples{
+				Content: []*xsql.JoinTuple{
+					{
+						Tuples: []xsql.Row{
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1}},
+							&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 1, "color": "w1"}},
+						},
+					},
+					{
+						Tuples: []xsql.Row{
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 2}},
+							&xsql.Tuple{Emitter: "src2", Message: xsql.Message{"id": 2}},
 						},
 					},
 				},
 			},
+			result: []map[string]interface{}{
+				{"id": 1, "color": "w1"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt, err := xsql.NewParser(strings.NewReader(tt.sql)).Parse()
+			require.NoError(t, err)
+			pp := &ProjectOp{RequireFields: tt.fields, EnableLimit: tt.limit > 0, LimitCount: tt.limit}
+			parseStmt(pp, stmt.Fields)
+			fv, afv := xsql.NewFunctionValuersForOp(nil)
+			opResult := pp.Apply(ctx, tt.data, fv, afv)
+			if rows, ok := opResult.([]xsql.Row); ok {
+				require.Empty(t, rows)
+				require.Nil(t, tt.result)
+				return
+			}
+			result, err := parseResult(opResult, pp.IsAggregate)
+			require.NoError(t, err)
+			require.Equal(t, tt.result, result)
+		})
+	}
+}
+
+func TestProjectComputedIndex(t *testing.T) {
+	contextLogger := conf.Log.WithField("rule", "TestProjectComputedIndex")
+	ctx := context.WithValue(context.Background(), context.LoggerKey, contextLogger)
+	labels := []interface{}{"low", "medium", "high"}
+	tests := []struct {
+		name    string
+		sql     string
+		lenient bool
+		data    *xsql.Tuple
+		result  interface{}
+	}{
+		{
+			name:   "bucket index",
+			sql:    `SELECT labels[floor(value / 10)] AS label FROM test`,
+			data:   &xsql.Tuple{Emitter: "test", Message: xsql.Message{"labels": labels, "value": 15}},
+			result: []map[string]interface{}{{"label": "medium"}},
+		},
+		{
+			name:   "negative index from field",
+			sql:    `SELECT labels[0 - idx] AS label FROM test`,
+			data:   &xsql.Tuple{Emitter: "test", Message: xsql.Message{"labels": labels, "idx": 1}},
+			result: []map[string]interface{}{{"label": "high"}},
+		},
+		{
+			name:   "nil index",
+			sql:    `SELECT labels[idx] AS label FROM test`,
+			data:   &xsql.Tuple{Emitter: "test", Message: xsql.Message{"labels": labels}},
+			result: []map[string]interface{}{{}},
+		},
+		{
+			name:   "out of range",
+			sql:    `SELECT labels[floor(value / 10)] AS label FROM test`,
+			data:   &xsql.Tuple{Emitter: "test", Message: xsql.Message{"labels": labels, "value": 45}},
+			result: errors.New("run Select error: alias: label expr: binaryExpr:{ $$default.labels[Call:{ name:floor, args:[binaryExpr:{ $$default.value / 10 }] }] } meet error, err:out of index: 4 of 3"),
+		},
+		{
+			name:    "out of range lenient",
+			sql:     `SELECT labels[floor(value / 10)] AS label FROM test`,
+			lenient: true,
+			data:    &xsql.Tuple{Emitter: "test", Message: xsql.Message{"labels": labels, "value": 45}},
+			result:  []map[string]interface{}{{}},
+		},
+		{
+			name:    "negative out of range lenient",
+			sql:     `SELECT labels[0 - idx] AS label FROM test`,
+			lenient: true,
+			data:    &xsql.Tuple{Emitter: "test", Message: xsql.Message{"labels": labels, "idx": 5}},
+			result:  []map[string]interface{}{{}},
+		},
+		{
+			name:    "out of range lenient with default",
+			sql:     `SELECT labels[floor(value / 10)] ?? "unknown" AS label, labels[0 - idx] ?? labels[0] AS first FROM test`,
+			lenient: true,
+			data:    &xsql.Tuple{Emitter: "test", Message: xsql.Message{"labels": labels, "value": 45, "idx": 5}},
+			result:  []map[string]interface{}{{"label": "unknown", "first": "low"}},
+		},
+		{
+			name:    "in range with default",
+			sql:     `SELECT labels[floor(value / 10)] ?? "unknown" AS label FROM test`,
+			lenient: true,
+			data:    &xsql.Tuple{Emitter: "test", Message: xsql.Message{"labels": labels, "value": 15}},
+			result:  []map[string]interface{}{{"label": "medium"}},
+		},
+		{
+			name:    "chained defaults",
+			sql:     `SELECT nums[5] ?? nums[4] ?? 0 + 1 AS n, (nums[5] ?? 0) + 1 AS m FROM test`,
+			lenient: true,
+			data:    &xsql.Tuple{Emitter: "test", Message: xsql.Message{"nums": []interface{}{1, 2}}},
+			result:  []map[string]interface{}{{"n": int64(1), "m": int64(1)}},
+		},
+		{
+			name:   "out of range with default",
+			sql:    `SELECT labels[floor(value / 10)] ?? "unknown" AS label FROM test`,
+			data:   &xsql.Tuple{Emitter: "test", Message: xsql.Message{"labels": labels, "value": 45}},
+			result: errors.New("run Select error: alias: label expr: binaryExpr:{ binaryExpr:{ $$default.labels[Call:{ name:floor, args:[binaryExpr:{ $$default.value / 10 }] }] } ?? unknown } meet error, err:out of index: 4 of 3"),
+		},
+		{
+			name:   "non integral index",
+			sql:    `SELECT labels[value / 4.0] AS label FROM test`,
+			data:   &xsql.Tuple{Emitter: "test", Message: xsql.Message{"labels": labels, "value": 6}},
+			result: errors.New("run Select error: alias: label expr: binaryExpr:{ $$default.labels[binaryExpr:{ $$default.value / 4.000000 }] } meet error, err:index binaryExpr:{ $$default.value / 4.000000 } is not int: cannot convert float64(1.5) to int"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt, err := xsql.NewParser(strings.NewReader(tt.sql)).Parse()
+			require.NoError(t, err)
+			pp := &ProjectOp{LenientIndex: tt.lenient}
+			parseStmt(pp, stmt.Fields)
+			fv, afv := xsql.NewFunctionValuersForOp(nil)
+			opResult := pp.Apply(ctx, tt.data, fv, afv)
+			if e, ok := tt.result.(error); ok {
+				require.EqualError(t, opResult.(error), e.Error())
+				return
+			}
+			result, err := parseResult(opResult, pp.IsAggregate)
+			require.NoError(t, err)
+			require.Equal(t, tt.result, result)
+		})
+	}
+}
+
+func TestProjectEnrich(t *testing.T) {
+	function.RegisterEnrichTable("devices", map[string]map[string]interface{}{
+		"1": {"name": "pump", "site": "north"},
+		"2": {"name": "valve", "site": "south"},
+	})
+	defer function.DeregisterEnrichTable("devices")
+	contextLogger := conf.Log.WithField("rule", "TestProjectEnrich")
+	ctx := context.WithValue(context.Background(), context.LoggerKey, contextLogger)
+	tests := []struct {
+		name   string
+		sql    string
+		data   *xsql.Tuple
+		result interface{}
+	}{
+		{
+			name: "int key",
+			sql:  `SELECT id, temp, enrich(id, "devices") FROM test`,
+			data: &xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1, "temp": 20.5}},
+			result: []map[string]interface{}{{
+				"id": 1, "temp": 20.5, "devices_name": "pump", "devices_site": "north",
+			}},
+		},
+		{
+			name: "string key does not collide",
+			sql:  `SELECT id, name, enrich(id, "devices") FROM test`,
+			data: &xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": "2", "name": "sensor"}},
 			result: []map[string]interface{}{{
+				"id": "2", "name": "sensor", "devices_name": "valve", "devices_site": "south",
+			}},
+		},
+		{
+			name:   "missing key",
+			sql:    `SELECT id, enrich(id, "devices") FROM test`,
+			data:   &xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 3}},
+			result: []map[string]interface{}{{"id": 3}},
+		},
+		{
+			name:   "nil key",
+			sql:    `SELECT temp, enrich(id, "devices") FROM test`,
+			data:   &xsql.Tuple{Emitter: "test", Message: xsql.Message{"temp": 20}},
+			result: []map[string]interface{}{{"temp": 20}},
+		},
+		{
+			name:   "missing table",
+			sql:    `SELECT id, enrich(id, "sites") FROM test`,
+			data:   &xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": 1}},
+			result: errors.New("run Select error: expr: Call:{ name:enrich, args:[colFuncField:{ name: id, expr:{ $$default.id } }, colFuncField:{ expr:{ sites } }] } meet error, err:call func enrich error: enrich table sites is not found"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt, err := xsql.NewParser(strings.NewReader(tt.sql)).Parse()
+			require.NoError(t, err)
+			pp := &ProjectOp{}
+			parseStmt(pp, stmt.Fields)
+			fv, afv := xsql.NewFunctionValuersForOp(nil)
+			opResult := pp.Apply(ctx, tt.data, fv, afv)
+			if e, ok := tt.result.(error); ok {
+				require.EqualError(t, opResult.(error), e.Error())
+				return
+			}
+			result, err := parseResult(opResult, pp.IsAggregate)
+			require.NoError(t, err)
+			require.Equal(t, tt.result, result)
+		})
+	}
+}
+
+func TestProjectExpand(t *testing.T) {
+	contextLogger := conf.Log.WithField("rule", "TestProjectExpand")
+	ctx := context.WithValue(context.Background(), context.LoggerKey, contextLogger)
+	tests := []struct {
+		name   string
+		sql    string
+		data   *xsql.Tuple
+		result interface{}
+	}{
+		{
+			name: "sole projection",
+			sql:  `SELECT expand(a) FROM test`,
+			data: &xsql.Tuple{Emitter: "test", Message: xsql.Message{
 				"a": map[string]interface{}{
-					"b": "hello",
-					"c": map[string]interface{}{
-						"d": 35.2,
-					},
+					"temp": 20.5,
+					"pos":  map[string]interface{}{"x": 1, "y": 2},
 				},
+				"b": "b",
+			}},
+			result: []map[string]interface{}{{
+				"temp": 20.5,
+				"pos":  map[string]interface{}{"x": 1, "y": 2},
 			}},
 		},
-		// 30
 		{
-			sql: `SELECT * FROM test`,
-			data: &xsql.Tuple{
-				Emitter: "test",
-				Message: xsql.Message{
-					"a": "val1",
-					"b": 3.14,
+			name: "nested map",
+			sql:  `SELECT expand(a->pos) FROM test`,
+			data: &xsql.Tuple{Emitter: "test", Message: xsql.Message{
+				"a": map[string]interface{}{
+					"temp": 20.5,
+					"pos":  map[string]interface{}{"x": 1, "y": 2},
 				},
-			},
+			}},
 			result: []map[string]interface{}{{
-				"a": "val1",
-				"b": 3.14,
+				"x": 1,
+				"y": 2,
 			}},
 		},
-		// 31
 		{
-			sql: `SELECT 3*4 AS f1 FROM test`,
-			data: &xsql.Tuple{
-				Emitter: "test",
-				Message: xsql.Message{},
-			},
+			name: "overwrite",
+			sql:  `SELECT b, 1 AS c, expand(a) FROM test`,
+			data: &xsql.Tuple{Emitter: "test", Message: xsql.Message{
+				"a": map[string]interface{}{"b": "ab", "c": "ac", "d": "ad"},
+				"b": "b",
+			}},
 			result: []map[string]interface{}{{
-				"f1": int64(12),
+				"b": "ab",
+				"c": "ac",
+				"d": "ad",
 			}},
 		},
-		// 32
 		{
-			sql: `SELECT 4.5*2 AS f1 FROM test`,
-			data: &xsql.Tuple{
-				Emitter: "test",
-				Message: xsql.Message{},
-			},
+			name: "keep",
+			sql:  `SELECT b, 1 AS c, expand(a, "keep") FROM test`,
+			data: &xsql.Tuple{Emitter: "test", Message: xsql.Message{
+				"a": map[string]interface{}{"b": "ab", "c": "ac", "d": "ad"},
+				"b": "b",
+			}},
 			result: []map[string]interface{}{{
-				"f1": float64(9),
+				"b": "b",
+				"c": int64(1),
+				"d": "ad",
 			}},
 		},
-		// 33
 		{
-			sql: "SELECT `a.b.c` FROM test",
-			data: &xsql.Tuple{
-				Emitter: "test",
-				Message: xsql.Message{
-					"a.b.c": "val_a",
-				},
-			},
-			result: []map[string]interface{}{{
-				"a.b.c": "val_a",
+			name: "error",
+			sql:  `SELECT b, expand(a, "error") FROM test`,
+			data: &xsql.Tuple{Emitter: "test", Message: xsql.Message{
+				"a": map[string]interface{}{"b": "ab"},
+				"b": "b",
 			}},
+			result: errors.New("run Select error: expanded column b conflicts with the selected field"),
 		},
-		// 34
 		{
-			sql: `SELECT CASE a WHEN 10 THEN "true" END AS b FROM test`,
-			data: &xsql.Tuple{
-				Emitter: "test",
-				Message: xsql.Message{
-					"a": int64(10),
-				},
-			},
-			result: []map[string]interface{}{{
-				"b": "true",
-			}},
+			name:   "nil",
+			sql:    `SELECT b, expand(a) FROM test`,
+			data:   &xsql.Tuple{Emitter: "test", Message: xsql.Message{"b": "b"}},
+			result: []map[string]interface{}{{"b": "b"}},
 		},
-		// 35
 		{
-			sql: `SELECT a->b AS ab, *, abs(f1) FROM test`,
-			data: &xsql.Tuple{
-				Emitter: "test",
-				Message: xsql.Message{
-					"a": map[string]interface{}{
-						"b": "test",
-					},
-					"b":  "b",
-					"f1": -12,
-				},
-			},
-			result: []map[string]interface{}{{
-				"a": map[string]interface{}{
-					"b": "test",
-				},
-				"ab":  "test",
-				"abs": 12,
-				"b":   "b",
-				"f1":  -12,
-			}},
+			name:   "non object",
+			sql:    `SELECT expand(a) FROM test`,
+			data:   &xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 1}},
+			result: errors.New("run Select error: expr: Call:{ name:expand, args:[colFuncField:{ name: a, expr:{ $$default.a } }] } meet error, err:call func expand error: the argument for the expand function should be object but found int(1)"),
 		},
-		// 36
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt, err := xsql.NewParser(strings.NewReader(tt.sql)).Parse()
+			require.NoError(t, err)
+			pp := &ProjectOp{}
+			parseStmt(pp, stmt.Fields)
+			fv, afv := xsql.NewFunctionValuersForOp(nil)
+			opResult := pp.Apply(ctx, tt.data, fv, afv)
+			if e, ok := tt.result.(error); ok {
+				require.EqualError(t, opResult.(error), e.Error())
+				return
+			}
+			result, err := parseResult(opResult, pp.IsAggregate)
+			require.NoError(t, err)
+			require.Equal(t, tt.result, result)
+		})
+	}
+}
+
+// TestProjectGroupByExpr projects the expression-valued group by keys which are evaluated by the first tuple of each group
+func TestProjectGroupByExpr(t *testing.T) {
+	data := &xsql.WindowTuples{
+		Content: []xsql.Row{
+			&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id1": 1, "f1": "v1", "ts": 3600000}},
+			&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id1": 2, "f1": "v2", "ts": 3700000}},
+			&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id1": 3, "f1": "v1", "ts": 7300000}},
+			&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id1": 4, "f1": "v1", "ts": 7400000}},
+		},
+		WindowRange: xsql.NewWindowRange(1541152486013, 1541152487013, 1541152487013),
+	}
+	tests := []struct {
+		sql    string
+		result []map[string]interface{}
+	}{
 		{
-			sql: `SELECT * EXCEPT(a, b) from test`,
-			data: &xsql.Tuple{
-				Emitter: "test",
-				Message: xsql.Message{
-					"a": map[string]interface{}{
-						"b": "test",
-					},
-					"b": "b",
-					"c": "c",
-				},
-			},
+			sql: "SELECT floor(ts/3600000) AS h, count(*) AS c FROM src1 GROUP BY TUMBLINGWINDOW(ss, 10), floor(ts/3600000)",
 			result: []map[string]interface{}{
-				{
-					"c": "c",
-				},
+				{"h": 1.0, "c": 2},
+				{"h": 2.0, "c": 2},
 			},
 		},
-		// 37
 		{
-			sql: `SELECT * REPLACE(a->b as a) from test`,
-			data: &xsql.Tuple{
-				Emitter: "test",
-				Message: xsql.Message{
-					"a": map[string]interface{}{
-						"b": "test",
-					},
-					"b": "b",
-					"c": "c",
-				},
-			},
+			sql: "SELECT floor(ts/3600000), id1 FROM src1 GROUP BY TUMBLINGWINDOW(ss, 10), floor(ts/3600000)",
 			result: []map[string]interface{}{
-				{
-					"a": "test",
-					"b": "b",
-					"c": "c",
-				},
+				{"floor": 1.0, "id1": 1},
+				{"floor": 2.0, "id1": 3},
 			},
 		},
-		// 38
 		{
-			sql: `SELECT * EXCEPT(c) REPLACE("test" as b, a->b as a) from test`,
-			data: &xsql.Tuple{
-				Emitter: "test",
-				Message: xsql.Message{
-					"a": map[string]interface{}{
-						"b": "test",
-					},
-					"b": "b",
-					"c": 1,
-				},
+			sql: "SELECT f1, floor(ts/3600000) AS h, max(id1) AS m FROM src1 GROUP BY TUMBLINGWINDOW(ss, 10), f1, floor(ts/3600000)",
+			result: []map[string]interface{}{
+				{"f1": "v1", "h": 1.0, "m": int64(1)},
+				{"f1": "v2", "h": 1.0, "m": int64(2)},
+				{"f1": "v1", "h": 2.0, "m": int64(4)},
 			},
+		},
+		{
+			sql: "SELECT concat(f1, \"_x\") AS k, count(*) AS c FROM src1 GROUP BY TUMBLINGWINDOW(ss, 10), concat(f1, \"_x\")",
 			result: []map[string]interface{}{
-				{
-					"a": "test",
-					"b": "test",
-				},
+				{"k": "v1_x", "c": 3},
+				{"k": "v2_x", "c": 1},
 			},
 		},
 	}
-
-	contextLogger := conf.Log.WithField("rule", "TestProjectPlan_Apply1")
+	contextLogger := conf.Log.WithField("rule", "TestProjectGroupByExpr")
 	ctx := context.WithValue(context.Background(), context.LoggerKey, contextLogger)
-	for _, tt := range tests {
-		t.Run(tt.sql, func(t *testing.T) {
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
 			stmt, err := xsql.NewParser(strings.NewReader(tt.sql)).Parse()
 			require.NoError(t, err)
-			pp := &ProjectOp{SendMeta: true, SendNil: true, IsAggregate: xsql.WithAggFields(stmt)}
-			parseStmt(pp, stmt.Fields)
 			fv, afv := xsql.NewFunctionValuersForOp(nil)
-			opResult := pp.Apply(ctx, tt.data, fv, afv)
+			ap := &AggregateOp{Dimensions: stmt.Dimensions.GetGroups()}
+			grouped := ap.Apply(ctx, data.Clone(), fv, afv)
+			pp := &ProjectOp{IsAggregate: true}
+			parseStmt(pp, stmt.Fields)
+			opResult := pp.Apply(ctx, grouped, fv, afv)
 			result, err := parseResult(opResult, pp.IsAggregate)
 			require.NoError(t, err)
-			require.Equal(t, tt.result, result)
+			// the order of the groups is not guaranteed
+			require.ElementsMatch(t, tt.result, result)
 		})
 	}
 }
 
-func TestProjectSlice(t *testing.T) {
+func TestProjectWildcardReplaceAgg(t *testing.T) {
+	data := &xsql.WindowTuples{
+		Content: []xsql.Row{
+			&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id1": 1, "f1": "v1", "v": 10}},
+			&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id1": 2, "f1": "v2", "v": 30}},
+			&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id1": 3, "f1": "v1", "v": 50}},
+			&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id1": 4, "f1": "v1", "v": 20}},
+		},
+		WindowRange: xsql.NewWindowRange(1541152486013, 1541152487013, 1541152487013),
+	}
 	tests := []struct {
-		name   string
 		sql    string
-		data   any
-		result any
+		result []map[string]interface{}
 	}{
 		{
-			name: "normal",
-			sql:  `SELECT c, a FROM test`,
-			data: &xsql.SliceTuple{
-				SourceContent: model.SliceVal{"a0", "b0", "c0"},
-			},
-			result: &xsql.SliceTuple{
-				SourceContent: model.SliceVal{"c0", "a0"},
+			sql: "SELECT * REPLACE(max(v) AS v) FROM src1 GROUP BY TUMBLINGWINDOW(ss, 10), f1",
+			result: []map[string]interface{}{
+				{"id1": 1, "f1": "v1", "v": int64(50)},
+				{"id1": 2, "f1": "v2", "v": int64(30)},
 			},
 		},
 		{
-			name: "agg",
-			sql:  `SELECT b FROM test`,
-			data: &xsql.WindowTuples{
-				Content: []xsql.Row{
-					&xsql.SliceTuple{
-						SourceContent: model.SliceVal{"a0", "b0", "c0"},
-					},
-					&xsql.SliceTuple{
-						SourceContent: model.SliceVal{"a1", "b1", "c1"},
-					},
-					&xsql.SliceTuple{
-						SourceContent: model.SliceVal{"a2", "b2", "c2"},
-					},
-				},
-			},
-			result: &xsql.WindowTuples{
-				Content: []xsql.Row{
-					&xsql.SliceTuple{
-						SourceContent: model.SliceVal{"b0"},
-					},
-					&xsql.SliceTuple{
-						SourceContent: model.SliceVal{"b1"},
-					},
-					&xsql.SliceTuple{
-						SourceContent: model.SliceVal{"b2"},
-					},
-				},
+			sql: "SELECT * EXCEPT(id1) REPLACE(max(v) AS v, count(*) AS f1) FROM src1 GROUP BY TUMBLINGWINDOW(ss, 10)",
+			result: []map[string]interface{}{
+				{"f1": 4, "v": int64(50)},
 			},
 		},
 		{
-			name: "alias",
-			sql:  `SELECT concat(a,b) as ab, c FROM test`,
-			data: &xsql.SliceTuple{
-				SourceContent: model.SliceVal{"a0", "b0", "c0"},
-			},
-			result: &xsql.SliceTuple{
-				SourceContent: model.SliceVal{"a0b0", "c0"},
+			sql: "SELECT * REPLACE(avg(v) AS v, window_end() AS id1) FROM src1 GROUP BY TUMBLINGWINDOW(ss, 10), f1",
+			result: []map[string]interface{}{
+				{"id1": int64(1541152487013), "f1": "v1", "v": int64(26)},
+				{"id1": int64(1541152487013), "f1": "v2", "v": int64(30)},
 			},
 		},
 	}
-	contextLogger := conf.Log.WithField("rule", "TestProjectPlan_Apply1")
+	contextLogger := conf.Log.WithField("rule", "TestProjectWildcardReplaceAgg")
 	ctx := context.WithValue(context.Background(), context.LoggerKey, contextLogger)
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
 			stmt, err := xsql.NewParser(strings.NewReader(tt.sql)).Parse()
 			require.NoError(t, err)
-			pp := &ProjectOp{IsAggregate: xsql.WithAggFields(stmt)}
-			parseStmtWithSlice(pp, stmt.Fields, true)
 			fv, afv := xsql.NewFunctionValuersForOp(nil)
-			opResult := pp.Apply(ctx, tt.data, fv, afv)
+			ap := &AggregateOp{Dimensions: stmt.Dimensions.GetGroups()}
+			grouped := ap.Apply(ctx, data.Clone(), fv, afv)
+			pp := &ProjectOp{IsAggregate: xsql.WithAggFields(stmt)}
+			parseStmt(pp, stmt.Fields)
+			opResult := pp.Apply(ctx, grouped, fv, afv)
+			result, err := parseResult(opResult, pp.IsAggregate)
 			require.NoError(t, err)
-			require.Equal(t, tt.result, opResult)
+			// the order of the groups is not guaranteed
+			require.ElementsMatch(t, tt.result, result)
+		})
+	}
+}
+
+func TestProjectWildcardReference(t *testing.T) {
+	contextLogger := conf.Log.WithField("rule", "TestProjectWildcardReference")
+	ctx := context.WithValue(context.Background(), context.LoggerKey, contextLogger)
+	fv, afv := xsql.NewFunctionValuersForOp(nil)
+	msg := xsql.Message{"a": "val1", "b": 3.14}
+	tests := []struct {
+		sql  string
+		same bool
+	}{
+		{sql: "SELECT * FROM test", same: true},
+		{sql: "SELECT * EXCEPT(b) FROM test", same: false},
+		{sql: "SELECT *, a AS c FROM test", same: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			stmt, err := xsql.NewParser(strings.NewReader(tt.sql)).Parse()
+			require.NoError(t, err)
+			pp := &ProjectOp{SendMeta: true}
+			parseStmt(pp, stmt.Fields)
+			tuple := &xsql.Tuple{Emitter: "test", Message: msg, Metadata: xsql.Metadata{"topic": "t1"}}
+			tuple.Set("$$a_lag_0", 1)
+			opResult := pp.Apply(ctx, tuple, fv, afv)
+			require.Equal(t, tt.same, reflect.ValueOf(opResult.(*xsql.Tuple).Message).Pointer() == reflect.ValueOf(msg).Pointer())
+			_, ok := opResult.(*xsql.Tuple).Value("$$a_lag_0", "")
+			require.False(t, ok)
+			require.Equal(t, xsql.Message{"a": "val1", "b": 3.14}, msg)
+
+			window := &xsql.WindowTuples{Content: []xsql.Row{&xsql.Tuple{Emitter: "test", Message: msg}}}
+			opResult = pp.Apply(ctx, window, fv, afv)
+			require.Equal(t, tt.same, reflect.ValueOf(opResult.(*xsql.WindowTuples).Content[0].(*xsql.Tuple).Message).Pointer() == reflect.ValueOf(msg).Pointer())
 		})
 	}
+	// The wildcard only rule still sends the metadata
+	stmt, err := xsql.NewParser(strings.NewReader("SELECT * FROM test")).Parse()
+	require.NoError(t, err)
+	pp := &ProjectOp{SendMeta: true}
+	parseStmt(pp, stmt.Fields)
+	opResult := pp.Apply(ctx, &xsql.Tuple{Emitter: "test", Message: msg, Metadata: xsql.Metadata{"topic": "t1"}}, fv, afv)
+	require.Equal(t, map[string]interface{}{"a": "val1", "b": 3.14, "__meta": xsql.Metadata{"topic": "t1"}}, opResult.(*xsql.Tuple).ToMap())
+}
+
+func TestProjectDistinctLimit(t *testing.T) {
+	contextLogger := conf.Log.WithField("rule", "TestProjectDistinctLimit")
+	ctx := context.WithValue(context.Background(), context.LoggerKey, contextLogger)
+	stmt, err := xsql.NewParser(strings.NewReader("SELECT DISTINCT f1 FROM src1 GROUP BY TUMBLINGWINDOW(ss, 10) LIMIT 2")).Parse()
+	require.NoError(t, err)
+	require.True(t, stmt.Distinct)
+	pp := &ProjectOp{Distinct: true, EnableLimit: true, LimitCount: 2}
+	parseStmt(pp, stmt.Fields)
+	fv, afv := xsql.NewFunctionValuersForOp(nil)
+	data := &xsql.WindowTuples{
+		Content: []xsql.Row{
+			&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id1": 1, "f1": "v1"}},
+			&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id1": 2, "f1": "v1"}},
+			&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id1": 3, "f1": "v2"}},
+			&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id1": 4, "f1": "v3"}},
+		},
+	}
+	// The limit is applied after dropping the duplicated rows
+	opResult := pp.Apply(ctx, data, fv, afv)
+	result, err := parseResult(opResult, false)
+	require.NoError(t, err)
+	require.Equal(t, []map[string]interface{}{{"f1": "v1"}, {"f1": "v2"}}, result)
+}
+
+func BenchmarkProjectWildcard(b *testing.B) {
+	stmt, err := xsql.NewParser(strings.NewReader("SELECT * FROM test")).Parse()
+	require.NoError(b, err)
+	pp := &ProjectOp{}
+	parseStmt(pp, stmt.Fields)
+	contextLogger := conf.Log.WithField("rule", "BenchmarkProjectWildcard")
+	ctx := context.WithValue(context.Background(), context.LoggerKey, contextLogger)
+	fv, afv := xsql.NewFunctionValuersForOp(nil)
+	msg := xsql.Message{"a": "val1", "b": 3.14, "c": map[string]interface{}{"d": 35.2}}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		pp.Apply(ctx, &xsql.Tuple{Emitter: "test", Message: msg}, fv, afv)
+	}
 }
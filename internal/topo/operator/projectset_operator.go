@@ -23,9 +23,13 @@ import (
 )
 
 type ProjectSetOperator struct {
-	SrfMapping  map[string]struct{}
+	SrfMapping map[string]struct{}
+	// SrfAlias records the srf fields which have an alias. The object elements of an aliased srf are kept
+	// under the alias so that they can be navigated by the following operators like `r->field`
+	SrfAlias    map[string]struct{}
 	EnableLimit bool
 	LimitCount  int
+	SendNil     bool
 }
 
 // Apply implement UnOperation
@@ -34,6 +38,7 @@ type ProjectSetOperator struct {
 // {"a":[1,2],"b":3} => {"a":1,"b":3},{"a":2,"b":3}
 // For Collection, ProjectSetOperator will do the following transform:
 // [{"a":[1,2],"b":3},{"a":[1,2],"b":4}] = > [{"a":"1","b":3},{"a":"2","b":3},{"a":"1","b":4},{"a":"2","b":4}]
+// If the srf value is not an array or is empty, no row is produced. If SendNil is set, a row with nil srf value is produced instead.
 func (ps *ProjectSetOperator) Apply(ctx api.StreamContext, data interface{}, _ *xsql.FunctionValuer, _ *xsql.AggregateFunctionValuer) interface{} {
 	if ps.LimitCount == 0 && ps.EnableLimit {
 		return []xsql.Row{}
@@ -120,21 +125,23 @@ func (ps *ProjectSetOperator) handleSRFRow(ctx api.StreamContext, row xsql.Row)
 		srfName = k
 		break
 	}
-	aValue, ok := row.Value(srfName, "")
-	if !ok {
-		return nil, fmt.Errorf("can't find the result from the %v function", srfName)
-	}
+	// nil result is omitted by the project operator, so a missing value is handled as an empty array
+	aValue, _ := row.Value(srfName, "")
 	aValues, ok := aValue.([]interface{})
-	if !ok {
-		return nil, fmt.Errorf("the argument for the %v function should be array", srfName)
+	if !ok || len(aValues) == 0 {
+		if !ps.SendNil {
+			return newResultWrapper(0, row), nil
+		}
+		aValues = []interface{}{nil}
 	}
+	_, keepObject := ps.SrfAlias[srfName]
 	res := newResultWrapper(len(aValues), row)
 	for i, v := range aValues {
 		newRow := row.Clone().(xsql.Row)
 		newRow.SetTracerCtx(row.GetTracerCtx())
 		// clear original column value
 		newRow.Del(srfName)
-		if mv, ok := v.(map[string]interface{}); ok {
+		if mv, ok := v.(map[string]interface{}); ok && !keepObject {
 			for k, v := range mv {
 				newRow.Set(k, v)
 			}
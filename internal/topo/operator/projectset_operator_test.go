@@ -0,0 +1,210 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/conf"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/context"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+)
+
+func TestProjectSetOperator_Apply(t *testing.T) {
+	tests := []struct {
+		sql     string
+		srf     string
+		alias   bool
+		sendNil bool
+		data    *xsql.Tuple
+		result  []map[string]interface{}
+	}{
+		{ // 0
+			sql: "SELECT unnest(readings) AS r, x FROM test",
+			srf: "r",
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"readings": []interface{}{int64(1), int64(2)},
+					"x":        "a",
+				},
+			},
+			alias: true,
+			result: []map[string]interface{}{
+				{"r": int64(1), "x": "a"},
+				{"r": int64(2), "x": "a"},
+			},
+		},
+		{ // 1
+			sql: "SELECT unnest(readings) AS r, x FROM test",
+			srf: "r",
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"readings": []interface{}{
+						map[string]interface{}{"temp": 20},
+						map[string]interface{}{"temp": 21},
+					},
+					"x": "a",
+				},
+			},
+			alias: true,
+			result: []map[string]interface{}{
+				{"r": map[string]interface{}{"temp": 20}, "x": "a"},
+				{"r": map[string]interface{}{"temp": 21}, "x": "a"},
+			},
+		},
+		{ // 2
+			sql: "SELECT unnest(readings), x FROM test",
+			srf: "unnest",
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"readings": []interface{}{
+						map[string]interface{}{"temp": 20},
+						map[string]interface{}{"temp": 21},
+					},
+					"x": "a",
+				},
+			},
+			result: []map[string]interface{}{
+				{"temp": 20, "x": "a"},
+				{"temp": 21, "x": "a"},
+			},
+		},
+		{ // 3
+			sql: "SELECT unnest(readings) AS r, x FROM test",
+			srf: "r",
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"readings": 6,
+					"x":        "a",
+				},
+			},
+			alias:  true,
+			result: nil,
+		},
+		{ // 4
+			sql: "SELECT unnest(readings) AS r, x FROM test",
+			srf: "r",
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"readings": []interface{}{},
+					"x":        "a",
+				},
+			},
+			alias:  true,
+			result: nil,
+		},
+		{ // 5
+			sql: "SELECT unnest(readings) AS r, x FROM test",
+			srf: "r",
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"readings": []interface{}{},
+					"x":        "a",
+				},
+			},
+			alias:   true,
+			sendNil: true,
+			result: []map[string]interface{}{
+				{"r": nil, "x": "a"},
+			},
+		},
+		{ // 6
+			sql: "SELECT unnest(readings) AS r, x FROM test",
+			srf: "r",
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"x": "a",
+				},
+			},
+			alias:   true,
+			sendNil: true,
+			result: []map[string]interface{}{
+				{"r": nil, "x": "a"},
+			},
+		},
+	}
+	contextLogger := conf.Log.WithField("rule", "TestProjectSetOperator_Apply")
+	ctx := context.WithValue(context.Background(), context.LoggerKey, contextLogger)
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			stmt, err := xsql.NewParser(strings.NewReader(tt.sql)).Parse()
+			require.NoError(t, err)
+			pp := &ProjectOp{}
+			parseStmt(pp, stmt.Fields)
+			ps := &ProjectSetOperator{SrfMapping: map[string]struct{}{tt.srf: {}}, SendNil: tt.sendNil}
+			if tt.alias {
+				ps.SrfAlias = map[string]struct{}{tt.srf: {}}
+			}
+			fv, afv := xsql.NewFunctionValuersForOp(nil)
+			opResult := ps.Apply(ctx, pp.Apply(ctx, tt.data, fv, afv), fv, afv)
+			rows, ok := opResult.([]xsql.Row)
+			require.True(t, ok, "unexpected result %v", opResult)
+			var result []map[string]interface{}
+			for _, row := range rows {
+				result = append(result, row.ToMap())
+			}
+			require.Equal(t, tt.result, result)
+		})
+	}
+}
+
+func TestProjectSetOperator_Navigation(t *testing.T) {
+	contextLogger := conf.Log.WithField("rule", "TestProjectSetOperator_Navigation")
+	ctx := context.WithValue(context.Background(), context.LoggerKey, contextLogger)
+	stmt, err := xsql.NewParser(strings.NewReader("SELECT unnest(readings) AS r, x FROM test")).Parse()
+	require.NoError(t, err)
+	pp := &ProjectOp{}
+	parseStmt(pp, stmt.Fields)
+	ps := &ProjectSetOperator{SrfMapping: map[string]struct{}{"r": {}}, SrfAlias: map[string]struct{}{"r": {}}}
+	stmt, err = xsql.NewParser(strings.NewReader("SELECT r->temp AS t, x FROM test")).Parse()
+	require.NoError(t, err)
+	np := &ProjectOp{}
+	parseStmt(np, stmt.Fields)
+
+	data := &xsql.Tuple{
+		Emitter: "test",
+		Message: xsql.Message{
+			"readings": []interface{}{
+				map[string]interface{}{"temp": 20},
+				map[string]interface{}{"temp": 21},
+			},
+			"x": "a",
+		},
+	}
+	fv, afv := xsql.NewFunctionValuersForOp(nil)
+	rows, ok := ps.Apply(ctx, pp.Apply(ctx, data, fv, afv), fv, afv).([]xsql.Row)
+	require.True(t, ok)
+	var result []map[string]interface{}
+	for _, row := range rows {
+		r, err := parseResult(np.Apply(ctx, row, fv, afv), false)
+		require.NoError(t, err)
+		result = append(result, r...)
+	}
+	require.Equal(t, []map[string]interface{}{
+		{"t": 20, "x": "a"},
+		{"t": 21, "x": "a"},
+	}, result)
+}
@@ -16,12 +16,15 @@ package operator
 
 import (
 	"fmt"
+	"math"
 	"sort"
+	"time"
 
 	"github.com/lf-edge/ekuiper/contract/v2/api"
 
 	"github.com/lf-edge/ekuiper/v2/internal/xsql"
 	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
 )
 
 type WindowFuncOperator struct {
@@ -29,27 +32,449 @@ type WindowFuncOperator struct {
 }
 
 type windowFuncHandle interface {
-	handleTuple(input xsql.Row) xsql.Row
-	handleCollection(input xsql.Collection) xsql.Collection
+	handleTuple(input xsql.Row) (xsql.Row, error)
+	handleCollection(input xsql.Collection) (xsql.Collection, error)
 }
 
 type rowNumberFuncHandle struct {
 	name string
 }
 
-func (rh *rowNumberFuncHandle) handleTuple(input xsql.Row) xsql.Row {
+func (rh *rowNumberFuncHandle) handleTuple(input xsql.Row) (xsql.Row, error) {
 	input.Set(rh.name, 1)
-	return input
+	return input, nil
 }
 
-func (rh *rowNumberFuncHandle) handleCollection(input xsql.Collection) xsql.Collection {
+func (rh *rowNumberFuncHandle) handleCollection(input xsql.Collection) (xsql.Collection, error) {
 	index := 1
 	input.RangeSet(func(i int, r xsql.Row) (bool, error) {
 		r.Set(rh.name, index)
 		index++
 		return true, nil
 	})
-	return input
+	return input, nil
+}
+
+// rowsFunc is the window function which calculates the result of each row by all the rows in the window or partition
+type rowsFunc interface {
+	handleRows(rows []xsql.Row) error
+}
+
+type rowsFuncHandle struct {
+	rowsFunc
+}
+
+func (rh *rowsFuncHandle) handleTuple(input xsql.Row) (xsql.Row, error) {
+	return input, rh.handleRows([]xsql.Row{input})
+}
+
+func (rh *rowsFuncHandle) handleCollection(input xsql.Collection) (xsql.Collection, error) {
+	rows := make([]xsql.Row, 0, input.Len())
+	input.RangeSet(func(i int, r xsql.Row) (bool, error) {
+		rows = append(rows, r)
+		return true, nil
+	})
+	return input, rh.handleRows(rows)
+}
+
+// evalFloatArgs evaluates the arg for each row and converts the results to float. Nil results are kept as nil.
+func evalFloatArgs(funcName string, arg ast.Expr, rows []xsql.Row, fv *xsql.FunctionValuer) ([]*float64, error) {
+	result := make([]*float64, len(rows))
+	for i, r := range rows {
+		ve := &xsql.ValuerEval{Valuer: xsql.MultiValuer(r, fv)}
+		v := ve.Eval(arg)
+		switch vt := v.(type) {
+		case error:
+			return nil, vt
+		case nil:
+			continue
+		default:
+			f, err := cast.ToFloat64(vt, cast.CONVERT_SAMEKIND)
+			if err != nil {
+				return nil, fmt.Errorf("%s requires number but found %[2]T(%[2]v)", funcName, v)
+			}
+			result[i] = &f
+		}
+	}
+	return result, nil
+}
+
+type zscoreFuncHandle struct {
+	name      string
+	args      []ast.Expr
+	fv        *xsql.FunctionValuer
+	isOutlier bool
+}
+
+func (zh *zscoreFuncHandle) handleRows(rows []xsql.Row) error {
+	funcName := "zscore"
+	if zh.isOutlier {
+		funcName = "is_outlier"
+	}
+	values, err := evalFloatArgs(funcName, zh.args[0], rows, zh.fv)
+	if err != nil {
+		return err
+	}
+	// two passes: calculate the mean then the standard deviation
+	var (
+		sum   float64
+		count int
+	)
+	for _, v := range values {
+		if v != nil {
+			sum += *v
+			count++
+		}
+	}
+	var mean, stddev float64
+	if count > 0 {
+		mean = sum / float64(count)
+		var variance float64
+		for _, v := range values {
+			if v != nil {
+				variance += (*v - mean) * (*v - mean)
+			}
+		}
+		stddev = math.Sqrt(variance / float64(count))
+	}
+	for i, r := range rows {
+		var threshold float64
+		if zh.isOutlier {
+			ve := &xsql.ValuerEval{Valuer: xsql.MultiValuer(r, zh.fv)}
+			tv := ve.Eval(zh.args[1])
+			if e, ok := tv.(error); ok {
+				return e
+			}
+			threshold, err = cast.ToFloat64(tv, cast.CONVERT_SAMEKIND)
+			if err != nil {
+				return fmt.Errorf("is_outlier requires number threshold but found %[1]T(%[1]v)", tv)
+			}
+		}
+		if values[i] == nil {
+			r.Set(zh.name, nil)
+			continue
+		}
+		var z float64
+		if stddev != 0 {
+			z = (*values[i] - mean) / stddev
+		}
+		if zh.isOutlier {
+			r.Set(zh.name, math.Abs(z) > threshold)
+		} else {
+			r.Set(zh.name, z)
+		}
+	}
+	return nil
+}
+
+type cumulativeProductFuncHandle struct {
+	name string
+	args []ast.Expr
+	fv   *xsql.FunctionValuer
+}
+
+func (ch *cumulativeProductFuncHandle) handleRows(rows []xsql.Row) error {
+	values, err := evalFloatArgs("cumulative_product", ch.args[0], rows, ch.fv)
+	if err != nil {
+		return err
+	}
+	product := 1.0
+	for i, r := range rows {
+		// nil value does not affect the product
+		if values[i] == nil {
+			r.Set(ch.name, nil)
+			continue
+		}
+		product *= *values[i]
+		r.Set(ch.name, product)
+	}
+	return nil
+}
+
+type minMaxScaleFuncHandle struct {
+	name string
+	args []ast.Expr
+	fv   *xsql.FunctionValuer
+}
+
+func (mh *minMaxScaleFuncHandle) handleRows(rows []xsql.Row) error {
+	values, err := evalFloatArgs("min_max_scale", mh.args[0], rows, mh.fv)
+	if err != nil {
+		return err
+	}
+	// two passes: find the min and max then scale each value
+	var (
+		minV, maxV float64
+		found      bool
+	)
+	for _, v := range values {
+		if v == nil {
+			continue
+		}
+		if !found || *v < minV {
+			minV = *v
+		}
+		if !found || *v > maxV {
+			maxV = *v
+		}
+		found = true
+	}
+	for i, r := range rows {
+		if values[i] == nil {
+			r.Set(mh.name, nil)
+			continue
+		}
+		var scaled float64
+		if maxV != minV {
+			scaled = (*values[i] - minV) / (maxV - minV)
+		}
+		r.Set(mh.name, scaled)
+	}
+	return nil
+}
+
+type wmaFuncHandle struct {
+	name string
+	args []ast.Expr
+	fv   *xsql.FunctionValuer
+}
+
+// handleRows calculates the weighted average of the trailing N values where N is the length of the weights.
+// The weights are ordered from the oldest to the current row. For the early rows or null values, the result
+// is normalized by the sum of the applicable weights.
+func (wh *wmaFuncHandle) handleRows(rows []xsql.Row) error {
+	values, err := evalFloatArgs("wma", wh.args[0], rows, wh.fv)
+	if err != nil {
+		return err
+	}
+	for i, r := range rows {
+		ve := &xsql.ValuerEval{Valuer: xsql.MultiValuer(r, wh.fv)}
+		wv := ve.Eval(wh.args[1])
+		if e, ok := wv.(error); ok {
+			return e
+		}
+		weights, err := cast.ToFloat64Slice(wv, cast.CONVERT_SAMEKIND, cast.FORCE_CONVERT)
+		if err != nil {
+			return fmt.Errorf("wma requires number array weights but found %[1]T(%[1]v)", wv)
+		}
+		if len(weights) == 0 {
+			return fmt.Errorf("wma requires non-empty weights")
+		}
+		if values[i] == nil {
+			r.Set(wh.name, nil)
+			continue
+		}
+		var sum, weightSum float64
+		for j := 0; j < len(weights) && j <= i; j++ {
+			v := values[i-j]
+			if v == nil {
+				continue
+			}
+			w := weights[len(weights)-1-j]
+			sum += *v * w
+			weightSum += w
+		}
+		if weightSum == 0 {
+			r.Set(wh.name, nil)
+			continue
+		}
+		r.Set(wh.name, sum/weightSum)
+	}
+	return nil
+}
+
+type emaFuncHandle struct {
+	name string
+	args []ast.Expr
+	fv   *xsql.FunctionValuer
+}
+
+// handleRows calculates the exponential moving average with the smoothing factor alpha.
+// The first non-null value seeds the average and the null values do not change it.
+func (eh *emaFuncHandle) handleRows(rows []xsql.Row) error {
+	values, err := evalFloatArgs("ema", eh.args[0], rows, eh.fv)
+	if err != nil {
+		return err
+	}
+	var (
+		ema    float64
+		seeded bool
+	)
+	for i, r := range rows {
+		ve := &xsql.ValuerEval{Valuer: xsql.MultiValuer(r, eh.fv)}
+		av := ve.Eval(eh.args[1])
+		if e, ok := av.(error); ok {
+			return e
+		}
+		alpha, err := cast.ToFloat64(av, cast.CONVERT_SAMEKIND)
+		if err != nil {
+			return fmt.Errorf("ema requires number alpha but found %[1]T(%[1]v)", av)
+		}
+		if alpha <= 0 || alpha > 1 {
+			return fmt.Errorf("ema requires alpha in (0, 1] but found %v", alpha)
+		}
+		if values[i] == nil {
+			r.Set(eh.name, nil)
+			continue
+		}
+		if !seeded {
+			ema = *values[i]
+			seeded = true
+		} else {
+			ema = alpha**values[i] + (1-alpha)*ema
+		}
+		r.Set(eh.name, ema)
+	}
+	return nil
+}
+
+type slidingSumFuncHandle struct {
+	name string
+	args []ast.Expr
+	fv   *xsql.FunctionValuer
+}
+
+// handleRows calculates the sum of the trailing n values including the current row. The early rows sum fewer
+// values and the null values are skipped.
+func (sh *slidingSumFuncHandle) handleRows(rows []xsql.Row) error {
+	values, err := evalFloatArgs("sliding_sum", sh.args[0], rows, sh.fv)
+	if err != nil {
+		return err
+	}
+	for i, r := range rows {
+		ve := &xsql.ValuerEval{Valuer: xsql.MultiValuer(r, sh.fv)}
+		nv := ve.Eval(sh.args[1])
+		if e, ok := nv.(error); ok {
+			return e
+		}
+		n, err := cast.ToInt(nv, cast.STRICT)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("sliding_sum requires positive int n but found %[1]T(%[1]v)", nv)
+		}
+		if values[i] == nil {
+			r.Set(sh.name, nil)
+			continue
+		}
+		var sum float64
+		for j := 0; j < n && j <= i; j++ {
+			if v := values[i-j]; v != nil {
+				sum += *v
+			}
+		}
+		r.Set(sh.name, sum)
+	}
+	return nil
+}
+
+type rollingRateFuncHandle struct {
+	name string
+	args []ast.Expr
+	fv   *xsql.FunctionValuer
+}
+
+// handleRows calculates the rate in events per second of the trailing n rows including the current row, which is
+// n divided by the time span between the first and the last of these rows. The rows without enough history, with a
+// null timestamp at either end or with a zero time span get nil.
+func (rh *rollingRateFuncHandle) handleRows(rows []xsql.Row) error {
+	timestamps := make([]*time.Time, len(rows))
+	for i, r := range rows {
+		ve := &xsql.ValuerEval{Valuer: xsql.MultiValuer(r, rh.fv)}
+		v := ve.Eval(rh.args[0])
+		switch vt := v.(type) {
+		case error:
+			return vt
+		case nil:
+			continue
+		case time.Time:
+			timestamps[i] = &vt
+		case int, int64:
+			ts, _ := cast.InterfaceToTime(vt, "")
+			timestamps[i] = &ts
+		case float64:
+			// the epoch milliseconds decoded from json are float64
+			if vt != math.Trunc(vt) || math.IsInf(vt, 0) {
+				return fmt.Errorf("rolling_rate requires datetime but found %[1]T(%[1]v)", v)
+			}
+			ts, _ := cast.InterfaceToTime(vt, "")
+			timestamps[i] = &ts
+		default:
+			return fmt.Errorf("rolling_rate requires datetime but found %[1]T(%[1]v)", v)
+		}
+	}
+	for i, r := range rows {
+		ve := &xsql.ValuerEval{Valuer: xsql.MultiValuer(r, rh.fv)}
+		nv := ve.Eval(rh.args[1])
+		if e, ok := nv.(error); ok {
+			return e
+		}
+		n, err := cast.ToInt(nv, cast.STRICT)
+		if err != nil || n < 2 {
+			return fmt.Errorf("rolling_rate requires int n of at least 2 but found %[1]T(%[1]v)", nv)
+		}
+		if i+1 < n || timestamps[i] == nil || timestamps[i-n+1] == nil {
+			r.Set(rh.name, nil)
+			continue
+		}
+		span := timestamps[i].Sub(*timestamps[i-n+1]).Seconds()
+		if span == 0 {
+			r.Set(rh.name, nil)
+			continue
+		}
+		r.Set(rh.name, float64(n)/math.Abs(span))
+	}
+	return nil
+}
+
+type ntileLabelFuncHandle struct {
+	name string
+	args []ast.Expr
+	fv   *xsql.FunctionValuer
+}
+
+// handleRows divides the sorted values into n buckets of about the same size and sets the label of the bucket
+// which each value falls into. The equal values always fall into the same bucket.
+func (nh *ntileLabelFuncHandle) handleRows(rows []xsql.Row) error {
+	values, err := evalFloatArgs("ntile_label", nh.args[0], rows, nh.fv)
+	if err != nil {
+		return err
+	}
+	// two passes: sort the values then find the rank of each value
+	sorted := make([]float64, 0, len(values))
+	for _, v := range values {
+		if v != nil {
+			sorted = append(sorted, *v)
+		}
+	}
+	sort.Float64s(sorted)
+	for i, r := range rows {
+		ve := &xsql.ValuerEval{Valuer: xsql.MultiValuer(r, nh.fv)}
+		nv := ve.Eval(nh.args[1])
+		if e, ok := nv.(error); ok {
+			return e
+		}
+		n, err := cast.ToInt(nv, cast.STRICT)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("ntile_label requires positive int n but found %[1]T(%[1]v)", nv)
+		}
+		lv := ve.Eval(nh.args[2])
+		if e, ok := lv.(error); ok {
+			return e
+		}
+		labels, ok := lv.([]interface{})
+		if !ok {
+			return fmt.Errorf("ntile_label requires array labels but found %[1]T(%[1]v)", lv)
+		}
+		if len(labels) != n {
+			return fmt.Errorf("ntile_label requires %d labels but found %d", n, len(labels))
+		}
+		if values[i] == nil {
+			r.Set(nh.name, nil)
+			continue
+		}
+		rank := sort.SearchFloat64s(sorted, *values[i])
+		r.Set(nh.name, labels[rank*n/len(sorted)])
+	}
+	return nil
 }
 
 func (wf *WindowFuncOperator) Apply(ctx api.StreamContext, data interface{}, fv *xsql.FunctionValuer, afv *xsql.AggregateFunctionValuer) interface{} {
@@ -59,26 +484,31 @@ func (wf *WindowFuncOperator) Apply(ctx api.StreamContext, data interface{}, fv
 		name = windowFuncField.AName
 	}
 	var funcName string
+	var args []ast.Expr
 	var pr *ast.PartitionExpr
 	var sortFields ast.SortFields
 	switch c := windowFuncField.Expr.(type) {
 	case *ast.Call:
 		funcName = c.Name
+		args = c.Args
 		pr = c.Partition
 		sortFields = c.SortFields
 	case *ast.FieldRef:
 		call := c.AliasRef.Expression.(*ast.Call)
 		funcName = call.Name
+		args = call.Args
 		pr = call.Partition
 		sortFields = call.SortFields
 	}
-	wh, err := getWindowFuncHandle(funcName, name)
+	wh, err := getWindowFuncHandle(funcName, name, args, fv)
 	if err != nil {
 		return err
 	}
 	switch input := data.(type) {
 	case xsql.Row:
-		wh.handleTuple(input)
+		if _, err := wh.handleTuple(input); err != nil {
+			return err
+		}
 	case xsql.Collection:
 		if pr != nil {
 			// handle the following case:
@@ -93,21 +523,45 @@ func (wf *WindowFuncOperator) Apply(ctx api.StreamContext, data interface{}, fv
 			// handle the following case:
 			// 1: row_number() over (order by a)
 			input = sortCollection(ctx, input, fv, afv, sortFields)
-			input = wh.handleCollection(input)
+			input, err = wh.handleCollection(input)
+			if err != nil {
+				return err
+			}
 			return input
 		}
 		// handle the following case:
 		// 1: row_number() without over clause
-		input = wh.handleCollection(input)
+		input, err = wh.handleCollection(input)
+		if err != nil {
+			return err
+		}
 		return input
 	}
 	return data
 }
 
-func getWindowFuncHandle(funcName, colName string) (windowFuncHandle, error) {
+func getWindowFuncHandle(funcName, colName string, args []ast.Expr, fv *xsql.FunctionValuer) (windowFuncHandle, error) {
 	switch funcName {
 	case "row_number":
 		return &rowNumberFuncHandle{name: colName}, nil
+	case "zscore":
+		return &rowsFuncHandle{&zscoreFuncHandle{name: colName, args: args, fv: fv}}, nil
+	case "is_outlier":
+		return &rowsFuncHandle{&zscoreFuncHandle{name: colName, args: args, fv: fv, isOutlier: true}}, nil
+	case "cumulative_product":
+		return &rowsFuncHandle{&cumulativeProductFuncHandle{name: colName, args: args, fv: fv}}, nil
+	case "min_max_scale":
+		return &rowsFuncHandle{&minMaxScaleFuncHandle{name: colName, args: args, fv: fv}}, nil
+	case "wma":
+		return &rowsFuncHandle{&wmaFuncHandle{name: colName, args: args, fv: fv}}, nil
+	case "ema":
+		return &rowsFuncHandle{&emaFuncHandle{name: colName, args: args, fv: fv}}, nil
+	case "ntile_label":
+		return &rowsFuncHandle{&ntileLabelFuncHandle{name: colName, args: args, fv: fv}}, nil
+	case "sliding_sum":
+		return &rowsFuncHandle{&slidingSumFuncHandle{name: colName, args: args, fv: fv}}, nil
+	case "rolling_rate":
+		return &rowsFuncHandle{&rollingRateFuncHandle{name: colName, args: args, fv: fv}}, nil
 	}
 	return nil, fmt.Errorf("")
 }
@@ -149,7 +603,10 @@ func partitionCollection(ctx api.StreamContext, input xsql.Collection, fv *xsql.
 	sort.Strings(keys)
 	for _, key := range keys {
 		subOutput := sortCollection(ctx, result[key], fv, afv, sortFields)
-		subOutput = wh.handleCollection(subOutput)
+		subOutput, err = wh.handleCollection(subOutput)
+		if err != nil {
+			return nil, err
+		}
 		subOutput.Range(func(i int, r xsql.ReadonlyRow) (bool, error) {
 			t := r.(xsql.Row)
 			output.AddTuple(t)
@@ -16,6 +16,7 @@ package operator
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -160,6 +161,38 @@ func TestWindowFuncApplyCollection(t *testing.T) {
 				},
 			},
 		},
+		{
+			// without over clause, the tuples are numbered in the input order
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Message: map[string]interface{}{"a": 3}},
+					&xsql.Tuple{Message: map[string]interface{}{"a": 1}},
+					&xsql.Tuple{Message: map[string]interface{}{"a": 2}},
+				},
+			},
+			op: &WindowFuncOperator{
+				WindowFuncField: &ast.Field{
+					Name: "rn",
+					Expr: &ast.Call{
+						Name: "row_number",
+					},
+				},
+			},
+			expect: []map[string]interface{}{
+				{
+					"a":  3,
+					"rn": 1,
+				},
+				{
+					"a":  1,
+					"rn": 2,
+				},
+				{
+					"a":  2,
+					"rn": 3,
+				},
+			},
+		},
 	}
 	for _, tc := range testcases {
 		contextLogger := conf.Log.WithField("rule", "TestWindowFuncApplyCollection")
@@ -169,3 +202,692 @@ func TestWindowFuncApplyCollection(t *testing.T) {
 		require.Equal(t, tc.expect, output.ToMaps())
 	}
 }
+
+func TestWindowFuncZscore(t *testing.T) {
+	newData := func(values ...interface{}) *xsql.WindowTuples {
+		data := &xsql.WindowTuples{Content: make([]xsql.Row, 0, len(values))}
+		for _, v := range values {
+			data.Content = append(data.Content, &xsql.Tuple{
+				Message: map[string]interface{}{
+					"a": v,
+				},
+			})
+		}
+		return data
+	}
+	testcases := []struct {
+		name   string
+		data   *xsql.WindowTuples
+		call   *ast.Call
+		expect []interface{}
+		err    string
+	}{
+		{
+			name: "zscore",
+			data: newData(10, 10, 10, 10, 10, 10, 10, 10, 10, 100),
+			call: &ast.Call{
+				Name: "zscore",
+				Args: []ast.Expr{&ast.FieldRef{StreamName: "demo", Name: "a"}},
+			},
+			expect: []interface{}{-1.0 / 3, -1.0 / 3, -1.0 / 3, -1.0 / 3, -1.0 / 3, -1.0 / 3, -1.0 / 3, -1.0 / 3, -1.0 / 3, 3.0},
+		},
+		{
+			name: "is_outlier",
+			data: newData(10, 10, 10, 10, 10, 10, 10, 10, 10, 100),
+			call: &ast.Call{
+				Name: "is_outlier",
+				Args: []ast.Expr{&ast.FieldRef{StreamName: "demo", Name: "a"}, &ast.IntegerLiteral{Val: 2}},
+			},
+			expect: []interface{}{false, false, false, false, false, false, false, false, false, true},
+		},
+		{
+			name: "zscore",
+			data: newData(5.5, 5.5, 5.5),
+			call: &ast.Call{
+				Name: "zscore",
+				Args: []ast.Expr{&ast.FieldRef{StreamName: "demo", Name: "a"}},
+			},
+			expect: []interface{}{0.0, 0.0, 0.0},
+		},
+		{
+			name: "is_outlier",
+			data: newData(5.5, 5.5, 5.5),
+			call: &ast.Call{
+				Name: "is_outlier",
+				Args: []ast.Expr{&ast.FieldRef{StreamName: "demo", Name: "a"}, &ast.IntegerLiteral{Val: 2}},
+			},
+			expect: []interface{}{false, false, false},
+		},
+		{
+			name: "zscore",
+			data: newData(1, "s", 3),
+			call: &ast.Call{
+				Name: "zscore",
+				Args: []ast.Expr{&ast.FieldRef{StreamName: "demo", Name: "a"}},
+			},
+			err: "zscore requires number but found string(s)",
+		},
+	}
+	contextLogger := conf.Log.WithField("rule", "TestWindowFuncZscore")
+	ctx := context.WithValue(context.Background(), context.LoggerKey, contextLogger)
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			op := &WindowFuncOperator{
+				WindowFuncField: &ast.Field{Name: tc.name, Expr: tc.call},
+			}
+			fv, afv := xsql.NewFunctionValuersForOp(nil)
+			output := op.Apply(ctx, tc.data, fv, afv)
+			if tc.err != "" {
+				require.EqualError(t, output.(error), tc.err)
+				return
+			}
+			result := make([]interface{}, 0, len(tc.expect))
+			for _, m := range output.(xsql.Collection).ToMaps() {
+				result = append(result, m[tc.name])
+			}
+			require.Equal(t, tc.expect, result)
+		})
+	}
+}
+
+func TestWindowFuncCumulativeProduct(t *testing.T) {
+	testcases := []struct {
+		data   *xsql.WindowTuples
+		expect []interface{}
+		err    string
+	}{
+		{
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Message: map[string]interface{}{"a": 3, "b": 2}},
+					&xsql.Tuple{Message: map[string]interface{}{"a": 2, "b": 1}},
+					&xsql.Tuple{Message: map[string]interface{}{"a": 1.5, "b": 3}},
+					&xsql.Tuple{Message: map[string]interface{}{"b": 4}},
+					&xsql.Tuple{Message: map[string]interface{}{"a": 2, "b": 5}},
+				},
+			},
+			expect: []interface{}{2.0, 6.0, 9.0, nil, 18.0},
+		},
+		{
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Message: map[string]interface{}{"a": 3, "b": 1}},
+					&xsql.Tuple{Message: map[string]interface{}{"a": 0, "b": 2}},
+					&xsql.Tuple{Message: map[string]interface{}{"a": 5, "b": 3}},
+				},
+			},
+			expect: []interface{}{3.0, 0.0, 0.0},
+		},
+		{
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Message: map[string]interface{}{"a": 3, "b": 1}},
+					&xsql.Tuple{Message: map[string]interface{}{"a": "x", "b": 2}},
+				},
+			},
+			err: "cumulative_product requires number but found string(x)",
+		},
+	}
+	contextLogger := conf.Log.WithField("rule", "TestWindowFuncCumulativeProduct")
+	ctx := context.WithValue(context.Background(), context.LoggerKey, contextLogger)
+	for _, tc := range testcases {
+		op := &WindowFuncOperator{
+			WindowFuncField: &ast.Field{
+				Name: "p",
+				Expr: &ast.Call{
+					Name: "cumulative_product",
+					Args: []ast.Expr{&ast.FieldRef{StreamName: "demo", Name: "a"}},
+					SortFields: []ast.SortField{
+						{
+							Name:      "b",
+							Uname:     "b",
+							Ascending: true,
+							FieldExpr: &ast.FieldRef{StreamName: "demo", Name: "b"},
+						},
+					},
+				},
+			},
+		}
+		fv, afv := xsql.NewFunctionValuersForOp(nil)
+		output := op.Apply(ctx, tc.data, fv, afv)
+		if tc.err != "" {
+			require.EqualError(t, output.(error), tc.err)
+			continue
+		}
+		result := make([]interface{}, 0, len(tc.expect))
+		for _, m := range output.(xsql.Collection).ToMaps() {
+			result = append(result, m["p"])
+		}
+		require.Equal(t, tc.expect, result)
+	}
+}
+
+func TestWindowFuncMinMaxScale(t *testing.T) {
+	testcases := []struct {
+		data   *xsql.WindowTuples
+		expect []interface{}
+		err    string
+	}{
+		{
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Message: map[string]interface{}{"a": 10}},
+					&xsql.Tuple{Message: map[string]interface{}{"a": 20}},
+					&xsql.Tuple{Message: map[string]interface{}{}},
+					&xsql.Tuple{Message: map[string]interface{}{"a": 15.0}},
+					&xsql.Tuple{Message: map[string]interface{}{"a": 12}},
+				},
+			},
+			expect: []interface{}{0.0, 1.0, nil, 0.5, 0.2},
+		},
+		{
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Message: map[string]interface{}{"a": 3}},
+					&xsql.Tuple{Message: map[string]interface{}{"a": 3}},
+				},
+			},
+			expect: []interface{}{0.0, 0.0},
+		},
+		{
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Message: map[string]interface{}{"a": 3}},
+					&xsql.Tuple{Message: map[string]interface{}{"a": "x"}},
+				},
+			},
+			err: "min_max_scale requires number but found string(x)",
+		},
+	}
+	contextLogger := conf.Log.WithField("rule", "TestWindowFuncMinMaxScale")
+	ctx := context.WithValue(context.Background(), context.LoggerKey, contextLogger)
+	for _, tc := range testcases {
+		op := &WindowFuncOperator{
+			WindowFuncField: &ast.Field{
+				Name: "s",
+				Expr: &ast.Call{
+					Name: "min_max_scale",
+					Args: []ast.Expr{&ast.FieldRef{StreamName: "demo", Name: "a"}},
+				},
+			},
+		}
+		fv, afv := xsql.NewFunctionValuersForOp(nil)
+		output := op.Apply(ctx, tc.data, fv, afv)
+		if tc.err != "" {
+			require.EqualError(t, output.(error), tc.err)
+			continue
+		}
+		result := make([]interface{}, 0, len(tc.expect))
+		for _, m := range output.(xsql.Collection).ToMaps() {
+			v := m["s"]
+			if v != nil {
+				require.GreaterOrEqual(t, v.(float64), 0.0)
+				require.LessOrEqual(t, v.(float64), 1.0)
+			}
+			result = append(result, v)
+		}
+		require.Equal(t, tc.expect, result)
+	}
+}
+
+func TestWindowFuncWma(t *testing.T) {
+	weights := &ast.Call{
+		Name: "array_create",
+		Args: []ast.Expr{&ast.IntegerLiteral{Val: 1}, &ast.IntegerLiteral{Val: 2}, &ast.IntegerLiteral{Val: 3}},
+	}
+	testcases := []struct {
+		data    *xsql.WindowTuples
+		weights ast.Expr
+		expect  []interface{}
+		err     string
+	}{
+		{
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Message: map[string]interface{}{"a": 10}},
+					&xsql.Tuple{Message: map[string]interface{}{"a": 20}},
+					&xsql.Tuple{Message: map[string]interface{}{"a": 30}},
+					&xsql.Tuple{Message: map[string]interface{}{"a": 40.0}},
+					&xsql.Tuple{Message: map[string]interface{}{}},
+				},
+			},
+			weights: weights,
+			// 10, (10*2+20*3)/5, (10+20*2+30*3)/6, (20+30*2+40*3)/6, nil
+			expect: []interface{}{10.0, 16.0, 140.0 / 6, 200.0 / 6, nil},
+		},
+		{
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Message: map[string]interface{}{"a": 10}},
+					&xsql.Tuple{Message: map[string]interface{}{}},
+					&xsql.Tuple{Message: map[string]interface{}{"a": 40}},
+				},
+			},
+			weights: weights,
+			// the null value is excluded from the normalization
+			expect: []interface{}{10.0, nil, (10.0 + 40*3) / 4},
+		},
+		{
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Message: map[string]interface{}{"a": 3}},
+					&xsql.Tuple{Message: map[string]interface{}{"a": "x"}},
+				},
+			},
+			weights: weights,
+			err:     "wma requires number but found string(x)",
+		},
+		{
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Message: map[string]interface{}{"a": 3}},
+				},
+			},
+			weights: &ast.Call{Name: "array_create", Args: []ast.Expr{&ast.StringLiteral{Val: "x"}}},
+			err:     "wma requires number array weights but found []interface {}([x])",
+		},
+	}
+	contextLogger := conf.Log.WithField("rule", "TestWindowFuncWma")
+	ctx := context.WithValue(context.Background(), context.LoggerKey, contextLogger)
+	for _, tc := range testcases {
+		op := &WindowFuncOperator{
+			WindowFuncField: &ast.Field{
+				Name: "w",
+				Expr: &ast.Call{
+					Name: "wma",
+					Args: []ast.Expr{&ast.FieldRef{StreamName: "demo", Name: "a"}, tc.weights},
+				},
+			},
+		}
+		fv, afv := xsql.NewFunctionValuersForOp(nil)
+		output := op.Apply(ctx, tc.data, fv, afv)
+		if tc.err != "" {
+			require.EqualError(t, output.(error), tc.err)
+			continue
+		}
+		result := make([]interface{}, 0, len(tc.expect))
+		for _, m := range output.(xsql.Collection).ToMaps() {
+			result = append(result, m["w"])
+		}
+		require.Equal(t, tc.expect, result)
+	}
+}
+
+func TestWindowFuncEma(t *testing.T) {
+	testcases := []struct {
+		data   *xsql.WindowTuples
+		alpha  ast.Expr
+		expect []interface{}
+		err    string
+	}{
+		{
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Message: map[string]interface{}{"a": 10}},
+					&xsql.Tuple{Message: map[string]interface{}{"a": 20}},
+					&xsql.Tuple{Message: map[string]interface{}{"a": 30.0}},
+					&xsql.Tuple{Message: map[string]interface{}{"a": 10}},
+				},
+			},
+			alpha: &ast.NumberLiteral{Val: 0.5},
+			// 10, 0.5*20+0.5*10, 0.5*30+0.5*15, 0.5*10+0.5*22.5
+			expect: []interface{}{10.0, 15.0, 22.5, 16.25},
+		},
+		{
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Message: map[string]interface{}{}},
+					&xsql.Tuple{Message: map[string]interface{}{"a": 10}},
+					&xsql.Tuple{Message: map[string]interface{}{}},
+					&xsql.Tuple{Message: map[string]interface{}{"a": 20}},
+				},
+			},
+			alpha: &ast.NumberLiteral{Val: 0.25},
+			// the first non-null value seeds and the null values are skipped
+			expect: []interface{}{nil, 10.0, nil, 12.5},
+		},
+		{
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Message: map[string]interface{}{"a": 10}},
+					&xsql.Tuple{Message: map[string]interface{}{"a": 20}},
+				},
+			},
+			alpha:  &ast.IntegerLiteral{Val: 1},
+			expect: []interface{}{10.0, 20.0},
+		},
+		{
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Message: map[string]interface{}{"a": 3}},
+					&xsql.Tuple{Message: map[string]interface{}{"a": "x"}},
+				},
+			},
+			alpha: &ast.NumberLiteral{Val: 0.5},
+			err:   "ema requires number but found string(x)",
+		},
+		{
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Message: map[string]interface{}{"a": 3, "b": 2}},
+				},
+			},
+			alpha: &ast.FieldRef{StreamName: "demo", Name: "b"},
+			err:   "ema requires alpha in (0, 1] but found 2",
+		},
+	}
+	contextLogger := conf.Log.WithField("rule", "TestWindowFuncEma")
+	ctx := context.WithValue(context.Background(), context.LoggerKey, contextLogger)
+	for _, tc := range testcases {
+		op := &WindowFuncOperator{
+			WindowFuncField: &ast.Field{
+				Name: "e",
+				Expr: &ast.Call{
+					Name: "ema",
+					Args: []ast.Expr{&ast.FieldRef{StreamName: "demo", Name: "a"}, tc.alpha},
+				},
+			},
+		}
+		fv, afv := xsql.NewFunctionValuersForOp(nil)
+		output := op.Apply(ctx, tc.data, fv, afv)
+		if tc.err != "" {
+			require.EqualError(t, output.(error), tc.err)
+			continue
+		}
+		result := make([]interface{}, 0, len(tc.expect))
+		for _, m := range output.(xsql.Collection).ToMaps() {
+			result = append(result, m["e"])
+		}
+		require.Equal(t, tc.expect, result)
+	}
+}
+
+func TestWindowFuncNtileLabel(t *testing.T) {
+	labels := func(ls ...string) ast.Expr {
+		args := make([]ast.Expr, len(ls))
+		for i, l := range ls {
+			args[i] = &ast.StringLiteral{Val: l}
+		}
+		return &ast.Call{Name: "array_create", FuncType: ast.FuncTypeScalar, Args: args}
+	}
+	testcases := []struct {
+		data   *xsql.WindowTuples
+		n      int64
+		labels ast.Expr
+		expect []interface{}
+		err    string
+	}{
+		{
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Message: map[string]interface{}{"a": 50}},
+					&xsql.Tuple{Message: map[string]interface{}{"a": 10}},
+					&xsql.Tuple{Message: map[string]interface{}{"a": 80.5}},
+					&xsql.Tuple{Message: map[string]interface{}{"a": 30}},
+					&xsql.Tuple{Message: map[string]interface{}{"a": 70}},
+					&xsql.Tuple{Message: map[string]interface{}{"a": 20}},
+				},
+			},
+			n:      3,
+			labels: labels("low", "medium", "high"),
+			expect: []interface{}{"medium", "low", "high", "medium", "high", "low"},
+		},
+		{
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Message: map[string]interface{}{"a": 1}},
+					&xsql.Tuple{Message: map[string]interface{}{}},
+					&xsql.Tuple{Message: map[string]interface{}{"a": 2}},
+					&xsql.Tuple{Message: map[string]interface{}{"a": 2}},
+					&xsql.Tuple{Message: map[string]interface{}{"a": 3}},
+				},
+			},
+			n:      2,
+			labels: labels("bottom", "top"),
+			// nil values are not ranked and the equal values fall into the same bucket
+			expect: []interface{}{"bottom", nil, "bottom", "bottom", "top"},
+		},
+		{
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Message: map[string]interface{}{"a": 1}},
+				},
+			},
+			n:      4,
+			labels: labels("q1", "q2", "q3", "q4"),
+			expect: []interface{}{"q1"},
+		},
+		{
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Message: map[string]interface{}{"a": 1}},
+				},
+			},
+			n:      3,
+			labels: labels("low", "high"),
+			err:    "ntile_label requires 3 labels but found 2",
+		},
+		{
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Message: map[string]interface{}{"a": "x"}},
+				},
+			},
+			n:      1,
+			labels: labels("all"),
+			err:    "ntile_label requires number but found string(x)",
+		},
+	}
+	contextLogger := conf.Log.WithField("rule", "TestWindowFuncNtileLabel")
+	ctx := context.WithValue(context.Background(), context.LoggerKey, contextLogger)
+	for _, tc := range testcases {
+		op := &WindowFuncOperator{
+			WindowFuncField: &ast.Field{
+				Name: "l",
+				Expr: &ast.Call{
+					Name: "ntile_label",
+					Args: []ast.Expr{&ast.FieldRef{StreamName: "demo", Name: "a"}, &ast.IntegerLiteral{Val: tc.n}, tc.labels},
+				},
+			},
+		}
+		fv, afv := xsql.NewFunctionValuersForOp(nil)
+		output := op.Apply(ctx, tc.data, fv, afv)
+		if tc.err != "" {
+			require.EqualError(t, output.(error), tc.err)
+			continue
+		}
+		result := make([]interface{}, 0, len(tc.expect))
+		for _, m := range output.(xsql.Collection).ToMaps() {
+			result = append(result, m["l"])
+		}
+		require.Equal(t, tc.expect, result)
+	}
+}
+
+func TestWindowFuncSlidingSum(t *testing.T) {
+	testcases := []struct {
+		data   *xsql.WindowTuples
+		n      int64
+		expect []interface{}
+		err    string
+	}{
+		{
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Message: map[string]interface{}{"a": 3, "b": 2}},
+					&xsql.Tuple{Message: map[string]interface{}{"a": 1, "b": 1}},
+					&xsql.Tuple{Message: map[string]interface{}{"a": 2.5, "b": 3}},
+					&xsql.Tuple{Message: map[string]interface{}{"a": 4, "b": 4}},
+					&xsql.Tuple{Message: map[string]interface{}{"b": 5}},
+					&xsql.Tuple{Message: map[string]interface{}{"a": 6, "b": 6}},
+				},
+			},
+			n:      2,
+			expect: []interface{}{1.0, 4.0, 5.5, 6.5, nil, 6.0},
+		},
+		{
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Message: map[string]interface{}{"a": 3, "b": 1}},
+					&xsql.Tuple{Message: map[string]interface{}{"a": 2, "b": 2}},
+					&xsql.Tuple{Message: map[string]interface{}{"a": 5, "b": 3}},
+				},
+			},
+			n:      5,
+			expect: []interface{}{3.0, 5.0, 10.0},
+		},
+		{
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Message: map[string]interface{}{"a": 3, "b": 1}},
+					&xsql.Tuple{Message: map[string]interface{}{"a": "x", "b": 2}},
+				},
+			},
+			n:   2,
+			err: "sliding_sum requires number but found string(x)",
+		},
+		{
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Message: map[string]interface{}{"a": 3, "b": 1}},
+				},
+			},
+			n:   0,
+			err: "sliding_sum requires positive int n but found int64(0)",
+		},
+	}
+	contextLogger := conf.Log.WithField("rule", "TestWindowFuncSlidingSum")
+	ctx := context.WithValue(context.Background(), context.LoggerKey, contextLogger)
+	for _, tc := range testcases {
+		op := &WindowFuncOperator{
+			WindowFuncField: &ast.Field{
+				Name: "s",
+				Expr: &ast.Call{
+					Name: "sliding_sum",
+					Args: []ast.Expr{&ast.FieldRef{StreamName: "demo", Name: "a"}, &ast.IntegerLiteral{Val: tc.n}},
+					SortFields: []ast.SortField{
+						{
+							Name:      "b",
+							Uname:     "b",
+							Ascending: true,
+							FieldExpr: &ast.FieldRef{StreamName: "demo", Name: "b"},
+						},
+					},
+				},
+			},
+		}
+		fv, afv := xsql.NewFunctionValuersForOp(nil)
+		output := op.Apply(ctx, tc.data, fv, afv)
+		if tc.err != "" {
+			require.EqualError(t, output.(error), tc.err)
+			continue
+		}
+		result := make([]interface{}, 0, len(tc.expect))
+		for _, m := range output.(xsql.Collection).ToMaps() {
+			result = append(result, m["s"])
+		}
+		require.Equal(t, tc.expect, result)
+	}
+}
+
+func TestWindowFuncRollingRate(t *testing.T) {
+	base := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	testcases := []struct {
+		data   *xsql.WindowTuples
+		n      int64
+		expect []interface{}
+		err    string
+	}{
+		{
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Message: map[string]interface{}{"ts": base}},
+					&xsql.Tuple{Message: map[string]interface{}{"ts": base.Add(500 * time.Millisecond)}},
+					&xsql.Tuple{Message: map[string]interface{}{"ts": base.Add(2 * time.Second)}},
+					&xsql.Tuple{Message: map[string]interface{}{"ts": base.Add(4 * time.Second)}},
+					&xsql.Tuple{Message: map[string]interface{}{"ts": base.Add(4 * time.Second)}},
+				},
+			},
+			n:      2,
+			expect: []interface{}{nil, 4.0, 4.0 / 3, 1.0, nil},
+		},
+		{
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Message: map[string]interface{}{"ts": base.UnixMilli()}},
+					&xsql.Tuple{Message: map[string]interface{}{"ts": base.UnixMilli() + 1000}},
+					&xsql.Tuple{Message: map[string]interface{}{"ts": base.UnixMilli() + 1500}},
+					&xsql.Tuple{Message: map[string]interface{}{}},
+					&xsql.Tuple{Message: map[string]interface{}{"ts": base.UnixMilli() + 6000}},
+					&xsql.Tuple{Message: map[string]interface{}{"ts": base.UnixMilli() + 8000}},
+				},
+			},
+			n:      3,
+			expect: []interface{}{nil, nil, 2.0, nil, 3 / 4.5, nil},
+		},
+		{
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Message: map[string]interface{}{"ts": base}},
+					&xsql.Tuple{Message: map[string]interface{}{"ts": true}},
+				},
+			},
+			n:   2,
+			err: "rolling_rate requires datetime but found bool(true)",
+		},
+		{
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Message: map[string]interface{}{"ts": float64(base.UnixMilli())}},
+					&xsql.Tuple{Message: map[string]interface{}{"ts": float64(base.UnixMilli() + 500)}},
+					&xsql.Tuple{Message: map[string]interface{}{"ts": float64(base.UnixMilli() + 2000)}},
+				},
+			},
+			n:      2,
+			expect: []interface{}{nil, 4.0, 4.0 / 3},
+		},
+		{
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Message: map[string]interface{}{"ts": float64(base.UnixMilli())}},
+					&xsql.Tuple{Message: map[string]interface{}{"ts": 1.5}},
+				},
+			},
+			n:   2,
+			err: "rolling_rate requires datetime but found float64(1.5)",
+		},
+		{
+			data: &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Message: map[string]interface{}{"ts": base}},
+				},
+			},
+			n:   1,
+			err: "rolling_rate requires int n of at least 2 but found int64(1)",
+		},
+	}
+	contextLogger := conf.Log.WithField("rule", "TestWindowFuncRollingRate")
+	ctx := context.WithValue(context.Background(), context.LoggerKey, contextLogger)
+	for _, tc := range testcases {
+		op := &WindowFuncOperator{
+			WindowFuncField: &ast.Field{
+				Name: "r",
+				Expr: &ast.Call{
+					Name: "rolling_rate",
+					Args: []ast.Expr{&ast.FieldRef{StreamName: "demo", Name: "ts"}, &ast.IntegerLiteral{Val: tc.n}},
+				},
+			},
+		}
+		fv, afv := xsql.NewFunctionValuersForOp(nil)
+		output := op.Apply(ctx, tc.data, fv, afv)
+		if tc.err != "" {
+			require.EqualError(t, output.(error), tc.err)
+			continue
+		}
+		result := make([]interface{}, 0, len(tc.expect))
+		for _, m := range output.(xsql.Collection).ToMaps() {
+			result = append(result, m["r"])
+		}
+		require.Equal(t, tc.expect, result)
+	}
+}
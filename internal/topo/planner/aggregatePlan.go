@@ -33,6 +33,12 @@ func (p *AggregatePlan) BuildExplainInfo() {
 		info += "Dimension:{ "
 		for i, dimension := range p.dimensions {
 			if dimension.Expr != nil {
+				if dimension.Rollup {
+					info += "rollup:"
+				}
+				if len(dimension.GroupingSets) > 0 {
+					info += "groupingSets:"
+				}
 				info += dimension.Expr.String()
 				if i != len(p.dimensions)-1 {
 					info += ", "
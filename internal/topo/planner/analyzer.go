@@ -121,6 +121,7 @@ func decorateStmt(s *ast.SelectStatement, store kv.KeyValue, opt *def.RuleOption
 				AliasRef:   ar,
 			}
 			walkErr = fieldsMap.save(f.AName, ast.AliasStream, ar)
+			bindAliasInAggArgs(s.Fields, f.AName, ar)
 			if opt.PlanOptimizeStrategy.IsAliasRefCalEnable() {
 				for _, subF := range s.Fields {
 					if f.AName == subF.AName {
@@ -212,6 +213,33 @@ func decorateStmt(s *ast.SelectStatement, store kv.KeyValue, opt *def.RuleOption
 	return streamStmts, analyticFuncs, analyticFieldFuncs, walkErr
 }
 
+// bindAliasInAggArgs binds the field refs to the alias inside the aggregate function args such as
+// select a + b as s invisible, sum(s) from demo. The alias is defined per row, so it must be calculated
+// for each row of the group instead of reading the alias value of the group.
+func bindAliasInAggArgs(fields ast.Fields, aliasName string, ar *ast.AliasRef) {
+	for _, f := range fields {
+		if f.AName == aliasName {
+			continue
+		}
+		ast.WalkFunc(f.Expr, func(n ast.Node) bool {
+			c, ok := n.(*ast.Call)
+			if !ok || c.FuncType != ast.FuncTypeAgg {
+				return true
+			}
+			for _, arg := range c.Args {
+				ast.WalkFunc(arg, func(node ast.Node) bool {
+					if fr, ok := node.(*ast.FieldRef); ok && fr.Name == aliasName && fr.StreamName == ast.DefaultStream {
+						fr.StreamName = ast.AliasStream
+						fr.AliasRef = ar
+					}
+					return true
+				})
+			}
+			return false
+		})
+	}
+}
+
 type aliasTopoDegree struct {
 	alias  string
 	degree int
@@ -467,7 +495,7 @@ func allAggregate(expr ast.Expr) (r bool) {
 		switch f := expr.(type) {
 		case *ast.BinaryExpr:
 			switch f.OP {
-			case ast.SUBSET, ast.ARROW:
+			case ast.SUBSET, ast.ARROW, ast.SAFE_ARROW:
 				// do nothing
 			default:
 				r = allAggregate(f.LHS) && allAggregate(f.RHS)
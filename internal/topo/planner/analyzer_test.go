@@ -24,9 +24,12 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/lf-edge/ekuiper/v2/internal/conf"
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/store"
 	"github.com/lf-edge/ekuiper/v2/internal/testx"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/context"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/operator"
 	"github.com/lf-edge/ekuiper/v2/internal/xsql"
 	"github.com/lf-edge/ekuiper/v2/pkg/ast"
 )
@@ -507,3 +510,39 @@ func TestValidateStmt(t *testing.T) {
 	err = validate(stmt)
 	require.Error(t, err)
 }
+
+func TestAliasInAggregateArgs(t *testing.T) {
+	kv, err := store.GetKV("stream")
+	require.NoError(t, err)
+	s, err := json.Marshal(&xsql.StreamInfo{
+		StreamType: ast.TypeStream,
+		Statement:  `CREATE STREAM src1 () WITH (DATASOURCE="src1", FORMAT="json", KEY="ts");`,
+	})
+	require.NoError(t, err)
+	require.NoError(t, kv.Set("src1", string(s)))
+
+	// The invisible alias must be calculated for each row when referred by the aggregate function even the alias ref calculation is disabled
+	for _, strategy := range []*def.PlanOptimizeStrategy{nil, {DisableAliasRefCal: true}} {
+		stmt, err := xsql.NewParser(strings.NewReader("SELECT a + b AS s INVISIBLE, sum(s) AS total FROM src1 GROUP BY TumblingWindow(ss, 10)")).Parse()
+		require.NoError(t, err)
+		lp, err := CreateLogicalPlan(stmt, &def.RuleOption{SendError: true, PlanOptimizeStrategy: strategy}, kv)
+		require.NoError(t, err)
+		pp, ok := lp.(*ProjectPlan)
+		require.True(t, ok)
+		arg := pp.fields[1].Expr.(*ast.FieldRef).AliasRef.Expression.(*ast.Call).Args[0].(*ast.FieldRef)
+		require.True(t, arg.IsAlias())
+
+		op := &operator.ProjectOp{Fields: pp.fields, FieldLen: pp.fieldLen, ColNames: pp.colNames, AliasFields: pp.aliasFields, ExprFields: pp.exprFields, IsAggregate: pp.isAggregate, WildcardEmitters: pp.wildcardEmitters}
+		ctx := context.WithValue(context.Background(), context.LoggerKey, conf.Log)
+		fv, afv := xsql.NewFunctionValuersForOp(nil)
+		data := &xsql.WindowTuples{
+			Content: []xsql.Row{
+				&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"a": 1, "b": 10}},
+				&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"a": 2, "b": 20}},
+				&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"a": 3, "b": 30}},
+			},
+		}
+		result := op.Apply(ctx, data, fv, afv)
+		require.Equal(t, []map[string]interface{}{{"total": int64(66)}}, result.(xsql.Collection).ToMaps())
+	}
+}
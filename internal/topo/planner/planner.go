@@ -443,9 +443,14 @@ func buildOps(lp LogicalPlan, tp *topo.Topo, options *def.RuleOption, sources ma
 	case *OrderPlan:
 		op = Transform(&operator.OrderOp{SortFields: t.SortFields}, fmt.Sprintf("%d_order", newIndex), options)
 	case *ProjectPlan:
-		op = Transform(&operator.ProjectOp{Fields: t.fields, FieldLen: t.fieldLen, ColNames: t.colNames, AliasFields: t.aliasFields, ExprFields: t.exprFields, ExceptNames: t.exceptNames, IsAggregate: t.isAggregate, AllWildcard: t.allWildcard, WildcardEmitters: t.wildcardEmitters, SendMeta: t.sendMeta, SendNil: t.sendNil, LimitCount: t.limitCount, EnableLimit: t.enableLimit}, fmt.Sprintf("%d_project", newIndex), options)
+		var pp *operator.ProjectOp
+		pp, err = newProjectOp(t)
+		if err != nil {
+			return nil, 0, err
+		}
+		op = Transform(pp, fmt.Sprintf("%d_project", newIndex), options)
 	case *ProjectSetPlan:
-		op = Transform(&operator.ProjectSetOperator{SrfMapping: t.SrfMapping, LimitCount: t.limitCount, EnableLimit: t.enableLimit}, fmt.Sprintf("%d_projectset", newIndex), options)
+		op = Transform(&operator.ProjectSetOperator{SrfMapping: t.SrfMapping, SrfAlias: t.srfAlias, LimitCount: t.limitCount, EnableLimit: t.enableLimit, SendNil: t.sendNil}, fmt.Sprintf("%d_projectset", newIndex), options)
 	case *WindowFuncPlan:
 		op = Transform(&operator.WindowFuncOperator{WindowFuncField: t.windowFuncField}, fmt.Sprintf("%d_windowFunc", newIndex), options)
 	default:
@@ -460,6 +465,14 @@ func buildOps(lp LogicalPlan, tp *topo.Topo, options *def.RuleOption, sources ma
 	return op, newIndex, nil
 }
 
+func newProjectOp(t *ProjectPlan) (*operator.ProjectOp, error) {
+	pp := &operator.ProjectOp{Fields: t.fields, FieldLen: t.fieldLen, ColNames: t.colNames, AliasFields: t.aliasFields, ExprFields: t.exprFields, ExceptNames: t.exceptNames, ExceptMatching: t.exceptMatching, IsAggregate: t.isAggregate, AllWildcard: t.allWildcard, WildcardEmitters: t.wildcardEmitters, SendMeta: t.sendMeta, SendNil: t.sendNil, LimitCount: t.limitCount, EnableLimit: t.enableLimit, Distinct: t.distinct, OutputSchema: t.outputSchema, DedupKeys: t.dedupKeys, LenientIndex: t.lenientIndex, PreferIntResults: t.preferIntResults, KeyCase: t.keyCase, RequireFields: t.requireFields}
+	if err := pp.ValidateKeyCase(); err != nil {
+		return nil, err
+	}
+	return pp, nil
+}
+
 func convertFromDuration(timeUnit ast.Token, length, interval int, delay int64) (time.Duration, time.Duration, time.Duration) {
 	var unit time.Duration
 	switch timeUnit {
@@ -800,13 +813,20 @@ func createLogicalPlanFull(stmt *ast.SelectStatement, opt *def.RuleOption, store
 			limitCount = int(stmt.Limit.(*ast.LimitExpr).LimitCount.Val)
 		}
 		p = ProjectPlan{
-			fields:      fields,
-			fieldLen:    fieldLen,
-			isAggregate: xsql.WithAggFields(stmt) && len(rewriteRes.incAggFields) < 1,
-			sendMeta:    opt.SendMetaToSink,
-			sendNil:     opt.SendNil,
-			enableLimit: enableLimit,
-			limitCount:  limitCount,
+			fields:           fields,
+			fieldLen:         fieldLen,
+			isAggregate:      xsql.WithAggFields(stmt) && len(rewriteRes.incAggFields) < 1,
+			sendMeta:         opt.SendMetaToSink,
+			sendNil:          opt.SendNil,
+			enableLimit:      enableLimit,
+			limitCount:       limitCount,
+			distinct:         stmt.Distinct,
+			outputSchema:     opt.OutputSchema,
+			dedupKeys:        opt.DedupKeys,
+			lenientIndex:     opt.LenientIndex,
+			preferIntResults: opt.PreferIntResults,
+			keyCase:          opt.KeyCase,
+			requireFields:    opt.RequireFields,
 		}.Init()
 		p.SetChildren(children)
 		children = []LogicalPlan{p}
@@ -824,8 +844,10 @@ func createLogicalPlanFull(stmt *ast.SelectStatement, opt *def.RuleOption, store
 		}
 		p = ProjectSetPlan{
 			SrfMapping:  srfMapping,
+			srfAlias:    extractSRFAlias(stmt),
 			enableLimit: enableLimit,
 			limitCount:  limitCount,
+			sendNil:     opt.SendNil,
 		}.Init()
 		p.SetChildren(children)
 	}
@@ -854,6 +876,23 @@ func extractSRFMapping(stmt *ast.SelectStatement) map[string]struct{} {
 	return m
 }
 
+// extractSRFAlias extracts the aliased set-returning-function in the field
+func extractSRFAlias(stmt *ast.SelectStatement) map[string]struct{} {
+	var m map[string]struct{}
+	for _, field := range stmt.Fields {
+		if len(field.AName) == 0 {
+			continue
+		}
+		if f, ok := field.Expr.(*ast.FieldRef).AliasRef.Expression.(*ast.Call); ok && f.FuncType == ast.FuncTypeSrf {
+			if m == nil {
+				m = make(map[string]struct{})
+			}
+			m[field.AName] = struct{}{}
+		}
+	}
+	return m
+}
+
 func Transform(op node.UnOperation, name string, options *def.RuleOption) *node.UnaryOperator {
 	unaryOperator := node.New(name, options)
 	unaryOperator.SetOperation(op)
@@ -927,6 +966,11 @@ func rewriteIfIncAggStmt(stmt *ast.SelectStatement, opt *def.RuleOption) []*ast.
 	if stmt.Joins != nil {
 		return nil
 	}
+	for _, d := range stmt.Dimensions {
+		if d.Rollup || len(d.GroupingSets) > 0 {
+			return nil
+		}
+	}
 	index := 0
 	incAggFields, canIncAgg := extractNodeIncAgg(stmt.Fields, &index)
 	if !canIncAgg {
@@ -944,7 +988,8 @@ func extractNodeIncAgg(node ast.Node, index *int) ([]*ast.Field, bool) {
 		case *ast.Call:
 			if f.FuncType == ast.FuncTypeAgg {
 				hasAgg = true
-				if !function.IsSupportedIncAgg(f.Name) {
+				// ordered aggregate like collect(a ORDER BY ts) and filtered aggregate need the whole group
+				if !function.IsSupportedIncAgg(f.Name) || len(f.SortFields) > 0 || f.FilterExpr != nil || f.Distinct {
 					canIncAgg = false
 					return false
 				}
@@ -728,7 +728,7 @@ func parsePick(props map[string]interface{}, sourceNames []string) (*operator.Pr
 		fields:      stmt.Fields,
 		isAggregate: n.IsAgg,
 	}.Init()
-	return &operator.ProjectOp{Fields: t.fields, FieldLen: len(t.fields), ColNames: t.colNames, AliasFields: t.aliasFields, ExprFields: t.exprFields, ExceptNames: t.exceptNames, IsAggregate: t.isAggregate, AllWildcard: t.allWildcard, WildcardEmitters: t.wildcardEmitters, SendMeta: t.sendMeta, SendNil: t.sendNil}, nil
+	return &operator.ProjectOp{Fields: t.fields, FieldLen: len(t.fields), ColNames: t.colNames, AliasFields: t.aliasFields, ExprFields: t.exprFields, ExceptNames: t.exceptNames, ExceptMatching: t.exceptMatching, IsAggregate: t.isAggregate, AllWildcard: t.allWildcard, WildcardEmitters: t.wildcardEmitters, SendMeta: t.sendMeta, SendNil: t.sendNil}, nil
 }
 
 func parseFunc(props map[string]interface{}, sourceNames []string) (*operator.FuncOp, error) {
@@ -0,0 +1,134 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package planner
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/store"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/operator"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+func TestProjectOpOptions(t *testing.T) {
+	kv, err := store.GetKV("stream")
+	require.NoError(t, err)
+	s, err := json.Marshal(&xsql.StreamInfo{
+		StreamType: ast.TypeStream,
+		Statement:  `CREATE STREAM projectOptSrc () WITH (DATASOURCE="src1", FORMAT="json", KEY="ts");`,
+	})
+	require.NoError(t, err)
+	require.NoError(t, kv.Set("projectOptSrc", string(s)))
+
+	tests := []struct {
+		name   string
+		sql    string
+		opt    *def.RuleOption
+		assert func(t *testing.T, op *operator.ProjectOp)
+		err    string
+	}{
+		{
+			name: "default",
+			sql:  "SELECT a, b FROM projectOptSrc",
+			opt:  &def.RuleOption{},
+			assert: func(t *testing.T, op *operator.ProjectOp) {
+				require.Nil(t, op.OutputSchema)
+			},
+		},
+		{
+			name: "outputSchema",
+			sql:  "SELECT a, b FROM projectOptSrc",
+			opt:  &def.RuleOption{OutputSchema: []string{"b", "a", "c"}},
+			assert: func(t *testing.T, op *operator.ProjectOp) {
+				require.Equal(t, []string{"b", "a", "c"}, op.OutputSchema)
+			},
+		},
+		{
+			name: "dedupKeys",
+			sql:  "SELECT id, v FROM projectOptSrc",
+			opt:  &def.RuleOption{DedupKeys: []string{"id"}},
+			assert: func(t *testing.T, op *operator.ProjectOp) {
+				require.Equal(t, []string{"id"}, op.DedupKeys)
+			},
+		},
+		{
+			name: "lenientIndex",
+			sql:  "SELECT labels[floor(v / 10)] AS l FROM projectOptSrc",
+			opt:  &def.RuleOption{LenientIndex: true},
+			assert: func(t *testing.T, op *operator.ProjectOp) {
+				require.True(t, op.LenientIndex)
+			},
+		},
+		{
+			name: "preferIntResults",
+			sql:  "SELECT sum(v) AS s FROM projectOptSrc GROUP BY TumblingWindow(ss, 10)",
+			opt:  &def.RuleOption{PreferIntResults: true},
+			assert: func(t *testing.T, op *operator.ProjectOp) {
+				require.True(t, op.PreferIntResults)
+			},
+		},
+		{
+			name: "keyCase",
+			sql:  "SELECT Temp, b AS Hum FROM projectOptSrc",
+			opt:  &def.RuleOption{KeyCase: "lower"},
+			assert: func(t *testing.T, op *operator.ProjectOp) {
+				require.Equal(t, operator.KeyCaseLower, op.KeyCase)
+			},
+		},
+		{
+			name: "invalid keyCase",
+			sql:  "SELECT a FROM projectOptSrc",
+			opt:  &def.RuleOption{KeyCase: "camel"},
+			err:  `invalid key case "camel", expect none, lower or upper`,
+		},
+		{
+			name: "keyCase collision",
+			sql:  "SELECT temp, b AS Temp FROM projectOptSrc",
+			opt:  &def.RuleOption{KeyCase: "upper"},
+			err:  "output fields temp and Temp collide after converting to upper case",
+		},
+		{
+			name: "requireFields",
+			sql:  "SELECT id, temp FROM projectOptSrc",
+			opt:  &def.RuleOption{RequireFields: []string{"temp"}},
+			assert: func(t *testing.T, op *operator.ProjectOp) {
+				require.Equal(t, []string{"temp"}, op.RequireFields)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt, err := xsql.NewParser(strings.NewReader(tt.sql)).Parse()
+			require.NoError(t, err)
+			lp, err := CreateLogicalPlan(stmt, tt.opt, kv)
+			require.NoError(t, err)
+			pp, ok := lp.(*ProjectPlan)
+			require.True(t, ok, "unexpected plan %T", lp)
+			op, err := newProjectOp(pp)
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			tt.assert(t, op)
+		})
+	}
+}
@@ -410,12 +410,48 @@ func Test_createLogicalPlan(t *testing.T) {
 				enableLimit: true,
 			}.Init(),
 		},
+		{
+			sql: "select distinct name from src1 limit 1",
+			p: ProjectPlan{
+				baseLogicalPlan: baseLogicalPlan{
+					children: []LogicalPlan{
+						DataSourcePlan{
+							baseLogicalPlan: baseLogicalPlan{},
+							name:            "src1",
+							streamFields: map[string]*ast.JsonStreamField{
+								"name": {
+									Type: "string",
+								},
+							},
+							streamStmt:  streams["src1"],
+							metaFields:  []string{},
+							pruneFields: []string{},
+						}.Init(),
+					},
+				},
+				fields: []ast.Field{
+					{
+						Name: "name",
+						Expr: &ast.FieldRef{
+							StreamName: "src1",
+							Name:       "name",
+						},
+					},
+				},
+				limitCount:  1,
+				enableLimit: true,
+				distinct:    true,
+			}.Init(),
+		},
 		{
 			sql: "select unnest(myarray) as col from src1 limit 1",
 			p: ProjectSetPlan{
 				SrfMapping: map[string]struct{}{
 					"col": {},
 				},
+				srfAlias: map[string]struct{}{
+					"col": {},
+				},
 				limitCount:  1,
 				enableLimit: true,
 				baseLogicalPlan: baseLogicalPlan{
@@ -15,6 +15,7 @@
 package planner
 
 import (
+	"regexp"
 	"strconv"
 
 	"github.com/lf-edge/ekuiper/v2/pkg/ast"
@@ -30,11 +31,19 @@ type ProjectPlan struct {
 	fieldLen         int
 	colNames         [][]string
 	exceptNames      []string
+	exceptMatching   []*regexp.Regexp
 	wildcardEmitters map[string]bool
 	aliasFields      ast.Fields
 	exprFields       ast.Fields
 	enableLimit      bool
 	limitCount       int
+	distinct         bool
+	outputSchema     []string
+	dedupKeys        []string
+	lenientIndex     bool
+	preferIntResults bool
+	keyCase          string
+	requireFields    []string
 }
 
 func (p ProjectPlan) Init() *ProjectPlan {
@@ -48,6 +57,7 @@ func (p ProjectPlan) Init() *ProjectPlan {
 			case *ast.Wildcard:
 				p.allWildcard = true
 				p.exceptNames = ft.Except
+				p.exceptMatching = ft.ExceptMatching
 				for _, replace := range ft.Replace {
 					p.aliasFields = append(p.aliasFields, replace)
 				}
@@ -83,6 +93,9 @@ func (p *ProjectPlan) BuildExplainInfo() {
 		}
 		info += " ]"
 	}
+	if p.distinct {
+		info += ", Distinct:true"
+	}
 	if p.enableLimit {
 		info += ", Limit:" + strconv.Itoa(p.limitCount)
 	}
@@ -23,8 +23,10 @@ import (
 type ProjectSetPlan struct {
 	baseLogicalPlan
 	SrfMapping  map[string]struct{}
+	srfAlias    map[string]struct{}
 	enableLimit bool
 	limitCount  int
+	sendNil     bool
 }
 
 func (p ProjectSetPlan) Init() *ProjectSetPlan {
@@ -255,6 +255,9 @@ func (s *Topo) prepareContext() {
 		ctx := kctx.WithValue(kctx.RuleBackground(s.name), kctx.LoggerKey, contextLogger)
 		ctx = kctx.WithValue(ctx, kctx.RuleStartKey, timex.GetNowInMilli())
 		ctx = kctx.WithValue(ctx, kctx.RuleWaitGroupKey, s.opsWg)
+		if s.options != nil && s.options.FloatPrecision != nil {
+			ctx = kctx.WithValue(ctx, kctx.FloatPrecisionKey, *s.options.FloatPrecision)
+		}
 		nctx := ctx.WithRuleId(s.name)
 		s.ctx, s.cancel = nctx.WithCancel()
 	}
@@ -245,7 +245,7 @@ func (w *WindowTuples) Value(key, table string) (interface{}, bool) {
 
 func (w *WindowTuples) Meta(key, table string) (interface{}, bool) {
 	if len(w.Content) > 0 {
-		return w.Content[0].Value(key, table)
+		return w.Content[0].Meta(key, table)
 	}
 	return nil, false
 }
@@ -307,6 +307,8 @@ func (w *WindowTuples) ToMaps() []map[string]interface{} {
 }
 
 func (w *WindowTuples) Pick(allWildcard bool, cols [][]string, wildcardEmitters map[string]bool, except []string, sendNil bool) {
+	// invalidate cache, will calculate again
+	w.cachedMap = nil
 	cols = w.AffiliateRow.Pick(cols)
 	for i, t := range w.Content {
 		tc := t.Clone()
@@ -589,6 +591,26 @@ func (w WindowRangeValuer) Meta(_, _ string) (interface{}, bool) {
 	return nil, false
 }
 
+// WindowIndexValuer provides the 0-based position of the projected row in the current window for window_index()
+type WindowIndexValuer struct {
+	Index int
+}
+
+func (w WindowIndexValuer) Value(_, _ string) (interface{}, bool) {
+	return nil, false
+}
+
+func (w WindowIndexValuer) Meta(_, _ string) (interface{}, bool) {
+	return nil, false
+}
+
+func (w WindowIndexValuer) FuncValue(key string) (interface{}, bool) {
+	if key == "window_index" {
+		return int64(w.Index), true
+	}
+	return nil, false
+}
+
 type WindowRange struct {
 	windowStart   int64
 	windowEnd     int64
@@ -600,6 +622,10 @@ func NewWindowRange(windowStart int64, windowEnd int64, windowTrigger int64) *Wi
 }
 
 func (r *WindowRange) FuncValue(key string) (interface{}, bool) {
+	// The range may be absent such as a group without its own window range
+	if r == nil {
+		return nil, false
+	}
 	switch key {
 	case "window_start":
 		return r.windowStart, true
@@ -20,6 +20,9 @@ import (
 	"strings"
 	"sync"
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
 
 	"github.com/lf-edge/ekuiper/v2/pkg/timex"
 )
@@ -249,3 +252,26 @@ func TestCollectionAgg(t *testing.T) {
 		}
 	}
 }
+
+func TestEventTimes(t *testing.T) {
+	timex.Set(1568854590000)
+	data := &JoinTuples{
+		Content: []*JoinTuple{
+			{
+				Tuples: []Row{
+					&Tuple{Emitter: "a", Message: map[string]interface{}{"a": 1}, Timestamp: time.UnixMilli(1568854573431)},
+					&Tuple{Emitter: "b", Message: map[string]interface{}{"a": 1}, Timestamp: time.UnixMilli(1568854575431)},
+				},
+			},
+			{
+				Tuples: []Row{
+					&Tuple{Emitter: "a", Message: map[string]interface{}{"a": 2}},
+				},
+			},
+		},
+	}
+	result := eventTimes(data)
+	require.Len(t, result, 2)
+	require.Equal(t, int64(1568854575431), result[0].(time.Time).UnixMilli())
+	require.Equal(t, int64(1568854590000), result[1].(time.Time).UnixMilli())
+}
@@ -15,9 +15,14 @@
 package xsql
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/lf-edge/ekuiper/contract/v2/api"
 
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
 	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
 )
 
 type AggregateFunctionValuer struct {
@@ -85,3 +90,90 @@ func (v *AggregateFunctionValuer) Call(name string, funcId int, args []interface
 func (v *AggregateFunctionValuer) GetAllTuples() AggregateData {
 	return v.data
 }
+
+// filteredAggregateData only aggregates the rows which satisfy the FILTER (WHERE ...) clause of an aggregate function.
+// The predicate is evaluated for each row once and the results of AggregateEval are masked in the row order.
+type filteredAggregateData struct {
+	AggregateData
+	mask []bool
+}
+
+func newFilteredAggregateData(data AggregateData, filter ast.Expr, v CallValuer) (*filteredAggregateData, error) {
+	conds := data.AggregateEval(filter, v)
+	mask := make([]bool, len(conds))
+	for i, c := range conds {
+		switch ct := c.(type) {
+		case error:
+			return nil, ct
+		case bool:
+			mask[i] = ct
+		case nil:
+			// null is treated as false like WHERE
+		default:
+			return nil, fmt.Errorf("invalid condition that returns non-bool value %[1]T(%[1]v)", c)
+		}
+	}
+	return &filteredAggregateData{AggregateData: data, mask: mask}, nil
+}
+
+func (f *filteredAggregateData) AggregateEval(expr ast.Expr, v CallValuer) []interface{} {
+	return f.apply(f.AggregateData.AggregateEval(expr, v))
+}
+
+func (f *filteredAggregateData) apply(values []interface{}) []interface{} {
+	if len(values) != len(f.mask) {
+		return values
+	}
+	result := make([]interface{}, 0, len(values))
+	for i, val := range values {
+		if f.mask[i] {
+			result = append(result, val)
+		}
+	}
+	return result
+}
+
+// eventTimes returns the event time of each tuple in the aggregate data.
+// The tuples without timestamp use the processing time instead.
+func eventTimes(data AggregateData) []interface{} {
+	var rows []Row
+	switch dt := data.(type) {
+	case *filteredAggregateData:
+		return dt.apply(eventTimes(dt.AggregateData))
+	case *WindowTuples:
+		rows = dt.Content
+	case *GroupedTuples:
+		rows = dt.Content
+	case *JoinTuples:
+		rows = make([]Row, 0, len(dt.Content))
+		for _, jt := range dt.Content {
+			rows = append(rows, jt)
+		}
+	case Row:
+		rows = []Row{dt}
+	}
+	result := make([]interface{}, 0, len(rows))
+	for _, r := range rows {
+		result = append(result, rowEventTime(r))
+	}
+	return result
+}
+
+// rowEventTime returns the timestamp of the row. For a join tuple, it is the latest timestamp of the joined tuples.
+func rowEventTime(r Row) time.Time {
+	var ts time.Time
+	switch rt := r.(type) {
+	case Event:
+		ts = rt.GetTimestamp()
+	case *JoinTuple:
+		for _, t := range rt.Tuples {
+			if tt := rowEventTime(t); tt.After(ts) {
+				ts = tt
+			}
+		}
+	}
+	if ts.IsZero() {
+		return timex.GetNow()
+	}
+	return ts
+}
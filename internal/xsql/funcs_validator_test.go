@@ -438,7 +438,7 @@ func TestFuncValidator(t *testing.T) {
 		{
 			s:    `SELECT split_value(topic1) FROM tbl`,
 			stmt: nil,
-			err:  "validate function split_value error: the arguments for split_value should be 3",
+			err:  "validate function split_value error: the arguments for split_value should be 2 or 3",
 		},
 
 		{
@@ -502,6 +502,132 @@ func TestFuncValidator(t *testing.T) {
 			stmt: nil,
 			err:  "validate function deduplicate error: Expect bool type for parameter 2",
 		},
+
+		{
+			s:    `SELECT nth_value(a, b) FROM tbl`,
+			stmt: nil,
+			err:  "validate function nth_value error: Expect int type for parameter 2",
+		},
+
+		{
+			s:    `SELECT nth_value(a, 0) FROM tbl`,
+			stmt: nil,
+			err:  "validate function nth_value error: the second parameter n must be larger than 0 but found 0",
+		},
+
+		{
+			s:    `SELECT wma(a, array_create()) FROM tbl`,
+			stmt: nil,
+			err:  "validate function wma error: the weights must not be empty",
+		},
+
+		{
+			s:    `SELECT wma(a, 3) FROM tbl`,
+			stmt: nil,
+			err:  "validate function wma error: Expect array type for parameter 2",
+		},
+
+		{
+			s:    `SELECT wma(a, array_create(1, "x")) FROM tbl`,
+			stmt: nil,
+			err:  "validate function wma error: the weights must be numbers but found x",
+		},
+
+		{
+			s:    `SELECT ema(a, 0) FROM tbl`,
+			stmt: nil,
+			err:  "validate function ema error: the smoothing factor alpha must be in (0, 1] but found 0",
+		},
+
+		{
+			s:    `SELECT ema(a, 1.5) FROM tbl`,
+			stmt: nil,
+			err:  "validate function ema error: the smoothing factor alpha must be in (0, 1] but found 1.5",
+		},
+
+		{
+			s:    `SELECT ema(a, "x") FROM tbl`,
+			stmt: nil,
+			err:  "validate function ema error: Expect number - float or int type for parameter 2",
+		},
+
+		{
+			s:    `SELECT ntile_label(a, 3, array_create("low", "high")) FROM tbl`,
+			stmt: nil,
+			err:  "validate function ntile_label error: the labels length 2 does not match the number of buckets 3",
+		},
+
+		{
+			s:    `SELECT ntile_label(a, 0, array_create()) FROM tbl`,
+			stmt: nil,
+			err:  "validate function ntile_label error: the number of buckets must be positive but found 0",
+		},
+
+		{
+			s:    `SELECT ntile_label(a, 1.5, array_create("low", "high")) FROM tbl`,
+			stmt: nil,
+			err:  "validate function ntile_label error: Expect int type for parameter 2",
+		},
+
+		{
+			s:    `SELECT ntile_label(a, 2, "low") FROM tbl`,
+			stmt: nil,
+			err:  "validate function ntile_label error: Expect array type for parameter 3",
+		},
+
+		{
+			s:    `SELECT sliding_sum(a, 0) FROM tbl`,
+			stmt: nil,
+			err:  "validate function sliding_sum error: the window size must be positive but found 0",
+		},
+
+		{
+			s:    `SELECT sliding_sum(a, 1.5) FROM tbl`,
+			stmt: nil,
+			err:  "validate function sliding_sum error: Expect int type for parameter 2",
+		},
+
+		{
+			s:    `SELECT rolling_rate(ts, 1) FROM tbl`,
+			stmt: nil,
+			err:  "validate function rolling_rate error: the window size must be at least 2 but found 1",
+		},
+
+		{
+			s:    `SELECT rolling_rate(1, 2) FROM tbl`,
+			stmt: nil,
+			err:  "validate function rolling_rate error: Expect datetime type for parameter 1",
+		},
+
+		{
+			s:    `SELECT window_start("2006-01-02", 1) FROM tbl`,
+			stmt: nil,
+			err:  "validate function window_start error: Expect 0 or 1 arguments only",
+		},
+
+		{
+			s:    `SELECT window_end(1) FROM tbl`,
+			stmt: nil,
+			err:  "validate function window_end error: Expect string type for parameter 1",
+		},
+
+		{
+			s:    `SELECT default_for_type(a, "int") FROM tbl`,
+			stmt: nil,
+			err:  "validate function default_for_type error: Expect one of following value for the 2nd parameter: bigint, float, string, boolean, datetime, bytea.",
+		},
+
+		{
+			s:    `SELECT mode_binned(a, 0) FROM tbl`,
+			stmt: nil,
+			err:  "validate function mode_binned error: the bin width must be positive but found 0",
+		},
+
+		{
+			s:    `SELECT coalesce_meta(a, 1) FROM tbl`,
+			stmt: nil,
+			err:  "validate function coalesce_meta error: Expect meta key type for parameter 2",
+		},
 	}
 
 	for _, tt := range tests {
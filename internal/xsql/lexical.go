@@ -141,7 +141,23 @@ func (s *Scanner) Scan() (tok ast.Token, lit string) {
 	case ']':
 		return ast.RBRACKET, ast.Tokens[ast.RBRACKET]
 	case ':':
+		if r := s.read(); r == ':' {
+			return ast.DOUBLECOLON, ast.Tokens[ast.DOUBLECOLON]
+		}
+		s.unread()
 		return ast.COLON, ast.Tokens[ast.COLON]
+	case '?':
+		if r := s.read(); r == '?' {
+			return ast.COALESCE, ast.Tokens[ast.COALESCE]
+		}
+		s.unread()
+		// peek to keep the runes for the next token if it is not ?->
+		if bs, _ := s.r.Peek(2); string(bs) == "->" {
+			s.read()
+			s.read()
+			return ast.SAFE_ARROW, ast.Tokens[ast.SAFE_ARROW]
+		}
+		return ast.ILLEGAL, "?"
 	case '#':
 		return ast.HASH, ast.Tokens[ast.HASH]
 	case ';':
@@ -233,10 +249,16 @@ func (s *Scanner) ScanIdent() (tok ast.Token, lit string) {
 		return ast.EXCEPT, lit
 	case "INVISIBLE":
 		return ast.INVISIBLE, lit
+	case "ROLLUP":
+		return ast.ROLLUP, lit
 	case "TRUE":
 		return ast.TRUE, lit
 	case "FALSE":
 		return ast.FALSE, lit
+	case "NULL":
+		return ast.NULL, lit
+	case "IS":
+		return ast.IS, lit
 	case "DD":
 		return ast.DD, lit
 	case "HH":
@@ -332,6 +354,22 @@ func (s *Scanner) ScanNumber(startWithDot bool, isNeg bool) (tok ast.Token, lit
 	ch := s.read()
 	s.buf.WriteRune(ch)
 
+	// hex integer such as 0xFF
+	if ch == '0' && !startWithDot {
+		if b, _ := s.r.Peek(2); len(b) == 2 && (b[0] == 'x' || b[0] == 'X') && isHexDigit(rune(b[1])) {
+			s.buf.WriteRune(s.read())
+			for {
+				if ch := s.read(); isHexDigit(ch) {
+					s.buf.WriteRune(ch)
+				} else {
+					s.unread()
+					break
+				}
+			}
+			return ast.INTEGER, s.buf.String()
+		}
+	}
+
 	isNum := false
 	for {
 		if ch := s.read(); isDigit(ch) {
@@ -339,6 +377,24 @@ func (s *Scanner) ScanNumber(startWithDot bool, isNeg bool) (tok ast.Token, lit
 		} else if ch == '.' {
 			isNum = true
 			s.buf.WriteRune(ch)
+		} else if (ch == 'e' || ch == 'E') && s.isExponent() {
+			// scientific notation such as 1e3 or 1.5e-3
+			isNum = true
+			s.buf.WriteRune(ch)
+			if sign := s.read(); sign == '+' || sign == '-' {
+				s.buf.WriteRune(sign)
+			} else {
+				s.unread()
+			}
+			for {
+				if ch := s.read(); isDigit(ch) {
+					s.buf.WriteRune(ch)
+				} else {
+					s.unread()
+					break
+				}
+			}
+			break
 		} else {
 			s.unread()
 			break
@@ -351,6 +407,15 @@ func (s *Scanner) ScanNumber(startWithDot bool, isNeg bool) (tok ast.Token, lit
 	}
 }
 
+// isExponent checks if the following runes after e/E are the exponent digits of a scientific notation
+func (s *Scanner) isExponent() bool {
+	b, _ := s.r.Peek(2)
+	if len(b) > 0 && isDigit(rune(b[0])) {
+		return true
+	}
+	return len(b) == 2 && (b[0] == '+' || b[0] == '-') && isDigit(rune(b[1]))
+}
+
 func (s *Scanner) ScanBackquoteIdent() (tok ast.Token, lit string) {
 	s.buf.Reset()
 	for {
@@ -428,6 +493,10 @@ func isLetter(ch rune) bool { return (ch >= 'a' && ch <= 'z') || (ch >= 'A' && c
 
 func isDigit(ch rune) bool { return ch >= '0' && ch <= '9' }
 
+func isHexDigit(ch rune) bool {
+	return isDigit(ch) || (ch >= 'a' && ch <= 'f') || (ch >= 'A' && ch <= 'F')
+}
+
 func isQuotation(ch rune) bool { return ch == '"' || ch == '\'' }
 
 func isBackquote(ch rune) bool { return ch == '`' }
@@ -19,6 +19,7 @@ import (
 	"io"
 	"math"
 	"reflect"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -156,6 +157,7 @@ func (p *Parser) Parse() (*ast.SelectStatement, error) {
 		return nil, fmt.Errorf("Found %q, Expected SELECT.\n", lit)
 	}
 	p.clause = "select"
+	selects.Distinct = p.parseDistinct()
 	if fields, err := p.parseFields(); err != nil {
 		return nil, err
 	} else {
@@ -359,12 +361,39 @@ func (p *Parser) parseDimensions() (ast.Dimensions, error) {
 	var ds ast.Dimensions
 	if t, _ := p.scanIgnoreWhitespace(); t == ast.GROUP {
 		if t1, l1 := p.scanIgnoreWhitespace(); t1 == ast.BY {
+			hasRollup, hasSets := false, false
 			for {
-				if exp, err := p.ParseExpr(); err != nil {
-					return nil, err
+				if tok, lit := p.scanIgnoreWhitespace(); tok == ast.ROLLUP {
+					if hasSets {
+						return nil, fmt.Errorf("only one ROLLUP or GROUPING SETS is allowed in GROUP BY.")
+					}
+					if hasRollup {
+						return nil, fmt.Errorf("only one ROLLUP is allowed in GROUP BY.")
+					}
+					hasRollup = true
+					rds, err := p.parseRollup()
+					if err != nil {
+						return nil, err
+					}
+					ds = append(ds, rds...)
+				} else if p.isGroupingSets(tok, lit) {
+					if hasRollup || hasSets {
+						return nil, fmt.Errorf("only one ROLLUP or GROUPING SETS is allowed in GROUP BY.")
+					}
+					hasSets = true
+					sds, err := p.parseGroupingSets()
+					if err != nil {
+						return nil, err
+					}
+					ds = append(ds, sds...)
 				} else {
-					d := ast.Dimension{Expr: exp}
-					ds = append(ds, d)
+					p.unscan()
+					if exp, err := p.ParseExpr(); err != nil {
+						return nil, err
+					} else {
+						d := ast.Dimension{Expr: exp}
+						ds = append(ds, d)
+					}
 				}
 				if tok, _ := p.scanIgnoreWhitespace(); tok == ast.COMMA {
 					continue
@@ -381,6 +410,145 @@ func (p *Parser) parseDimensions() (ast.Dimensions, error) {
 	return ds, nil
 }
 
+// parseRollup parses the column list of ROLLUP(a, b, ...) in GROUP BY
+func (p *Parser) parseRollup() (ast.Dimensions, error) {
+	if tok, lit := p.scanIgnoreWhitespace(); tok != ast.LPAREN {
+		return nil, fmt.Errorf("found %q, expected ( after ROLLUP.", lit)
+	}
+	var ds ast.Dimensions
+	for {
+		exp, err := p.ParseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := exp.(*ast.FieldRef); !ok {
+			return nil, fmt.Errorf("ROLLUP only supports column, but found %s.", exp)
+		}
+		ds = append(ds, ast.Dimension{Expr: exp, Rollup: true})
+		if tok, lit := p.scanIgnoreWhitespace(); tok == ast.COMMA {
+			continue
+		} else if tok == ast.RPAREN {
+			break
+		} else {
+			return nil, fmt.Errorf("found %q, expected , or ) in ROLLUP.", lit)
+		}
+	}
+	return ds, nil
+}
+
+// parseDistinct parses the DISTINCT modifier at the beginning of the function arguments like collect(DISTINCT a) or
+// the select fields like SELECT DISTINCT a. DISTINCT is not reserved, so it is a column name if it is followed by an
+// operator, a comma, ), AS or FROM. The *, unary - or + and ( start a field, so DISTINCT before them is the modifier.
+func (p *Parser) parseDistinct() bool {
+	if tok, lit := p.scanIgnoreWhitespace(); tok != ast.IDENT || !strings.EqualFold(lit, "DISTINCT") {
+		p.unscan()
+		return false
+	}
+	tok, _ := p.scanIgnoreWhitespace()
+	p.unscan()
+	startsField := tok == ast.ASTERISK || tok == ast.SUB || tok == ast.ADD
+	if tok == ast.RPAREN || tok == ast.COMMA || tok == ast.ORDER || tok == ast.AS || tok == ast.FROM || tok == ast.EOF || (tok.IsOperator() && !startsField) {
+		p.unscan()
+		return false
+	}
+	return true
+}
+
+// isGroupingSets checks whether the current token starts GROUPING SETS. GROUPING and SETS are not reserved so that
+// they can still be used as column names.
+func (p *Parser) isGroupingSets(tok ast.Token, lit string) bool {
+	if tok != ast.IDENT || !strings.EqualFold(lit, "GROUPING") {
+		return false
+	}
+	if tok1, lit1 := p.scanIgnoreWhitespace(); tok1 == ast.IDENT && strings.EqualFold(lit1, "SETS") {
+		return true
+	}
+	p.unscan()
+	return false
+}
+
+// parseGroupingSets parses the set list of GROUPING SETS((a), (a, b), ()) in GROUP BY. A set with a single column can
+// omit the parentheses. Each distinct column becomes a dimension which records the sets it belongs to.
+func (p *Parser) parseGroupingSets() (ast.Dimensions, error) {
+	if tok, lit := p.scanIgnoreWhitespace(); tok != ast.LPAREN {
+		return nil, fmt.Errorf("found %q, expected ( after GROUPING SETS.", lit)
+	}
+	var (
+		cols []ast.Expr
+		sets [][]int
+	)
+	indexOf := func(exp ast.Expr) (int, error) {
+		if _, ok := exp.(*ast.FieldRef); !ok {
+			return 0, fmt.Errorf("GROUPING SETS only supports column, but found %s.", exp)
+		}
+		for i, c := range cols {
+			if c.String() == exp.String() {
+				return i, nil
+			}
+		}
+		cols = append(cols, exp)
+		return len(cols) - 1, nil
+	}
+	for {
+		var set []int
+		if tok, _ := p.scanIgnoreWhitespace(); tok == ast.LPAREN {
+			if tok1, _ := p.scanIgnoreWhitespace(); tok1 != ast.RPAREN {
+				p.unscan()
+				for {
+					exp, err := p.ParseExpr()
+					if err != nil {
+						return nil, err
+					}
+					i, err := indexOf(exp)
+					if err != nil {
+						return nil, err
+					}
+					set = append(set, i)
+					if tok2, lit2 := p.scanIgnoreWhitespace(); tok2 == ast.COMMA {
+						continue
+					} else if tok2 == ast.RPAREN {
+						break
+					} else {
+						return nil, fmt.Errorf("found %q, expected , or ) in GROUPING SETS.", lit2)
+					}
+				}
+			}
+		} else {
+			p.unscan()
+			exp, err := p.ParseExpr()
+			if err != nil {
+				return nil, err
+			}
+			i, err := indexOf(exp)
+			if err != nil {
+				return nil, err
+			}
+			set = append(set, i)
+		}
+		sets = append(sets, set)
+		if tok, lit := p.scanIgnoreWhitespace(); tok == ast.COMMA {
+			continue
+		} else if tok == ast.RPAREN {
+			break
+		} else {
+			return nil, fmt.Errorf("found %q, expected , or ) in GROUPING SETS.", lit)
+		}
+	}
+	if len(cols) == 0 {
+		return nil, fmt.Errorf("GROUPING SETS requires at least one column.")
+	}
+	ds := make(ast.Dimensions, len(cols))
+	for i, c := range cols {
+		ds[i] = ast.Dimension{Expr: c, GroupingSets: make([]bool, len(sets))}
+	}
+	for si, set := range sets {
+		for _, i := range set {
+			ds[i].GroupingSets[si] = true
+		}
+	}
+	return ds, nil
+}
+
 func (p *Parser) parseHaving() (ast.Expr, error) {
 	if tok, _ := p.scanIgnoreWhitespace(); tok != ast.HAVING {
 		p.unscan()
@@ -425,14 +593,15 @@ func (p *Parser) parseSorts() (ast.SortFields, error) {
 
 					if t2, _ := p.scanIgnoreWhitespace(); t2 == ast.DESC {
 						s.Ascending = false
-						ss = append(ss, s)
-					} else if t2 == ast.ASC {
-						ss = append(ss, s)
-					} else {
-						ss = append(ss, s)
+					} else if t2 != ast.ASC {
 						p.unscan()
-						continue
 					}
+					if nf, err := p.parseNullsOrder(); err != nil {
+						return nil, err
+					} else {
+						s.NullsFirst = nf
+					}
+					ss = append(ss, s)
 				} else if t1 == ast.COMMA {
 					continue
 				} else {
@@ -450,6 +619,24 @@ func (p *Parser) parseSorts() (ast.SortFields, error) {
 	return ss, nil
 }
 
+// parseNullsOrder parses the optional NULLS FIRST|LAST after a sort field. Default is NULLS LAST.
+func (p *Parser) parseNullsOrder() (bool, error) {
+	if t, l := p.scanIgnoreWhitespace(); t != ast.IDENT || !strings.EqualFold(l, "nulls") {
+		p.unscan()
+		return false, nil
+	}
+	t, l := p.scanIgnoreWhitespace()
+	if t == ast.IDENT {
+		switch strings.ToLower(l) {
+		case "first":
+			return true, nil
+		case "last":
+			return false, nil
+		}
+	}
+	return false, fmt.Errorf("found %q, expected FIRST or LAST after NULLS.", l)
+}
+
 func (p *Parser) parseFields() (ast.Fields, error) {
 	var fields ast.Fields
 
@@ -480,7 +667,7 @@ func (p *Parser) parseField() (*ast.Field, error) {
 		field.Expr = exp
 	}
 
-	if alias, err := p.parseAlias(); err != nil {
+	if alias, nested, err := p.parseAlias(); err != nil {
 		return nil, err
 	} else {
 		if alias != "" {
@@ -488,6 +675,7 @@ func (p *Parser) parseField() (*ast.Field, error) {
 				return nil, fmt.Errorf("alias is not supported for *")
 			}
 			field.AName = alias
+			field.NestedAlias = nested
 		}
 	}
 	if field.Name == "" && field.AName == "" {
@@ -509,22 +697,43 @@ func nameExpr(exp ast.Expr) string {
 		return e.Name
 	case *ast.Wildcard:
 		return ast.Tokens[ast.ASTERISK]
+	case *ast.CastExpr:
+		return nameExpr(e.Expr)
 	default:
 		return ""
 	}
 }
 
-func (p *Parser) parseAlias() (string, error) {
+// parseAlias parses the alias of a field. A dotted alias like payload.temp is a nested alias.
+func (p *Parser) parseAlias() (string, bool, error) {
 	tok, _ := p.scanIgnoreWhitespace()
 	if tok == ast.AS {
-		if tok, lit := p.scanIgnoreWhitespace(); tok != ast.IDENT {
-			return "", fmt.Errorf("found %q, expected as alias.", lit)
-		} else {
-			return lit, nil
+		tok, lit := p.scanIgnoreWhitespace()
+		if tok != ast.IDENT {
+			return "", false, fmt.Errorf("found %q, expected as alias.", lit)
 		}
+		var path []string
+		for {
+			if t, _ := p.scanIgnoreWhitespace(); t != ast.DOT {
+				p.unscan()
+				break
+			}
+			t, l := p.scanIgnoreWhitespace()
+			if t != ast.IDENT {
+				return "", false, fmt.Errorf("found %q, expected as alias.", l)
+			}
+			if path == nil {
+				path = []string{lit}
+			}
+			path = append(path, l)
+		}
+		if path != nil {
+			return strings.Join(path, "."), true, nil
+		}
+		return lit, false, nil
 	}
 	p.unscan()
-	return "", nil
+	return "", false, nil
 }
 
 func (p *Parser) ParseExpr() (ast.Expr, error) {
@@ -537,10 +746,23 @@ func (p *Parser) ParseExpr() (ast.Expr, error) {
 	}
 
 	for {
-		op, _ := p.scanIgnoreWhitespace()
+		op, lit := p.scanIgnoreWhitespace()
+		if op == ast.DOUBLECOLON {
+			if err := p.parseCast(root); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		// DEFAULT is not a keyword, it is only recognized after a safe navigation
+		if op == ast.IDENT && strings.EqualFold(lit, "DEFAULT") {
+			if err := p.parseNavDefault(root); err != nil {
+				return nil, err
+			}
+			continue
+		}
 		if !op.IsOperator() {
 			p.unscan()
-			return root.RHS, nil
+			return rewriteArrayMap(root.RHS)
 		} else if op == ast.ASTERISK { // Change the asterisk to Mul token.
 			op = ast.MUL
 		} else if op == ast.LBRACKET { // LBRACKET is a special token, need to unscan
@@ -599,7 +821,12 @@ func (p *Parser) ParseExpr() (ast.Expr, error) {
 		}
 
 		var rhs ast.Expr
-		if rhs, err = p.parseUnaryExpr(op == ast.ARROW || op == ast.DOT); err != nil {
+		if op == ast.IS {
+			if op, err = p.parseIsNull(); err != nil {
+				return nil, err
+			}
+			rhs = &ast.NullLiteral{}
+		} else if rhs, err = p.parseUnaryExpr(op == ast.ARROW || op == ast.SAFE_ARROW || op == ast.DOT); err != nil {
 			return nil, err
 		} else if op == ast.DOT {
 			op = ast.ARROW
@@ -619,6 +846,11 @@ func (p *Parser) ParseExpr() (ast.Expr, error) {
 		for node := root; ; {
 			r, ok := node.RHS.(*ast.BinaryExpr)
 			if !ok || r.OP.Precedence() >= op.Precedence() {
+				if op == ast.EQ || op == ast.NEQ {
+					if isNullLiteral(node.RHS) || isNullLiteral(rhs) {
+						return nil, fmt.Errorf("comparison with NULL by %s is not supported, use IS NULL or IS NOT NULL instead.", op)
+					}
+				}
 				node.RHS = &ast.BinaryExpr{LHS: node.RHS, RHS: rhs, OP: op}
 				break
 			}
@@ -627,6 +859,109 @@ func (p *Parser) ParseExpr() (ast.Expr, error) {
 	}
 }
 
+// parseCast parses the type after :: and applies the cast to the last operand.
+// The json path and index operators bind tighter than the cast, so a->b::bigint casts a->b.
+func (p *Parser) parseCast(root *ast.BinaryExpr) error {
+	tok, lit := p.scanIgnoreWhitespace()
+	if tok != ast.IDENT {
+		return fmt.Errorf("found %q, expected type after ::.", lit)
+	}
+	dt := ast.GetDataType(lit)
+	if !dt.IsSimpleType() {
+		return fmt.Errorf("unsupported type %s after ::, only bigint, float, string, bytea, datetime and boolean are supported.", lit)
+	}
+	node := root
+	for {
+		r, ok := node.RHS.(*ast.BinaryExpr)
+		if !ok || r.OP == ast.ARROW || r.OP == ast.SAFE_ARROW || r.OP == ast.SUBSET {
+			break
+		}
+		node = r
+	}
+	node.RHS = &ast.CastExpr{Expr: node.RHS, Type: dt}
+	return nil
+}
+
+// parseNavDefault parses the default value after the safe navigation such as a?->b DEFAULT 0
+func (p *Parser) parseNavDefault(root *ast.BinaryExpr) error {
+	node := root
+	for {
+		r, ok := node.RHS.(*ast.BinaryExpr)
+		if !ok || r.OP == ast.ARROW || r.OP == ast.SAFE_ARROW || r.OP == ast.SUBSET {
+			break
+		}
+		node = r
+	}
+	if !hasSafeNav(node.RHS) {
+		return fmt.Errorf("DEFAULT is only supported after the safe navigation ?->.")
+	}
+	// the default value is an operand with its json path, so DEFAULT binds tighter than the binary operators
+	def, err := p.parseUnaryExpr(false)
+	if err != nil {
+		return err
+	}
+	for {
+		op, _ := p.scanIgnoreWhitespace()
+		if op == ast.LBRACKET {
+			op = ast.SUBSET
+			p.unscan()
+		} else if op != ast.ARROW && op != ast.SAFE_ARROW && op != ast.DOT {
+			p.unscan()
+			break
+		}
+		rhs, err := p.parseUnaryExpr(op != ast.SUBSET)
+		if err != nil {
+			return err
+		}
+		if op == ast.DOT {
+			op = ast.ARROW
+		}
+		def = &ast.BinaryExpr{LHS: def, RHS: rhs, OP: op}
+	}
+	node.RHS = &ast.DefaultExpr{Expr: node.RHS, Default: def}
+	return nil
+}
+
+// hasSafeNav checks if the navigation chain like a?->b->c contains the safe navigation
+func hasSafeNav(expr ast.Expr) bool {
+	if c, ok := expr.(*ast.CastExpr); ok {
+		expr = c.Expr
+	}
+	for {
+		b, ok := expr.(*ast.BinaryExpr)
+		if !ok {
+			return false
+		}
+		switch b.OP {
+		case ast.SAFE_ARROW:
+			return true
+		case ast.ARROW, ast.SUBSET:
+			expr = b.LHS
+		default:
+			return false
+		}
+	}
+}
+
+// parseIsNull parses the rest of IS [NOT] NULL after the IS token
+func (p *Parser) parseIsNull() (ast.Token, error) {
+	op := ast.IS
+	tok, lit := p.scanIgnoreWhitespace()
+	if tok == ast.NOT {
+		op = ast.ISNOT
+		tok, lit = p.scanIgnoreWhitespace()
+	}
+	if tok != ast.NULL {
+		return op, fmt.Errorf("found %q, expected NULL after %s.", lit, op)
+	}
+	return op, nil
+}
+
+func isNullLiteral(expr ast.Expr) bool {
+	_, ok := expr.(*ast.NullLiteral)
+	return ok
+}
+
 func (p *Parser) parseBetween(lhs ast.Expr, op ast.Token) (ast.Expr, error) {
 	alhs, err := p.parseUnaryExpr(false)
 	if err != nil {
@@ -719,6 +1054,14 @@ func (p *Parser) parseUnaryExpr(isSubField bool) (ast.Expr, error) {
 	} else if tok == ast.STRING || tok == ast.SINGLEQUOTE {
 		return &ast.StringLiteral{Val: lit}, nil
 	} else if tok == ast.INTEGER {
+		if h := strings.TrimPrefix(lit, "-"); len(h) > 2 && (h[1] == 'x' || h[1] == 'X') {
+			// hex integer such as 0xFF
+			v, err := strconv.ParseInt(lit, 0, 64)
+			if err != nil {
+				return nil, fmt.Errorf("found %q, invalid hex integer value.", lit)
+			}
+			return &ast.IntegerLiteral{Val: v}, nil
+		}
 		val, _ := strconv.Atoi(lit)
 		return &ast.IntegerLiteral{Val: int64(val)}, nil
 	} else if tok == ast.NUMBER {
@@ -733,6 +1076,8 @@ func (p *Parser) parseUnaryExpr(isSubField bool) (ast.Expr, error) {
 		} else {
 			return &ast.BooleanLiteral{Val: v}, nil
 		}
+	} else if tok == ast.NULL {
+		return &ast.NullLiteral{}, nil
 	} else if tok.IsTimeLiteral() {
 		return &ast.TimeLiteral{Val: tok}, nil
 	} else if tok == ast.ASTERISK {
@@ -785,6 +1130,12 @@ func (p *Parser) parseBracketExpr() (ast.Expr, error) {
 	if tok2 == ast.RBRACKET {
 		// field[]
 		return &ast.ColonExpr{Start: &ast.IntegerLiteral{Val: 0}, End: &ast.IntegerLiteral{Val: math.MinInt32}}, nil
+	} else if tok2 == ast.ASTERISK {
+		// field[*], it will be rewritten to ArrayMapExpr after the whole expression is parsed
+		if tok3, lit3 := p.scanIgnoreWhitespace(); tok3 != ast.RBRACKET {
+			return nil, fmt.Errorf("Found %q, expected right bracket.", lit3)
+		}
+		return &ast.ArrayElementRef{}, nil
 	} else if tok2 == ast.INTEGER {
 		start, err := strconv.Atoi(lit2)
 		if err != nil {
@@ -796,25 +1147,83 @@ func (p *Parser) parseBracketExpr() (ast.Expr, error) {
 		} else if tok3 == ast.COLON {
 			// Such as field[2:] or field[2:4]
 			return p.parseColonExpr(&ast.IntegerLiteral{Val: int64(start)})
+		} else if tok3 == ast.DOUBLECOLON {
+			// Such as field[2::2]
+			return p.parseStepValue(&ast.ColonExpr{Start: &ast.IntegerLiteral{Val: int64(start)}, End: &ast.IntegerLiteral{Val: math.MinInt32}})
 		}
+		// Such as field[2 * i], parse the whole index as an expression
+		p.unscan()
+		p.unscan()
+		return p.parseBracketIndexExpr(lit2)
 	} else if tok2 == ast.COLON {
 		// Such as field[:3] or [:]
 		return p.parseColonExpr(&ast.IntegerLiteral{Val: 0})
+	} else if tok2 == ast.DOUBLECOLON {
+		// Such as field[::2]
+		return p.parseStepValue(&ast.ColonExpr{Start: &ast.IntegerLiteral{Val: 0}, End: &ast.IntegerLiteral{Val: math.MinInt32}})
 	} else {
 		p.unscan()
-		start, err := p.ParseExpr()
-		if err != nil {
-			return nil, fmt.Errorf("The start index %s is invalid in bracket expression.", lit2)
-		}
-		if tok3, _ := p.scanIgnoreWhitespace(); tok3 == ast.RBRACKET {
-			// Such as field[2]
-			return &ast.IndexExpr{Index: start}, nil
-		} else if tok3 == ast.COLON {
-			// Such as field[2:] or field[2:4]
-			return p.parseColonExpr(start)
+		return p.parseBracketIndexExpr(lit2)
+	}
+}
+
+// parseBracketIndexExpr parses the bracket content which starts with an expression such as field[i + 1] or field[i:]
+func (p *Parser) parseBracketIndexExpr(lit string) (ast.Expr, error) {
+	start, err := p.ParseExpr()
+	if err != nil {
+		return nil, fmt.Errorf("The start index %s is invalid in bracket expression.", lit)
+	}
+	if tok, _ := p.scanIgnoreWhitespace(); tok == ast.RBRACKET {
+		// Such as field[i]
+		return &ast.IndexExpr{Index: start}, nil
+	} else if tok == ast.COLON {
+		// Such as field[i:] or field[i:j]
+		return p.parseColonExpr(start)
+	}
+	return nil, fmt.Errorf("Unexpected token %q. when parsing bracket expressions.", lit)
+}
+
+// rewriteArrayMap rewrites the expression with [*] such as a[*]->b * 2 into ArrayMapExpr,
+// so that the whole expression after [*] is applied to each element of the array.
+// All [*] in one expression must refer to the same array.
+func rewriteArrayMap(expr ast.Expr) (ast.Expr, error) {
+	var (
+		arr     ast.Expr
+		arrName string
+		err     error
+	)
+	var replace func(e ast.Expr) ast.Expr
+	replace = func(e ast.Expr) ast.Expr {
+		be, ok := e.(*ast.BinaryExpr)
+		if !ok || err != nil {
+			return e
+		}
+		if _, ok := be.RHS.(*ast.ArrayElementRef); ok && be.OP == ast.SUBSET {
+			if arr == nil {
+				arr = be.LHS
+				arrName = arr.String()
+			} else if be.LHS.String() != arrName {
+				err = fmt.Errorf("[*] must refer to the same array in one expression, but found %s and %s.", arrName, be.LHS.String())
+				return e
+			}
+			return &ast.ArrayElementRef{}
 		}
+		be.LHS = replace(be.LHS)
+		be.RHS = replace(be.RHS)
+		return be
+	}
+	r := replace(expr)
+	if err != nil {
+		return nil, err
+	}
+	if arr == nil {
+		return expr, nil
+	}
+	a, err := rewriteArrayMap(arr)
+	if err != nil {
+		return nil, err
 	}
-	return nil, fmt.Errorf("Unexpected token %q. when parsing bracket expressions.", lit2)
+	return &ast.ArrayMapExpr{Array: a, Expr: r}, nil
 }
 
 func (p *Parser) parseColonExpr(start ast.Expr) (ast.Expr, error) {
@@ -824,25 +1233,57 @@ func (p *Parser) parseColonExpr(start ast.Expr) (ast.Expr, error) {
 		if err != nil {
 			return nil, fmt.Errorf("The end index %s is not an int value in bracket expression.", lit)
 		}
-
-		if tok1, lit1 := p.scanIgnoreWhitespace(); tok1 == ast.RBRACKET {
-			return &ast.ColonExpr{Start: start, End: &ast.IntegerLiteral{Val: int64(end)}}, nil
-		} else {
-			return nil, fmt.Errorf("Found %q, expected right bracket.", lit1)
-		}
+		return p.parseColonStep(&ast.ColonExpr{Start: start, End: &ast.IntegerLiteral{Val: int64(end)}})
 	} else if tok == ast.RBRACKET {
 		return &ast.ColonExpr{Start: start, End: &ast.IntegerLiteral{Val: math.MinInt32}}, nil
+	} else if tok == ast.COLON {
+		// Such as field[2::2], the end is omitted
+		p.unscan()
+		return p.parseColonStep(&ast.ColonExpr{Start: start, End: &ast.IntegerLiteral{Val: math.MinInt32}})
 	}
 	p.unscan()
 	end, err := p.ParseExpr()
 	if err != nil {
 		return nil, fmt.Errorf("The end index %s is invalid in bracket expression.", lit)
 	}
-	if tok1, lit1 := p.scanIgnoreWhitespace(); tok1 == ast.RBRACKET {
-		return &ast.ColonExpr{Start: start, End: end}, nil
+	return p.parseColonStep(&ast.ColonExpr{Start: start, End: end})
+}
+
+// parseColonStep parses the optional step of the slice such as :2] in field[0:10:2] until the right bracket
+func (p *Parser) parseColonStep(c *ast.ColonExpr) (ast.Expr, error) {
+	tok, lit := p.scanIgnoreWhitespace()
+	if tok == ast.RBRACKET {
+		return c, nil
+	} else if tok != ast.COLON {
+		return nil, fmt.Errorf("Found %q, expected right bracket.", lit)
+	}
+	return p.parseStepValue(c)
+}
+
+// parseStepValue parses the step value after the second colon until the right bracket
+func (p *Parser) parseStepValue(c *ast.ColonExpr) (ast.Expr, error) {
+	tok, lit := p.scanIgnoreWhiteSpaceWithNegativeNum()
+	if tok == ast.RBRACKET {
+		// Such as field[0:10:], the step is omitted
+		return c, nil
+	} else if tok == ast.INTEGER {
+		step, err := strconv.Atoi(lit)
+		if err != nil {
+			return nil, fmt.Errorf("The step %s is not an int value in bracket expression.", lit)
+		}
+		c.Step = &ast.IntegerLiteral{Val: int64(step)}
 	} else {
+		p.unscan()
+		step, err := p.ParseExpr()
+		if err != nil {
+			return nil, fmt.Errorf("The step %s is invalid in bracket expression.", lit)
+		}
+		c.Step = step
+	}
+	if tok1, lit1 := p.scanIgnoreWhitespace(); tok1 != ast.RBRACKET {
 		return nil, fmt.Errorf("Found %q, expected right bracket.", lit1)
 	}
+	return c, nil
 }
 
 func (p *Parser) scanIgnoreWhiteSpaceWithNegativeNum() (ast.Token, string) {
@@ -898,7 +1339,11 @@ func (p *Parser) parseCall(n string) (ast.Expr, error) {
 	if ft == ast.FuncTypeCols && p.clause != "select" {
 		return nil, fmt.Errorf("function %s can only be used inside the select clause", n)
 	}
-	var args []ast.Expr
+	var (
+		args    []ast.Expr
+		orderBy ast.SortFields
+	)
+	distinct := p.parseDistinct()
 	for {
 		if tok, _ := p.scanIgnoreWhitespace(); tok == ast.RPAREN {
 			break
@@ -917,12 +1362,32 @@ func (p *Parser) parseCall(n string) (ast.Expr, error) {
 		}
 
 		if tok, lit := p.scanIgnoreWhitespace(); tok != ast.COMMA {
+			if tok == ast.ORDER && ft == ast.FuncTypeAgg {
+				p.unscan()
+				sorts, err := p.parseSorts()
+				if err != nil {
+					return nil, err
+				}
+				orderBy = sorts
+				if tok, lit = p.scanIgnoreWhitespace(); tok == ast.RPAREN {
+					break
+				}
+			}
 			if tok != ast.RPAREN {
 				return nil, fmt.Errorf("found function call %q, expected ), but with %q.", name, lit)
 			}
 			break
 		}
 	}
+	// The second argument of coalesce_meta is the metadata key
+	if name == "coalesce_meta" && len(args) == 2 {
+		switch ka := args[1].(type) {
+		case *ast.FieldRef:
+			args[1] = &ast.MetaRef{StreamName: ka.StreamName, Name: ka.Name}
+		case *ast.StringLiteral:
+			args[1] = &ast.MetaRef{StreamName: ast.DefaultStream, Name: ka.Val}
+		}
+	}
 	if wt, err := validateWindows(name, args); wt == ast.NOT_WINDOW {
 		switch name {
 		case "dedup_trigger":
@@ -950,7 +1415,21 @@ func (p *Parser) parseCall(n string) (ast.Expr, error) {
 		if name == "deduplicate" {
 			args = append([]ast.Expr{&ast.Wildcard{Token: ast.ASTERISK}}, args...)
 		}
-		c := &ast.Call{Name: name, Args: args, FuncId: p.fn, FuncType: ft}
+		filter, err := p.parseFilter()
+		if err != nil {
+			return nil, err
+		}
+		if filter != nil && ft != ast.FuncTypeAgg {
+			return nil, fmt.Errorf("FILTER is only supported for aggregate functions but found %s", name)
+		}
+		if distinct && ft != ast.FuncTypeAgg {
+			return nil, fmt.Errorf("DISTINCT is only supported for aggregate functions but found %s", name)
+		}
+		// count accepts multiple columns only with DISTINCT such as count(DISTINCT a, b)
+		if name == "count" && len(args) > 1 && !distinct {
+			return nil, fmt.Errorf("validate function count error: Expect 1 arguments but found %d.", len(args))
+		}
+		c := &ast.Call{Name: name, Args: args, FuncId: p.fn, FuncType: ft, SortFields: orderBy, FilterExpr: filter, Distinct: distinct}
 		p.fn += 1
 		e := p.parseOver(c)
 		return c, e
@@ -1658,7 +2137,16 @@ loop:
 		tok, _ := p.scanIgnoreWhitespace()
 		switch tok {
 		case ast.EXCEPT:
-			if tok1, lit := p.scanIgnoreWhitespace(); tok1 != ast.LPAREN {
+			tok1, lit := p.scanIgnoreWhitespace()
+			if tok1 == ast.IDENT && strings.EqualFold(lit, "MATCHING") {
+				patterns, err := p.parseExceptMatching()
+				if err != nil {
+					return nil, err
+				}
+				w.ExceptMatching = patterns
+				continue
+			}
+			if tok1 != ast.LPAREN {
 				return nil, fmt.Errorf("Found %q after EXCEPT, expect left parentheses.", lit)
 			}
 			fieldNames := make([]string, 0)
@@ -1710,6 +2198,33 @@ loop:
 	return &w, nil
 }
 
+// parseExceptMatching parses the regular expressions in EXCEPT MATCHING('pattern1', 'pattern2').
+// The patterns are anchored to match the whole field name.
+func (p *Parser) parseExceptMatching() ([]*regexp.Regexp, error) {
+	if tok, lit := p.scanIgnoreWhitespace(); tok != ast.LPAREN {
+		return nil, fmt.Errorf("Found %q after EXCEPT MATCHING, expect left parentheses.", lit)
+	}
+	var patterns []*regexp.Regexp
+	for {
+		tok, lit := p.scanIgnoreWhitespace()
+		if tok != ast.STRING && tok != ast.SINGLEQUOTE {
+			return nil, fmt.Errorf("Found %q in EXCEPT MATCHING, expect string pattern.", lit)
+		}
+		re, err := regexp.Compile("^(?:" + lit + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("Invalid pattern %q in EXCEPT MATCHING: %v", lit, err)
+		}
+		patterns = append(patterns, re)
+		tok, lit = p.scanIgnoreWhitespace()
+		if tok == ast.RPAREN {
+			return patterns, nil
+		}
+		if tok != ast.COMMA {
+			return nil, fmt.Errorf("Found %q in EXCEPT MATCHING", lit)
+		}
+	}
+}
+
 func (p *Parser) inmeta() bool {
 	return p.inFunc == "meta" || p.inFunc == "mqtt"
 }
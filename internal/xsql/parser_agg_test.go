@@ -42,6 +42,8 @@ func TestIsAggStatement(t *testing.T) {
 		{s: `SELECT count(f1) FROM tbl group by tumblingwindow(ss, 5)`, agg: true},
 		{s: `SELECT f1 FROM tbl group by tumblingwindow(ss, 5) having count(f1) > 3`, agg: false},
 		{s: `SELECT f1 FROM tbl left join tbl2 on tbl1.f1 = tbl2.f2`, agg: false},
+		{s: `SELECT * REPLACE(max(f1) AS f1) FROM tbl`, agg: true},
+		{s: `SELECT * REPLACE(f1 * 2 AS f1) FROM tbl`, agg: false},
 	}
 
 	fmt.Printf("The test bucket size is %d.\n\n", len(tests))
@@ -355,6 +355,29 @@ func TestParser_ParseStatement(t *testing.T) {
 				Sources: []ast.Source{&ast.Table{Name: "tbl"}},
 			},
 		},
+		{
+			s: `SELECT * EXCEPT MATCHING('sensor_.*', "tmp") FROM tbl`,
+			stmt: &ast.SelectStatement{
+				Fields: []ast.Field{
+					{
+						Expr:  &ast.Wildcard{Token: ast.ASTERISK, ExceptMatching: []*regexp.Regexp{regexp.MustCompile("^(?:sensor_.*)$"), regexp.MustCompile("^(?:tmp)$")}},
+						Name:  "*",
+						AName: "",
+					},
+				},
+				Sources: []ast.Source{&ast.Table{Name: "tbl"}},
+			},
+		},
+		{
+			s:    `SELECT * EXCEPT MATCHING(a) FROM tbl`,
+			stmt: nil,
+			err:  `Found "a" in EXCEPT MATCHING, expect string pattern.`,
+		},
+		{
+			s:    `SELECT * EXCEPT MATCHING('sensor_(') FROM tbl`,
+			stmt: nil,
+			err:  "Invalid pattern \"sensor_(\" in EXCEPT MATCHING: error parsing regexp: missing closing ): `^(?:sensor_()$`",
+		},
 		{
 			s: `SELECT * REPLACE(a * 2 AS a, b / 2 AS b) FROM tbl`,
 			stmt: &ast.SelectStatement{
@@ -436,6 +459,23 @@ func TestParser_ParseStatement(t *testing.T) {
 			},
 		},
 
+		{
+			s: "SELECT a AS payload.temp, b AS `payload.hum` FROM tbl",
+			stmt: &ast.SelectStatement{
+				Fields: []ast.Field{
+					{Expr: &ast.FieldRef{Name: "a", StreamName: ast.DefaultStream}, Name: "a", AName: "payload.temp", NestedAlias: true},
+					{Expr: &ast.FieldRef{Name: "b", StreamName: ast.DefaultStream}, Name: "b", AName: "payload.hum"},
+				},
+				Sources: []ast.Source{&ast.Table{Name: "tbl"}},
+			},
+		},
+
+		{
+			s:    `SELECT a AS payload. FROM tbl`,
+			stmt: nil,
+			err:  `found "FROM", expected as alias.`,
+		},
+
 		{
 			s: `SELECT LenGth("test") FROM tbl`,
 			stmt: &ast.SelectStatement{
@@ -733,6 +773,36 @@ func TestParser_ParseStatement(t *testing.T) {
 			},
 		},
 
+		{
+			s: `SELECT * REPLACE(CASE WHEN a IS NULL THEN 0 ELSE a END AS a) FROM tbl`,
+			stmt: &ast.SelectStatement{
+				Fields: []ast.Field{
+					{
+						Expr: &ast.Wildcard{Token: ast.ASTERISK, Replace: []ast.Field{
+							{
+								AName: "a",
+								Expr: &ast.CaseExpr{
+									WhenClauses: []*ast.WhenClause{
+										{
+											Expr: &ast.BinaryExpr{
+												LHS: &ast.FieldRef{Name: "a", StreamName: ast.DefaultStream},
+												OP:  ast.IS,
+												RHS: &ast.NullLiteral{},
+											},
+											Result: &ast.IntegerLiteral{Val: 0},
+										},
+									},
+									ElseClause: &ast.FieldRef{Name: "a", StreamName: ast.DefaultStream},
+								},
+							},
+						}},
+						Name:  "*",
+						AName: "",
+					},
+				},
+				Sources: []ast.Source{&ast.Table{Name: "tbl"}},
+			},
+		},
 		{
 			s: `SELECT count(* REPLACE(a * 2 AS a, b / 2 AS b)) FROM tbl`,
 			stmt: &ast.SelectStatement{
@@ -1424,6 +1494,44 @@ func TestParser_ParseStatement(t *testing.T) {
 			s:   `SELECT a FROM tbl WHERE f1 NOT BETWEEN 1 OR 2`,
 			err: "expect AND expression after between but found OR",
 		},
+		{
+			s: `SELECT NULL AS placeholder FROM tbl WHERE a IS NULL AND b IS NOT NULL`,
+			stmt: &ast.SelectStatement{
+				Fields: []ast.Field{
+					{
+						AName: "placeholder",
+						Name:  "",
+						Expr:  &ast.NullLiteral{},
+					},
+				},
+				Sources: []ast.Source{&ast.Table{Name: "tbl"}},
+				Condition: &ast.BinaryExpr{
+					OP: ast.AND,
+					LHS: &ast.BinaryExpr{
+						OP:  ast.IS,
+						LHS: &ast.FieldRef{Name: "a", StreamName: ast.DefaultStream},
+						RHS: &ast.NullLiteral{},
+					},
+					RHS: &ast.BinaryExpr{
+						OP:  ast.ISNOT,
+						LHS: &ast.FieldRef{Name: "b", StreamName: ast.DefaultStream},
+						RHS: &ast.NullLiteral{},
+					},
+				},
+			},
+		},
+		{
+			s:   `SELECT a FROM tbl WHERE a = NULL`,
+			err: "comparison with NULL by = is not supported, use IS NULL or IS NOT NULL instead.",
+		},
+		{
+			s:   `SELECT a FROM tbl WHERE NULL != a`,
+			err: "comparison with NULL by != is not supported, use IS NULL or IS NOT NULL instead.",
+		},
+		{
+			s:   `SELECT a FROM tbl WHERE a IS 1`,
+			err: "found \"1\", expected NULL after IS.",
+		},
 
 		{
 			s: `SELECT a FROM tbl WHERE a LIKE "foo"`,
@@ -1811,6 +1919,87 @@ func TestParser_ParseStatement(t *testing.T) {
 			},
 		},
 
+		{
+			s: `SELECT region, device FROM tbl GROUP BY name, ROLLUP(region, device)`,
+			stmt: &ast.SelectStatement{
+				Fields: []ast.Field{
+					{Expr: &ast.FieldRef{Name: "region", StreamName: ast.DefaultStream}, Name: "region", AName: ""},
+					{Expr: &ast.FieldRef{Name: "device", StreamName: ast.DefaultStream}, Name: "device", AName: ""},
+				},
+				Sources: []ast.Source{&ast.Table{Name: "tbl"}},
+				Dimensions: ast.Dimensions{
+					ast.Dimension{Expr: &ast.FieldRef{Name: "name", StreamName: ast.DefaultStream}},
+					ast.Dimension{Expr: &ast.FieldRef{Name: "region", StreamName: ast.DefaultStream}, Rollup: true},
+					ast.Dimension{Expr: &ast.FieldRef{Name: "device", StreamName: ast.DefaultStream}, Rollup: true},
+				},
+			},
+		},
+
+		{
+			s:   `SELECT region FROM tbl GROUP BY ROLLUP(region), ROLLUP(device)`,
+			err: "only one ROLLUP is allowed in GROUP BY.",
+		},
+
+		{
+			s:   `SELECT region FROM tbl GROUP BY ROLLUP(region + 1)`,
+			err: "ROLLUP only supports column, but found binaryExpr:{ $$default.region + 1 }.",
+		},
+
+		{
+			s:   `SELECT region FROM tbl GROUP BY ROLLUP region`,
+			err: "found \"region\", expected ( after ROLLUP.",
+		},
+
+		{
+			s: `SELECT device, color FROM tbl GROUP BY GROUPING SETS ((device), (device, color), ())`,
+			stmt: &ast.SelectStatement{
+				Fields: []ast.Field{
+					{Expr: &ast.FieldRef{Name: "device", StreamName: ast.DefaultStream}, Name: "device", AName: ""},
+					{Expr: &ast.FieldRef{Name: "color", StreamName: ast.DefaultStream}, Name: "color", AName: ""},
+				},
+				Sources: []ast.Source{&ast.Table{Name: "tbl"}},
+				Dimensions: ast.Dimensions{
+					ast.Dimension{Expr: &ast.FieldRef{Name: "device", StreamName: ast.DefaultStream}, GroupingSets: []bool{true, true, false}},
+					ast.Dimension{Expr: &ast.FieldRef{Name: "color", StreamName: ast.DefaultStream}, GroupingSets: []bool{false, true, false}},
+				},
+			},
+		},
+
+		{
+			s: `SELECT grouping FROM tbl GROUP BY grouping, Grouping Sets (region, (grouping))`,
+			stmt: &ast.SelectStatement{
+				Fields: []ast.Field{
+					{Expr: &ast.FieldRef{Name: "grouping", StreamName: ast.DefaultStream}, Name: "grouping", AName: ""},
+				},
+				Sources: []ast.Source{&ast.Table{Name: "tbl"}},
+				Dimensions: ast.Dimensions{
+					ast.Dimension{Expr: &ast.FieldRef{Name: "grouping", StreamName: ast.DefaultStream}},
+					ast.Dimension{Expr: &ast.FieldRef{Name: "region", StreamName: ast.DefaultStream}, GroupingSets: []bool{true, false}},
+					ast.Dimension{Expr: &ast.FieldRef{Name: "grouping", StreamName: ast.DefaultStream}, GroupingSets: []bool{false, true}},
+				},
+			},
+		},
+
+		{
+			s:   `SELECT region FROM tbl GROUP BY ROLLUP(region), GROUPING SETS ((device))`,
+			err: "only one ROLLUP or GROUPING SETS is allowed in GROUP BY.",
+		},
+
+		{
+			s:   `SELECT region FROM tbl GROUP BY GROUPING SETS ((region + 1))`,
+			err: "GROUPING SETS only supports column, but found binaryExpr:{ $$default.region + 1 }.",
+		},
+
+		{
+			s:   `SELECT count(*) FROM tbl GROUP BY GROUPING SETS ((), ())`,
+			err: "GROUPING SETS requires at least one column.",
+		},
+
+		{
+			s:   `SELECT region FROM tbl GROUP BY GROUPING SETS ((region) (device))`,
+			err: "found \"(\", expected , or ) in GROUPING SETS.",
+		},
+
 		{
 			s:    `SELECT id,AVG(data) FROM t GROUP BY SUM(data)>10`,
 			stmt: nil,
@@ -2142,6 +2331,40 @@ func TestParser_ParseStatement(t *testing.T) {
 			},
 		},
 
+		{
+			s: `SELECT collect(a ORDER BY ts DESC NULLS FIRST, b) AS c FROM tbl`,
+			stmt: &ast.SelectStatement{
+				Fields: []ast.Field{
+					{
+						Name:  "collect",
+						AName: "c",
+						Expr: &ast.Call{
+							Name:     "collect",
+							Args:     []ast.Expr{&ast.FieldRef{Name: "a", StreamName: ast.DefaultStream}},
+							FuncType: ast.FuncTypeAgg,
+							SortFields: []ast.SortField{
+								{Uname: "ts", Name: "ts", Ascending: false, NullsFirst: true, FieldExpr: &ast.FieldRef{Name: "ts", StreamName: ast.DefaultStream}},
+								{Uname: "b", Name: "b", Ascending: true, FieldExpr: &ast.FieldRef{Name: "b", StreamName: ast.DefaultStream}},
+							},
+						},
+					},
+				},
+				Sources: []ast.Source{&ast.Table{Name: "tbl"}},
+			},
+		},
+
+		{
+			s:    `SELECT collect(a ORDER BY ts NULLS a) AS c FROM tbl`,
+			stmt: nil,
+			err:  `found "a", expected FIRST or LAST after NULLS.`,
+		},
+
+		{
+			s:    `SELECT abs(a ORDER BY ts) AS c FROM tbl`,
+			stmt: nil,
+			err:  `found function call "abs", expected ), but with "ORDER".`,
+		},
+
 		{
 			s: `SELECT * FROM topic/sensor1 GROUP BY name, name2,power(name3,1.8) ORDER BY name DESC, name2 ASC`,
 			stmt: &ast.SelectStatement{
@@ -2338,76 +2561,557 @@ func TestParser_ParseStatement(t *testing.T) {
 		},
 
 		{
-			s:    `SELECT sample(-.3,) FROM tbl`,
-			stmt: nil,
-			err:  "function sample not found",
-		},
-
-		{
-			s:    `select timestamp() as tp from demo`,
-			stmt: nil,
-			err:  "function timestamp not found",
-		},
-
-		{
-			s: `select tstamp() as tp from demo`,
+			s: `SELECT 1e3 AS t1, 1.5e-3 AS t2, 2E+2 AS t3, 0x10 AS t4, 0xFF AS t5, -0x10 AS t6 FROM tbl`,
 			stmt: &ast.SelectStatement{
 				Fields: []ast.Field{
 					{
-						Expr: &ast.Call{
-							Name: "tstamp",
-							Args: nil,
-						},
-						Name:  "tstamp",
-						AName: "tp",
+						Expr:  &ast.NumberLiteral{Val: 1000},
+						Name:  "",
+						AName: "t1",
 					},
-				},
-				Sources: []ast.Source{&ast.Table{Name: "demo"}},
-			},
-			err: "",
-		},
-		{
-			s: `select rule_id() as rule_id from demo`,
-			stmt: &ast.SelectStatement{
-				Fields: []ast.Field{
 					{
-						Expr: &ast.Call{
-							Name: "rule_id",
-							Args: nil,
-						},
-						Name:  "rule_id",
-						AName: "rule_id",
+						Expr:  &ast.NumberLiteral{Val: 0.0015},
+						Name:  "",
+						AName: "t2",
+					},
+					{
+						Expr:  &ast.NumberLiteral{Val: 200},
+						Name:  "",
+						AName: "t3",
+					},
+					{
+						Expr:  &ast.IntegerLiteral{Val: 16},
+						Name:  "",
+						AName: "t4",
+					},
+					{
+						Expr:  &ast.IntegerLiteral{Val: 255},
+						Name:  "",
+						AName: "t5",
+					},
+					{
+						Expr:  &ast.IntegerLiteral{Val: -16},
+						Name:  "",
+						AName: "t6",
 					},
 				},
-				Sources: []ast.Source{&ast.Table{Name: "demo"}},
+				Sources: []ast.Source{&ast.Table{Name: "tbl"}},
 			},
-			err: "",
 		},
+
 		{
-			s:    "SELECT `half FROM tb",
+			s:    `SELECT 0xFFFFFFFFFFFFFFFFF AS t1 FROM tbl`,
 			stmt: nil,
-			err:  `found "EOF", expected FROM.`,
+			err:  "found \"0xFFFFFFFFFFFFFFFFF\", invalid hex integer value.",
 		},
+
 		{
-			s: "SELECT `space var` FROM tbl",
+			s: `SELECT a + b::float AS t1, a->b::bigint, c[0]::string AS t2 FROM tbl`,
 			stmt: &ast.SelectStatement{
 				Fields: []ast.Field{
 					{
-						Expr:  &ast.FieldRef{Name: "space var", StreamName: ast.DefaultStream},
-						Name:  "space var",
+						Expr: &ast.BinaryExpr{
+							LHS: &ast.FieldRef{Name: "a", StreamName: ast.DefaultStream},
+							OP:  ast.ADD,
+							RHS: &ast.CastExpr{Expr: &ast.FieldRef{Name: "b", StreamName: ast.DefaultStream}, Type: ast.FLOAT},
+						},
+						Name:  "",
+						AName: "t1",
+					},
+					{
+						Expr: &ast.CastExpr{
+							Expr: &ast.BinaryExpr{
+								LHS: &ast.FieldRef{Name: "a", StreamName: ast.DefaultStream},
+								OP:  ast.ARROW,
+								RHS: &ast.JsonFieldRef{Name: "b"},
+							},
+							Type: ast.BIGINT,
+						},
+						Name:  "kuiper_field_0",
 						AName: "",
 					},
+					{
+						Expr: &ast.CastExpr{
+							Expr: &ast.BinaryExpr{
+								LHS: &ast.FieldRef{Name: "c", StreamName: ast.DefaultStream},
+								OP:  ast.SUBSET,
+								RHS: &ast.IndexExpr{Index: &ast.IntegerLiteral{Val: 0}},
+							},
+							Type: ast.STRINGS,
+						},
+						Name:  "",
+						AName: "t2",
+					},
 				},
 				Sources: []ast.Source{&ast.Table{Name: "tbl"}},
 			},
 		},
+
 		{
-			s: "SELECT `中文 Chinese` FROM tbl",
+			s: `SELECT a?->b?->c, a?->b->c AS t1, a->b?->c::bigint AS t2 FROM tbl`,
 			stmt: &ast.SelectStatement{
 				Fields: []ast.Field{
 					{
-						Expr:  &ast.FieldRef{Name: "中文 Chinese", StreamName: ast.DefaultStream},
-						Name:  "中文 Chinese",
+						Expr: &ast.BinaryExpr{
+							LHS: &ast.BinaryExpr{
+								LHS: &ast.FieldRef{Name: "a", StreamName: ast.DefaultStream},
+								OP:  ast.SAFE_ARROW,
+								RHS: &ast.JsonFieldRef{Name: "b"},
+							},
+							OP:  ast.SAFE_ARROW,
+							RHS: &ast.JsonFieldRef{Name: "c"},
+						},
+						Name:  "kuiper_field_0",
+						AName: "",
+					},
+					{
+						Expr: &ast.BinaryExpr{
+							LHS: &ast.BinaryExpr{
+								LHS: &ast.FieldRef{Name: "a", StreamName: ast.DefaultStream},
+								OP:  ast.SAFE_ARROW,
+								RHS: &ast.JsonFieldRef{Name: "b"},
+							},
+							OP:  ast.ARROW,
+							RHS: &ast.JsonFieldRef{Name: "c"},
+						},
+						Name:  "",
+						AName: "t1",
+					},
+					{
+						Expr: &ast.CastExpr{
+							Expr: &ast.BinaryExpr{
+								LHS: &ast.BinaryExpr{
+									LHS: &ast.FieldRef{Name: "a", StreamName: ast.DefaultStream},
+									OP:  ast.ARROW,
+									RHS: &ast.JsonFieldRef{Name: "b"},
+								},
+								OP:  ast.SAFE_ARROW,
+								RHS: &ast.JsonFieldRef{Name: "c"},
+							},
+							Type: ast.BIGINT,
+						},
+						Name:  "",
+						AName: "t2",
+					},
+				},
+				Sources: []ast.Source{&ast.Table{Name: "tbl"}},
+			},
+		},
+
+		{
+			s: `SELECT a?->b?->c DEFAULT 0 AS t1, a?->b->c default "none" AS t2 FROM tbl`,
+			stmt: &ast.SelectStatement{
+				Fields: []ast.Field{
+					{
+						Expr: &ast.DefaultExpr{
+							Expr: &ast.BinaryExpr{
+								LHS: &ast.BinaryExpr{
+									LHS: &ast.FieldRef{Name: "a", StreamName: ast.DefaultStream},
+									OP:  ast.SAFE_ARROW,
+									RHS: &ast.JsonFieldRef{Name: "b"},
+								},
+								OP:  ast.SAFE_ARROW,
+								RHS: &ast.JsonFieldRef{Name: "c"},
+							},
+							Default: &ast.IntegerLiteral{Val: 0},
+						},
+						Name:  "",
+						AName: "t1",
+					},
+					{
+						Expr: &ast.DefaultExpr{
+							Expr: &ast.BinaryExpr{
+								LHS: &ast.BinaryExpr{
+									LHS: &ast.FieldRef{Name: "a", StreamName: ast.DefaultStream},
+									OP:  ast.SAFE_ARROW,
+									RHS: &ast.JsonFieldRef{Name: "b"},
+								},
+								OP:  ast.ARROW,
+								RHS: &ast.JsonFieldRef{Name: "c"},
+							},
+							Default: &ast.StringLiteral{Val: "none"},
+						},
+						Name:  "",
+						AName: "t2",
+					},
+				},
+				Sources: []ast.Source{&ast.Table{Name: "tbl"}},
+			},
+		},
+
+		{
+			s: `SELECT a?->b DEFAULT 0 > 5 AS t1, a?->b DEFAULT 0 + 1 AS t2, a?->b DEFAULT c->d * 2 AS t3 FROM tbl`,
+			stmt: &ast.SelectStatement{
+				Fields: []ast.Field{
+					{
+						Expr: &ast.BinaryExpr{
+							LHS: &ast.DefaultExpr{
+								Expr: &ast.BinaryExpr{
+									LHS: &ast.FieldRef{Name: "a", StreamName: ast.DefaultStream},
+									OP:  ast.SAFE_ARROW,
+									RHS: &ast.JsonFieldRef{Name: "b"},
+								},
+								Default: &ast.IntegerLiteral{Val: 0},
+							},
+							OP:  ast.GT,
+							RHS: &ast.IntegerLiteral{Val: 5},
+						},
+						Name:  "",
+						AName: "t1",
+					},
+					{
+						Expr: &ast.BinaryExpr{
+							LHS: &ast.DefaultExpr{
+								Expr: &ast.BinaryExpr{
+									LHS: &ast.FieldRef{Name: "a", StreamName: ast.DefaultStream},
+									OP:  ast.SAFE_ARROW,
+									RHS: &ast.JsonFieldRef{Name: "b"},
+								},
+								Default: &ast.IntegerLiteral{Val: 0},
+							},
+							OP:  ast.ADD,
+							RHS: &ast.IntegerLiteral{Val: 1},
+						},
+						Name:  "",
+						AName: "t2",
+					},
+					{
+						Expr: &ast.BinaryExpr{
+							LHS: &ast.DefaultExpr{
+								Expr: &ast.BinaryExpr{
+									LHS: &ast.FieldRef{Name: "a", StreamName: ast.DefaultStream},
+									OP:  ast.SAFE_ARROW,
+									RHS: &ast.JsonFieldRef{Name: "b"},
+								},
+								Default: &ast.BinaryExpr{
+									LHS: &ast.FieldRef{Name: "c", StreamName: ast.DefaultStream},
+									OP:  ast.ARROW,
+									RHS: &ast.JsonFieldRef{Name: "d"},
+								},
+							},
+							OP:  ast.MUL,
+							RHS: &ast.IntegerLiteral{Val: 2},
+						},
+						Name:  "",
+						AName: "t3",
+					},
+				},
+				Sources: []ast.Source{&ast.Table{Name: "tbl"}},
+			},
+		},
+
+		{
+			s: `SELECT collect(DISTINCT a ORDER BY ts DESC) AS l, count(distinct) AS c FROM tbl`,
+			stmt: &ast.SelectStatement{
+				Fields: []ast.Field{
+					{
+						Expr: &ast.Call{
+							Name:     "collect",
+							FuncType: ast.FuncTypeAgg,
+							Args:     []ast.Expr{&ast.FieldRef{Name: "a", StreamName: ast.DefaultStream}},
+							SortFields: ast.SortFields{
+								{Name: "ts", Uname: "ts", Ascending: false, FieldExpr: &ast.FieldRef{Name: "ts", StreamName: ast.DefaultStream}},
+							},
+							Distinct: true,
+						},
+						Name:  "collect",
+						AName: "l",
+					},
+					{
+						Expr: &ast.Call{
+							Name:     "count",
+							FuncId:   1,
+							FuncType: ast.FuncTypeAgg,
+							Args:     []ast.Expr{&ast.FieldRef{Name: "distinct", StreamName: ast.DefaultStream}},
+						},
+						Name:  "count",
+						AName: "c",
+					},
+				},
+				Sources: []ast.Source{&ast.Table{Name: "tbl"}},
+			},
+		},
+
+		{
+			s: `SELECT count(DISTINCT a, b) AS c FROM tbl`,
+			stmt: &ast.SelectStatement{
+				Fields: []ast.Field{
+					{
+						Expr: &ast.Call{
+							Name:     "count",
+							FuncType: ast.FuncTypeAgg,
+							Args: []ast.Expr{
+								&ast.FieldRef{Name: "a", StreamName: ast.DefaultStream},
+								&ast.FieldRef{Name: "b", StreamName: ast.DefaultStream},
+							},
+							Distinct: true,
+						},
+						Name:  "count",
+						AName: "c",
+					},
+				},
+				Sources: []ast.Source{&ast.Table{Name: "tbl"}},
+			},
+		},
+
+		{
+			s:    `SELECT count(a, b) FROM tbl`,
+			stmt: nil,
+			err:  "validate function count error: Expect 1 arguments but found 2.",
+		},
+
+		{
+			s:    `SELECT abs(DISTINCT a) FROM tbl`,
+			stmt: nil,
+			err:  "DISTINCT is only supported for aggregate functions but found abs",
+		},
+
+		{
+			s: `SELECT DISTINCT a, b AS c FROM tbl`,
+			stmt: &ast.SelectStatement{
+				Distinct: true,
+				Fields: []ast.Field{
+					{
+						Expr:  &ast.FieldRef{Name: "a", StreamName: ast.DefaultStream},
+						Name:  "a",
+						AName: "",
+					},
+					{
+						Expr:  &ast.FieldRef{Name: "b", StreamName: ast.DefaultStream},
+						Name:  "b",
+						AName: "c",
+					},
+				},
+				Sources: []ast.Source{&ast.Table{Name: "tbl"}},
+			},
+		},
+
+		{
+			s: `SELECT distinct, distinct AS d FROM tbl`,
+			stmt: &ast.SelectStatement{
+				Fields: []ast.Field{
+					{
+						Expr:  &ast.FieldRef{Name: "distinct", StreamName: ast.DefaultStream},
+						Name:  "distinct",
+						AName: "",
+					},
+					{
+						Expr:  &ast.FieldRef{Name: "distinct", StreamName: ast.DefaultStream},
+						Name:  "distinct",
+						AName: "d",
+					},
+				},
+				Sources: []ast.Source{&ast.Table{Name: "tbl"}},
+			},
+		},
+
+		{
+			s: `SELECT DISTINCT * FROM tbl`,
+			stmt: &ast.SelectStatement{
+				Distinct: true,
+				Fields: []ast.Field{
+					{
+						Expr:  &ast.Wildcard{Token: ast.ASTERISK},
+						Name:  "*",
+						AName: "",
+					},
+				},
+				Sources: []ast.Source{&ast.Table{Name: "tbl"}},
+			},
+		},
+
+		{
+			s: `SELECT DISTINCT -3 AS b FROM tbl`,
+			stmt: &ast.SelectStatement{
+				Distinct: true,
+				Fields: []ast.Field{
+					{
+						Expr:  &ast.IntegerLiteral{Val: -3},
+						Name:  "",
+						AName: "b",
+					},
+				},
+				Sources: []ast.Source{&ast.Table{Name: "tbl"}},
+			},
+		},
+
+		{
+			// the unary - of a column is not supported like SELECT -a, instead of parsing as the column distinct - a
+			s:    `SELECT DISTINCT -a AS b FROM tbl`,
+			stmt: nil,
+			err:  `found "-", expected expression.`,
+		},
+
+		{
+			s: `SELECT DISTINCT (a+b) AS c FROM tbl`,
+			stmt: &ast.SelectStatement{
+				Distinct: true,
+				Fields: []ast.Field{
+					{
+						Expr: &ast.ParenExpr{
+							Expr: &ast.BinaryExpr{
+								OP:  ast.ADD,
+								LHS: &ast.FieldRef{Name: "a", StreamName: ast.DefaultStream},
+								RHS: &ast.FieldRef{Name: "b", StreamName: ast.DefaultStream},
+							},
+						},
+						Name:  "",
+						AName: "c",
+					},
+				},
+				Sources: []ast.Source{&ast.Table{Name: "tbl"}},
+			},
+		},
+
+		{
+			s: `SELECT distinct FROM tbl`,
+			stmt: &ast.SelectStatement{
+				Fields: []ast.Field{
+					{
+						Expr:  &ast.FieldRef{Name: "distinct", StreamName: ast.DefaultStream},
+						Name:  "distinct",
+						AName: "",
+					},
+				},
+				Sources: []ast.Source{&ast.Table{Name: "tbl"}},
+			},
+		},
+
+		{
+			s: `SELECT a[5] ?? b ?? 0 + 1 > 2 AS t FROM tbl`,
+			stmt: &ast.SelectStatement{
+				Fields: []ast.Field{
+					{
+						Expr: &ast.BinaryExpr{
+							LHS: &ast.BinaryExpr{
+								LHS: &ast.BinaryExpr{
+									LHS: &ast.BinaryExpr{
+										LHS: &ast.FieldRef{Name: "a", StreamName: ast.DefaultStream},
+										OP:  ast.SUBSET,
+										RHS: &ast.IndexExpr{Index: &ast.IntegerLiteral{Val: 5}},
+									},
+									OP:  ast.COALESCE,
+									RHS: &ast.FieldRef{Name: "b", StreamName: ast.DefaultStream},
+								},
+								OP: ast.COALESCE,
+								RHS: &ast.BinaryExpr{
+									LHS: &ast.IntegerLiteral{Val: 0},
+									OP:  ast.ADD,
+									RHS: &ast.IntegerLiteral{Val: 1},
+								},
+							},
+							OP:  ast.GT,
+							RHS: &ast.IntegerLiteral{Val: 2},
+						},
+						Name:  "",
+						AName: "t",
+					},
+				},
+				Sources: []ast.Source{&ast.Table{Name: "tbl"}},
+			},
+		},
+
+		{
+			s:    `SELECT a->b DEFAULT 0 FROM tbl`,
+			stmt: nil,
+			err:  "DEFAULT is only supported after the safe navigation ?->.",
+		},
+
+		{
+			s:    `SELECT a?x FROM tbl`,
+			stmt: nil,
+			err:  "found \"?\", expected FROM.",
+		},
+
+		{
+			s:    `SELECT a?-x FROM tbl`,
+			stmt: nil,
+			err:  "found \"?\", expected FROM.",
+		},
+
+		{
+			s:    `SELECT a::array FROM tbl`,
+			stmt: nil,
+			err:  "unsupported type array after ::, only bigint, float, string, bytea, datetime and boolean are supported.",
+		},
+
+		{
+			s:    `SELECT a:: FROM tbl`,
+			stmt: nil,
+			err:  "found \"FROM\", expected type after ::.",
+		},
+
+		{
+			s:    `SELECT sample(-.3,) FROM tbl`,
+			stmt: nil,
+			err:  "function sample not found",
+		},
+
+		{
+			s:    `select timestamp() as tp from demo`,
+			stmt: nil,
+			err:  "function timestamp not found",
+		},
+
+		{
+			s: `select tstamp() as tp from demo`,
+			stmt: &ast.SelectStatement{
+				Fields: []ast.Field{
+					{
+						Expr: &ast.Call{
+							Name: "tstamp",
+							Args: nil,
+						},
+						Name:  "tstamp",
+						AName: "tp",
+					},
+				},
+				Sources: []ast.Source{&ast.Table{Name: "demo"}},
+			},
+			err: "",
+		},
+		{
+			s: `select rule_id() as rule_id from demo`,
+			stmt: &ast.SelectStatement{
+				Fields: []ast.Field{
+					{
+						Expr: &ast.Call{
+							Name: "rule_id",
+							Args: nil,
+						},
+						Name:  "rule_id",
+						AName: "rule_id",
+					},
+				},
+				Sources: []ast.Source{&ast.Table{Name: "demo"}},
+			},
+			err: "",
+		},
+		{
+			s:    "SELECT `half FROM tb",
+			stmt: nil,
+			err:  `found "EOF", expected FROM.`,
+		},
+		{
+			s: "SELECT `space var` FROM tbl",
+			stmt: &ast.SelectStatement{
+				Fields: []ast.Field{
+					{
+						Expr:  &ast.FieldRef{Name: "space var", StreamName: ast.DefaultStream},
+						Name:  "space var",
+						AName: "",
+					},
+				},
+				Sources: []ast.Source{&ast.Table{Name: "tbl"}},
+			},
+		},
+		{
+			s: "SELECT `中文 Chinese` FROM tbl",
+			stmt: &ast.SelectStatement{
+				Fields: []ast.Field{
+					{
+						Expr:  &ast.FieldRef{Name: "中文 Chinese", StreamName: ast.DefaultStream},
+						Name:  "中文 Chinese",
 						AName: "",
 					},
 				},
@@ -3654,11 +4358,35 @@ func TestParser_ParseWindowsExpr(t *testing.T) {
 				},
 			},
 		},
-		// to be supported
 		{
-			s:    `SELECT sum(f1) FILTER( where revenue > 100 ) FROM tbl GROUP BY year`,
+			s: `SELECT sum(f1) FILTER( where revenue > 100 ) FROM tbl GROUP BY year`,
+			stmt: &ast.SelectStatement{
+				Fields: []ast.Field{
+					{
+						Expr: &ast.Call{
+							Name:     "sum",
+							FuncType: ast.FuncTypeAgg,
+							Args:     []ast.Expr{&ast.FieldRef{Name: "f1", StreamName: ast.DefaultStream}},
+							FilterExpr: &ast.BinaryExpr{
+								LHS: &ast.FieldRef{Name: "revenue", StreamName: ast.DefaultStream},
+								OP:  ast.GT,
+								RHS: &ast.IntegerLiteral{Val: 100},
+							},
+						},
+						Name:  "sum",
+						AName: "",
+					},
+				},
+				Sources: []ast.Source{&ast.Table{Name: "tbl"}},
+				Dimensions: ast.Dimensions{
+					ast.Dimension{Expr: &ast.FieldRef{Name: "year", StreamName: ast.DefaultStream}},
+				},
+			},
+		},
+		{
+			s:    `SELECT abs(f1) FILTER( where revenue > 100 ) FROM tbl`,
 			stmt: nil,
-			err:  "found \"FILTER\", expected FROM.",
+			err:  "FILTER is only supported for aggregate functions but found abs",
 		},
 		{
 			s:    `SELECT * FROM demo GROUP BY COUNTWINDOW(3,1) FILTER where revenue > 100`,
@@ -3731,6 +4459,43 @@ func TestParser_ParseJsonExpr(t *testing.T) {
 			},
 		},
 
+		{
+			s: `SELECT a[*]->temp * 2 FROM demo`,
+			stmt: &ast.SelectStatement{
+				Fields: []ast.Field{
+					{
+						Expr: &ast.ArrayMapExpr{
+							Array: &ast.FieldRef{Name: "a", StreamName: ast.DefaultStream},
+							Expr: &ast.BinaryExpr{
+								LHS: &ast.BinaryExpr{
+									LHS: &ast.ArrayElementRef{},
+									OP:  ast.ARROW,
+									RHS: &ast.JsonFieldRef{Name: "temp"},
+								},
+								OP:  ast.MUL,
+								RHS: &ast.IntegerLiteral{Val: 2},
+							},
+						},
+						Name:  "kuiper_field_0",
+						AName: "",
+					},
+				},
+				Sources: []ast.Source{&ast.Table{Name: "demo"}},
+			},
+		},
+
+		{
+			s:    `SELECT a[*]->temp + b[*]->temp FROM demo`,
+			stmt: nil,
+			err:  "[*] must refer to the same array in one expression, but found $$default.a and $$default.b.",
+		},
+
+		{
+			s:    `SELECT a[*b] FROM demo`,
+			stmt: nil,
+			err:  "Found \"b\", expected right bracket.",
+		},
+
 		{
 			s: `SELECT children->first[2] FROM demo`,
 			stmt: &ast.SelectStatement{
@@ -3945,6 +4710,57 @@ func TestParser_ParseJsonExpr(t *testing.T) {
 			},
 		},
 
+		{
+			s: `SELECT children[0:10:2] AS c, children[::3] AS d, children[1::k] AS e, children[:5:] AS f FROM demo`,
+			stmt: &ast.SelectStatement{
+				Fields: []ast.Field{
+					{
+						Expr: &ast.BinaryExpr{
+							LHS: &ast.FieldRef{Name: "children", StreamName: ast.DefaultStream},
+							OP:  ast.SUBSET,
+							RHS: &ast.ColonExpr{Start: &ast.IntegerLiteral{Val: 0}, End: &ast.IntegerLiteral{Val: 10}, Step: &ast.IntegerLiteral{Val: 2}},
+						},
+						Name:  "",
+						AName: "c",
+					},
+					{
+						Expr: &ast.BinaryExpr{
+							LHS: &ast.FieldRef{Name: "children", StreamName: ast.DefaultStream},
+							OP:  ast.SUBSET,
+							RHS: &ast.ColonExpr{Start: &ast.IntegerLiteral{Val: 0}, End: &ast.IntegerLiteral{Val: math.MinInt32}, Step: &ast.IntegerLiteral{Val: 3}},
+						},
+						Name:  "",
+						AName: "d",
+					},
+					{
+						Expr: &ast.BinaryExpr{
+							LHS: &ast.FieldRef{Name: "children", StreamName: ast.DefaultStream},
+							OP:  ast.SUBSET,
+							RHS: &ast.ColonExpr{Start: &ast.IntegerLiteral{Val: 1}, End: &ast.IntegerLiteral{Val: math.MinInt32}, Step: &ast.FieldRef{Name: "k", StreamName: ast.DefaultStream}},
+						},
+						Name:  "",
+						AName: "e",
+					},
+					{
+						Expr: &ast.BinaryExpr{
+							LHS: &ast.FieldRef{Name: "children", StreamName: ast.DefaultStream},
+							OP:  ast.SUBSET,
+							RHS: &ast.ColonExpr{Start: &ast.IntegerLiteral{Val: 0}, End: &ast.IntegerLiteral{Val: 5}},
+						},
+						Name:  "",
+						AName: "f",
+					},
+				},
+				Sources: []ast.Source{&ast.Table{Name: "demo"}},
+			},
+		},
+
+		{
+			s:    `SELECT children[0:10:2 AS c FROM demo`,
+			stmt: nil,
+			err:  "Found \"AS\", expected right bracket.",
+		},
+
 		{
 			s: `SELECT children[2:]->first AS c FROM demo`,
 			stmt: &ast.SelectStatement{
@@ -4172,6 +4988,48 @@ func TestParser_ParseJsonExpr(t *testing.T) {
 				Sources: []ast.Source{&ast.Table{Name: "demo"}},
 			},
 		},
+		{
+			s: `SELECT children[2 * index] FROM demo`,
+			stmt: &ast.SelectStatement{
+				Fields: []ast.Field{
+					{
+						Expr: &ast.BinaryExpr{
+							LHS: &ast.FieldRef{Name: "children", StreamName: ast.DefaultStream},
+							OP:  ast.SUBSET,
+							RHS: &ast.IndexExpr{Index: &ast.BinaryExpr{
+								LHS: &ast.IntegerLiteral{Val: 2},
+								OP:  ast.MUL,
+								RHS: &ast.FieldRef{Name: "index", StreamName: ast.DefaultStream},
+							}},
+						},
+						Name:  "kuiper_field_0",
+						AName: "",
+					},
+				},
+				Sources: []ast.Source{&ast.Table{Name: "demo"}},
+			},
+		},
+		{
+			s: `SELECT children[1 + index:] FROM demo`,
+			stmt: &ast.SelectStatement{
+				Fields: []ast.Field{
+					{
+						Expr: &ast.BinaryExpr{
+							LHS: &ast.FieldRef{Name: "children", StreamName: ast.DefaultStream},
+							OP:  ast.SUBSET,
+							RHS: &ast.ColonExpr{Start: &ast.BinaryExpr{
+								LHS: &ast.IntegerLiteral{Val: 1},
+								OP:  ast.ADD,
+								RHS: &ast.FieldRef{Name: "index", StreamName: ast.DefaultStream},
+							}, End: &ast.IntegerLiteral{Val: math.MinInt32}},
+						},
+						Name:  "kuiper_field_0",
+						AName: "",
+					},
+				},
+				Sources: []ast.Source{&ast.Table{Name: "demo"}},
+			},
+		},
 	}
 
 	fmt.Printf("The test bucket size is %d.\n\n", len(tests))
@@ -101,11 +101,22 @@ type ControlTuple interface {
 	ControlType() string
 }
 
+// OrderedRow is a row which carries the declared order of the output fields.
+// Consumers with fixed schema can use it to output the columns in order since the map is unordered. No built-in sink
+// reads it yet, the CSV format orders the columns by the sink fields property instead.
+type OrderedRow interface {
+	// FieldOrder returns the ordered field names of ToMap result. It returns nil if the order is not declared.
+	FieldOrder() []string
+	SetFieldOrder(keys []string)
+}
+
 // AffiliateRow part of other row types do help calculation of newly added cols
 type AffiliateRow struct {
 	lock     sync.RWMutex
 	CalCols  map[string]interface{} // mutable and must be cloned when broadcast
 	AliasMap map[string]interface{}
+	// The declared order of the output fields, it is immutable once set
+	fieldOrder []string
 }
 
 func (d *AffiliateRow) AppendAlias(key string, value interface{}) bool {
@@ -180,9 +191,22 @@ func (d *AffiliateRow) Clone() AffiliateRow {
 			nd.AliasMap[k] = v
 		}
 	}
+	nd.fieldOrder = d.fieldOrder
 	return *nd //nolint:govet
 }
 
+func (d *AffiliateRow) FieldOrder() []string {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	return d.fieldOrder
+}
+
+func (d *AffiliateRow) SetFieldOrder(keys []string) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.fieldOrder = keys
+}
+
 func (d *AffiliateRow) IsEmpty() bool {
 	d.lock.RLock()
 	defer d.lock.RUnlock()
@@ -376,8 +400,10 @@ type GroupedTuples struct {
 	Content []Row
 	*WindowRange
 	AffiliateRow
-	lock      sync.Mutex
-	cachedMap map[string]interface{} // clone of the row and cached for performance of toMap
+	// RollupCols are the group by columns which are rolled up in this subtotal group. Their values are always nil.
+	RollupCols []*ast.FieldRef
+	lock       sync.Mutex
+	cachedMap  map[string]interface{} // clone of the row and cached for performance of toMap
 }
 
 func (s *GroupedTuples) GetTracerCtx() api.StreamContext {
@@ -498,6 +524,10 @@ func (t *Tuple) ToMap() map[string]interface{} {
 
 func (t *Tuple) Meta(key, table string) (interface{}, bool) {
 	if key == "*" {
+		// meta(*) is always an object even if there is no metadata
+		if t.Metadata == nil {
+			return map[string]interface{}{}, true
+		}
 		return map[string]interface{}(t.Metadata), true
 	}
 	return t.Metadata.Value(key, table)
@@ -536,11 +566,39 @@ func (t *Tuple) FuncValue(key string) (interface{}, bool) {
 	switch key {
 	case "event_time":
 		return t.Timestamp.UnixMilli(), true
+	case "row":
+		return deepCopy(map[string]interface{}(t.Message)), true
 	default:
 		return nil, false
 	}
 }
 
+// deepCopy copies the maps and arrays of the value recursively so that the result can be modified safely
+func deepCopy(v interface{}) interface{} {
+	switch vt := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(vt))
+		for k, vv := range vt {
+			m[k] = deepCopy(vv)
+		}
+		return m
+	case []interface{}:
+		a := make([]interface{}, len(vt))
+		for i, vv := range vt {
+			a[i] = deepCopy(vv)
+		}
+		return a
+	case []map[string]interface{}:
+		a := make([]map[string]interface{}, len(vt))
+		for i, vv := range vt {
+			a[i] = deepCopy(vv).(map[string]interface{})
+		}
+		return a
+	default:
+		return v
+	}
+}
+
 func (t *Tuple) Pick(allWildcard bool, cols [][]string, wildcardEmitters map[string]bool, except []string, sendNil bool) {
 	// invalidate cache, will calculate again
 	t.cachedMap = nil
@@ -718,6 +776,9 @@ func (s *GroupedTuples) AggregateEval(expr ast.Expr, v CallValuer) []interface{}
 }
 
 func (s *GroupedTuples) Value(key, table string) (interface{}, bool) {
+	if s.isRollupCol(key, table) {
+		return nil, true
+	}
 	r, ok := s.AffiliateRow.Value(key, table)
 	if ok {
 		return r, ok
@@ -756,13 +817,31 @@ func (s *GroupedTuples) Clone() Row {
 		Content:      ts,
 		WindowRange:  s.WindowRange,
 		AffiliateRow: s.AffiliateRow.Clone(),
+		RollupCols:   s.RollupCols,
 	}
 	return c
 }
 
 func (s *GroupedTuples) Pick(allWildcard bool, cols [][]string, wildcardEmitters map[string]bool, except []string, sendNil bool) {
+	// invalidate cache, will calculate again
+	s.cachedMap = nil
 	cols = s.AffiliateRow.Pick(cols)
 	sc := s.Content[0].Clone()
 	sc.Pick(allWildcard, cols, wildcardEmitters, except, sendNil)
+	// The rolled up columns are always output as nil
+	for _, c := range s.RollupCols {
+		if _, ok := sc.Value(c.Name, ""); ok {
+			sc.Set(c.Name, nil)
+		}
+	}
 	s.Content[0] = sc
 }
+
+func (s *GroupedTuples) isRollupCol(key, table string) bool {
+	for _, c := range s.RollupCols {
+		if c.Name == key && (table == "" || c.StreamName == ast.DefaultStream || string(c.StreamName) == table) {
+			return true
+		}
+	}
+	return false
+}
@@ -21,6 +21,8 @@ import (
 	"sync"
 	"testing"
 
+	"github.com/stretchr/testify/require"
+
 	"github.com/lf-edge/ekuiper/v2/pkg/timex"
 )
 
@@ -228,3 +230,29 @@ func TestRow(t *testing.T) {
 		}
 	}
 }
+
+func TestTupleRowFuncValue(t *testing.T) {
+	msg := Message{
+		"status": "ok",
+		"a": map[string]interface{}{
+			"b": 3,
+			"c": []interface{}{1, map[string]interface{}{"d": true}},
+		},
+	}
+	tuple := &Tuple{Emitter: "test", Message: msg}
+	r, ok := tuple.FuncValue("row")
+	require.True(t, ok)
+	require.Equal(t, map[string]interface{}(msg), r)
+	// modifying the result does not affect the tuple
+	m := r.(map[string]interface{})
+	m["status"] = "failed"
+	m["a"].(map[string]interface{})["b"] = 4
+	m["a"].(map[string]interface{})["c"].([]interface{})[1].(map[string]interface{})["d"] = false
+	require.Equal(t, Message{
+		"status": "ok",
+		"a": map[string]interface{}{
+			"b": 3,
+			"c": []interface{}{1, map[string]interface{}{"d": true}},
+		},
+	}, tuple.Message)
+}
@@ -65,23 +65,122 @@ func (ms *MultiSorter) Less(i, j int) bool {
 		n := field.Uname
 		vp, _ := p[n]
 		vq, _ := q[n]
-		if vp == nil && vq != nil {
-			return false
-		} else if vp != nil && vq == nil {
-			return true
-		} else if vp == nil && vq == nil {
+		if vp == nil && vq == nil {
 			return false
 		}
-		switch {
-		case v.SimpleDataEval(vp, vq, ast.LT):
-			return field.Ascending
-		case v.SimpleDataEval(vq, vp, ast.LT):
-			return !field.Ascending
+		if less, ok := sortLess(v, field, vp, vq); ok {
+			return less
 		}
 	}
 	return false
 }
 
+// sortLess compares two values of a sort field. The second return value is false if they are equal.
+func sortLess(v *ValuerEval, field ast.SortField, vp, vq interface{}) (bool, bool) {
+	if vp == nil && vq != nil {
+		return field.NullsFirst, true
+	} else if vp != nil && vq == nil {
+		return !field.NullsFirst, true
+	} else if vp == nil && vq == nil {
+		return false, false
+	}
+	switch {
+	case v.SimpleDataEval(vp, vq, ast.LT):
+		return field.Ascending, true
+	case v.SimpleDataEval(vq, vp, ast.LT):
+		return !field.Ascending, true
+	}
+	return false, false
+}
+
+// sortAggArgs sorts the per row arguments of an ordered aggregate function like collect(a ORDER BY ts).
+// The sort keys of a field must be of the comparable types.
+func sortAggArgs(args []interface{}, fields ast.SortFields, data AggregateData, cv CallValuer) error {
+	keys := make([][]interface{}, len(fields))
+	for i, field := range fields {
+		keys[i] = data.AggregateEval(field.FieldExpr, cv)
+		t := ""
+		for _, k := range keys[i] {
+			if e, ok := k.(error); ok {
+				return e
+			}
+			if t == "" && k != nil {
+				t = fmt.Sprintf("%T", k)
+			}
+			if err := validate(t, k); err != nil {
+				return err
+			}
+		}
+	}
+	n := len(keys[0])
+	indexes := make([]int, n)
+	for i := range indexes {
+		indexes[i] = i
+	}
+	v := &ValuerEval{Valuer: MultiValuer(cv)}
+	sort.SliceStable(indexes, func(i, j int) bool {
+		for k, field := range fields {
+			if less, ok := sortLess(v, field, keys[k][indexes[i]], keys[k][indexes[j]]); ok {
+				return less
+			}
+		}
+		return false
+	})
+	for i, arg := range args {
+		vals, ok := arg.([]interface{})
+		if !ok || len(vals) != n {
+			continue
+		}
+		sorted := make([]interface{}, n)
+		for j, idx := range indexes {
+			sorted[j] = vals[idx]
+		}
+		args[i] = sorted
+	}
+	return nil
+}
+
+// distinctAggArgs drops the rows whose per row arguments are all the same as a previous row, so the first seen ones
+// are kept in order. The option arguments which are not per row like n of chunk(*, n) are untouched.
+func distinctAggArgs(args []interface{}) {
+	if len(args) == 0 {
+		return
+	}
+	rows, ok := args[0].([]interface{})
+	if !ok {
+		return
+	}
+	n := len(rows)
+	seen := make(map[string]struct{}, n)
+	kept := make([]int, 0, n)
+	for j := 0; j < n; j++ {
+		key := ""
+		for _, arg := range args {
+			if vals, ok := arg.([]interface{}); ok && len(vals) == n {
+				key += fmt.Sprintf("%[1]T(%[1]v),", vals[j])
+			}
+		}
+		if _, ok := seen[key]; !ok {
+			seen[key] = struct{}{}
+			kept = append(kept, j)
+		}
+	}
+	if len(kept) == n {
+		return
+	}
+	for i, arg := range args {
+		vals, ok := arg.([]interface{})
+		if !ok || len(vals) != n {
+			continue
+		}
+		picked := make([]interface{}, len(kept))
+		for j, idx := range kept {
+			picked[j] = vals[idx]
+		}
+		args[i] = picked
+	}
+}
+
 func (ms *MultiSorter) Swap(i, j int) {
 	ms.values[i], ms.values[j] = ms.values[j], ms.values[i]
 	ms.SortingData.Swap(i, j)
@@ -171,10 +171,20 @@ func validateExpr(expr ast.Expr, streamName []string) ast.Expr {
 	case *ast.ColonExpr:
 		e.Start = validateExpr(e.Start, streamName)
 		e.End = validateExpr(e.End, streamName)
+		if e.Step != nil {
+			e.Step = validateExpr(e.Step, streamName)
+		}
 		return e
 	case *ast.IndexExpr:
 		e.Index = validateExpr(e.Index, streamName)
 		return e
+	case *ast.CastExpr:
+		e.Expr = validateExpr(e.Expr, streamName)
+		return e
+	case *ast.DefaultExpr:
+		e.Expr = validateExpr(e.Expr, streamName)
+		e.Default = validateExpr(e.Default, streamName)
+		return e
 	case *ast.Call:
 		for i, arg := range e.Args {
 			e.Args[i] = validateExpr(arg, streamName)
@@ -187,6 +197,9 @@ func validateExpr(expr ast.Expr, streamName []string) ast.Expr {
 		if e.WhenExpr != nil {
 			e.WhenExpr = validateExpr(e.WhenExpr, streamName)
 		}
+		if e.FilterExpr != nil {
+			e.FilterExpr = validateExpr(e.FilterExpr, streamName)
+		}
 		return e
 	case *ast.BinaryExpr:
 		exp := ast.BinaryExpr{}
@@ -34,6 +34,8 @@ var (
 		"window_end":     true,
 		"event_time":     true,
 		"window_trigger": true,
+		"row":            true,
+		"window_index":   true,
 	}
 	// ImplicitStateFuncs is a set of functions that read/update global state implicitly.
 	ImplicitStateFuncs = map[string]bool{
@@ -42,6 +44,11 @@ var (
 		"last_agg_hit_time":  true,
 		"last_agg_hit_count": true,
 	}
+	// implicitEventTimeFuncs is a set of aggregate functions that are passed the event time of each tuple implicitly.
+	implicitEventTimeFuncs = map[string]bool{
+		"min_time": true,
+		"max_time": true,
+	}
 )
 
 /*
@@ -269,6 +276,14 @@ func (a *multiAggregateValuer) AliasValue(key string) (interface{}, bool) {
  * Eval Logics
  */
 
+func init() {
+	// the functions like array_find match the values with the same engine as the = operator
+	function.RegisterEqualFunc(func(a, b interface{}) bool {
+		r, ok := (&ValuerEval{}).SimpleDataEval(a, b, ast.EQ).(bool)
+		return ok && r
+	})
+}
+
 // Eval evaluates expr against a map.
 func Eval(expr ast.Expr, m Valuer) interface{} {
 	eval := ValuerEval{Valuer: m}
@@ -282,6 +297,12 @@ type ValuerEval struct {
 	// IntegerFloatDivision will set the eval system to treat
 	// a division between two integers as a floating point division.
 	IntegerFloatDivision bool
+
+	// LenientIndex makes an out of range array index return nil instead of an error
+	LenientIndex bool
+
+	// the current array element when evaluating ArrayMapExpr
+	element interface{}
 }
 
 // Eval evaluates an expression and returns a value.
@@ -303,6 +324,8 @@ func (v *ValuerEval) Eval(expr ast.Expr) interface{} {
 		return et.Val
 	case *ast.BooleanLiteral:
 		return et.Val
+	case *ast.NullLiteral:
+		return nil
 	case *ast.ColonExpr:
 		s, e := v.Eval(et.Start), v.Eval(et.End)
 		si, err := cast.ToInt(s, cast.CONVERT_SAMEKIND)
@@ -314,9 +337,32 @@ func (v *ValuerEval) Eval(expr ast.Expr) interface{} {
 			return fmt.Errorf("colon end %v is not int: %v", et.End, err)
 		}
 		return &BracketEvalResult{Start: si, End: ei}
+	case *ast.CastExpr:
+		val := v.Eval(et.Expr)
+		switch val.(type) {
+		case error, nil:
+			return val
+		}
+		r, _ := cast.ToType(val, et.Type.String())
+		return r
+	case *ast.DefaultExpr:
+		val := v.Eval(et.Expr)
+		if val == nil {
+			return v.Eval(et.Default)
+		}
+		return val
+	case *ast.ArrayMapExpr:
+		return v.evalArrayMap(et)
+	case *ast.ArrayElementRef:
+		return v.element
 	case *ast.IndexExpr:
 		i := v.Eval(et.Index)
-		ii, err := cast.ToInt(i, cast.CONVERT_SAMEKIND)
+		switch i.(type) {
+		case error, nil:
+			return i
+		}
+		// The index can be any expression, but it must be evaluated to an integer value
+		ii, err := cast.ToInt(i, cast.STRICT)
 		if err != nil {
 			return fmt.Errorf("index %v is not int: %v", et.Index, err)
 		}
@@ -341,6 +387,10 @@ func (v *ValuerEval) Eval(expr ast.Expr) interface{} {
 			if vv, ok := v.Valuer.(FuncValuer); ok {
 				val, ok := vv.FuncValue(et.Name)
 				if ok {
+					// window_start and window_end can be formatted by the layout arg
+					if len(et.Args) > 0 {
+						return v.formatWindowBoundary(val, et.Args[0])
+					}
 					return val
 				}
 			}
@@ -374,13 +424,38 @@ func (v *ValuerEval) Eval(expr ast.Expr) interface{} {
 					val, _ := valuer.Call(et.Name, et.FuncId, args)
 					return val
 				}
+				// the aggregate data of the group, only the rows satisfying the FILTER clause are kept
+				var aggData AggregateData
+				if aggreValuer, ok := valuer.(AggregateCallValuer); ok && ft == ast.FuncTypeAgg {
+					aggData = aggreValuer.GetAllTuples()
+					if et.FilterExpr != nil {
+						fd, err := newFilteredAggregateData(aggData, et.FilterExpr, aggreValuer.GetSingleCallValuer())
+						if err != nil {
+							return fmt.Errorf("call %s filter error: %v", et.Name, err)
+						}
+						aggData = fd
+					}
+				}
+				if _, ok := implicitEventTimeFuncs[et.Name]; ok {
+					if aggData != nil {
+						args = []interface{}{eventTimes(aggData)}
+					} else {
+						return fmt.Errorf("call %s error: %v", et.Name, "cannot get the tuples")
+					}
+				}
 				if len(et.Args) > 0 {
 					switch ft {
 					case ast.FuncTypeAgg:
 						args = make([]interface{}, len(et.Args))
 						for i, arg := range et.Args {
 							if aggreValuer, ok := valuer.(AggregateCallValuer); ok {
-								args[i] = aggreValuer.GetAllTuples().AggregateEval(arg, aggreValuer.GetSingleCallValuer())
+								r := aggData.AggregateEval(arg, aggreValuer.GetSingleCallValuer())
+								// The literal parameters after the first one are options such as n of chunk(*, n)
+								// Keep them available even if the group is empty
+								if i > 0 && len(r) == 0 && isLiteral(arg) {
+									r = []interface{}{v.Eval(arg)}
+								}
+								args[i] = r
 							} else {
 								args[i] = v.Eval(arg)
 								if _, ok := args[i].(error); ok {
@@ -388,6 +463,16 @@ func (v *ValuerEval) Eval(expr ast.Expr) interface{} {
 								}
 							}
 						}
+						if aggreValuer, ok := valuer.(AggregateCallValuer); ok {
+							if len(et.SortFields) > 0 {
+								if err := sortAggArgs(args, et.SortFields, aggData, aggreValuer.GetSingleCallValuer()); err != nil {
+									return fmt.Errorf("call %s order by error: %v", et.Name, err)
+								}
+							}
+							if et.Distinct {
+								distinctAggArgs(args)
+							}
+						}
 					case ast.FuncTypeScalar, ast.FuncTypeSrf:
 						args = make([]interface{}, len(et.Args))
 						for i, arg := range et.Args {
@@ -527,7 +612,7 @@ func (v *ValuerEval) Eval(expr ast.Expr) interface{} {
 		}
 		val := make(map[string]interface{})
 		for k, v := range al {
-			if !contains(et.Except, k) {
+			if !contains(et.Except, k) && !matchAny(et.ExceptMatching, k) {
 				val[k] = v
 			}
 		}
@@ -573,6 +658,31 @@ func (v *ValuerEval) Eval(expr ast.Expr) interface{} {
 
 func (v *ValuerEval) evalBinaryExpr(expr *ast.BinaryExpr) interface{} {
 	lhs := v.Eval(expr.LHS)
+	switch expr.OP {
+	case ast.IS, ast.ISNOT:
+		if e, ok := lhs.(error); ok {
+			return e
+		}
+		return (lhs == nil) == (expr.OP == ast.IS)
+	case ast.COALESCE:
+		// the right side is only evaluated if the left value is nil
+		if lhs != nil {
+			return lhs
+		}
+		return v.Eval(expr.RHS)
+	case ast.SAFE_ARROW:
+		// short circuit to nil without error if the left value is not an object
+		switch val := lhs.(type) {
+		case map[string]interface{}:
+			return v.evalJsonExpr(val, ast.ARROW, expr.RHS)
+		case Message:
+			return v.evalJsonExpr(map[string]interface{}(val), ast.ARROW, expr.RHS)
+		case error:
+			return val
+		default:
+			return nil
+		}
+	}
 	switch val := lhs.(type) {
 	case map[string]interface{}:
 		return v.evalJsonExpr(val, expr.OP, expr.RHS)
@@ -777,17 +887,75 @@ func (v *ValuerEval) evalJsonExpr(result interface{}, op ast.Token, expr ast.Exp
 	}
 }
 
+// matchAny returns whether the name matches any of the patterns
+func matchAny(patterns []*regexp.Regexp, n string) bool {
+	for _, re := range patterns {
+		if re.MatchString(n) {
+			return true
+		}
+	}
+	return false
+}
+
+func isLiteral(expr ast.Expr) bool {
+	switch expr.(type) {
+	case *ast.IntegerLiteral, *ast.NumberLiteral, *ast.StringLiteral, *ast.BooleanLiteral:
+		return true
+	default:
+		return false
+	}
+}
+
+// evalArrayMap evaluates the expression for each element of the array and returns the results as an array
+func (v *ValuerEval) evalArrayMap(expr *ast.ArrayMapExpr) interface{} {
+	arr := v.Eval(expr.Array)
+	switch at := arr.(type) {
+	case error:
+		return at
+	case nil:
+		return nil
+	}
+	if !isSliceOrArray(arr) {
+		return fmt.Errorf("[*] requires array but found %[1]T(%[1]v)", arr)
+	}
+	val := reflect.ValueOf(arr)
+	result := make([]interface{}, val.Len())
+	for i := 0; i < val.Len(); i++ {
+		ele := val.Index(i).Interface()
+		if ele == nil {
+			continue
+		}
+		ve := &ValuerEval{Valuer: v.Valuer, IntegerFloatDivision: v.IntegerFloatDivision, LenientIndex: v.LenientIndex, element: ele}
+		r := ve.Eval(expr.Expr)
+		if e, ok := r.(error); ok {
+			return fmt.Errorf("evaluate array element %d error: %v", i, e)
+		}
+		result[i] = r
+	}
+	return result
+}
+
 func (v *ValuerEval) subset(result interface{}, expr ast.Expr) interface{} {
 	val := reflect.ValueOf(result)
 	ber := v.Eval(expr)
+	switch ber.(type) {
+	case error, nil:
+		return ber
+	}
 	if berVal, ok1 := ber.(*BracketEvalResult); ok1 {
 		if berVal.isIndex() {
 			if 0 > berVal.Start {
 				if 0 > berVal.Start+val.Len() {
+					if v.LenientIndex {
+						return nil
+					}
 					return fmt.Errorf("out of index: %d of %d", berVal.Start, val.Len())
 				}
 				berVal.Start += val.Len()
 			} else if berVal.Start >= val.Len() {
+				if v.LenientIndex {
+					return nil
+				}
 				return fmt.Errorf("out of index: %d of %d", berVal.Start, val.Len())
 			}
 			return val.Index(berVal.Start).Interface()
@@ -812,6 +980,9 @@ func (v *ValuerEval) subset(result interface{}, expr ast.Expr) interface{} {
 			} else if berVal.Start >= berVal.End {
 				return fmt.Errorf("start cannot be greater than end. start:%d  end:%d", berVal.Start, berVal.End)
 			}
+			if c, ok := expr.(*ast.ColonExpr); ok && c.Step != nil {
+				return v.sliceWithStep(val, berVal.Start, berVal.End, c.Step)
+			}
 			return val.Slice(berVal.Start, berVal.End).Interface()
 		}
 	} else {
@@ -819,6 +990,26 @@ func (v *ValuerEval) subset(result interface{}, expr ast.Expr) interface{} {
 	}
 }
 
+// sliceWithStep returns every step-th element of the array in the range [start, end)
+func (v *ValuerEval) sliceWithStep(val reflect.Value, start, end int, stepExpr ast.Expr) interface{} {
+	s := v.Eval(stepExpr)
+	if e, ok := s.(error); ok {
+		return e
+	}
+	step, err := cast.ToInt(s, cast.CONVERT_SAMEKIND)
+	if err != nil {
+		return fmt.Errorf("colon step %v is not int: %v", stepExpr, err)
+	}
+	if step <= 0 {
+		return fmt.Errorf("colon step must be a positive integer but found %d", step)
+	}
+	r := reflect.MakeSlice(reflect.SliceOf(val.Type().Elem()), 0, (end-start+step-1)/step)
+	for i := start; i < end; i += step {
+		r = reflect.Append(r, val.Index(i))
+	}
+	return r.Interface()
+}
+
 // SimpleDataEval lhs and rhs are non-nil
 func (v *ValuerEval) SimpleDataEval(lhs, rhs any, op ast.Token) any {
 	if lhs == nil || rhs == nil {
@@ -1244,6 +1435,30 @@ func invalidOpError(lhs interface{}, op ast.Token, rhs interface{}) error {
 	return fmt.Errorf("invalid operation %[1]T(%[1]v) %s %[3]T(%[3]v)", lhs, ast.Tokens[op], rhs)
 }
 
+// layoutRefTime differs from the reference time of the Go layout in every element so that a layout without any
+// time element is formatted to itself
+var layoutRefTime = time.Date(1999, 11, 30, 22, 33, 44, 0, time.FixedZone("", 3*3600+30*60))
+
+// formatWindowBoundary formats the window boundary in epoch milliseconds with the Go layout
+func (v *ValuerEval) formatWindowBoundary(val interface{}, layoutExpr ast.Expr) interface{} {
+	lv := v.Eval(layoutExpr)
+	if e, ok := lv.(error); ok {
+		return e
+	}
+	layout, ok := lv.(string)
+	if !ok {
+		return fmt.Errorf("the layout must be a string but found %[1]T(%[1]v)", lv)
+	}
+	if layout == "" || layoutRefTime.Format(layout) == layout {
+		return fmt.Errorf("invalid layout %q which has no time element", layout)
+	}
+	ms, err := cast.ToInt64(val, cast.CONVERT_SAMEKIND)
+	if err != nil {
+		return err
+	}
+	return cast.TimeFromUnixMilli(ms).Format(layout)
+}
+
 func convertNum(para interface{}) interface{} {
 	if isInt(para) {
 		// Already check type of para so that there will be no error, just ignore error
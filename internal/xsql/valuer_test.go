@@ -395,6 +395,60 @@ func TestArray(t *testing.T) {
 	}
 }
 
+func TestArrayFind(t *testing.T) {
+	testTime, _ := cast.InterfaceToTime(1541152488442, "")
+	m := map[string]interface{}{
+		"a": []interface{}{
+			map[string]interface{}{"id": "t1", "v": 20.5},
+			map[string]interface{}{"id": int64(2), "v": 21},
+			map[string]interface{}{"id": 2.5, "v": 22},
+			map[string]interface{}{"id": testTime, "v": 23},
+		},
+	}
+	tests := []struct {
+		sql string
+		r   interface{}
+	}{
+		{
+			sql: `select a[1]->id = 2.0 as t from src`,
+			r:   true,
+		},
+		{
+			sql: `select array_find(a, "id", 2.0) as t from src`,
+			r:   map[string]interface{}{"id": int64(2), "v": 21},
+		},
+		{
+			sql: `select array_find(a, "id", 2.5) as t from src`,
+			r:   map[string]interface{}{"id": 2.5, "v": 22},
+		},
+		{
+			sql: `select a[3]->id = 1541152488442 as t from src`,
+			r:   true,
+		},
+		{
+			sql: `select array_find(a, "id", 1541152488442) as t from src`,
+			r:   map[string]interface{}{"id": testTime, "v": 23},
+		},
+		{
+			sql: `select array_find(a, "id", "t3") as t from src`,
+			r:   nil,
+		},
+	}
+	fv, _ := NewFunctionValuersForOp(nil)
+	for i, tt := range tests {
+		stmt, err := NewParser(strings.NewReader(tt.sql)).Parse()
+		if err != nil {
+			t.Fatal(err)
+		}
+		tuple := &Tuple{Emitter: "src", Message: m, Timestamp: timex.GetNow(), Metadata: nil}
+		ve := &ValuerEval{Valuer: MultiValuer(tuple, fv)}
+		result := ve.Eval(stmt.Fields[0].Expr)
+		if !reflect.DeepEqual(tt.r, result) {
+			t.Errorf("%d. %s\nstmt mismatch:\n\nexp=%#v\n\ngot=%#v\n\n", i, tt.sql, tt.r, result)
+		}
+	}
+}
+
 func TestLike(t *testing.T) {
 	data := []struct {
 		m Message
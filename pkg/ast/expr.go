@@ -17,6 +17,7 @@ package ast
 import (
 	"errors"
 	"fmt"
+	"regexp"
 	"strconv"
 )
 
@@ -57,9 +58,11 @@ type BracketExpr struct {
 	Expr Expr
 }
 
+// ColonExpr is the slice of an array such as a[start:end:step]. The Step is nil if omitted which means 1.
 type ColonExpr struct {
 	Start Expr
 	End   Expr
+	Step  Expr
 }
 
 func (c *ColonExpr) ValidateExpr() error {
@@ -70,6 +73,9 @@ func (c *ColonExpr) ValidateExpr() error {
 			}
 		}
 	}
+	if step, ok := c.Step.(*IntegerLiteral); ok && step.Val <= 0 {
+		return fmt.Errorf("colon step must be a positive integer but found %d", step.Val)
+	}
 	return nil
 }
 
@@ -77,6 +83,30 @@ type IndexExpr struct {
 	Index Expr
 }
 
+// CastExpr coerces the value of the Expr to the Type such as a::bigint.
+// The Type is also used as the type annotation of the field.
+type CastExpr struct {
+	Expr Expr
+	Type DataType
+}
+
+// DefaultExpr evaluates the Default if the safe navigation Expr such as a?->b DEFAULT 0 returns null.
+// The Default is evaluated lazily only when the navigation fails.
+type DefaultExpr struct {
+	Expr    Expr
+	Default Expr
+}
+
+// ArrayMapExpr applies the Expr to each element of the Array such as a[*]->b * 2.
+// In the Expr, the current element is referred by ArrayElementRef.
+type ArrayMapExpr struct {
+	Array Expr
+	Expr  Expr
+}
+
+// ArrayElementRef refers to the current element of the array in ArrayMapExpr. It is parsed from [*].
+type ArrayElementRef struct{}
+
 type BooleanLiteral struct {
 	Val bool
 }
@@ -97,10 +127,15 @@ type NumberLiteral struct {
 	Val float64
 }
 
+// NullLiteral is the explicit NULL keyword which is evaluated to nil
+type NullLiteral struct{}
+
 type Wildcard struct {
 	Token   Token
 	Replace []Field
 	Except  []string
+	// ExceptMatching are the patterns of EXCEPT MATCHING. The fields whose names fully match any of them are excluded
+	ExceptMatching []*regexp.Regexp
 }
 
 func (pe *ParenExpr) expr() {}
@@ -147,6 +182,9 @@ func (be *ColonExpr) String() string {
 		}
 		e += "end:{ " + be.End.String() + " }"
 	}
+	if be.Step != nil {
+		e += ", step:{ " + be.Step.String() + " }"
+	}
 	return "ColonExpr:{ " + s + e + " }"
 }
 
@@ -160,6 +198,30 @@ func (be *IndexExpr) String() string {
 	return i
 }
 
+func (c *CastExpr) expr() {}
+func (c *CastExpr) node() {}
+func (c *CastExpr) String() string {
+	return "castExpr:{ " + c.Expr.String() + "::" + c.Type.String() + " }"
+}
+
+func (d *DefaultExpr) expr() {}
+func (d *DefaultExpr) node() {}
+func (d *DefaultExpr) String() string {
+	return "defaultExpr:{ " + d.Expr.String() + " DEFAULT " + d.Default.String() + " }"
+}
+
+func (a *ArrayMapExpr) expr() {}
+func (a *ArrayMapExpr) node() {}
+func (a *ArrayMapExpr) String() string {
+	return "arrayMapExpr:{ array:{ " + a.Array.String() + " }, expr:{ " + a.Expr.String() + " } }"
+}
+
+func (a *ArrayElementRef) expr() {}
+func (a *ArrayElementRef) node() {}
+func (a *ArrayElementRef) String() string {
+	return "[*]"
+}
+
 func (w *Wildcard) expr() {}
 func (w *Wildcard) node() {}
 func (w *Wildcard) String() string {
@@ -194,6 +256,13 @@ func (nl *NumberLiteral) String() string {
 	return fmt.Sprintf("%f", nl.Val)
 }
 
+func (nl *NullLiteral) expr()    {}
+func (nl *NullLiteral) literal() {}
+func (nl *NullLiteral) node()    {}
+func (nl *NullLiteral) String() string {
+	return Tokens[NULL]
+}
+
 func (sl *StringLiteral) expr()    {}
 func (sl *StringLiteral) literal() {}
 func (sl *StringLiteral) node()    {}
@@ -227,8 +296,14 @@ type Call struct {
 	Partition   *PartitionExpr
 	WhenExpr    Expr
 
-	// This is used for window functions.
+	// This is used for window functions and ordered aggregate functions such as collect(a ORDER BY ts).
 	SortFields SortFields
+	// FilterExpr is the predicate of an aggregate function such as count(*) FILTER (WHERE a > 1).
+	// Only the rows which satisfy it are aggregated.
+	FilterExpr Expr
+	// Distinct drops the duplicated arguments of an aggregate function such as collect(DISTINCT a).
+	// The first seen ones are kept after sorting by SortFields.
+	Distinct bool
 }
 
 func (c *Call) expr()    {}
@@ -250,6 +325,12 @@ func (c *Call) String() string {
 	if c.WhenExpr != nil {
 		when += ", when:{ " + c.WhenExpr.String() + " }"
 	}
+	if c.FilterExpr != nil {
+		when += ", filter:{ " + c.FilterExpr.String() + " }"
+	}
+	if c.Distinct {
+		when += ", distinct:true"
+	}
 	return "Call:{ name:" + c.Name + args + when + " }"
 }
 
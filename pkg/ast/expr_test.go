@@ -249,3 +249,39 @@ func Test_exprStringPlan(t *testing.T) {
 		}
 	}
 }
+
+func TestColonExprValidate(t *testing.T) {
+	tests := []struct {
+		e   *ColonExpr
+		err string
+	}{
+		{
+			e: &ColonExpr{Start: &IntegerLiteral{Val: 0}, End: &IntegerLiteral{Val: 10}, Step: &IntegerLiteral{Val: 2}},
+		},
+		{
+			e: &ColonExpr{Start: &IntegerLiteral{Val: 0}, End: &IntegerLiteral{Val: 10}, Step: &FieldRef{Name: "k"}},
+		},
+		{
+			e:   &ColonExpr{Start: &IntegerLiteral{Val: 0}, End: &IntegerLiteral{Val: 10}, Step: &IntegerLiteral{Val: 0}},
+			err: "colon step must be a positive integer but found 0",
+		},
+		{
+			e:   &ColonExpr{Start: &IntegerLiteral{Val: 0}, End: &IntegerLiteral{Val: 10}, Step: &IntegerLiteral{Val: -1}},
+			err: "colon step must be a positive integer but found -1",
+		},
+		{
+			e:   &ColonExpr{Start: &IntegerLiteral{Val: 3}, End: &IntegerLiteral{Val: 1}},
+			err: "colon start value can't be greater than end value",
+		},
+	}
+	for i, tt := range tests {
+		err := tt.e.ValidateExpr()
+		if tt.err == "" {
+			if err != nil {
+				t.Errorf("case %d: expect no error but got %v", i, err)
+			}
+		} else if err == nil || err.Error() != tt.err {
+			t.Errorf("case %d: expect error %s but got %v", i, tt.err, err)
+		}
+	}
+}
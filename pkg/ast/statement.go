@@ -22,6 +22,8 @@ type Statement interface {
 }
 
 type SelectStatement struct {
+	// Distinct drops the duplicated rows of the projected output such as SELECT DISTINCT a, b
+	Distinct   bool
 	Fields     Fields
 	Sources    Sources
 	Joins      Joins
@@ -59,10 +61,12 @@ func (f Fields) Less(i int, j int) bool {
 }
 
 type Field struct {
-	Name      string
-	AName     string
-	Expr      Expr
-	Invisible bool
+	Name  string
+	AName string
+	// NestedAlias is set for a dotted alias such as `a AS payload.temp` to build the nested output object
+	NestedAlias bool
+	Expr        Expr
+	Invisible   bool
 	Node
 }
 
@@ -153,6 +157,10 @@ func (j Joins) node() {}
 
 type Dimension struct {
 	Expr Expr
+	// Rollup indicates the dimension is in the ROLLUP list to generate subtotal groups
+	Rollup bool
+	// GroupingSets marks which sets of GROUPING SETS include the dimension. Its length is the number of the sets.
+	GroupingSets []bool
 
 	Node
 }
@@ -228,6 +236,7 @@ type SortField struct {
 	StreamName StreamName
 	Uname      string // unique name of a field
 	Ascending  bool
+	NullsFirst bool
 	FieldExpr  Expr
 
 	Expr
@@ -238,7 +247,11 @@ func (sf *SortField) String() string {
 	if sf.FieldExpr != nil {
 		fe += ", fieldExpr:{ " + sf.FieldExpr.String() + " }"
 	}
-	return "sortField:{ name:" + sf.Name + ", ascending:" + strconv.FormatBool(sf.Ascending) + fe + " }"
+	nf := ""
+	if sf.NullsFirst {
+		nf = ", nullsFirst:true"
+	}
+	return "sortField:{ name:" + sf.Name + ", ascending:" + strconv.FormatBool(sf.Ascending) + nf + fe + " }"
 }
 
 func (wd *Window) String() string {
@@ -56,32 +56,37 @@ const (
 	GT  // >
 	GTE // >=
 
-	SUBSET //[
-	ARROW  //->
-	IN     // IN
-	NOT    // NOT
-	NOTIN  // NOT
+	SUBSET     //[
+	ARROW      //->
+	SAFE_ARROW //?->
+	COALESCE   //??
+	IN         // IN
+	NOT        // NOT
+	NOTIN      // NOT
 	BETWEEN
 	NOTBETWEEN
 	LIKE
 	NOTLIKE
 	REPLACE
 	EXCEPT
+	IS
+	ISNOT
 
 	operatorEnd
 
 	// Misc characters
-	ASTERISK  // *
-	COMMA     // ,
-	LPAREN    // (
-	RPAREN    // )
-	LBRACKET  //[
-	RBRACKET  //]
-	HASH      // #
-	DOT       // .
-	COLON     //:
-	SEMICOLON //;
-	COLSEP    //\007
+	ASTERISK    // *
+	COMMA       // ,
+	LPAREN      // (
+	RPAREN      // )
+	LBRACKET    //[
+	RBRACKET    //]
+	HASH        // #
+	DOT         // .
+	COLON       //:
+	DOUBLECOLON //::
+	SEMICOLON   //;
+	COLSEP      //\007
 
 	// Keywords
 	SELECT
@@ -110,9 +115,11 @@ const (
 	OVER
 	PARTITION
 	INVISIBLE
+	ROLLUP
 
 	TRUE
 	FALSE
+	NULL
 
 	DD
 	HH
@@ -148,22 +155,25 @@ var Tokens = []string{
 	GT:  ">",
 	GTE: ">=",
 
-	SUBSET: "[]",
-	ARROW:  "->",
-	IN:     "IN",
+	SUBSET:     "[]",
+	ARROW:      "->",
+	SAFE_ARROW: "?->",
+	COALESCE:   "??",
+	IN:         "IN",
 
 	ASTERISK: "*",
 	COMMA:    ",",
 
-	LPAREN:    "(",
-	RPAREN:    ")",
-	LBRACKET:  "[",
-	RBRACKET:  "]",
-	HASH:      "#",
-	DOT:       ".",
-	SEMICOLON: ";",
-	COLON:     ":",
-	COLSEP:    "\007",
+	LPAREN:      "(",
+	RPAREN:      ")",
+	LBRACKET:    "[",
+	RBRACKET:    "]",
+	HASH:        "#",
+	DOT:         ".",
+	SEMICOLON:   ";",
+	COLON:       ":",
+	DOUBLECOLON: "::",
+	COLSEP:      "\007",
 
 	SELECT:    "SELECT",
 	FROM:      "FROM",
@@ -188,6 +198,7 @@ var Tokens = []string{
 	OVER:      "OVER",
 	PARTITION: "PARTITION",
 	INVISIBLE: "INVISIBLE",
+	ROLLUP:    "ROLLUP",
 
 	AND:        "AND",
 	OR:         "OR",
@@ -200,6 +211,9 @@ var Tokens = []string{
 	NOTLIKE:    "NOT LIKE",
 	REPLACE:    "REPLACE",
 	EXCEPT:     "EXCEPT",
+	IS:         "IS",
+	ISNOT:      "IS NOT",
+	NULL:       "NULL",
 
 	DD: "DD",
 	HH: "HH",
@@ -306,12 +320,14 @@ func (tok Token) Precedence() int {
 		return 1
 	case AND:
 		return 2
-	case EQ, NEQ, LT, LTE, GT, GTE, IN, NOTIN, BETWEEN, NOTBETWEEN, LIKE, NOTLIKE:
+	case EQ, NEQ, LT, LTE, GT, GTE, IN, NOTIN, BETWEEN, NOTBETWEEN, LIKE, NOTLIKE, IS, ISNOT:
 		return 3
-	case ADD, SUB, BITWISE_OR, BITWISE_XOR:
+	case COALESCE:
 		return 4
-	case MUL, DIV, MOD, BITWISE_AND, SUBSET, ARROW, DOT:
+	case ADD, SUB, BITWISE_OR, BITWISE_XOR:
 		return 5
+	case MUL, DIV, MOD, BITWISE_AND, SUBSET, ARROW, SAFE_ARROW, DOT:
+		return 6
 	}
 	return 0
 }
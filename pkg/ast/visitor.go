@@ -115,6 +115,10 @@ func Walk(v Visitor, node Node) {
 			Walk(v, n.WhenExpr)
 		}
 
+		if n.FilterExpr != nil {
+			Walk(v, n.FilterExpr)
+		}
+
 	case *ParenExpr:
 		Walk(v, n.Expr)
 
@@ -127,10 +131,22 @@ func Walk(v Visitor, node Node) {
 	case *ColonExpr:
 		Walk(v, n.Start)
 		Walk(v, n.End)
+		Walk(v, n.Step)
 
 	case *IndexExpr:
 		Walk(v, n.Index)
 
+	case *CastExpr:
+		Walk(v, n.Expr)
+
+	case *DefaultExpr:
+		Walk(v, n.Expr)
+		Walk(v, n.Default)
+
+	case *ArrayMapExpr:
+		Walk(v, n.Array)
+		Walk(v, n.Expr)
+
 	case *CaseExpr:
 		Walk(v, n.Value)
 		for _, w := range n.WhenClauses {